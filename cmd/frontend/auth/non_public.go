@@ -25,6 +25,11 @@ var RequireAuthMiddleware = &Middleware{
 			// If an anonymous user tries to access an API endpoint that requires authentication,
 			// prevent access.
 			if !actor.FromContext(r.Context()).IsAuthenticated() && !AllowAnonymousRequest(r) {
+				if strings.HasPrefix(r.URL.Path, "/.api/git/") {
+					// Let git clients prompt for credentials (an access token).
+					w.Header().Set("WWW-Authenticate", `Basic realm="Sourcegraph"`)
+				}
+
 				// Report HTTP 401 Unauthorized for API requests.
 				http.Error(w, "Private mode requires authentication.", http.StatusUnauthorized)
 				return
@@ -96,6 +101,12 @@ func AllowAnonymousRequest(req *http.Request) bool {
 		return true
 	}
 
+	// Webhooks are sent by code hosts, which authenticate them with the
+	// webhook secret of an external service instead (see serveWebhook).
+	if strings.HasPrefix(req.URL.Path, "/.api/webhooks/") {
+		return true
+	}
+
 	apiRouteName := matchedRouteName(req, router.Router())
 	if apiRouteName == router.UI {
 		// Test against UI router. (Some of its handlers inject private data into the title or meta tags.)
@@ -0,0 +1,40 @@
+package authz
+
+import (
+	"sync"
+	"time"
+)
+
+// PermsSyncer syncs the repository permissions of users in the background and stores them (see
+// db.UserPermissions), so that they need not be fetched from the code hosts whenever repositories
+// are filtered for a user.
+type PermsSyncer interface {
+	// RequestSync requests that the repository permissions of the user with the given ID be synced
+	// soon (e.g., because the user just signed in). It must not block.
+	RequestSync(userID int32)
+
+	// Interval returns the interval after which a user's repository permissions are synced again.
+	Interval() time.Duration
+}
+
+var (
+	permsSyncer   PermsSyncer
+	permsSyncerMu sync.RWMutex
+)
+
+// SetPermsSyncer sets the syncer of users' repository permissions. If it is nil (the default),
+// permissions are not synced in the background and are fetched from the authz providers when
+// needed. It is concurrency-safe.
+func SetPermsSyncer(s PermsSyncer) {
+	permsSyncerMu.Lock()
+	defer permsSyncerMu.Unlock()
+	permsSyncer = s
+}
+
+// GetPermsSyncer returns the syncer of users' repository permissions, or nil if there is none. It is
+// concurrency-safe.
+func GetPermsSyncer() PermsSyncer {
+	permsSyncerMu.RLock()
+	defer permsSyncerMu.RUnlock()
+	return permsSyncer
+}
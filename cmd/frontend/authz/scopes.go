@@ -2,12 +2,50 @@ package authz
 
 const (
 	// Access token scopes.
-	ScopeUserAll       = "user:all"        // Full control of all resources accessible to the user account.
-	ScopeSiteAdminSudo = "site-admin:sudo" // Ability to perform any action as any other user.
+	ScopeUserAll               = "user:all"                // Full control of all resources accessible to the user account.
+	ScopeUserRead              = "user:read"               // Read-only access to all resources accessible to the user account (such as searching and browsing repositories).
+	ScopeSettingsWrite         = "settings:write"          // Ability to edit settings (and saved searches) that the user can administer.
+	ScopeSavedSearchesWrite    = "saved-searches:write"    // Ability to create, update, and delete saved searches.
+	ScopeExtensionsPublish     = "extensions:publish"      // Ability to create, update, publish, and delete extensions in the extension registry.
+	ScopeExternalServicesWrite = "external-services:write" // Ability to add, update, and delete external services (if the user is a site admin).
+	ScopeSiteAdminSudo         = "site-admin:sudo"         // Ability to perform any action as any other user.
 )
 
 // AllScopes is a list of all known access token scopes.
 var AllScopes = []string{
 	ScopeUserAll,
+	ScopeUserRead,
+	ScopeSettingsWrite,
+	ScopeSavedSearchesWrite,
+	ScopeExtensionsPublish,
+	ScopeExternalServicesWrite,
 	ScopeSiteAdminSudo,
 }
+
+// UserScopes is the list of access token scopes that grant access to the subject user's account
+// (i.e., all scopes except "site-admin:sudo"). Every access token must have at least one of these
+// scopes.
+var UserScopes = []string{
+	ScopeUserAll,
+	ScopeUserRead,
+	ScopeSettingsWrite,
+	ScopeSavedSearchesWrite,
+	ScopeExtensionsPublish,
+	ScopeExternalServicesWrite,
+}
+
+// RestrictedScopes returns the scopes that an actor authenticated by an access token with the given
+// scopes is restricted to, or nil if the access token has the "user:all" scope (and the actor is
+// therefore not restricted).
+func RestrictedScopes(tokenScopes []string) []string {
+	restricted := []string{}
+	for _, scope := range tokenScopes {
+		if scope == ScopeUserAll {
+			return nil
+		}
+		if scope != ScopeSiteAdminSudo {
+			restricted = append(restricted, scope)
+		}
+	}
+	return restricted
+}
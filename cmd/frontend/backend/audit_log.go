@@ -0,0 +1,34 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/requestclient"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// LogAuditEvent records a security-sensitive action performed by the current actor in the audit
+// log, along with the network address of the client that performed it. The diff (if any) describes
+// the changes made to the target (see auditlog.DiffJSONC).
+//
+// It must be called after the action succeeded. Failure to record the entry does not undo the
+// action, so errors are logged instead of returned.
+func LogAuditEvent(ctx context.Context, action, targetType, targetID string, diff json.RawMessage) {
+	e := db.AuditLogEntry{
+		ActorUserID: actor.FromContext(ctx).UID,
+		Action:      action,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		Diff:        diff,
+	}
+	if client := requestclient.FromContext(ctx); client != nil {
+		e.IP = client.IP
+		e.ForwardedFor = client.ForwardedFor
+	}
+	if err := db.AuditLog.Create(ctx, &e); err != nil {
+		log15.Error("Unable to record action in audit log.", "action", action, "targetType", targetType, "targetID", targetID, "actor", e.ActorUserID, "error", err)
+	}
+}
@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// The actions that are recorded in the audit log.
+const (
+	AuditActionSiteConfigUpdate      = "site_config.update"      // the site or critical configuration was changed
+	AuditActionGlobalSettingsUpdate  = "global_settings.update"  // the global settings were changed
+	AuditActionAccessTokenCreate     = "access_token.create"     // an access token was created
+	AuditActionAccessTokenDelete     = "access_token.delete"     // an access token was deleted
+	AuditActionSiteAdminGrant        = "site_admin.grant"        // a user was promoted to site admin
+	AuditActionSiteAdminRevoke       = "site_admin.revoke"       // a user's site admin privileges were revoked
+	AuditActionExternalServiceCreate = "external_service.create" // an external service was added
+	AuditActionExternalServiceUpdate = "external_service.update" // an external service was modified
+	AuditActionExternalServiceDelete = "external_service.delete" // an external service was deleted
+)
+
+// AuditActions lists all actions that are recorded in the audit log.
+var AuditActions = []string{
+	AuditActionSiteConfigUpdate,
+	AuditActionGlobalSettingsUpdate,
+	AuditActionAccessTokenCreate,
+	AuditActionAccessTokenDelete,
+	AuditActionSiteAdminGrant,
+	AuditActionSiteAdminRevoke,
+	AuditActionExternalServiceCreate,
+	AuditActionExternalServiceUpdate,
+	AuditActionExternalServiceDelete,
+}
+
+// The types of targets of audit log entries.
+const (
+	AuditTargetSiteConfig      = "site_config"      // the target ID is "critical" or "site"
+	AuditTargetSettings        = "settings"         // the target ID is the settings subject ("global")
+	AuditTargetUser            = "user"             // the target ID is the user ID
+	AuditTargetAccessToken     = "access_token"     // the target ID is the access token ID
+	AuditTargetExternalService = "external_service" // the target ID is the external service ID
+)
+
+// An AuditLogEntry is a record of a security-sensitive action that was performed on the site.
+type AuditLogEntry struct {
+	ID           int64
+	CreatedAt    time.Time
+	ActorUserID  int32  // the user who performed the action, or 0 for internal or anonymous actors
+	Action       string // one of the AuditAction* values
+	TargetType   string // one of the AuditTarget* values
+	TargetID     string
+	IP           string          // the IP address of the client that performed the action
+	ForwardedFor string          // the X-Forwarded-For HTTP request header value of the client
+	Diff         json.RawMessage // the changes made to the target (if any), as a JSON array
+}
+
+type auditLog struct{}
+
+// Create records an entry in the audit log. The ID and CreatedAt fields of the entry are ignored.
+//
+// Audit log entries can't be modified after they are created.
+func (*auditLog) Create(ctx context.Context, e *AuditLogEntry) error {
+	if Mocks.AuditLog.Create != nil {
+		return Mocks.AuditLog.Create(e)
+	}
+
+	var actorUserID *int32
+	if e.ActorUserID != 0 {
+		actorUserID = &e.ActorUserID
+	}
+	var diff *string
+	if e.Diff != nil {
+		s := string(e.Diff)
+		diff = &s
+	}
+	_, err := dbconn.Global.ExecContext(ctx,
+		"INSERT INTO audit_log(actor_user_id, action, target_type, target_id, ip, forwarded_for, diff) VALUES($1, $2, $3, $4, $5, $6, $7)",
+		actorUserID, e.Action, e.TargetType, e.TargetID, e.IP, e.ForwardedFor, diff,
+	)
+	return err
+}
+
+// AuditLogListOptions contains options for listing audit log entries.
+type AuditLogListOptions struct {
+	ActorUserID   int32  // only list entries of actions performed by this user
+	Action        string // only list entries with this action
+	TargetType    string // only list entries with this target type
+	TargetID      string // only list entries with this target ID
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	*LimitOffset
+}
+
+func (o AuditLogListOptions) sqlConditions() []*sqlf.Query {
+	conds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if o.ActorUserID != 0 {
+		conds = append(conds, sqlf.Sprintf("actor_user_id=%d", o.ActorUserID))
+	}
+	if o.Action != "" {
+		conds = append(conds, sqlf.Sprintf("action=%s", o.Action))
+	}
+	if o.TargetType != "" {
+		conds = append(conds, sqlf.Sprintf("target_type=%s", o.TargetType))
+	}
+	if o.TargetID != "" {
+		conds = append(conds, sqlf.Sprintf("target_id=%s", o.TargetID))
+	}
+	if o.CreatedAfter != nil {
+		conds = append(conds, sqlf.Sprintf("created_at>%d", o.CreatedAfter))
+	}
+	if o.CreatedBefore != nil {
+		conds = append(conds, sqlf.Sprintf("created_at<%d", o.CreatedBefore))
+	}
+	return conds
+}
+
+// List lists the audit log entries that satisfy the options, most recent first.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (*auditLog) List(ctx context.Context, opt AuditLogListOptions) ([]*AuditLogEntry, error) {
+	if Mocks.AuditLog.List != nil {
+		return Mocks.AuditLog.List(opt)
+	}
+
+	q := sqlf.Sprintf(`
+SELECT id, created_at, actor_user_id, action, target_type, target_id, ip, forwarded_for, diff FROM audit_log
+WHERE (%s)
+ORDER BY id DESC
+%s`,
+		sqlf.Join(opt.sqlConditions(), ") AND ("),
+		opt.LimitOffset.SQL(),
+	)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*AuditLogEntry
+	for rows.Next() {
+		var (
+			e           AuditLogEntry
+			actorUserID *int32
+			diff        *[]byte
+		)
+		if err := rows.Scan(&e.ID, &e.CreatedAt, &actorUserID, &e.Action, &e.TargetType, &e.TargetID, &e.IP, &e.ForwardedFor, &diff); err != nil {
+			return nil, err
+		}
+		if actorUserID != nil {
+			e.ActorUserID = *actorUserID
+		}
+		if diff != nil {
+			e.Diff = json.RawMessage(*diff)
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// Count counts the audit log entries that satisfy the options (ignoring limit and offset).
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (*auditLog) Count(ctx context.Context, opt AuditLogListOptions) (int, error) {
+	if Mocks.AuditLog.Count != nil {
+		return Mocks.AuditLog.Count(opt)
+	}
+
+	q := sqlf.Sprintf("SELECT COUNT(*) FROM audit_log WHERE (%s)", sqlf.Join(opt.sqlConditions(), ") AND ("))
+	var count int
+	if err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// DeleteCreatedBefore deletes the audit log entries that were created before t. It is used to
+// enforce the audit log retention period.
+func (*auditLog) DeleteCreatedBefore(ctx context.Context, t time.Time) error {
+	_, err := dbconn.Global.ExecContext(ctx, "DELETE FROM audit_log WHERE created_at < $1", t)
+	return err
+}
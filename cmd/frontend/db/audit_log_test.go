@@ -0,0 +1,83 @@
+package db
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestAuditLog(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	entries := []*AuditLogEntry{
+		{ActorUserID: 1, Action: AuditActionSiteConfigUpdate, TargetType: AuditTargetSiteConfig, TargetID: "site", IP: "127.0.0.1", Diff: json.RawMessage(`[{"path":["a"],"before":1,"after":2}]`)},
+		{ActorUserID: 2, Action: AuditActionSiteAdminGrant, TargetType: AuditTargetUser, TargetID: "3", IP: "127.0.0.2", ForwardedFor: "10.0.0.1"},
+		{Action: AuditActionAccessTokenDelete, TargetType: AuditTargetAccessToken, TargetID: "4"},
+	}
+	for _, e := range entries {
+		if err := AuditLog.Create(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := AuditLog.List(ctx, AuditLogListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("got %d entries, want 3", len(all))
+	}
+	// Most recent first.
+	if all[0].Action != AuditActionAccessTokenDelete || all[0].ActorUserID != 0 || all[0].Diff != nil {
+		t.Errorf("unexpected entry: %+v", all[0])
+	}
+	if all[2].ActorUserID != 1 || all[2].IP != "127.0.0.1" || all[2].CreatedAt.IsZero() {
+		t.Errorf("unexpected entry: %+v", all[2])
+	}
+	var diff []map[string]interface{}
+	if err := json.Unmarshal(all[2].Diff, &diff); err != nil || len(diff) != 1 {
+		t.Errorf("unexpected diff %s (error %v)", all[2].Diff, err)
+	}
+
+	byActor, err := AuditLog.List(ctx, AuditLogListOptions{ActorUserID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byActor) != 1 || byActor[0].TargetID != "3" || byActor[0].ForwardedFor != "10.0.0.1" {
+		t.Errorf("unexpected entries: %+v", byActor)
+	}
+	if n, err := AuditLog.Count(ctx, AuditLogListOptions{Action: AuditActionSiteConfigUpdate}); err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Errorf("got count %d, want 1", n)
+	}
+	if n, err := AuditLog.Count(ctx, AuditLogListOptions{TargetType: AuditTargetUser, TargetID: "4"}); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Errorf("got count %d, want 0", n)
+	}
+
+	// Entries may not be modified.
+	if _, err := dbconn.Global.ExecContext(ctx, "UPDATE audit_log SET action='x'"); err == nil {
+		t.Error("got no error updating audit log entries, want error")
+	}
+
+	if err := AuditLog.DeleteCreatedBefore(ctx, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := AuditLog.Count(ctx, AuditLogListOptions{}); n != 3 {
+		t.Errorf("got count %d after deleting old entries, want 3", n)
+	}
+	if err := AuditLog.DeleteCreatedBefore(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := AuditLog.Count(ctx, AuditLogListOptions{}); n != 0 {
+		t.Errorf("got count %d after deleting all entries, want 0", n)
+	}
+}
@@ -5,6 +5,7 @@ var Mocks MockStores
 // MockStores has a field for each store interface with the concrete mock type (to obviate the need for tedious type assertions in test code).
 type MockStores struct {
 	AccessTokens MockAccessTokens
+	AuditLog     MockAuditLog
 
 	DiscussionThreads         MockDiscussionThreads
 	DiscussionComments        MockDiscussionComments
@@ -18,6 +19,8 @@ type MockStores struct {
 	Users         MockUsers
 	UserEmails    MockUserEmails
 
+	UserPermissions MockUserPermissions
+
 	Phabricator MockPhabricator
 
 	ExternalAccounts MockExternalAccounts
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	regexpsyntax "regexp/syntax"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db/query"
@@ -116,6 +118,47 @@ func (s *repos) Count(ctx context.Context, opt ReposListOptions) (int, error) {
 	return count, nil
 }
 
+// StarCounts returns the number of stars of each of the given repositories on
+// its code host, as recorded in the repository's metadata when it was synced
+// (only GitHub and GitLab repositories have stars). Repositories without a
+// star count are omitted.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to view the
+// repositories.
+func (s *repos) StarCounts(ctx context.Context, ids []api.RepoID) (map[api.RepoID]int, error) {
+	if Mocks.Repos.StarCounts != nil {
+		return Mocks.Repos.StarCounts(ctx, ids)
+	}
+
+	int32IDs := make([]int32, len(ids))
+	for i, id := range ids {
+		int32IDs[i] = int32(id)
+	}
+	q := sqlf.Sprintf(`
+SELECT id, COALESCE(metadata->'Stargazers'->>'TotalCount', metadata->>'star_count')::integer
+FROM repo
+WHERE deleted_at IS NULL AND id = ANY(%v)
+AND COALESCE(metadata->'Stargazers'->>'TotalCount', metadata->>'star_count') IS NOT NULL`, pq.Array(int32IDs))
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[api.RepoID]int, len(ids))
+	for rows.Next() {
+		var (
+			id    api.RepoID
+			count int
+		)
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, err
+		}
+		counts[id] = count
+	}
+	return counts, rows.Err()
+}
+
 const getRepoByQueryFmtstr = `
 SELECT id, name, description, language, enabled, created_at,
   updated_at, external_id, external_service_type, external_service_id
@@ -198,6 +241,22 @@ type ReposListOptions struct {
 	// OnlyArchived excludes non-archived repositories from the list.
 	OnlyArchived bool
 
+	// ExternalServiceID, if non-zero, includes only repositories that are
+	// synced from the external service with this ID.
+	ExternalServiceID int64
+
+	// Owner, if non-empty, includes only repositories that are owned by this
+	// user, organization, group, or project on the code host (case-insensitive).
+	Owner string
+
+	// Topic, if non-empty, includes only repositories that are tagged with this
+	// topic on the code host (case-insensitive).
+	Topic string
+
+	// Language, if non-empty, includes only repositories whose primary language
+	// is this language (case-insensitive).
+	Language string
+
 	// Index when set will only include repositories which should be indexed
 	// if true. If false it will exclude repositories which should be
 	// indexed. An example use case of this is for indexed search only
@@ -396,6 +455,39 @@ func (*repos) listSQL(opt ReposListOptions) (conds []*sqlf.Query, err error) {
 		conds = append(conds, sqlf.Sprintf("archived"))
 	}
 
+	if opt.ExternalServiceID != 0 {
+		conds = append(conds, sqlf.Sprintf("sources ? (SELECT 'extsvc:' || lower(kind) || ':' || id FROM external_services WHERE id=%d)", opt.ExternalServiceID))
+	}
+	if opt.Owner != "" {
+		// The owner is stored in the metadata of the code host that the
+		// repository is synced from (GitHub, GitLab, and Bitbucket Server,
+		// respectively).
+		owner := strings.ToLower(opt.Owner)
+		conds = append(conds, sqlf.Sprintf(`(
+	lower(split_part(metadata->>'NameWithOwner', '/', 1)) = %s
+	OR left(lower(metadata->>'path_with_namespace'), %d) = %s
+	OR lower(metadata->'project'->>'key') = %s
+)`, owner, utf8.RuneCountInString(owner)+1, owner+"/", owner))
+	}
+	if opt.Topic != "" {
+		// Topics are stored in the metadata of GitHub and GitLab repositories.
+		// Nil slices are stored as JSON null, so guard against non-arrays.
+		topic := strings.ToLower(opt.Topic)
+		conds = append(conds, sqlf.Sprintf(`(
+	EXISTS (
+		SELECT 1 FROM jsonb_array_elements(CASE jsonb_typeof(metadata->'RepositoryTopics'->'Nodes') WHEN 'array' THEN metadata->'RepositoryTopics'->'Nodes' ELSE '[]' END) t
+		WHERE lower(t->'Topic'->>'Name') = %s
+	)
+	OR EXISTS (
+		SELECT 1 FROM jsonb_array_elements_text(CASE jsonb_typeof(metadata->'tag_list') WHEN 'array' THEN metadata->'tag_list' ELSE '[]' END) t
+		WHERE lower(t) = %s
+	)
+)`, topic, topic))
+	}
+	if opt.Language != "" {
+		conds = append(conds, sqlf.Sprintf("lower(language) = %s", strings.ToLower(opt.Language)))
+	}
+
 	if opt.Index != nil {
 		// We don't currently have an index column, but when we want the
 		// indexable repositories to be a subset it will live in the database
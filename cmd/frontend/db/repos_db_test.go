@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"sort"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
 	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
 )
 
@@ -150,6 +152,156 @@ func TestRepos_List_fork(t *testing.T) {
 	}
 }
 
+func TestRepos_List_codeHostMetadata(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	mockAuthzFilter = func(ctx context.Context, repos []*types.Repo, p authz.Perm) ([]*types.Repo, error) {
+		return repos, nil
+	}
+	defer func() { mockAuthzFilter = nil }()
+	ctx := dbtesting.TestContext(t)
+	ctx = actor.WithActor(ctx, &actor.Actor{})
+
+	var githubID, gitlabID int64
+	for kind, id := range map[string]*int64{"GITHUB": &githubID, "GITLAB": &gitlabID} {
+		if err := dbconn.Global.QueryRowContext(ctx, "INSERT INTO external_services(kind, display_name, config) VALUES($1, $1, '{}') RETURNING id", kind).Scan(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mustCreate(ctx, t,
+		&types.Repo{Name: "github.com/Org/a"},
+		&types.Repo{Name: "gitlab.example.com/group/sub/b"},
+		&types.Repo{Name: "bitbucket.example.com/PROJ/c"},
+		&types.Repo{Name: "github.com/other/d"},
+	)
+	for _, u := range []struct {
+		name, sources, metadata, language string
+	}{
+		{
+			name:     "github.com/Org/a",
+			sources:  fmt.Sprintf(`{"extsvc:github:%d": {}}`, githubID),
+			metadata: `{"NameWithOwner": "Org/a", "RepositoryTopics": {"Nodes": [{"Topic": {"Name": "go"}}]}}`,
+			language: "Go",
+		},
+		{
+			name:     "gitlab.example.com/group/sub/b",
+			sources:  fmt.Sprintf(`{"extsvc:gitlab:%d": {}}`, gitlabID),
+			metadata: `{"path_with_namespace": "group/sub/b", "tag_list": ["Go", "cli"]}`,
+		},
+		{
+			name:     "bitbucket.example.com/PROJ/c",
+			metadata: `{"project": {"key": "PROJ"}}`,
+		},
+		{
+			name:     "github.com/other/d",
+			sources:  fmt.Sprintf(`{"extsvc:github:%d": {}}`, githubID),
+			metadata: `{"NameWithOwner": "other/d", "RepositoryTopics": {"Nodes": null}}`,
+			language: "TypeScript",
+		},
+	} {
+		if u.sources == "" {
+			u.sources = "{}"
+		}
+		if _, err := dbconn.Global.ExecContext(ctx, "UPDATE repo SET sources=$2, metadata=$3, language=$4 WHERE name=$1", u.name, u.sources, u.metadata, u.language); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		opt  ReposListOptions
+		want []api.RepoName
+	}{
+		{
+			name: "external service",
+			opt:  ReposListOptions{ExternalServiceID: githubID},
+			want: []api.RepoName{"github.com/Org/a", "github.com/other/d"},
+		},
+		{
+			name: "GitHub owner",
+			opt:  ReposListOptions{Owner: "org"},
+			want: []api.RepoName{"github.com/Org/a"},
+		},
+		{
+			name: "GitLab group",
+			opt:  ReposListOptions{Owner: "group"},
+			want: []api.RepoName{"gitlab.example.com/group/sub/b"},
+		},
+		{
+			name: "GitLab subgroup",
+			opt:  ReposListOptions{Owner: "group/sub"},
+			want: []api.RepoName{"gitlab.example.com/group/sub/b"},
+		},
+		{
+			name: "Bitbucket Server project",
+			opt:  ReposListOptions{Owner: "proj"},
+			want: []api.RepoName{"bitbucket.example.com/PROJ/c"},
+		},
+		{
+			name: "topic",
+			opt:  ReposListOptions{Topic: "go"},
+			want: []api.RepoName{"github.com/Org/a", "gitlab.example.com/group/sub/b"},
+		},
+		{
+			name: "language",
+			opt:  ReposListOptions{Language: "typescript"},
+			want: []api.RepoName{"github.com/other/d"},
+		},
+		{
+			name: "topic and external service",
+			opt:  ReposListOptions{Topic: "go", ExternalServiceID: gitlabID},
+			want: []api.RepoName{"gitlab.example.com/group/sub/b"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.opt.Enabled = true
+			repos, err := Repos.List(ctx, test.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sortedRepoNames(repos); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got repos %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRepos_StarCounts(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := dbtesting.TestContext(t)
+
+	repos := mustCreate(ctx, t,
+		&types.Repo{Name: "github.com/a/a"},
+		&types.Repo{Name: "gitlab.example.com/b/b"},
+		&types.Repo{Name: "bitbucket.example.com/C/c"},
+	)
+	for name, metadata := range map[api.RepoName]string{
+		"github.com/a/a":            `{"NameWithOwner": "a/a", "Stargazers": {"TotalCount": 42}}`,
+		"gitlab.example.com/b/b":    `{"path_with_namespace": "b/b", "star_count": 7}`,
+		"bitbucket.example.com/C/c": `{"project": {"key": "C"}}`,
+	} {
+		if _, err := dbconn.Global.ExecContext(ctx, "UPDATE repo SET metadata=$2 WHERE name=$1", name, metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts, err := Repos.StarCounts(ctx, []api.RepoID{repos[0].ID, repos[1].ID, repos[2].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[api.RepoID]int{repos[0].ID: 42, repos[1].ID: 7}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("got %v, want %v", counts, want)
+	}
+}
+
 func TestRepos_List_pagination(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
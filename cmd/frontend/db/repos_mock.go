@@ -10,12 +10,13 @@ import (
 )
 
 type MockRepos struct {
-	Get       func(ctx context.Context, repo api.RepoID) (*types.Repo, error)
-	GetByName func(ctx context.Context, repo api.RepoName) (*types.Repo, error)
-	List      func(v0 context.Context, v1 ReposListOptions) ([]*types.Repo, error)
-	Delete    func(ctx context.Context, repo api.RepoID) error
-	Count     func(ctx context.Context, opt ReposListOptions) (int, error)
-	Upsert    func(api.InsertRepoOp) error
+	Get        func(ctx context.Context, repo api.RepoID) (*types.Repo, error)
+	GetByName  func(ctx context.Context, repo api.RepoName) (*types.Repo, error)
+	List       func(v0 context.Context, v1 ReposListOptions) ([]*types.Repo, error)
+	Delete     func(ctx context.Context, repo api.RepoID) error
+	Count      func(ctx context.Context, opt ReposListOptions) (int, error)
+	Upsert     func(api.InsertRepoOp) error
+	StarCounts func(ctx context.Context, ids []api.RepoID) (map[api.RepoID]int, error)
 }
 
 func (s *MockRepos) MockGet(t *testing.T, wantRepo api.RepoID) (called *bool) {
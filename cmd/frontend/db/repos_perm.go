@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
@@ -40,7 +41,12 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perm) ([]*typ
 		}
 	}
 
-	filteredRepoNames, err := getFilteredRepoNames(ctx, currentUser, authz.ToRepos(repos), p)
+	repoIDs := make(map[api.RepoName]api.RepoID, len(repos))
+	for _, repo := range repos {
+		repoIDs[repo.Name] = repo.ID
+	}
+
+	filteredRepoNames, err := getFilteredRepoNames(ctx, currentUser, authz.ToRepos(repos), repoIDs, p)
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +69,7 @@ func isInternalActor(ctx context.Context) bool {
 	return actor.FromContext(ctx).Internal
 }
 
-func getFilteredRepoNames(ctx context.Context, currentUser *types.User, repos map[authz.Repo]struct{}, p authz.Perm) (accepted map[api.RepoName]struct{}, err error) {
+func getFilteredRepoNames(ctx context.Context, currentUser *types.User, repos map[authz.Repo]struct{}, repoIDs map[api.RepoName]api.RepoID, p authz.Perm) (accepted map[api.RepoName]struct{}, err error) {
 	var accts []*extsvc.ExternalAccount
 	authzAllowByDefault, authzProviders := authz.GetProviders()
 	if len(authzProviders) > 0 && currentUser != nil {
@@ -73,6 +79,28 @@ func getFilteredRepoNames(ctx context.Context, currentUser *types.User, repos ma
 		}
 	}
 
+	// If permissions are synced in the background, use the user's synced permissions for the code
+	// hosts whose permissions have been synced recently.
+	permsSyncer := authz.GetPermsSyncer()
+	type codeHost struct{ serviceType, serviceID string }
+	syncedPerms := make(map[codeHost]*UserPermission)
+	if len(authzProviders) > 0 && currentUser != nil && permsSyncer != nil {
+		perms, err := UserPermissions.ListByUser(ctx, currentUser.ID, p)
+		if err != nil {
+			return nil, err
+		}
+		// 🚨 SECURITY: Permissions that were not synced within twice the sync interval (e.g.,
+		// because syncing is failing or was disabled) may grant access that was since revoked, so
+		// they are fetched below instead.
+		maxAge := 2 * permsSyncer.Interval()
+		for _, perm := range perms {
+			if time.Since(perm.UpdatedAt) > maxAge {
+				continue
+			}
+			syncedPerms[codeHost{perm.ServiceType, perm.ServiceID}] = perm
+		}
+	}
+
 	accepted = make(map[api.RepoName]struct{})  // repositories that have been claimed and have read permissions
 	unverified := make(map[authz.Repo]struct{}) // repositories that have not been claimed by any authz provider
 	for repo := range repos {
@@ -91,6 +119,22 @@ func getFilteredRepoNames(ctx context.Context, currentUser *types.User, repos ma
 			break
 		}
 
+		if perms, ok := syncedPerms[codeHost{authzProvider.ServiceType(), authzProvider.ServiceID()}]; ok {
+			myUnverified, nextUnverified := authzProvider.Repos(ctx, unverified)
+			for unverifiedRepo := range myUnverified {
+				if id, ok := repoIDs[unverifiedRepo.RepoName]; ok && perms.HasRepo(id) {
+					accepted[unverifiedRepo.RepoName] = struct{}{}
+				}
+			}
+			unverified = nextUnverified
+			continue
+		}
+		if permsSyncer != nil && currentUser != nil {
+			// The user's permissions for this code host have not been synced (recently), so fetch
+			// them below (which can be slow) until they are.
+			permsSyncer.RequestSync(currentUser.ID)
+		}
+
 		// determine external account to use
 		var providerAcct *extsvc.ExternalAccount
 		for _, acct := range accts {
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
@@ -647,6 +648,94 @@ func Test_authzFilter_createsNewUsers(t *testing.T) {
 	}
 }
 
+type mockPermsSyncer struct{ requests []int32 }
+
+func (s *mockPermsSyncer) RequestSync(userID int32) { s.requests = append(s.requests, userID) }
+
+func (s *mockPermsSyncer) Interval() time.Duration { return time.Hour }
+
+func Test_authzFilter_syncedPerms(t *testing.T) {
+	syncer := &mockPermsSyncer{}
+	authz.SetPermsSyncer(syncer)
+	defer authz.SetPermsSyncer(nil)
+
+	var synced []*UserPermission
+	Mocks.UserPermissions.ListByUser = func(userID int32, perm authz.Perm) ([]*UserPermission, error) {
+		if userID != 1 || perm != authz.Read {
+			t.Fatalf("unexpected ListByUser(%d, %q)", userID, perm)
+		}
+		return synced, nil
+	}
+	defer func() { Mocks.UserPermissions.ListByUser = nil }()
+	Mocks.ExternalAccounts.List = func(ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) {
+		return []*extsvc.ExternalAccount{acct(1, "gitlab", "https://gitlab.mine/", "u1")}, nil
+	}
+	Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: actor.FromContext(ctx).UID}, nil
+	}
+
+	repos := []*types.Repo{makeRepo("gitlab.mine/r1", 1), makeRepo("gitlab.mine/r2", 2), makeRepo("gitlab.mine/r3", 3)}
+	authz.SetProviders(false, []authz.Provider{
+		&MockAuthzProvider{
+			serviceID:   "https://gitlab.mine/",
+			serviceType: "gitlab",
+			repos: map[api.RepoName]struct{}{
+				"gitlab.mine/r1": {},
+				"gitlab.mine/r2": {},
+				"gitlab.mine/r3": {},
+			},
+			perms: map[extsvc.ExternalAccount]map[api.RepoName]map[authz.Perm]bool{
+				*acct(1, "gitlab", "https://gitlab.mine/", "u1"): {
+					"gitlab.mine/r1": {authz.Read: true},
+				},
+			},
+		},
+	})
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+
+	// Without synced permissions, the permissions are fetched and a sync is requested.
+	filteredRepos, err := authzFilter(ctx, repos, authz.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := repos[:1]; !reflect.DeepEqual(filteredRepos, want) {
+		t.Errorf("got filtered repos %s, want %s", asJSON(t, filteredRepos), asJSON(t, want))
+	}
+	if want := []int32{1}; !reflect.DeepEqual(syncer.requests, want) {
+		t.Errorf("got sync requests %v, want %v", syncer.requests, want)
+	}
+
+	// With synced permissions, those are used instead.
+	syncer.requests = nil
+	perm := &UserPermission{UserID: 1, Perm: authz.Read, ServiceType: "gitlab", ServiceID: "https://gitlab.mine/", UpdatedAt: time.Now()}
+	perm.AddRepo(2)
+	perm.AddRepo(3)
+	synced = []*UserPermission{perm}
+	filteredRepos, err = authzFilter(ctx, repos, authz.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := repos[1:]; !reflect.DeepEqual(filteredRepos, want) {
+		t.Errorf("got filtered repos %s, want %s", asJSON(t, filteredRepos), asJSON(t, want))
+	}
+	if len(syncer.requests) != 0 {
+		t.Errorf("got sync requests %v, want none", syncer.requests)
+	}
+
+	// Synced permissions older than twice the sync interval are ignored.
+	perm.UpdatedAt = time.Now().Add(-3 * time.Hour)
+	filteredRepos, err = authzFilter(ctx, repos, authz.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := repos[:1]; !reflect.DeepEqual(filteredRepos, want) {
+		t.Errorf("got filtered repos %s, want %s", asJSON(t, filteredRepos), asJSON(t, want))
+	}
+	if want := []int32{1}; !reflect.DeepEqual(syncer.requests, want) {
+		t.Errorf("got sync requests %v, want %v", syncer.requests, want)
+	}
+}
+
 func acct(userID int32, serviceType, serviceID, accountID string) *extsvc.ExternalAccount {
 	return &extsvc.ExternalAccount{
 		UserID: userID,
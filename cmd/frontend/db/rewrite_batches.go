@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// The states of a rewrite batch.
+const (
+	RewriteBatchStatePreviewing = "PREVIEWING" // the diffs of the repositories are being computed
+	RewriteBatchStatePreviewed  = "PREVIEWED"  // the diffs are ready for review
+	RewriteBatchStateCommitting = "COMMITTING" // the diffs are being committed
+	RewriteBatchStateCommitted  = "COMMITTED"  // the diffs were committed
+	RewriteBatchStateErrored    = "ERRORED"    // previewing or committing was interrupted (e.g., by a restart)
+)
+
+// The states of a repository in a rewrite batch.
+const (
+	RewriteBatchRepoStatePending   = "PENDING"    // the diff has not been computed yet
+	RewriteBatchRepoStatePreviewed = "PREVIEWED"  // the diff is ready for review
+	RewriteBatchRepoStateNoChanges = "NO_CHANGES" // the rewrite did not change any file
+	RewriteBatchRepoStateCommitted = "COMMITTED"  // the diff was committed
+	RewriteBatchRepoStateErrored   = "ERRORED"    // computing or committing the diff failed
+)
+
+// A RewriteBatch is a code rewrite that is run across all repositories
+// matched by a search query. The diff of each repository is previewed before
+// it is committed on a new branch.
+type RewriteBatch struct {
+	ID              int64
+	UserID          int32 // the user who created the rewrite batch
+	Query           string
+	MatchTemplate   string
+	RewriteTemplate string
+	FileExtension   string
+	State           string
+	Branch          string // the branch that the diffs are committed on (empty until committed)
+	CommitMessage   string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// A RewriteBatchRepo is a repository in a rewrite batch.
+type RewriteBatchRepo struct {
+	ID             int64
+	RewriteBatchID int64
+	RepoID         api.RepoID
+	Rev            string       // the revision of the repository matched by the query
+	BaseCommit     api.CommitID // the commit that Rev resolved to, which the diff applies to
+	Diff           string       // the unified diff of the rewrite
+	State          string
+	Error          string
+	CommitRef      string // the ref of the commit created from the diff
+	UpdatedAt      time.Time
+}
+
+// RewriteBatchNotFoundError occurs when a rewrite batch is not found.
+type RewriteBatchNotFoundError struct {
+	ID int64
+}
+
+// NotFound implements errcode.NotFounder.
+func (err RewriteBatchNotFoundError) NotFound() bool { return true }
+
+func (err RewriteBatchNotFoundError) Error() string {
+	return fmt.Sprintf("rewrite batch not found: %d", err.ID)
+}
+
+type rewriteBatches struct{}
+
+// Create creates a rewrite batch and its repositories, which are all in the
+// pending state.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to create
+// rewrite batches.
+func (*rewriteBatches) Create(ctx context.Context, b *RewriteBatch, repos []*RewriteBatchRepo) (_ *RewriteBatch, err error) {
+	tx, err := dbconn.Global.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			rollErr := tx.Rollback()
+			if rollErr != nil {
+				err = multierror.Append(err, rollErr)
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	nb := *b
+	nb.State = RewriteBatchStatePreviewing
+	if err := tx.QueryRowContext(
+		ctx,
+		"INSERT INTO rewrite_batches(user_id, query, match_template, rewrite_template, file_extension, state) VALUES($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at",
+		nb.UserID, nb.Query, nb.MatchTemplate, nb.RewriteTemplate, nb.FileExtension, nb.State,
+	).Scan(&nb.ID, &nb.CreatedAt, &nb.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	for _, r := range repos {
+		r.RewriteBatchID = nb.ID
+		r.State = RewriteBatchRepoStatePending
+		if err := tx.QueryRowContext(
+			ctx,
+			"INSERT INTO rewrite_batch_repos(rewrite_batch_id, repo_id, rev, state) VALUES($1, $2, $3, $4) RETURNING id, updated_at",
+			r.RewriteBatchID, r.RepoID, r.Rev, r.State,
+		).Scan(&r.ID, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+	return &nb, nil
+}
+
+// GetByID returns the rewrite batch with the given ID.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to view
+// this rewrite batch.
+func (*rewriteBatches) GetByID(ctx context.Context, id int64) (*RewriteBatch, error) {
+	var b RewriteBatch
+	err := dbconn.Global.QueryRowContext(ctx, `
+SELECT id, user_id, query, match_template, rewrite_template, file_extension, state, branch, commit_message, created_at, updated_at
+FROM rewrite_batches WHERE id=$1`, id).Scan(
+		&b.ID, &b.UserID, &b.Query, &b.MatchTemplate, &b.RewriteTemplate, &b.FileExtension,
+		&b.State, &b.Branch, &b.CommitMessage, &b.CreatedAt, &b.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, RewriteBatchNotFoundError{ID: id}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ListRepos lists the repositories in the rewrite batch.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to view
+// this rewrite batch.
+func (*rewriteBatches) ListRepos(ctx context.Context, id int64) ([]*RewriteBatchRepo, error) {
+	rows, err := dbconn.Global.QueryContext(ctx, `
+SELECT id, rewrite_batch_id, repo_id, rev, base_commit, diff, state, error, commit_ref, updated_at
+FROM rewrite_batch_repos WHERE rewrite_batch_id=$1
+ORDER BY id ASC`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*RewriteBatchRepo
+	for rows.Next() {
+		var r RewriteBatchRepo
+		if err := rows.Scan(&r.ID, &r.RewriteBatchID, &r.RepoID, &r.Rev, &r.BaseCommit, &r.Diff, &r.State, &r.Error, &r.CommitRef, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		results = append(results, &r)
+	}
+	return results, rows.Err()
+}
+
+// UpdateState sets the state of the rewrite batch.
+func (*rewriteBatches) UpdateState(ctx context.Context, id int64, state string) error {
+	res, err := dbconn.Global.ExecContext(ctx, "UPDATE rewrite_batches SET state=$2, updated_at=now() WHERE id=$1", id, state)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return RewriteBatchNotFoundError{ID: id}
+	}
+	return nil
+}
+
+// StartCommitting moves the rewrite batch from the previewed state to the
+// committing state, and records the branch and commit message of the commits.
+// It returns an error if the rewrite batch is not in the previewed state (e.g.,
+// because it is already being committed).
+func (s *rewriteBatches) StartCommitting(ctx context.Context, id int64, branch, commitMessage string) error {
+	q := sqlf.Sprintf("UPDATE rewrite_batches SET state=%s, branch=%s, commit_message=%s, updated_at=now() WHERE id=%d AND state=%s",
+		RewriteBatchStateCommitting, branch, commitMessage, id, RewriteBatchStatePreviewed)
+	res, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		if _, err := s.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return fmt.Errorf("rewrite batch %d is not ready to be committed", id)
+	}
+	return nil
+}
+
+// FailInterrupted moves the rewrite batches that are being previewed or
+// committed, but that (along with their repositories) were not updated since
+// before staleAfter, to the errored state. Those batches were interrupted,
+// because the process that ran them died. Their repositories whose diffs were
+// not computed or committed yet are also moved to the errored state.
+func (*rewriteBatches) FailInterrupted(ctx context.Context, staleAfter time.Duration) error {
+	stale := time.Now().Add(-staleAfter)
+	q := sqlf.Sprintf(`
+WITH interrupted AS (
+	UPDATE rewrite_batches b SET state=%s, updated_at=now()
+	FROM (
+		SELECT s.id, s.state FROM rewrite_batches s
+		WHERE s.state IN (%s, %s) AND s.updated_at < %s
+		AND NOT EXISTS (SELECT 1 FROM rewrite_batch_repos r WHERE r.rewrite_batch_id=s.id AND r.updated_at >= %s)
+		FOR UPDATE
+	) old
+	WHERE b.id=old.id
+	RETURNING b.id, old.state AS old_state
+)
+UPDATE rewrite_batch_repos r SET state=%s, error=%s, updated_at=now()
+FROM interrupted
+WHERE r.rewrite_batch_id=interrupted.id AND (r.state=%s OR (interrupted.old_state=%s AND r.state=%s))
+`,
+		RewriteBatchStateErrored,
+		RewriteBatchStatePreviewing, RewriteBatchStateCommitting, stale, stale,
+		RewriteBatchRepoStateErrored, "interrupted (e.g., by a restart of Sourcegraph)",
+		RewriteBatchRepoStatePending, RewriteBatchStateCommitting, RewriteBatchRepoStatePreviewed,
+	)
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+// UpdateRepo updates the base commit, diff, state, error, and commit ref of
+// a repository in a rewrite batch.
+func (*rewriteBatches) UpdateRepo(ctx context.Context, r *RewriteBatchRepo) error {
+	_, err := dbconn.Global.ExecContext(ctx,
+		"UPDATE rewrite_batch_repos SET base_commit=$2, diff=$3, state=$4, error=$5, commit_ref=$6, updated_at=now() WHERE id=$1",
+		r.ID, r.BaseCommit, r.Diff, r.State, r.Error, r.CommitRef,
+	)
+	return err
+}
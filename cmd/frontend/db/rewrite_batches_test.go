@@ -0,0 +1,130 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+func TestRewriteBatches(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	user, err := Users.Create(ctx, NewUser{
+		Email:                 "a@example.com",
+		Username:              "u",
+		Password:              "p",
+		EmailVerificationCode: "c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Repos.Upsert(ctx, api.InsertRepoOp{Name: "myrepo", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := Repos.GetByName(ctx, "myrepo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch, err := RewriteBatches.Create(ctx, &RewriteBatch{
+		UserID:          user.ID,
+		Query:           "repo:myrepo",
+		MatchTemplate:   "foo(:[args])",
+		RewriteTemplate: "bar(:[args])",
+		FileExtension:   ".go",
+	}, []*RewriteBatchRepo{{RepoID: repo.ID, Rev: "master"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.State != RewriteBatchStatePreviewing {
+		t.Errorf("got state %q, want %q", batch.State, RewriteBatchStatePreviewing)
+	}
+
+	if got, err := RewriteBatches.GetByID(ctx, batch.ID); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(got, batch) {
+		t.Errorf("got %+v, want %+v", got, batch)
+	}
+	if _, err := RewriteBatches.GetByID(ctx, 12345 /* doesn't exist */); !errcode.IsNotFound(err) {
+		t.Errorf("got err %v, want errcode.IsNotFound", err)
+	}
+
+	repos, err := RewriteBatches.ListRepos(ctx, batch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 1 || repos[0].RepoID != repo.ID || repos[0].State != RewriteBatchRepoStatePending {
+		t.Fatalf("got repos %+v, want 1 pending repo", repos)
+	}
+
+	// Committing is only possible after the diffs were previewed.
+	if err := RewriteBatches.StartCommitting(ctx, batch.ID, "rewrite", "Rewrite foo"); err == nil {
+		t.Error("expected error when committing a rewrite batch that is being previewed")
+	}
+
+	r := repos[0]
+	r.BaseCommit = "deadbeef"
+	r.Diff = "diff --git a/a.go b/a.go\n"
+	r.State = RewriteBatchRepoStatePreviewed
+	if err := RewriteBatches.UpdateRepo(ctx, r); err != nil {
+		t.Fatal(err)
+	}
+	if err := RewriteBatches.UpdateState(ctx, batch.ID, RewriteBatchStatePreviewed); err != nil {
+		t.Fatal(err)
+	}
+	if err := RewriteBatches.StartCommitting(ctx, batch.ID, "rewrite", "Rewrite foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RewriteBatches.StartCommitting(ctx, batch.ID, "rewrite", "Rewrite foo"); err == nil {
+		t.Error("expected error when committing a rewrite batch twice")
+	}
+
+	got, err := RewriteBatches.GetByID(ctx, batch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != RewriteBatchStateCommitting || got.Branch != "rewrite" || got.CommitMessage != "Rewrite foo" {
+		t.Errorf("got %+v, want committing on branch rewrite", got)
+	}
+	repos, err = RewriteBatches.ListRepos(ctx, batch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repos[0].BaseCommit != r.BaseCommit || repos[0].Diff != r.Diff || repos[0].State != r.State {
+		t.Errorf("got repo %+v, want %+v", repos[0], r)
+	}
+
+	// Batches that are still being updated are not interrupted.
+	if err := RewriteBatches.FailInterrupted(ctx, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := RewriteBatches.GetByID(ctx, batch.ID); err != nil {
+		t.Fatal(err)
+	} else if got.State != RewriteBatchStateCommitting {
+		t.Errorf("got state %q, want %q", got.State, RewriteBatchStateCommitting)
+	}
+
+	// The repositories of interrupted batches that were not committed yet fail.
+	if err := RewriteBatches.FailInterrupted(ctx, -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := RewriteBatches.GetByID(ctx, batch.ID); err != nil {
+		t.Fatal(err)
+	} else if got.State != RewriteBatchStateErrored {
+		t.Errorf("got state %q, want %q", got.State, RewriteBatchStateErrored)
+	}
+	repos, err = RewriteBatches.ListRepos(ctx, batch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repos[0].State != RewriteBatchRepoStateErrored || repos[0].Error == "" {
+		t.Errorf("got repo %+v, want errored", repos[0])
+	}
+}
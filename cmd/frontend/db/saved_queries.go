@@ -3,9 +3,11 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
 )
 
@@ -77,11 +79,99 @@ func (s *queryRunnerState) Set(ctx context.Context, info *SavedQueryInfo) error
 	return nil
 }
 
+// Delete deletes the saved query information and the runs of the given query.
 func (s *queryRunnerState) Delete(ctx context.Context, query string) error {
-	_, err := dbconn.Global.ExecContext(
+	if _, err := dbconn.Global.ExecContext(
 		ctx,
 		"DELETE FROM query_runner_state WHERE query=$1",
 		query,
+	); err != nil {
+		return err
+	}
+	_, err := dbconn.Global.ExecContext(
+		ctx,
+		"DELETE FROM query_runner_runs WHERE query=$1",
+		query,
 	)
 	return err
 }
+
+// maxQueryRunnerRuns is the number of most recent runs that are kept for each
+// query.
+const maxQueryRunnerRuns = 2
+
+// GetLastRun gets the most recent run of the given query. nil is returned if
+// the query has not run yet.
+func (s *queryRunnerState) GetLastRun(ctx context.Context, query string) (*api.SavedQueryRun, error) {
+	run := &api.SavedQueryRun{
+		Query: query,
+	}
+	var matches, added, removed []byte
+	err := dbconn.Global.QueryRowContext(
+		ctx,
+		"SELECT executed_at, matches, added, removed FROM query_runner_runs WHERE query=$1 ORDER BY id DESC LIMIT 1",
+		query,
+	).Scan(&run.ExecutedAt, &matches, &added, &removed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "QueryRow")
+	}
+	if err := json.Unmarshal(matches, &run.Matches); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(added, &run.Added); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(removed, &run.Removed); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// AddRun records a run of the given run.Query and deletes its older runs, so
+// that only the most recent runs are kept.
+func (s *queryRunnerState) AddRun(ctx context.Context, run *api.SavedQueryRun) error {
+	matches, err := json.Marshal(nonNilSavedQueryMatches(run.Matches))
+	if err != nil {
+		return err
+	}
+	added, err := json.Marshal(nonNilSavedQueryMatches(run.Added))
+	if err != nil {
+		return err
+	}
+	removed, err := json.Marshal(nonNilSavedQueryMatches(run.Removed))
+	if err != nil {
+		return err
+	}
+	if _, err := dbconn.Global.ExecContext(
+		ctx,
+		"INSERT INTO query_runner_runs(query, executed_at, matches, added, removed) VALUES($1, $2, $3, $4, $5)",
+		run.Query,
+		run.ExecutedAt,
+		matches,
+		added,
+		removed,
+	); err != nil {
+		return errors.Wrap(err, "INSERT")
+	}
+	if _, err := dbconn.Global.ExecContext(
+		ctx,
+		"DELETE FROM query_runner_runs WHERE query=$1 AND id NOT IN (SELECT id FROM query_runner_runs WHERE query=$1 ORDER BY id DESC LIMIT $2)",
+		run.Query,
+		maxQueryRunnerRuns,
+	); err != nil {
+		return errors.Wrap(err, "DELETE")
+	}
+	return nil
+}
+
+// nonNilSavedQueryMatches returns matches, or an empty slice if matches is nil,
+// so that it is stored as a JSON array.
+func nonNilSavedQueryMatches(matches []api.SavedQueryMatch) []api.SavedQueryMatch {
+	if matches == nil {
+		return []api.SavedQueryMatch{}
+	}
+	return matches
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestQueryRunnerState_Runs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	if run, err := QueryRunnerState.GetLastRun(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	} else if run != nil {
+		t.Fatalf("got run %+v, want nil", run)
+	}
+
+	m1 := api.SavedQueryMatch{Fingerprint: "f1", Repository: "r", Path: "a.go", LineNumber: 1, Preview: "foo()"}
+	m2 := api.SavedQueryMatch{Fingerprint: "f2", Repository: "r", Path: "b.go", LineNumber: 2, Preview: "foo()"}
+	executedAt := time.Now().UTC().Truncate(time.Second)
+	runs := []*api.SavedQueryRun{
+		{Query: "foo", ExecutedAt: executedAt, Matches: []api.SavedQueryMatch{m1}},
+		{Query: "foo", ExecutedAt: executedAt.Add(time.Minute), Matches: []api.SavedQueryMatch{m1, m2}, Added: []api.SavedQueryMatch{m2}},
+		{Query: "foo", ExecutedAt: executedAt.Add(2 * time.Minute), Matches: []api.SavedQueryMatch{m2}, Removed: []api.SavedQueryMatch{m1}},
+		{Query: "bar", ExecutedAt: executedAt, Matches: []api.SavedQueryMatch{m1}},
+	}
+	for _, run := range runs {
+		if err := QueryRunnerState.AddRun(ctx, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run, err := QueryRunnerState.GetLastRun(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run.ExecutedAt = run.ExecutedAt.UTC()
+	want := &api.SavedQueryRun{
+		Query:      "foo",
+		ExecutedAt: executedAt.Add(2 * time.Minute),
+		Matches:    []api.SavedQueryMatch{m2},
+		Added:      []api.SavedQueryMatch{},
+		Removed:    []api.SavedQueryMatch{m1},
+	}
+	if !reflect.DeepEqual(run, want) {
+		t.Errorf("got run %+v, want %+v", run, want)
+	}
+
+	// Only the most recent runs of a query are kept.
+	var count int
+	if err := dbconn.Global.QueryRowContext(ctx, "SELECT COUNT(*) FROM query_runner_runs WHERE query='foo'").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != maxQueryRunnerRuns {
+		t.Errorf("got %d runs, want %d", count, maxQueryRunnerRuns)
+	}
+
+	if err := QueryRunnerState.Delete(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if run, err := QueryRunnerState.GetLastRun(ctx, "foo"); err != nil {
+		t.Fatal(err)
+	} else if run != nil {
+		t.Errorf("got run %+v after delete, want nil", run)
+	}
+	if run, err := QueryRunnerState.GetLastRun(ctx, "bar"); err != nil {
+		t.Fatal(err)
+	} else if run == nil {
+		t.Error("got no run for other query after delete")
+	}
+}
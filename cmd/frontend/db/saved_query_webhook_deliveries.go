@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// maxSavedQueryWebhookDeliveries is the number of most recent webhook
+// deliveries that are kept for each saved query.
+const maxSavedQueryWebhookDeliveries = 50
+
+type savedQueryWebhookDeliveries struct{}
+
+// savedQuerySpecCond returns the SQL condition that matches the rows of the
+// saved query with the given spec.
+func savedQuerySpecCond(spec api.SavedQueryIDSpec) *sqlf.Query {
+	conds := []*sqlf.Query{sqlf.Sprintf("saved_query_key=%s", spec.Key)}
+	switch {
+	case spec.Subject.User != nil:
+		conds = append(conds, sqlf.Sprintf("user_id=%d AND org_id IS NULL", *spec.Subject.User))
+	case spec.Subject.Org != nil:
+		conds = append(conds, sqlf.Sprintf("org_id=%d AND user_id IS NULL", *spec.Subject.Org))
+	default:
+		conds = append(conds, sqlf.Sprintf("user_id IS NULL AND org_id IS NULL"))
+	}
+	return sqlf.Join(conds, "AND")
+}
+
+// Create records a webhook delivery of a saved query notification, and deletes
+// the saved query's older deliveries, so that only the most recent deliveries
+// are kept.
+func (*savedQueryWebhookDeliveries) Create(ctx context.Context, d *api.SavedQueryWebhookDelivery) error {
+	var statusCode *int
+	if d.StatusCode != 0 {
+		statusCode = &d.StatusCode
+	}
+	if _, err := dbconn.Global.ExecContext(
+		ctx,
+		"INSERT INTO saved_query_webhook_deliveries(user_id, org_id, saved_query_key, event, url, status_code, response_body, error, attempts) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		d.Spec.Subject.User, d.Spec.Subject.Org, d.Spec.Key, d.Event, d.URL, statusCode, d.ResponseBody, d.Error, d.Attempts,
+	); err != nil {
+		return errors.Wrap(err, "INSERT")
+	}
+
+	cond := savedQuerySpecCond(d.Spec)
+	q := sqlf.Sprintf("DELETE FROM saved_query_webhook_deliveries WHERE %s AND id NOT IN (SELECT id FROM saved_query_webhook_deliveries WHERE %s ORDER BY id DESC LIMIT %d)", cond, cond, maxSavedQueryWebhookDeliveries)
+	if _, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+		return errors.Wrap(err, "DELETE")
+	}
+	return nil
+}
+
+// ListBySavedQuery lists the most recent webhook deliveries of the saved query
+// with the given spec, newest first.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to view the
+// saved query.
+func (*savedQueryWebhookDeliveries) ListBySavedQuery(ctx context.Context, spec api.SavedQueryIDSpec, limit int) ([]*api.SavedQueryWebhookDelivery, error) {
+	q := sqlf.Sprintf("SELECT id, event, url, status_code, response_body, error, attempts, created_at FROM saved_query_webhook_deliveries WHERE %s ORDER BY id DESC LIMIT %d", savedQuerySpecCond(spec), limit)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*api.SavedQueryWebhookDelivery
+	for rows.Next() {
+		d := api.SavedQueryWebhookDelivery{Spec: spec}
+		var statusCode sql.NullInt64
+		if err := rows.Scan(&d.ID, &d.Event, &d.URL, &statusCode, &d.ResponseBody, &d.Error, &d.Attempts, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		d.StatusCode = int(statusCode.Int64)
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestSavedQueryWebhookDeliveries(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	user, err := Users.Create(ctx, NewUser{
+		Email:                 "a@example.com",
+		Username:              "u",
+		Password:              "p",
+		EmailVerificationCode: "c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	org, err := Orgs.Create(ctx, "o", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userSpec := api.SavedQueryIDSpec{Subject: api.SettingsSubject{User: &user.ID}, Key: "k"}
+	orgSpec := api.SavedQueryIDSpec{Subject: api.SettingsSubject{Org: &org.ID}, Key: "k"}
+	siteSpec := api.SavedQueryIDSpec{Subject: api.SettingsSubject{Site: true}, Key: "k"}
+
+	for i := 0; i < maxSavedQueryWebhookDeliveries+2; i++ {
+		if err := SavedQueryWebhookDeliveries.Create(ctx, &api.SavedQueryWebhookDelivery{
+			Spec:       userSpec,
+			Event:      "saved_search.results",
+			URL:        "https://example.com/hook",
+			StatusCode: 200 + i,
+			Attempts:   1,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := SavedQueryWebhookDeliveries.Create(ctx, &api.SavedQueryWebhookDelivery{
+		Spec:     orgSpec,
+		Event:    "saved_search.test",
+		URL:      "https://example.com/hook",
+		Error:    "connection refused",
+		Attempts: 5,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the most recent deliveries are kept, and they are listed newest
+	// first.
+	deliveries, err := SavedQueryWebhookDeliveries.ListBySavedQuery(ctx, userSpec, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != maxSavedQueryWebhookDeliveries {
+		t.Fatalf("got %d deliveries, want %d", len(deliveries), maxSavedQueryWebhookDeliveries)
+	}
+	if want := 200 + maxSavedQueryWebhookDeliveries + 1; deliveries[0].StatusCode != want {
+		t.Errorf("got status code %d, want %d", deliveries[0].StatusCode, want)
+	}
+
+	deliveries, err = SavedQueryWebhookDeliveries.ListBySavedQuery(ctx, orgSpec, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	if d := deliveries[0]; d.StatusCode != 0 || d.Error != "connection refused" || d.Attempts != 5 || d.Event != "saved_search.test" {
+		t.Errorf("got delivery %+v", d)
+	}
+
+	deliveries, err = SavedQueryWebhookDeliveries.ListBySavedQuery(ctx, siteSpec, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 0 {
+		t.Errorf("got %d deliveries, want 0", len(deliveries))
+	}
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// savedQueryWebhookSecrets stores the secrets that saved query webhook
+// notifications are signed with. They are stored here instead of in settings,
+// because settings can be read by users (such as the members of an org) who
+// must not be able to forge notifications.
+type savedQueryWebhookSecrets struct{}
+
+// Set sets the secret of the saved query's webhook notifications. An empty
+// secret removes it.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to edit the
+// saved query.
+func (*savedQueryWebhookSecrets) Set(ctx context.Context, spec api.SavedQueryIDSpec, secret string) error {
+	q := sqlf.Sprintf("DELETE FROM saved_query_webhook_secrets WHERE %s", savedQuerySpecCond(spec))
+	if secret != "" {
+		q = sqlf.Sprintf(`
+INSERT INTO saved_query_webhook_secrets(user_id, org_id, saved_query_key, secret) VALUES(%s, %s, %s, %s)
+ON CONFLICT (COALESCE(user_id, 0), COALESCE(org_id, 0), saved_query_key) DO UPDATE SET secret=excluded.secret, updated_at=now()`,
+			spec.Subject.User, spec.Subject.Org, spec.Key, secret)
+	}
+	if _, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+		return errors.Wrap(err, "setting saved query webhook secret")
+	}
+	return nil
+}
+
+// Get returns the secret of the saved query's webhook notifications, or "" if
+// there is none.
+//
+// 🚨 SECURITY: The secret must only be used by the query-runner to sign
+// notifications. It must never be shown to users.
+func (*savedQueryWebhookSecrets) Get(ctx context.Context, spec api.SavedQueryIDSpec) (string, error) {
+	q := sqlf.Sprintf("SELECT secret FROM saved_query_webhook_secrets WHERE %s", savedQuerySpecCond(spec))
+	var secret string
+	err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&secret)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return secret, err
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestSavedQueryWebhookSecrets(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	org, err := Orgs.Create(ctx, "o", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orgSpec := api.SavedQueryIDSpec{Subject: api.SettingsSubject{Org: &org.ID}, Key: "k"}
+	siteSpec := api.SavedQueryIDSpec{Subject: api.SettingsSubject{Site: true}, Key: "k"}
+
+	get := func(spec api.SavedQueryIDSpec) string {
+		t.Helper()
+		secret, err := SavedQueryWebhookSecrets.Get(ctx, spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return secret
+	}
+
+	if got := get(orgSpec); got != "" {
+		t.Errorf("got secret %q before it was set, want none", got)
+	}
+	for _, secret := range []string{"s1", "s2"} {
+		if err := SavedQueryWebhookSecrets.Set(ctx, siteSpec, secret); err != nil {
+			t.Fatal(err)
+		}
+		if got := get(siteSpec); got != secret {
+			t.Errorf("got secret %q, want %q", got, secret)
+		}
+	}
+	if got := get(orgSpec); got != "" {
+		t.Errorf("got org secret %q, want none (only the site secret was set)", got)
+	}
+
+	if err := SavedQueryWebhookSecrets.Set(ctx, siteSpec, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(siteSpec); got != "" {
+		t.Errorf("got secret %q after it was removed, want none", got)
+	}
+}
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// The formats of search exports.
+const (
+	SearchExportFormatCSV   = "CSV"   // comma-separated values with a header row
+	SearchExportFormatJSONL = "JSONL" // JSON Lines (one JSON object per match)
+)
+
+// The states of a search export.
+const (
+	SearchExportStateQueued    = "QUEUED"    // the search is waiting to be run by a worker
+	SearchExportStateRunning   = "RUNNING"   // the search is running
+	SearchExportStateCompleted = "COMPLETED" // the export is ready to be downloaded
+	SearchExportStateErrored   = "ERRORED"   // the search failed
+)
+
+// A SearchExport is a search that is run to completion in the background, and
+// whose matches are written to a file in the given format.
+type SearchExport struct {
+	ID         int64
+	UserID     int32 // the user who created the export
+	Query      string
+	Format     string
+	State      string
+	RowCount   int32 // the number of matches in the export
+	Incomplete bool  // whether some matches are missing (e.g., because of limits or timeouts)
+	Error      string
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+}
+
+// SearchExportNotFoundError occurs when a search export is not found.
+type SearchExportNotFoundError struct {
+	ID int64
+}
+
+// NotFound implements errcode.NotFounder.
+func (err SearchExportNotFoundError) NotFound() bool { return true }
+
+func (err SearchExportNotFoundError) Error() string {
+	return fmt.Sprintf("search export not found: %d", err.ID)
+}
+
+type searchExports struct{}
+
+// Create creates a search export in the queued state.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is the user of the
+// search export.
+func (*searchExports) Create(ctx context.Context, e *SearchExport) (*SearchExport, error) {
+	ne := *e
+	ne.State = SearchExportStateQueued
+	if err := dbconn.Global.QueryRowContext(
+		ctx,
+		"INSERT INTO search_exports(user_id, query, format, state) VALUES($1, $2, $3, $4) RETURNING id, created_at",
+		ne.UserID, ne.Query, ne.Format, ne.State,
+	).Scan(&ne.ID, &ne.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &ne, nil
+}
+
+const searchExportColumns = "id, user_id, query, format, state, row_count, incomplete, error, created_at, finished_at"
+
+func scanSearchExport(scanner interface{ Scan(...interface{}) error }) (*SearchExport, error) {
+	var e SearchExport
+	if err := scanner.Scan(&e.ID, &e.UserID, &e.Query, &e.Format, &e.State, &e.RowCount, &e.Incomplete, &e.Error, &e.CreatedAt, &e.FinishedAt); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// GetByID returns the search export with the given ID (without its data).
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to view
+// this search export.
+func (*searchExports) GetByID(ctx context.Context, id int64) (*SearchExport, error) {
+	e, err := scanSearchExport(dbconn.Global.QueryRowContext(ctx, "SELECT "+searchExportColumns+" FROM search_exports WHERE id=$1", id))
+	if err == sql.ErrNoRows {
+		return nil, SearchExportNotFoundError{ID: id}
+	}
+	return e, err
+}
+
+// ListByUser returns the user's search exports (without their data), most
+// recent first.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to view the
+// user's search exports.
+func (*searchExports) ListByUser(ctx context.Context, userID int32) ([]*SearchExport, error) {
+	rows, err := dbconn.Global.QueryContext(ctx, "SELECT "+searchExportColumns+" FROM search_exports WHERE user_id=$1 ORDER BY id DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exports []*SearchExport
+	for rows.Next() {
+		e, err := scanSearchExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, e)
+	}
+	return exports, rows.Err()
+}
+
+// GetData returns the contents of the completed search export.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to view
+// this search export.
+func (*searchExports) GetData(ctx context.Context, id int64) ([]byte, error) {
+	var data []byte
+	err := dbconn.Global.QueryRowContext(ctx, "SELECT data FROM search_exports WHERE id=$1 AND state=$2", id, SearchExportStateCompleted).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, SearchExportNotFoundError{ID: id}
+	}
+	return data, err
+}
+
+// Dequeue claims the oldest queued search export (or a running export whose
+// heartbeat is older than staleAfter, because the process that ran it died)
+// and moves it to the running state. The caller must call Heartbeat at least
+// every staleAfter until it completes or fails the export. If there is no
+// export to run, it returns nil.
+//
+// Exports that were claimed maxAttempts times without finishing are failed
+// instead of being run again, so that an export that crashes the process
+// running it isn't retried forever.
+func (*searchExports) Dequeue(ctx context.Context, staleAfter time.Duration, maxAttempts int) (*SearchExport, error) {
+	stale := time.Now().Add(-staleAfter)
+	if _, err := dbconn.Global.ExecContext(ctx,
+		"UPDATE search_exports SET state=$1, error=$2, finished_at=now() WHERE state=$3 AND (heartbeat_at IS NULL OR heartbeat_at < $4) AND attempts >= $5",
+		SearchExportStateErrored, "the search export was interrupted too many times", SearchExportStateRunning, stale, maxAttempts,
+	); err != nil {
+		return nil, err
+	}
+
+	// SKIP LOCKED ensures that concurrent workers (in other frontend
+	// processes) claim different exports.
+	e, err := scanSearchExport(dbconn.Global.QueryRowContext(ctx, `
+UPDATE search_exports SET state=$1, heartbeat_at=now(), attempts=attempts+1
+WHERE id=(
+	SELECT id FROM search_exports
+	WHERE state=$2 OR (state=$1 AND (heartbeat_at IS NULL OR heartbeat_at < $3))
+	ORDER BY id ASC
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING `+searchExportColumns,
+		SearchExportStateRunning, SearchExportStateQueued, stale,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return e, err
+}
+
+// Heartbeat records that the running search export is still being run.
+func (*searchExports) Heartbeat(ctx context.Context, id int64) error {
+	return updateSearchExport(ctx,
+		"UPDATE search_exports SET heartbeat_at=now() WHERE id=$1 AND state=$2",
+		id, SearchExportStateRunning,
+	)
+}
+
+// Complete stores the contents of the search export and moves it to the
+// completed state.
+func (*searchExports) Complete(ctx context.Context, id int64, data []byte, rowCount int32, incomplete bool) error {
+	return updateSearchExport(ctx,
+		"UPDATE search_exports SET state=$2, data=$3, row_count=$4, incomplete=$5, finished_at=now() WHERE id=$1",
+		id, SearchExportStateCompleted, data, rowCount, incomplete,
+	)
+}
+
+// Fail moves the search export to the errored state.
+func (*searchExports) Fail(ctx context.Context, id int64, errorMessage string) error {
+	return updateSearchExport(ctx,
+		"UPDATE search_exports SET state=$2, error=$3, finished_at=now() WHERE id=$1",
+		id, SearchExportStateErrored, errorMessage,
+	)
+}
+
+func updateSearchExport(ctx context.Context, query string, id int64, args ...interface{}) error {
+	res, err := dbconn.Global.ExecContext(ctx, query, append([]interface{}{id}, args...)...)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return SearchExportNotFoundError{ID: id}
+	}
+	return nil
+}
+
+// DeleteFinishedBefore deletes the search exports that finished before t.
+func (*searchExports) DeleteFinishedBefore(ctx context.Context, t time.Time) error {
+	_, err := dbconn.Global.ExecContext(ctx, "DELETE FROM search_exports WHERE finished_at < $1", t)
+	return err
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+func TestSearchExports(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	user, err := Users.Create(ctx, NewUser{
+		Email:                 "a@example.com",
+		Username:              "u",
+		Password:              "p",
+		EmailVerificationCode: "c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e1, err := SearchExports.Create(ctx, &SearchExport{UserID: user.ID, Query: "foo", Format: SearchExportFormatCSV})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e1.State != SearchExportStateQueued {
+		t.Errorf("got state %q, want %q", e1.State, SearchExportStateQueued)
+	}
+	e2, err := SearchExports.Create(ctx, &SearchExport{UserID: user.ID, Query: "bar", Format: SearchExportFormatJSONL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Exports are dequeued oldest first, and each export is dequeued only once
+	// while its heartbeat is recent.
+	for _, want := range []int64{e1.ID, e2.ID, 0} {
+		got, err := SearchExports.Dequeue(ctx, time.Hour, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want == 0 {
+			if got != nil {
+				t.Errorf("got export %d, want none", got.ID)
+			}
+			continue
+		}
+		if got == nil || got.ID != want || got.State != SearchExportStateRunning {
+			t.Fatalf("got export %+v, want running export %d", got, want)
+		}
+	}
+	if err := SearchExports.Heartbeat(ctx, e1.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The data of a running export is not available.
+	if _, err := SearchExports.GetData(ctx, e1.ID); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+
+	if err := SearchExports.Complete(ctx, e1.ID, []byte("a,b\n"), 1, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := SearchExports.Fail(ctx, e2.ID, "boom"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := SearchExports.GetByID(ctx, e1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.State != SearchExportStateCompleted || got.RowCount != 1 || !got.Incomplete || got.FinishedAt == nil {
+		t.Errorf("unexpected completed export: %+v", got)
+	}
+	data, err := SearchExports.GetData(ctx, e1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "a,b\n" {
+		t.Errorf("got data %q", data)
+	}
+
+	exports, err := SearchExports.ListByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exports) != 2 || exports[0].ID != e2.ID || exports[0].State != SearchExportStateErrored || exports[0].Error != "boom" {
+		t.Errorf("unexpected exports: %+v", exports)
+	}
+
+	// Running exports whose heartbeat is stale are dequeued again, until they
+	// were attempted too many times.
+	e3, err := SearchExports.Create(ctx, &SearchExport{UserID: user.ID, Query: "baz", Format: SearchExportFormatCSV})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if got, err := SearchExports.Dequeue(ctx, -time.Second, 2); err != nil {
+			t.Fatal(err)
+		} else if got == nil || got.ID != e3.ID {
+			t.Fatalf("attempt %d: got export %+v, want %d", i, got, e3.ID)
+		}
+	}
+	if got, err := SearchExports.Dequeue(ctx, -time.Second, 2); err != nil {
+		t.Fatal(err)
+	} else if got != nil {
+		t.Errorf("got export %+v, want none", got)
+	}
+	if got, err := SearchExports.GetByID(ctx, e3.ID); err != nil {
+		t.Fatal(err)
+	} else if got.State != SearchExportStateErrored {
+		t.Errorf("got state %q, want %q", got.State, SearchExportStateErrored)
+	}
+
+	if err := SearchExports.DeleteFinishedBefore(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SearchExports.GetByID(ctx, e1.ID); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+	if err := SearchExports.Fail(ctx, e1.ID, "x"); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+}
@@ -2,22 +2,29 @@ package db
 
 var (
 	AccessTokens              = &accessTokens{}
+	AuditLog                  = &auditLog{}
 	ExternalServices          = &ExternalServicesStore{}
 	DiscussionThreads         = &discussionThreads{}
 	DiscussionComments        = &discussionComments{}
 	DiscussionMailReplyTokens = &discussionMailReplyTokens{}
 	Repos                     = &repos{}
+	RewriteBatches            = &rewriteBatches{}
 	Phabricator               = &phabricator{}
 	QueryRunnerState          = &queryRunnerState{}
 	Orgs                      = &orgs{}
 	OrgMembers                = &orgMembers{}
 	SavedSearches             = &savedSearches{}
+	SearchExports             = &searchExports{}
 	Settings                  = &settings{}
 	Users                     = &users{}
 	UserEmails                = &userEmails{}
+	UserPermissions           = &userPermissions{}
 
 	SurveyResponses = &surveyResponses{}
 
+	SavedQueryWebhookDeliveries = &savedQueryWebhookDeliveries{}
+	SavedQueryWebhookSecrets    = &savedQueryWebhookSecrets{}
+
 	ExternalAccounts = &userExternalAccounts{}
 
 	OrgInvitations = &orgInvitations{}
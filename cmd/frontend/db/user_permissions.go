@@ -0,0 +1,180 @@
+package db
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"math/bits"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
+)
+
+// UserPermission is the set of repositories on which a user has a permission, as determined by the
+// authz provider of the code host with the given service type and ID. It is synced in the background
+// (see authz.PermsSyncer) so that the user's permissions need not be fetched from the code host when
+// repositories are filtered for the user.
+type UserPermission struct {
+	UserID      int32
+	Perm        authz.Perm
+	ServiceType string
+	ServiceID   string
+	UpdatedAt   time.Time
+
+	// repoIDs is a bitmap of the IDs of the repositories on which the user has the permission.
+	repoIDs []uint64
+}
+
+// AddRepo records that the user has the permission on the repository with the given ID.
+func (p *UserPermission) AddRepo(id api.RepoID) {
+	i := int(id) / 64
+	if i >= len(p.repoIDs) {
+		p.repoIDs = append(p.repoIDs, make([]uint64, i+1-len(p.repoIDs))...)
+	}
+	p.repoIDs[i] |= 1 << (uint(id) % 64)
+}
+
+// HasRepo reports whether the user has the permission on the repository with the given ID.
+func (p *UserPermission) HasRepo(id api.RepoID) bool {
+	i := int(id) / 64
+	return id >= 0 && i < len(p.repoIDs) && p.repoIDs[i]&(1<<(uint(id)%64)) != 0
+}
+
+// RepoIDs returns the IDs of the repositories on which the user has the permission, in ascending
+// order.
+func (p *UserPermission) RepoIDs() []api.RepoID {
+	var ids []api.RepoID
+	for i, w := range p.repoIDs {
+		for w != 0 {
+			b := bits.TrailingZeros64(w)
+			ids = append(ids, api.RepoID(i*64+b))
+			w &^= 1 << uint(b)
+		}
+	}
+	return ids
+}
+
+// marshalRepoIDs encodes the bitmap of repository IDs for storage. The bitmap is compressed, because
+// it is usually sparse.
+func (p *UserPermission) marshalRepoIDs() ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, p.repoIDs); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (p *UserPermission) unmarshalRepoIDs(data []byte) error {
+	b, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return err
+	}
+	if len(b)%8 != 0 {
+		return errors.Errorf("invalid repository ID bitmap length %d", len(b))
+	}
+	p.repoIDs = make([]uint64, len(b)/8)
+	for i := range p.repoIDs {
+		p.repoIDs[i] = binary.LittleEndian.Uint64(b[i*8:])
+	}
+	return nil
+}
+
+type userPermissions struct{}
+
+// ListByUser lists the synced permissions of the user, one for each code host whose permissions were
+// synced.
+func (*userPermissions) ListByUser(ctx context.Context, userID int32, perm authz.Perm) ([]*UserPermission, error) {
+	if Mocks.UserPermissions.ListByUser != nil {
+		return Mocks.UserPermissions.ListByUser(userID, perm)
+	}
+
+	q := sqlf.Sprintf("SELECT service_type, service_id, object_ids, updated_at FROM user_permissions WHERE user_id=%d AND permission=%s ORDER BY service_type, service_id", userID, string(perm))
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var perms []*UserPermission
+	for rows.Next() {
+		p := UserPermission{UserID: userID, Perm: perm}
+		var repoIDs []byte
+		if err := rows.Scan(&p.ServiceType, &p.ServiceID, &repoIDs, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := p.unmarshalRepoIDs(repoIDs); err != nil {
+			return nil, errors.Wrapf(err, "user permissions for %s %s", p.ServiceType, p.ServiceID)
+		}
+		perms = append(perms, &p)
+	}
+	return perms, rows.Err()
+}
+
+// Upsert stores the permission, replacing the previously synced permission of the user for the same
+// code host. It sets p.UpdatedAt to the time of the update.
+func (*userPermissions) Upsert(ctx context.Context, p *UserPermission) error {
+	if Mocks.UserPermissions.Upsert != nil {
+		return Mocks.UserPermissions.Upsert(p)
+	}
+
+	repoIDs, err := p.marshalRepoIDs()
+	if err != nil {
+		return err
+	}
+	if err := dbconn.Global.QueryRowContext(ctx, `
+INSERT INTO user_permissions(user_id, permission, service_type, service_id, object_ids, updated_at)
+VALUES($1, $2, $3, $4, $5, now())
+ON CONFLICT (user_id, permission, service_type, service_id)
+DO UPDATE SET object_ids=excluded.object_ids, updated_at=excluded.updated_at
+RETURNING updated_at`,
+		p.UserID, string(p.Perm), p.ServiceType, p.ServiceID, repoIDs,
+	).Scan(&p.UpdatedAt); err != nil {
+		return errors.Wrap(err, "UPSERT")
+	}
+	return nil
+}
+
+// ListStaleUserIDs returns the IDs of up to limit users whose permissions for the code host with
+// the given service type and ID were not synced since the given time (or were never synced), least
+// recently synced first.
+func (*userPermissions) ListStaleUserIDs(ctx context.Context, perm authz.Perm, serviceType, serviceID string, since time.Time, limit int) ([]int32, error) {
+	if Mocks.UserPermissions.ListStaleUserIDs != nil {
+		return Mocks.UserPermissions.ListStaleUserIDs(perm, serviceType, serviceID, since, limit)
+	}
+
+	q := sqlf.Sprintf(`
+SELECT users.id FROM users
+LEFT JOIN user_permissions p ON p.user_id=users.id AND p.permission=%s AND p.service_type=%s AND p.service_id=%s
+WHERE users.deleted_at IS NULL AND (p.updated_at IS NULL OR p.updated_at < %s)
+ORDER BY p.updated_at ASC NULLS FIRST, users.id ASC
+LIMIT %d`,
+		string(perm), serviceType, serviceID, since, limit)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, id)
+	}
+	return userIDs, rows.Err()
+}
@@ -0,0 +1,13 @@
+package db
+
+import (
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+)
+
+type MockUserPermissions struct {
+	ListByUser       func(userID int32, perm authz.Perm) ([]*UserPermission, error)
+	Upsert           func(p *UserPermission) error
+	ListStaleUserIDs func(perm authz.Perm, serviceType, serviceID string, since time.Time, limit int) ([]int32, error)
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/db/dbtesting"
+)
+
+func TestUserPermission_repoIDs(t *testing.T) {
+	var p UserPermission
+	for _, id := range []api.RepoID{1, 63, 64, 1000, 1} {
+		p.AddRepo(id)
+	}
+	want := []api.RepoID{1, 63, 64, 1000}
+	if got := p.RepoIDs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got repo IDs %v, want %v", got, want)
+	}
+	if p.HasRepo(2) || p.HasRepo(1001) || p.HasRepo(-1) || !p.HasRepo(64) {
+		t.Error("unexpected HasRepo result")
+	}
+
+	data, err := p.marshalRepoIDs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p2 UserPermission
+	if err := p2.unmarshalRepoIDs(data); err != nil {
+		t.Fatal(err)
+	}
+	if got := p2.RepoIDs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got unmarshaled repo IDs %v, want %v", got, want)
+	}
+}
+
+func TestUserPermissions(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	ctx := dbtesting.TestContext(t)
+
+	var userIDs []int32
+	for _, username := range []string{"u1", "u2"} {
+		user, err := Users.Create(ctx, NewUser{Username: username})
+		if err != nil {
+			t.Fatal(err)
+		}
+		userIDs = append(userIDs, user.ID)
+	}
+
+	stale, err := UserPermissions.ListStaleUserIDs(ctx, authz.Read, "gitlab", "https://gitlab.com/", time.Now(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stale, userIDs) {
+		t.Errorf("got stale user IDs %v, want %v", stale, userIDs)
+	}
+
+	p := &UserPermission{UserID: userIDs[0], Perm: authz.Read, ServiceType: "gitlab", ServiceID: "https://gitlab.com/"}
+	p.AddRepo(1)
+	if err := UserPermissions.Upsert(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	p.AddRepo(2)
+	if err := UserPermissions.Upsert(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	perms, err := UserPermissions.ListByUser(ctx, userIDs[0], authz.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(perms) != 1 {
+		t.Fatalf("got %d permissions, want 1", len(perms))
+	}
+	if got, want := perms[0].RepoIDs(), []api.RepoID{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got repo IDs %v, want %v", got, want)
+	}
+	if !perms[0].UpdatedAt.Equal(p.UpdatedAt) {
+		t.Errorf("got updated at %s, want %s", perms[0].UpdatedAt, p.UpdatedAt)
+	}
+	if perms, err := UserPermissions.ListByUser(ctx, userIDs[1], authz.Read); err != nil {
+		t.Fatal(err)
+	} else if len(perms) != 0 {
+		t.Errorf("got %d permissions for user without synced permissions, want 0", len(perms))
+	}
+
+	stale, err = UserPermissions.ListStaleUserIDs(ctx, authz.Read, "gitlab", "https://gitlab.com/", p.UpdatedAt, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := userIDs[1:]; !reflect.DeepEqual(stale, want) {
+		t.Errorf("got stale user IDs %v, want %v", stale, want)
+	}
+}
@@ -71,3 +71,11 @@ func (r *accessTokenResolver) LastUsedAt() *string {
 	t := r.accessToken.LastUsedAt.Format(time.RFC3339)
 	return &t
 }
+
+func (r *accessTokenResolver) ExpiresAt() *string {
+	if r.accessToken.ExpiresAt == nil {
+		return nil
+	}
+	t := r.accessToken.ExpiresAt.Format(time.RFC3339)
+	return &t
+}
@@ -0,0 +1,262 @@
+package graphqlbackend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+)
+
+// mutationScopes maps each root Mutation field that may be performed by an actor with a
+// scope-restricted access token (i.e., one without the "user:all" scope) to the scopes that permit
+// it. Mutations that are not listed here may only be performed by unrestricted actors.
+//
+// 🚨 SECURITY: The resolvers for these fields must also check the actor's scopes (with
+// backend.CheckActorHasScope), because some of them (such as settingsMutation) have nested fields
+// that require more specific scopes.
+var mutationScopes = map[string][]string{
+	"settingsMutation":                {authz.ScopeSettingsWrite, authz.ScopeSavedSearchesWrite},
+	"configurationMutation":           {authz.ScopeSettingsWrite, authz.ScopeSavedSearchesWrite},
+	"sendSavedSearchTestNotification": {authz.ScopeSavedSearchesWrite, authz.ScopeSettingsWrite},
+	"extensionRegistry":               {authz.ScopeExtensionsPublish},
+	"addExternalService":              {authz.ScopeExternalServicesWrite},
+	"updateExternalService":           {authz.ScopeExternalServicesWrite},
+	"deleteExternalService":           {authz.ScopeExternalServicesWrite},
+}
+
+// CheckAccessTokenScopes returns an error if the GraphQL document contains a mutation that the
+// actor is not permitted to perform given its access token scopes. All access tokens may perform
+// queries.
+//
+// 🚨 SECURITY: This must be called before executing GraphQL requests from actors that may have been
+// authenticated with an access token.
+func CheckAccessTokenScopes(ctx context.Context, doc string) error {
+	if actor.FromContext(ctx).Scopes == nil {
+		return nil
+	}
+
+	fields, err := mutationFields(doc)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		scopes, ok := mutationScopes[field]
+		if !ok {
+			return &backend.InsufficientScopeError{Scopes: []string{authz.ScopeUserAll}}
+		}
+		if err := backend.CheckActorHasScope(ctx, scopes...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var errUnsupportedScopedMutation = errors.New("mutations that select root fields using fragments are not supported for scope-restricted access tokens")
+
+// mutationFields returns the names of the root fields selected by all mutation (and subscription)
+// operations in the GraphQL document, regardless of the operation name that will be executed.
+//
+// It is deliberately conservative: it returns an error for any document that it does not fully
+// understand, even if the document would be valid.
+func mutationFields(doc string) ([]string, error) {
+	tokens, err := lexGraphQL(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for i := 0; i < len(tokens); {
+		if tokens[i] == "{" {
+			// Query shorthand.
+			i, err = skipBalanced(tokens, i, "{", "}")
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		op := tokens[i]
+		switch op {
+		case "query", "mutation", "subscription", "fragment":
+		default:
+			return nil, fmt.Errorf("unexpected %q in GraphQL document", op)
+		}
+
+		// Skip the name, variable definitions, and directives to find the selection set. Default
+		// values in variable definitions may contain braces, so only consider braces outside of
+		// parentheses.
+		j, depth := i+1, 0
+		for ; j < len(tokens); j++ {
+			if tokens[j] == "(" {
+				depth++
+			} else if tokens[j] == ")" {
+				depth--
+			} else if tokens[j] == "{" && depth == 0 {
+				break
+			}
+		}
+		if j == len(tokens) {
+			return nil, fmt.Errorf("GraphQL %s has no selection set", op)
+		}
+
+		if op == "mutation" || op == "subscription" {
+			var opFields []string
+			opFields, i, err = rootSelections(tokens, j)
+			fields = append(fields, opFields...)
+		} else {
+			i, err = skipBalanced(tokens, j, "{", "}")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+// rootSelections returns the field names (not aliases) in the selection set that starts at
+// tokens[start] and the index of the token after the selection set.
+func rootSelections(tokens []string, start int) (fields []string, end int, err error) {
+	peek := func(i int) string {
+		if i < len(tokens) {
+			return tokens[i]
+		}
+		return ""
+	}
+
+	i := start + 1
+	for {
+		switch t := peek(i); {
+		case t == "}":
+			return fields, i + 1, nil
+		case t == "...":
+			return nil, 0, errUnsupportedScopedMutation
+		case !isGraphQLName(t):
+			return nil, 0, fmt.Errorf("unexpected %q in GraphQL selection set", t)
+		}
+
+		name := tokens[i]
+		i++
+		if peek(i) == ":" {
+			if name = peek(i + 1); !isGraphQLName(name) {
+				return nil, 0, fmt.Errorf("unexpected %q after GraphQL alias", name)
+			}
+			i += 2
+		}
+		fields = append(fields, name)
+
+		if peek(i) == "(" {
+			if i, err = skipBalanced(tokens, i, "(", ")"); err != nil {
+				return nil, 0, err
+			}
+		}
+		for peek(i) == "@" && isGraphQLName(peek(i+1)) {
+			i += 2
+			if peek(i) == "(" {
+				if i, err = skipBalanced(tokens, i, "(", ")"); err != nil {
+					return nil, 0, err
+				}
+			}
+		}
+		if peek(i) == "{" {
+			if i, err = skipBalanced(tokens, i, "{", "}"); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+}
+
+// skipBalanced returns the index of the token after the close token that matches the open token
+// at tokens[start].
+func skipBalanced(tokens []string, start int, open, close string) (int, error) {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch tokens[i] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unbalanced %q in GraphQL document", open)
+}
+
+// lexGraphQL splits a GraphQL document into tokens. String and number values are replaced by
+// placeholder tokens because their contents are not needed.
+//
+// It mirrors the lexing behavior of our GraphQL server (including that it does not support block
+// strings), so that it is not possible to hide a mutation from it (e.g., in what it considers to
+// be a string or comment).
+func lexGraphQL(doc string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(doc); {
+		c := doc[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+
+		case c == '#':
+			for i < len(doc) && doc[i] != '\n' && doc[i] != '\r' {
+				i++
+			}
+
+		case c == '"':
+			j := i + 1
+			for ; j < len(doc) && doc[j] != '"'; j++ {
+				if doc[j] == '\n' || doc[j] == '\r' {
+					return nil, errors.New("unterminated string in GraphQL document")
+				}
+				if doc[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(doc) {
+				return nil, errors.New("unterminated string in GraphQL document")
+			}
+			tokens = append(tokens, `""`)
+			i = j + 1
+
+		case strings.HasPrefix(doc[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+
+		case strings.IndexByte("!$():=@[]{}|", c) != -1:
+			tokens = append(tokens, string(c))
+			i++
+
+		case isGraphQLNameStart(c):
+			j := i + 1
+			for j < len(doc) && (isGraphQLNameStart(doc[j]) || isDigit(doc[j])) {
+				j++
+			}
+			tokens = append(tokens, doc[i:j])
+			i = j
+
+		case c == '-' || isDigit(c):
+			j := i + 1
+			for j < len(doc) && (isDigit(doc[j]) || strings.IndexByte(".eE+-", doc[j]) != -1) {
+				j++
+			}
+			tokens = append(tokens, "0")
+			i = j
+
+		default:
+			return nil, fmt.Errorf("unexpected character %q in GraphQL document", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isGraphQLName(s string) bool { return s != "" && isGraphQLNameStart(s[0]) }
+
+func isGraphQLNameStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
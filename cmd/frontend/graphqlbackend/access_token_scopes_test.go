@@ -0,0 +1,99 @@
+package graphqlbackend
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+)
+
+func TestMutationFields(t *testing.T) {
+	tests := map[string][]string{
+		`{ currentUser { username } }`:                                           nil,
+		`query Q($a: Int = 1) @d(x: {y: "}"}) { search { results } }`:            nil,
+		`mutation { logUserEvent(event: "x", userCookieID: "y") { alwaysNil } }`: {"logUserEvent"},
+		`mutation M($s: ID!) {
+			a: settingsMutation(input: {subject: $s}) { editSettings(edit: {keyPath: []}) { empty { alwaysNil } } }
+			deleteExternalService(externalService: "x") @include(if: true) { alwaysNil }
+		}`: {"settingsMutation", "deleteExternalService"},
+		`query A { x } mutation B { y } fragment F on Query { z } mutation { w }`: {"y", "w"},
+		`subscription { s }`:                     {"s"},
+		"mutation { a(x: \"}\\\"\") # } b\n c }": {"a", "c"},
+	}
+	for doc, want := range tests {
+		got, err := mutationFields(doc)
+		if err != nil {
+			t.Errorf("%q: %s", doc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got fields %q, want %q", doc, got, want)
+		}
+	}
+
+	for _, doc := range []string{
+		`mutation { ...F } fragment F on Mutation { deleteUser(user: "x") { alwaysNil } }`,
+		`mutation { ... on Mutation { deleteUser(user: "x") { alwaysNil } } }`,
+		`mutation { a(x: "unterminated) }`,
+		`mutation { a`,
+		`mutation`,
+		`foo { a }`,
+		`mutation { a: }`,
+		"mutation { a(x: \"\n\") }",
+		"mutation { é }",
+	} {
+		if _, err := mutationFields(doc); err == nil {
+			t.Errorf("%q: got no error, want error", doc)
+		}
+	}
+}
+
+func TestCheckAccessTokenScopes(t *testing.T) {
+	withScopes := func(scopes ...string) context.Context {
+		return actor.WithActor(context.Background(), &actor.Actor{UID: 1, Scopes: scopes})
+	}
+
+	tests := map[string]struct {
+		ctx     context.Context
+		doc     string
+		wantErr bool
+	}{
+		"unrestricted actor": {
+			ctx: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+			doc: `mutation { createAccessToken(user: "x", scopes: [], note: "") { token } }`,
+		},
+		"query": {
+			ctx: withScopes(authz.ScopeUserRead),
+			doc: `query { currentUser { username } }`,
+		},
+		"mutation without scope": {
+			ctx:     withScopes(authz.ScopeUserRead),
+			doc:     `mutation { settingsMutation(input: {subject: "x"}) { overwriteSettings(contents: "{}") { empty { alwaysNil } } } }`,
+			wantErr: true,
+		},
+		"mutation with scope": {
+			ctx: withScopes(authz.ScopeSettingsWrite),
+			doc: `mutation { settingsMutation(input: {subject: "x"}) { overwriteSettings(contents: "{}") { empty { alwaysNil } } } }`,
+		},
+		"mutation not permitted by any scope": {
+			ctx:     withScopes(authz.ScopeSettingsWrite, authz.ScopeExternalServicesWrite),
+			doc:     `mutation { createAccessToken(user: "x", scopes: ["user:all"], note: "") { token } }`,
+			wantErr: true,
+		},
+		"mutation hidden in other operation": {
+			ctx:     withScopes(authz.ScopeUserRead),
+			doc:     `query A { currentUser { username } } mutation B { deleteUser(user: "x") { alwaysNil } }`,
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := CheckAccessTokenScopes(test.ctx, test.doc)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("got error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
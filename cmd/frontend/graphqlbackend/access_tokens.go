@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"sync"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
@@ -17,9 +19,10 @@ import (
 )
 
 type createAccessTokenInput struct {
-	User   graphql.ID
-	Scopes []string
-	Note   string
+	User      graphql.ID
+	Scopes    []string
+	Note      string
+	ExpiresAt *string
 }
 
 func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAccessTokenInput) (*createAccessTokenResult, error) {
@@ -46,13 +49,13 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 	}
 
 	// Validate scopes.
-	var hasUserAllScope bool
+	var hasUserScope bool
 	seenScope := map[string]struct{}{}
 	sort.Strings(args.Scopes)
 	for _, scope := range args.Scopes {
 		switch scope {
-		case authz.ScopeUserAll:
-			hasUserAllScope = true
+		case authz.ScopeUserAll, authz.ScopeUserRead, authz.ScopeSettingsWrite, authz.ScopeSavedSearchesWrite, authz.ScopeExtensionsPublish, authz.ScopeExternalServicesWrite:
+			hasUserScope = true
 		case authz.ScopeSiteAdminSudo:
 			// 🚨 SECURITY: Only site admins may create a token with the "site-admin:sudo" scope.
 			if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
@@ -67,12 +70,28 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 		}
 		seenScope[scope] = struct{}{}
 	}
-	if !hasUserAllScope {
-		return nil, fmt.Errorf("all access tokens must have scope %q", authz.ScopeUserAll)
+	if !hasUserScope {
+		return nil, fmt.Errorf("all access tokens must have at least one scope other than %q (such as %q)", authz.ScopeSiteAdminSudo, authz.ScopeUserAll)
 	}
 
-	id, token, err := db.AccessTokens.Create(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID)
-	return &createAccessTokenResult{id: marshalAccessTokenID(id), token: token}, err
+	var expiresAt *time.Time
+	if args.ExpiresAt != nil {
+		t, err := time.Parse(time.RFC3339, *args.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid access token expiration date %q (must be an RFC 3339 date)", *args.ExpiresAt)
+		}
+		if !t.After(time.Now()) {
+			return nil, errors.New("access token expiration date must be in the future")
+		}
+		expiresAt = &t
+	}
+
+	id, token, err := db.AccessTokens.Create(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	backend.LogAuditEvent(ctx, db.AuditActionAccessTokenCreate, db.AuditTargetAccessToken, strconv.FormatInt(id, 10), nil)
+	return &createAccessTokenResult{id: marshalAccessTokenID(id), token: token}, nil
 }
 
 type createAccessTokenResult struct {
@@ -116,13 +135,16 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 		if err := db.AccessTokens.DeleteByID(ctx, token.ID, token.SubjectUserID); err != nil {
 			return nil, err
 		}
+		backend.LogAuditEvent(ctx, db.AuditActionAccessTokenDelete, db.AuditTargetAccessToken, strconv.FormatInt(token.ID, 10), nil)
 
 	case args.ByToken != nil:
 		// 🚨 SECURITY: This is easier than the ByID case because anyone holding the access token's
 		// secret value is assumed to be allowed to delete it.
-		if err := db.AccessTokens.DeleteByToken(ctx, *args.ByToken); err != nil {
+		id, err := db.AccessTokens.DeleteByToken(ctx, *args.ByToken)
+		if err != nil {
 			return nil, err
 		}
+		backend.LogAuditEvent(ctx, db.AuditActionAccessTokenDelete, db.AuditTargetAccessToken, strconv.FormatInt(id, 10), nil)
 	}
 	if err != nil {
 		return nil, err
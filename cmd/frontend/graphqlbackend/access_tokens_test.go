@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/gqltesting"
@@ -17,7 +18,7 @@ import (
 // 🚨 SECURITY: This tests that users can't create tokens for users they aren't allowed to do so for.
 func TestMutation_CreateAccessToken(t *testing.T) {
 	mockAccessTokensCreate := func(t *testing.T, wantCreatorUserID int32, wantScopes []string) {
-		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (int64, string, error) {
+		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32, expiresAt *time.Time) (int64, string, error) {
 			if want := int32(1); subjectUserID != want {
 				t.Errorf("got %v, want %v", subjectUserID, want)
 			}
@@ -32,6 +33,13 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 			}
 			return 1, "t", nil
 		}
+		db.Mocks.AuditLog.Create = func(e *db.AuditLogEntry) error {
+			want := db.AuditLogEntry{ActorUserID: wantCreatorUserID, Action: db.AuditActionAccessTokenCreate, TargetType: db.AuditTargetAccessToken, TargetID: "1"}
+			if !reflect.DeepEqual(*e, want) {
+				t.Errorf("got audit log entry %+v, want %+v", *e, want)
+			}
+			return nil
+		}
 	}
 
 	const uid1GQLID = "VXNlcjox"
@@ -76,6 +84,77 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 		}
 	})
 
+	t.Run("authenticated as user, using restricted scopes with expiration date", func(t *testing.T) {
+		resetMocks()
+		wantExpiresAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+		var calledCreate bool
+		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32, expiresAt *time.Time) (int64, string, error) {
+			calledCreate = true
+			if want := []string{authz.ScopeSettingsWrite, authz.ScopeUserRead}; !reflect.DeepEqual(scopes, want) {
+				t.Errorf("got %q, want %q", scopes, want)
+			}
+			if expiresAt == nil || !expiresAt.Equal(wantExpiresAt) {
+				t.Errorf("got expiresAt %v, want %v", expiresAt, wantExpiresAt)
+			}
+			return 1, "t", nil
+		}
+		db.Mocks.AuditLog.Create = func(*db.AuditLogEntry) error { return nil }
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		expiresAt := wantExpiresAt.Format(time.RFC3339)
+		if _, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:      uid1GQLID,
+			Scopes:    []string{authz.ScopeUserRead, authz.ScopeSettingsWrite},
+			Note:      "n",
+			ExpiresAt: &expiresAt,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if !calledCreate {
+			t.Error("!calledCreate")
+		}
+	})
+
+	t.Run("authenticated as user, using expiration date in the past", func(t *testing.T) {
+		resetMocks()
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		expiresAt := time.Now().Add(-time.Hour).Format(time.RFC3339)
+		result, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:      uid1GQLID,
+			Scopes:    []string{authz.ScopeUserAll},
+			Note:      "n",
+			ExpiresAt: &expiresAt,
+		})
+		if err == nil {
+			t.Error("err == nil")
+		}
+		if result != nil {
+			t.Errorf("got result %v, want nil", result)
+		}
+	})
+
+	t.Run("authenticated as site admin, using only sudo scope", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+			return &types.User{ID: 1, SiteAdmin: true}, nil
+		}
+		defer func() { db.Mocks.Users.GetByCurrentAuthUser = nil }()
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		result, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:   uid1GQLID,
+			Scopes: []string{authz.ScopeSiteAdminSudo},
+			Note:   "n",
+		})
+		if err == nil {
+			t.Error("err == nil")
+		}
+		if result != nil {
+			t.Errorf("got result %v, want nil", result)
+		}
+	})
+
 	t.Run("authenticated as user, using site-admin-only scopes", func(t *testing.T) {
 		resetMocks()
 		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
@@ -220,6 +299,12 @@ func TestMutation_DeleteAccessToken(t *testing.T) {
 			}
 			return &db.AccessToken{ID: 1, SubjectUserID: 2}, nil
 		}
+		db.Mocks.AuditLog.Create = func(e *db.AuditLogEntry) error {
+			if e.Action != db.AuditActionAccessTokenDelete || e.TargetID != "1" {
+				t.Errorf("unexpected audit log entry %+v", *e)
+			}
+			return nil
+		}
 	}
 
 	token1GQLID := graphql.ID("QWNjZXNzVG9rZW46MQ==")
@@ -0,0 +1,149 @@
+package graphqlbackend
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+type auditLogArgs struct {
+	graphqlutil.ConnectionArgs
+	Actor      *graphql.ID
+	Action     *string
+	TargetType *string
+	TargetID   *string
+}
+
+func (r *siteResolver) AuditLog(ctx context.Context, args *auditLogArgs) (*auditLogEntryConnectionResolver, error) {
+	// 🚨 SECURITY: Only site admins can view the audit log.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var opt db.AuditLogListOptions
+	if args.Actor != nil {
+		var err error
+		opt.ActorUserID, err = UnmarshalUserID(*args.Actor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if args.Action != nil {
+		opt.Action = *args.Action
+	}
+	if args.TargetType != nil {
+		opt.TargetType = *args.TargetType
+	}
+	if args.TargetID != nil {
+		opt.TargetID = *args.TargetID
+	}
+	args.ConnectionArgs.Set(&opt.LimitOffset)
+	return &auditLogEntryConnectionResolver{opt: opt}, nil
+}
+
+// auditLogEntryConnectionResolver resolves a list of audit log entries.
+//
+// 🚨 SECURITY: When instantiating an auditLogEntryConnectionResolver value, the caller MUST check
+// permissions.
+type auditLogEntryConnectionResolver struct {
+	opt db.AuditLogListOptions
+
+	// cache results because they are used by multiple fields
+	once    sync.Once
+	entries []*db.AuditLogEntry
+	err     error
+}
+
+func (r *auditLogEntryConnectionResolver) compute(ctx context.Context) ([]*db.AuditLogEntry, error) {
+	r.once.Do(func() {
+		opt2 := r.opt
+		if opt2.LimitOffset != nil {
+			tmp := *opt2.LimitOffset
+			opt2.LimitOffset = &tmp
+			opt2.Limit++ // so we can detect if there is a next page
+		}
+
+		r.entries, r.err = db.AuditLog.List(ctx, opt2)
+	})
+	return r.entries, r.err
+}
+
+func (r *auditLogEntryConnectionResolver) Nodes(ctx context.Context) ([]*auditLogEntryResolver, error) {
+	entries, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.LimitOffset != nil && len(entries) > r.opt.Limit {
+		entries = entries[:r.opt.Limit]
+	}
+
+	l := make([]*auditLogEntryResolver, len(entries))
+	for i, entry := range entries {
+		l[i] = &auditLogEntryResolver{entry: entry}
+	}
+	return l, nil
+}
+
+func (r *auditLogEntryConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := db.AuditLog.Count(ctx, r.opt)
+	return int32(count), err
+}
+
+func (r *auditLogEntryConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	entries, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(r.opt.LimitOffset != nil && len(entries) > r.opt.Limit), nil
+}
+
+type auditLogEntryResolver struct {
+	entry *db.AuditLogEntry
+}
+
+func (r *auditLogEntryResolver) ID() graphql.ID { return relay.MarshalID("AuditLogEntry", r.entry.ID) }
+
+func (r *auditLogEntryResolver) CreatedAt() string { return r.entry.CreatedAt.Format(time.RFC3339) }
+
+func (r *auditLogEntryResolver) Actor(ctx context.Context) (*UserResolver, error) {
+	if r.entry.ActorUserID == 0 {
+		return nil, nil
+	}
+	user, err := UserByIDInt32(ctx, r.entry.ActorUserID)
+	if errcode.IsNotFound(err) {
+		return nil, nil // the user was deleted
+	}
+	return user, err
+}
+
+func (r *auditLogEntryResolver) ActorUserID() *int32 {
+	if r.entry.ActorUserID == 0 {
+		return nil
+	}
+	return &r.entry.ActorUserID
+}
+
+func (r *auditLogEntryResolver) Action() string       { return r.entry.Action }
+func (r *auditLogEntryResolver) TargetType() string   { return r.entry.TargetType }
+func (r *auditLogEntryResolver) TargetID() string     { return r.entry.TargetID }
+func (r *auditLogEntryResolver) IP() string           { return r.entry.IP }
+func (r *auditLogEntryResolver) ForwardedFor() string { return r.entry.ForwardedFor }
+
+func (r *auditLogEntryResolver) Diff() (*jsonValue, error) {
+	if r.entry.Diff == nil {
+		return nil, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(r.entry.Diff, &v); err != nil {
+		return nil, err
+	}
+	return &jsonValue{value: v}, nil
+}
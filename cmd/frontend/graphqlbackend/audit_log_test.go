@@ -0,0 +1,98 @@
+package graphqlbackend
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+)
+
+func TestSite_AuditLog(t *testing.T) {
+	resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: true}, nil
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "alice"}, nil
+	}
+	db.Mocks.AuditLog.List = func(opt db.AuditLogListOptions) ([]*db.AuditLogEntry, error) {
+		if opt.ActorUserID != 1 || opt.Action != db.AuditActionSiteConfigUpdate || opt.Limit != 2 {
+			t.Errorf("unexpected options %+v", opt)
+		}
+		entry := &db.AuditLogEntry{
+			ID:          3,
+			CreatedAt:   time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC),
+			ActorUserID: 1,
+			Action:      db.AuditActionSiteConfigUpdate,
+			TargetType:  db.AuditTargetSiteConfig,
+			TargetID:    "site",
+			IP:          "127.0.0.1",
+			Diff:        json.RawMessage(`[{"path":["a"],"before":1,"after":2}]`),
+		}
+		return []*db.AuditLogEntry{entry, entry}, nil // 2 entries (1 more than the limit)
+	}
+	db.Mocks.AuditLog.Count = func(opt db.AuditLogListOptions) (int, error) { return 5, nil }
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+			Schema:  GraphQLSchema,
+			Query: `
+				{
+					site {
+						auditLog(first: 1, actor: "VXNlcjox", action: "site_config.update") {
+							nodes { createdAt actor { username } action targetType targetID ip forwardedFor diff }
+							totalCount
+							pageInfo { hasNextPage }
+						}
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"site": {
+						"auditLog": {
+							"nodes": [
+								{
+									"createdAt": "2019-01-02T03:04:05Z",
+									"actor": { "username": "alice" },
+									"action": "site_config.update",
+									"targetType": "site_config",
+									"targetID": "site",
+									"ip": "127.0.0.1",
+									"forwardedFor": "",
+									"diff": [{"path": ["a"], "before": 1, "after": 2}]
+								}
+							],
+							"totalCount": 5,
+							"pageInfo": { "hasNextPage": true }
+						}
+					}
+				}
+			`,
+		},
+	})
+}
+
+// 🚨 SECURITY: This tests that only site admins can view the audit log.
+func TestSite_AuditLog_nonSiteAdmin(t *testing.T) {
+	resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1}, nil
+	}
+	db.Mocks.AuditLog.List = func(db.AuditLogListOptions) ([]*db.AuditLogEntry, error) {
+		t.Fatal("audit log must not be listed")
+		return nil, nil
+	}
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	if _, err := (&siteResolver{}).AuditLog(ctx, &auditLogArgs{}); err != backend.ErrMustBeSiteAdmin {
+		t.Errorf("got error %v, want %v", err, backend.ErrMustBeSiteAdmin)
+	}
+}
@@ -0,0 +1,31 @@
+package graphqlbackend
+
+import (
+	"errors"
+	"strconv"
+)
+
+// bigInt implements the BigInt scalar type. In GraphQL queries, it is represented as a string of
+// decimal digits, because JSON numbers can't represent all 64-bit integers precisely.
+type bigInt int64
+
+func (bigInt) ImplementsGraphQLType(name string) bool {
+	return name == "BigInt"
+}
+
+func (v *bigInt) UnmarshalGraphQL(input interface{}) error {
+	s, ok := input.(string)
+	if !ok {
+		return errors.New("BigInt: expected string")
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return errors.New("BigInt: expected integer")
+	}
+	*v = bigInt(n)
+	return nil
+}
+
+func (v bigInt) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strconv.FormatInt(int64(v), 10))), nil
+}
@@ -3,17 +3,22 @@ package graphqlbackend
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/auditlog"
+	"github.com/sourcegraph/sourcegraph/pkg/jsonc"
 	"github.com/sourcegraph/sourcegraph/pkg/repoupdater"
+	log15 "gopkg.in/inconshreveable/log15.v2"
 )
 
 func (r *schemaResolver) AddExternalService(ctx context.Context, args *struct {
@@ -27,6 +32,9 @@ func (r *schemaResolver) AddExternalService(ctx context.Context, args *struct {
 	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
 		return nil, err
 	}
+	if err := backend.CheckActorHasScope(ctx, authz.ScopeExternalServicesWrite); err != nil {
+		return nil, err
+	}
 
 	externalService := &types.ExternalService{
 		Kind:        args.Input.Kind,
@@ -37,6 +45,7 @@ func (r *schemaResolver) AddExternalService(ctx context.Context, args *struct {
 	if err := db.ExternalServices.Create(ctx, externalService); err != nil {
 		return nil, err
 	}
+	logExternalServiceAuditEvent(ctx, db.AuditActionExternalServiceCreate, externalService.ID, nil, externalService)
 
 	if err := syncExternalService(ctx, externalService); err != nil {
 		return nil, errors.Wrap(err, "warning: external service created, but sync request failed")
@@ -61,11 +70,19 @@ func (*schemaResolver) UpdateExternalService(ctx context.Context, args *struct {
 	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
 		return nil, err
 	}
+	if err := backend.CheckActorHasScope(ctx, authz.ScopeExternalServicesWrite); err != nil {
+		return nil, err
+	}
 
 	if args.Input.Config != nil && strings.TrimSpace(*args.Input.Config) == "" {
 		return nil, fmt.Errorf("blank external service configuration is invalid (must be valid JSONC)")
 	}
 
+	oldExternalService, err := db.ExternalServices.GetByID(ctx, externalServiceID)
+	if err != nil {
+		return nil, err
+	}
+
 	update := &db.ExternalServiceUpdate{
 		DisplayName: args.Input.DisplayName,
 		Config:      args.Input.Config,
@@ -78,6 +95,7 @@ func (*schemaResolver) UpdateExternalService(ctx context.Context, args *struct {
 	if err != nil {
 		return nil, err
 	}
+	logExternalServiceAuditEvent(ctx, db.AuditActionExternalServiceUpdate, externalServiceID, oldExternalService, externalService)
 
 	if err = syncExternalService(ctx, externalService); err != nil {
 		return nil, errors.Wrap(err, "warning: external service updated, but sync request failed")
@@ -86,6 +104,28 @@ func (*schemaResolver) UpdateExternalService(ctx context.Context, args *struct {
 	return &externalServiceResolver{externalService: externalService}, nil
 }
 
+// logExternalServiceAuditEvent records a change to an external service in the audit log. The before
+// and after values are nil if the external service was created or deleted, respectively.
+func logExternalServiceAuditEvent(ctx context.Context, action string, id int64, before, after *types.ExternalService) {
+	value := func(svc *types.ExternalService) interface{} {
+		if svc == nil {
+			return nil
+		}
+		var config interface{}
+		if err := jsonc.Unmarshal(svc.Config, &config); err != nil {
+			// The configuration is validated when it is saved, so this should never happen. Don't
+			// record the raw configuration, because it may contain secrets.
+			config = "(invalid JSONC)"
+		}
+		return map[string]interface{}{"kind": svc.Kind, "displayName": svc.DisplayName, "config": config}
+	}
+	diff, err := auditlog.Diff(value(before), value(after))
+	if err != nil {
+		log15.Warn("Unable to compute audit log diff of external service.", "id", id, "error", err)
+	}
+	backend.LogAuditEvent(ctx, action, db.AuditTargetExternalService, strconv.FormatInt(id, 10), diff)
+}
+
 // Eagerly trigger a repo-updater sync.
 func syncExternalService(ctx context.Context, svc *types.ExternalService) error {
 	res, err := repoupdater.DefaultClient.SyncExternalService(ctx, api.ExternalService{
@@ -112,6 +152,9 @@ func (*schemaResolver) DeleteExternalService(ctx context.Context, args *struct {
 	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
 		return nil, err
 	}
+	if err := backend.CheckActorHasScope(ctx, authz.ScopeExternalServicesWrite); err != nil {
+		return nil, err
+	}
 
 	id, err := unmarshalExternalServiceID(args.ExternalService)
 	if err != nil {
@@ -126,6 +169,7 @@ func (*schemaResolver) DeleteExternalService(ctx context.Context, args *struct {
 	if err := db.ExternalServices.Delete(ctx, id); err != nil {
 		return nil, err
 	}
+	logExternalServiceAuditEvent(ctx, db.AuditActionExternalServiceDelete, id, externalService, nil)
 
 	if err = syncExternalService(ctx, externalService); err != nil {
 		return nil, errors.Wrap(err, "warning: external service deleted, but sync request failed")
@@ -136,6 +136,16 @@ func (r *nodeResolver) ToRegistryExtension() (RegistryExtension, bool) {
 	return NodeToRegistryExtension(r.node)
 }
 
+func (r *nodeResolver) ToRewriteBatch() (*rewriteBatchResolver, bool) {
+	n, ok := r.node.(*rewriteBatchResolver)
+	return n, ok
+}
+
+func (r *nodeResolver) ToSearchExport() (*searchExportResolver, bool) {
+	n, ok := r.node.(*searchExportResolver)
+	return n, ok
+}
+
 func (r *nodeResolver) ToSite() (*siteResolver, bool) {
 	n, ok := r.node.(*siteResolver)
 	return n, ok
@@ -210,6 +220,10 @@ func nodeByID(ctx context.Context, id graphql.ID) (node, error) {
 		return RegistryExtensionByID(ctx, id)
 	case "SavedQuery":
 		return savedQueryByID(ctx, id)
+	case searchExportIDKind:
+		return searchExportByID(ctx, id)
+	case rewriteBatchIDKind:
+		return rewriteBatchByID(ctx, id)
 	case "Site":
 		return siteByGQLID(ctx, id)
 	default:
@@ -0,0 +1,219 @@
+package graphqlbackend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// repoGroupRuleCacheTTL is how long the repositories of a repository group
+// defined by a rule (in the search.repositoryGroupRules settings field) are
+// cached before the rule is evaluated again.
+const repoGroupRuleCacheTTL = 5 * time.Minute
+
+// maxRepoGroupRuleSavedSearchResults is the result count used to evaluate a
+// saved search of a repository group rule if the saved search's query does not
+// specify a count.
+const maxRepoGroupRuleSavedSearchResults = 10000
+
+type repoGroupRuleCacheEntry struct {
+	repos   []*types.Repo
+	expires time.Time
+}
+
+var (
+	repoGroupRuleCacheMu sync.Mutex
+	repoGroupRuleCache   = map[string]repoGroupRuleCacheEntry{}
+)
+
+// repoGroupRuleSavedSearchKey is the context key that is set while the saved
+// search of a repository group rule is evaluated.
+type repoGroupRuleSavedSearchKey struct{}
+
+// resolveRepoGroupRules evaluates the repository group rules in settings of
+// the groups with the given names, and returns the repositories of each of
+// those groups. The rules of other groups are not evaluated, so that a slow
+// or failing rule only affects the searches that use its group.
+func resolveRepoGroupRules(ctx context.Context, settings *schema.Settings, names []string) (map[string][]*types.Repo, error) {
+	groups := make(map[string][]*types.Repo, len(names))
+	if len(settings.SearchRepositoryGroupRules) == 0 {
+		return groups, nil
+	}
+
+	// A saved search of a rule may not use a group that is defined by a rule
+	// (which would otherwise recurse, possibly without end). Such groups are
+	// empty while a saved search is evaluated.
+	if ctx.Value(repoGroupRuleSavedSearchKey{}) != nil {
+		return groups, nil
+	}
+
+	for _, name := range names {
+		rule := settings.SearchRepositoryGroupRules[name]
+		if rule == nil {
+			continue
+		}
+		repos, err := cachedEvaluateRepoGroupRule(ctx, rule, settings.SearchSavedQueries)
+		if err != nil {
+			return nil, fmt.Errorf("repository group %q: %s", name, err)
+		}
+		groups[name] = repos
+	}
+	return groups, nil
+}
+
+func cachedEvaluateRepoGroupRule(ctx context.Context, rule *schema.RepositoryGroupRule, savedQueries []*schema.SearchSavedQueries) ([]*types.Repo, error) {
+	// The repositories that a rule matches depend on the repositories that
+	// the actor may access, and on the query of the rule's saved search.
+	var savedQuery *schema.SearchSavedQueries
+	if rule.SavedSearch != "" {
+		for _, q := range savedQueries {
+			if q != nil && q.Key == rule.SavedSearch {
+				savedQuery = q
+				break
+			}
+		}
+		if savedQuery == nil {
+			return nil, fmt.Errorf("no saved search with key %q", rule.SavedSearch)
+		}
+	}
+	ruleJSON, err := json.Marshal(rule)
+	if err != nil {
+		return nil, err
+	}
+	key := strconv.FormatInt(int64(actor.FromContext(ctx).UID), 10) + ":" + string(ruleJSON)
+	if savedQuery != nil {
+		key += ":" + savedQuery.Query
+	}
+
+	repoGroupRuleCacheMu.Lock()
+	entry, ok := repoGroupRuleCache[key]
+	repoGroupRuleCacheMu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.repos, nil
+	}
+
+	repos, err := evaluateRepoGroupRule(ctx, rule, savedQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	repoGroupRuleCacheMu.Lock()
+	defer repoGroupRuleCacheMu.Unlock()
+	now := time.Now()
+	for k, e := range repoGroupRuleCache {
+		if now.After(e.expires) {
+			delete(repoGroupRuleCache, k)
+		}
+	}
+	repoGroupRuleCache[key] = repoGroupRuleCacheEntry{repos: repos, expires: now.Add(repoGroupRuleCacheTTL)}
+	return repos, nil
+}
+
+// evaluateRepoGroupRule returns the repositories that match all of the
+// conditions of the rule.
+func evaluateRepoGroupRule(ctx context.Context, rule *schema.RepositoryGroupRule, savedQuery *schema.SearchSavedQueries) ([]*types.Repo, error) {
+	var savedSearchRepos map[api.RepoName]*types.Repo
+	if savedQuery != nil {
+		var err error
+		savedSearchRepos, err = savedSearchResultRepos(ctx, savedQuery.Query)
+		if err != nil {
+			return nil, fmt.Errorf("saved search %q: %s", savedQuery.Key, err)
+		}
+		if rule.RepositoryPattern == "" && rule.ExternalService == "" && rule.Owner == "" && rule.Topic == "" && rule.Language == "" {
+			repos := make([]*types.Repo, 0, len(savedSearchRepos))
+			for _, repo := range savedSearchRepos {
+				repos = append(repos, repo)
+			}
+			return repos, nil
+		}
+	}
+
+	opt := db.ReposListOptions{
+		Enabled:  true,
+		Owner:    rule.Owner,
+		Topic:    rule.Topic,
+		Language: rule.Language,
+	}
+	if rule.RepositoryPattern != "" {
+		opt.IncludePatterns = []string{rule.RepositoryPattern}
+	}
+	if rule.ExternalService != "" {
+		id, err := unmarshalExternalServiceID(graphql.ID(rule.ExternalService))
+		if err != nil {
+			return nil, err
+		}
+		opt.ExternalServiceID = id
+	}
+	repos, err := backend.Repos.List(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	if savedSearchRepos != nil {
+		filtered := repos[:0]
+		for _, repo := range repos {
+			if _, ok := savedSearchRepos[repo.Name]; ok {
+				filtered = append(filtered, repo)
+			}
+		}
+		repos = filtered
+	}
+	return repos, nil
+}
+
+// savedSearchQuery parses the query of a rule's saved search. If it does not
+// specify a count, count:maxRepoGroupRuleSavedSearchResults is added.
+func savedSearchQuery(rawQuery string) (*query.Query, error) {
+	q, err := query.ParseAndCheck(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+	if count, _ := q.StringValues(query.FieldCount); len(count) > 0 {
+		return q, nil
+	}
+	return query.WithFields(q, map[string]string{query.FieldCount: strconv.Itoa(maxRepoGroupRuleSavedSearchResults)})
+}
+
+// savedSearchResultRepos returns the repositories that have results for the
+// search query.
+func savedSearchResultRepos(ctx context.Context, rawQuery string) (map[api.RepoName]*types.Repo, error) {
+	q, err := savedSearchQuery(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, repoGroupRuleSavedSearchKey{}, true)
+	results, err := (&searchResolver{query: q}).Results(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	repos := map[api.RepoName]*types.Repo{}
+	for _, result := range results.results {
+		var repo *types.Repo
+		switch {
+		case result.fileMatch != nil:
+			repo = result.fileMatch.repo
+		case result.repo != nil:
+			repo = result.repo.repo
+		case result.diff != nil && result.diff.commit != nil && result.diff.commit.repo != nil:
+			repo = result.diff.commit.repo.repo
+		}
+		if repo != nil {
+			repos[repo.Name] = repo
+		}
+	}
+	return repos, nil
+}
@@ -0,0 +1,96 @@
+package graphqlbackend
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query/syntax"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestResolveRepoGroupRules(t *testing.T) {
+	resetMocks()
+	defer func() { repoGroupRuleCache = map[string]repoGroupRuleCacheEntry{} }()
+
+	var calls int
+	db.Mocks.Repos.List = func(_ context.Context, op db.ReposListOptions) ([]*types.Repo, error) {
+		calls++
+		want := db.ReposListOptions{Enabled: true, IncludePatterns: []string{"^github\\.com/foo/"}, Topic: "go", Language: "Go"}
+		if !reflect.DeepEqual(op, want) {
+			t.Fatalf("got %+v, want %+v", op, want)
+		}
+		return []*types.Repo{{Name: "github.com/foo/a"}, {Name: "github.com/foo/b"}}, nil
+	}
+	defer func() { db.Mocks.Repos.List = nil }()
+
+	settings := &schema.Settings{
+		SearchRepositoryGroupRules: map[string]*schema.RepositoryGroupRule{
+			"foo-go": {RepositoryPattern: "^github\\.com/foo/", Topic: "go", Language: "Go"},
+		},
+	}
+	names := []string{"foo-go"}
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	for i := 0; i < 2; i++ {
+		groups, err := resolveRepoGroupRules(ctx, settings, names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string][]*types.Repo{"foo-go": {{Name: "github.com/foo/a"}, {Name: "github.com/foo/b"}}}
+		if !reflect.DeepEqual(groups, want) {
+			t.Errorf("got %+v, want %+v", groups, want)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("got %d Repos.List calls, want 1 (the second evaluation should be cached)", calls)
+	}
+
+	// A different actor may have access to different repositories, so the
+	// cached result must not be used.
+	if _, err := resolveRepoGroupRules(actor.WithActor(context.Background(), &actor.Actor{UID: 2}), settings, names); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("got %d Repos.List calls, want 2", calls)
+	}
+
+	// Rules are not evaluated while the saved search of a rule is evaluated.
+	groups, err := resolveRepoGroupRules(context.WithValue(ctx, repoGroupRuleSavedSearchKey{}, true), settings, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 0 {
+		t.Errorf("got groups %+v, want none", groups)
+	}
+
+	// A rule that refers to an unknown saved search is an error, but only
+	// for the searches that use its group.
+	settings.SearchRepositoryGroupRules["bad"] = &schema.RepositoryGroupRule{SavedSearch: "missing"}
+	if _, err := resolveRepoGroupRules(ctx, settings, names); err != nil {
+		t.Errorf("got error %q for a group that doesn't use the bad rule", err)
+	}
+	if _, err := resolveRepoGroupRules(ctx, settings, []string{"bad"}); err == nil {
+		t.Error("got nil error for unknown saved search")
+	}
+}
+
+func TestSavedSearchQuery(t *testing.T) {
+	for rawQuery, want := range map[string]string{
+		"foo":                 "foo count:10000",
+		"foo count:5":         "foo count:5",
+		"foo or bar":          "(foo or bar) count:10000",
+		"repo:x (foo or bar)": "repo:x (foo or bar) count:10000",
+	} {
+		q, err := savedSearchQuery(rawQuery)
+		if err != nil {
+			t.Errorf("%q: %s", rawQuery, err)
+			continue
+		}
+		if got := syntax.ExprString(q.Syntax.Expr); got != want {
+			t.Errorf("%q: got %q, want %q", rawQuery, got, want)
+		}
+	}
+}
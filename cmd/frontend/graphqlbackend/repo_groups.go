@@ -7,30 +7,36 @@ import (
 )
 
 type repoGroup struct {
-	name         string
-	repositories []api.RepoName
+	name string
 }
 
 func (g repoGroup) Name() string { return g.name }
 
-func (g repoGroup) Repositories() []string { return repoNamesToStrings(g.repositories) }
+// Repositories resolves the repositories of the group on demand, so that
+// listing the groups doesn't evaluate the rules of every group (see
+// resolveRepoGroupRules).
+func (g repoGroup) Repositories(ctx context.Context) ([]string, error) {
+	groups, err := resolveRepoGroups(ctx, []string{g.name})
+	if err != nil {
+		return nil, err
+	}
+	repos := groups[g.name]
+	repoPaths := make([]api.RepoName, len(repos))
+	for i, repo := range repos {
+		repoPaths[i] = repo.Name
+	}
+	return repoNamesToStrings(repoPaths), nil
+}
 
 func (r *schemaResolver) RepoGroups(ctx context.Context) ([]*repoGroup, error) {
-	groupsByName, err := resolveRepoGroups(ctx)
+	names, err := repoGroupNames(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	groups := make([]*repoGroup, 0, len(groupsByName))
-	for name, repos := range groupsByName {
-		repoPaths := make([]api.RepoName, len(repos))
-		for i, repo := range repos {
-			repoPaths[i] = repo.Name
-		}
-		groups = append(groups, &repoGroup{
-			name:         name,
-			repositories: repoPaths,
-		})
+	groups := make([]*repoGroup, len(names))
+	for i, name := range names {
+		groups[i] = &repoGroup{name: name}
 	}
 	return groups, nil
 }
@@ -120,6 +120,41 @@ func (r *repositoryMirrorInfoResolver) UpdatedAt(ctx context.Context) (*string,
 	return &s, nil
 }
 
+func (r *repositoryMirrorInfoResolver) DiskSizeBytes(ctx context.Context) (*bigInt, error) {
+	info, err := r.gitserverRepoInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Cloned || info.Size == 0 {
+		return nil, nil
+	}
+	size := bigInt(info.Size)
+	return &size, nil
+}
+
+func (r *repositoryMirrorInfoResolver) LastAccessedAt(ctx context.Context) (*string, error) {
+	info, err := r.gitserverRepoInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if info.LastAccessed == nil {
+		return nil, nil
+	}
+	s := info.LastAccessed.Format(time.RFC3339)
+	return &s, nil
+}
+
+func (r *repositoryMirrorInfoResolver) SizeLimitError(ctx context.Context) (*string, error) {
+	info, err := r.gitserverRepoInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if info.SizeLimitError == "" {
+		return nil, nil
+	}
+	return &info.SizeLimitError, nil
+}
+
 func (r *repositoryMirrorInfoResolver) UpdateSchedule(ctx context.Context) (*updateScheduleResolver, error) {
 	info, err := r.repoUpdateSchedulerInfo(ctx)
 	if err != nil {
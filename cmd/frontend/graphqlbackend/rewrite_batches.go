@@ -0,0 +1,460 @@
+package graphqlbackend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/neelance/parallel"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/replacer"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	replacerprotocol "github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// maxRewriteBatchRepos is the maximum number of repositories that a rewrite
+// batch may span.
+const maxRewriteBatchRepos = 500
+
+// rewriteBatchRepoTimeout is the maximum amount of time that computing or
+// committing the diff of a single repository in a rewrite batch may take.
+const rewriteBatchRepoTimeout = 2 * time.Minute
+
+// rewriteBatchStaleAfter is how long after the last update of a rewrite batch
+// (or of one of its repositories) a batch that is being previewed or committed
+// is considered interrupted (because the process that ran it died). At most
+// rewriteBatchRepoTimeout passes between the updates of a running batch.
+const rewriteBatchStaleAfter = 3 * rewriteBatchRepoTimeout
+
+func (r *schemaResolver) CreateRewriteBatch(ctx context.Context, args *struct {
+	Input *struct {
+		Query           string
+		MatchTemplate   string
+		RewriteTemplate string
+		FileExtension   *string
+	}
+}) (*rewriteBatchResolver, error) {
+	// 🚨 SECURITY: Only site admins may create rewrite batches, because they
+	// may create commits in any repository.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+	user, err := db.Users.GetByCurrentAuthUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(args.Input.MatchTemplate) == "" {
+		return nil, errors.New("rewrite batch match template must not be empty")
+	}
+	q, err := query.ParseAndCheck(args.Input.Query)
+	if err != nil {
+		return nil, err
+	}
+	repoRevs, _, _, overLimit, err := (&searchResolver{query: q}).resolveRepositories(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if overLimit || len(repoRevs) > maxRewriteBatchRepos {
+		return nil, fmt.Errorf("rewrite batch query matches too many repositories (the limit is %d); add repo: filters to the query to narrow it", maxRewriteBatchRepos)
+	}
+	if len(repoRevs) == 0 {
+		return nil, errors.New("rewrite batch query matches no repositories")
+	}
+
+	repos := make([]*db.RewriteBatchRepo, 0, len(repoRevs))
+	for _, repoRev := range repoRevs {
+		// Only the first revision of each repository is rewritten, because
+		// commits are created on a single new branch.
+		var rev string
+		if revs := repoRev.RevSpecs(); len(revs) > 0 {
+			rev = revs[0]
+		}
+		repos = append(repos, &db.RewriteBatchRepo{RepoID: repoRev.Repo.ID, Rev: rev})
+	}
+
+	batch := &db.RewriteBatch{
+		UserID:          user.ID,
+		Query:           args.Input.Query,
+		MatchTemplate:   args.Input.MatchTemplate,
+		RewriteTemplate: args.Input.RewriteTemplate,
+	}
+	if args.Input.FileExtension != nil {
+		batch.FileExtension = *args.Input.FileExtension
+	}
+	batch, err = db.RewriteBatches.Create(ctx, batch, repos)
+	if err != nil {
+		return nil, err
+	}
+
+	// The diffs are computed after the request finishes, on behalf of the
+	// same actor.
+	bgCtx := actor.WithActor(context.Background(), actor.FromContext(ctx))
+	goroutine.Go(func() {
+		previewRewriteBatch(bgCtx, batch, repos)
+	})
+
+	return &rewriteBatchResolver{batch: batch}, nil
+}
+
+func (r *schemaResolver) ApplyRewriteBatch(ctx context.Context, args *struct {
+	RewriteBatch  graphql.ID
+	Branch        string
+	CommitMessage string
+}) (*rewriteBatchResolver, error) {
+	// 🚨 SECURITY: Only site admins may apply rewrite batches.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+	user, err := db.Users.GetByCurrentAuthUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := unmarshalRewriteBatchID(args.RewriteBatch)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRewriteBatchBranch(args.Branch); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.CommitMessage) == "" {
+		return nil, errors.New("rewrite batch commit message must not be empty")
+	}
+
+	if err := db.RewriteBatches.StartCommitting(ctx, id, args.Branch, args.CommitMessage); err != nil {
+		return nil, err
+	}
+	batch, err := db.RewriteBatches.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := db.RewriteBatches.ListRepos(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	author := protocol.PatchCommitInfo{
+		Message:    args.CommitMessage,
+		AuthorName: user.Username,
+		Date:       time.Now(),
+	}
+	if user.DisplayName != "" {
+		author.AuthorName = user.DisplayName
+	}
+	if email, _, err := db.UserEmails.GetPrimaryEmail(ctx, user.ID); err == nil {
+		author.AuthorEmail = email
+	}
+
+	bgCtx := actor.WithActor(context.Background(), actor.FromContext(ctx))
+	goroutine.Go(func() {
+		commitRewriteBatch(bgCtx, batch, repos, author)
+	})
+
+	return &rewriteBatchResolver{batch: batch}, nil
+}
+
+// validateRewriteBatchBranch reports whether branch is a valid name for the
+// branch that the diffs of a rewrite batch are committed on. It is stricter
+// than `git check-ref-format --branch`.
+func validateRewriteBatchBranch(branch string) error {
+	invalid := branch == "" || branch == "@" ||
+		strings.HasPrefix(branch, "-") || strings.HasPrefix(branch, "/") ||
+		strings.HasSuffix(branch, "/") || strings.HasSuffix(branch, ".") || strings.HasSuffix(branch, ".lock") ||
+		strings.Contains(branch, "..") || strings.Contains(branch, "//") || strings.Contains(branch, "@{") ||
+		strings.ContainsAny(branch, " ~^:?*[\\")
+	for _, c := range branch {
+		if c < 0x20 || c == 0x7f {
+			invalid = true
+		}
+	}
+	for _, component := range strings.Split(branch, "/") {
+		if strings.HasPrefix(component, ".") || strings.HasSuffix(component, ".lock") {
+			invalid = true
+		}
+	}
+	if invalid {
+		return fmt.Errorf("invalid branch name %q", branch)
+	}
+	return nil
+}
+
+// StartRewriteBatchJanitor should be invoked only after the DB has been
+// initialized. The diffs of rewrite batches are previewed and committed in
+// goroutines of the frontend process that received the request, so batches
+// that were being previewed or committed when a frontend process died would
+// never finish. It periodically moves those batches to the errored state.
+//
+// It should be invoked in a separate goroutine.
+func StartRewriteBatchJanitor() {
+	for {
+		if err := db.RewriteBatches.FailInterrupted(context.Background(), rewriteBatchStaleAfter); err != nil {
+			log15.Error("Unable to fail interrupted rewrite batches.", "error", err)
+		}
+		time.Sleep(rewriteBatchStaleAfter / 2)
+	}
+}
+
+// previewRewriteBatch computes the diff of the rewrite in each repository of
+// the batch and moves the batch to the previewed state.
+func previewRewriteBatch(ctx context.Context, batch *db.RewriteBatch, repos []*db.RewriteBatchRepo) {
+	run := parallel.NewRun(8)
+	for _, r := range repos {
+		r := r
+		run.Acquire()
+		goroutine.Go(func() {
+			defer run.Release()
+			ctx, cancel := context.WithTimeout(ctx, rewriteBatchRepoTimeout)
+			defer cancel()
+
+			r.BaseCommit, r.Diff, r.Error = "", "", ""
+			if err := previewRewriteBatchRepo(ctx, batch, r); err != nil {
+				r.State, r.Error = db.RewriteBatchRepoStateErrored, err.Error()
+			} else if r.Diff == "" {
+				r.State = db.RewriteBatchRepoStateNoChanges
+			} else {
+				r.State = db.RewriteBatchRepoStatePreviewed
+			}
+			if err := db.RewriteBatches.UpdateRepo(context.Background(), r); err != nil {
+				log15.Error("Unable to update rewrite batch repository.", "batch", batch.ID, "repo", r.RepoID, "error", err)
+			}
+		})
+	}
+	_ = run.Wait()
+
+	if err := db.RewriteBatches.UpdateState(context.Background(), batch.ID, db.RewriteBatchStatePreviewed); err != nil {
+		log15.Error("Unable to update rewrite batch state.", "batch", batch.ID, "error", err)
+	}
+}
+
+func previewRewriteBatchRepo(ctx context.Context, batch *db.RewriteBatch, r *db.RewriteBatchRepo) error {
+	repo, err := db.Repos.Get(ctx, r.RepoID)
+	if err != nil {
+		return err
+	}
+	cachedRepo, err := backend.CachedGitRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+	r.BaseCommit, err = git.ResolveRevision(ctx, *cachedRepo, nil, r.Rev, nil)
+	if err != nil {
+		return err
+	}
+	r.Diff, err = replacer.Diff(ctx, replacerprotocol.Request{
+		Repo:         repo.Name,
+		URL:          cachedRepo.URL,
+		Commit:       r.BaseCommit,
+		FetchTimeout: "30s",
+		RewriteSpecification: replacerprotocol.RewriteSpecification{
+			MatchTemplate:   batch.MatchTemplate,
+			RewriteTemplate: batch.RewriteTemplate,
+			FileExtension:   batch.FileExtension,
+		},
+	})
+	return err
+}
+
+// commitRewriteBatch creates a commit from the diff of each repository of the
+// batch on the batch's branch, and moves the batch to the committed state.
+func commitRewriteBatch(ctx context.Context, batch *db.RewriteBatch, repos []*db.RewriteBatchRepo, info protocol.PatchCommitInfo) {
+	run := parallel.NewRun(8)
+	for _, r := range repos {
+		if r.State != db.RewriteBatchRepoStatePreviewed {
+			continue
+		}
+		r := r
+		run.Acquire()
+		goroutine.Go(func() {
+			defer run.Release()
+			ctx, cancel := context.WithTimeout(ctx, rewriteBatchRepoTimeout)
+			defer cancel()
+
+			if err := commitRewriteBatchRepo(ctx, batch, r, info); err != nil {
+				r.State, r.Error = db.RewriteBatchRepoStateErrored, err.Error()
+			} else {
+				r.State = db.RewriteBatchRepoStateCommitted
+			}
+			if err := db.RewriteBatches.UpdateRepo(context.Background(), r); err != nil {
+				log15.Error("Unable to update rewrite batch repository.", "batch", batch.ID, "repo", r.RepoID, "error", err)
+			}
+		})
+	}
+	_ = run.Wait()
+
+	if err := db.RewriteBatches.UpdateState(context.Background(), batch.ID, db.RewriteBatchStateCommitted); err != nil {
+		log15.Error("Unable to update rewrite batch state.", "batch", batch.ID, "error", err)
+	}
+}
+
+func commitRewriteBatchRepo(ctx context.Context, batch *db.RewriteBatch, r *db.RewriteBatchRepo, info protocol.PatchCommitInfo) error {
+	repo, err := db.Repos.Get(ctx, r.RepoID)
+	if err != nil {
+		return err
+	}
+	cachedRepo, err := backend.CachedGitRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	// The commit is pushed to the new branch on the code host (and then
+	// created in the gitserver repository, which mirrors the code host). Never
+	// overwrite an existing branch.
+	targetRef := "refs/heads/" + batch.Branch
+	if _, err := git.ResolveRevision(ctx, *cachedRepo, nil, targetRef, &git.ResolveRevisionOptions{NoEnsureRevision: true}); err == nil {
+		return fmt.Errorf("branch %q already exists", batch.Branch)
+	} else if !git.IsRevisionNotFound(err) {
+		return err
+	}
+
+	if _, err := gitserver.DefaultClient.CreateCommitFromPatch(ctx, protocol.CreateCommitFromPatchRequest{
+		Repo:       repo.Name,
+		BaseCommit: r.BaseCommit,
+		TargetRef:  targetRef,
+		Patch:      r.Diff,
+		CommitInfo: info,
+		Push:       &protocol.PushConfig{RemoteURL: cachedRepo.URL},
+	}); err != nil {
+		return err
+	}
+	r.CommitRef = targetRef
+	return nil
+}
+
+type rewriteBatchResolver struct {
+	batch *db.RewriteBatch
+}
+
+const rewriteBatchIDKind = "RewriteBatch"
+
+func rewriteBatchByID(ctx context.Context, id graphql.ID) (*rewriteBatchResolver, error) {
+	// 🚨 SECURITY: Only site admins may view rewrite batches.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	batchID, err := unmarshalRewriteBatchID(id)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := db.RewriteBatches.GetByID(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	return &rewriteBatchResolver{batch: batch}, nil
+}
+
+func marshalRewriteBatchID(id int64) graphql.ID {
+	return relay.MarshalID(rewriteBatchIDKind, id)
+}
+
+func unmarshalRewriteBatchID(id graphql.ID) (batchID int64, err error) {
+	if kind := relay.UnmarshalKind(id); kind != rewriteBatchIDKind {
+		err = fmt.Errorf("expected graphql ID to have kind %q; got %q", rewriteBatchIDKind, kind)
+		return
+	}
+	err = relay.UnmarshalSpec(id, &batchID)
+	return
+}
+
+func (r *rewriteBatchResolver) ID() graphql.ID { return marshalRewriteBatchID(r.batch.ID) }
+
+func (r *rewriteBatchResolver) Query() string { return r.batch.Query }
+
+func (r *rewriteBatchResolver) MatchTemplate() string { return r.batch.MatchTemplate }
+
+func (r *rewriteBatchResolver) RewriteTemplate() string { return r.batch.RewriteTemplate }
+
+func (r *rewriteBatchResolver) FileExtension() *string {
+	if r.batch.FileExtension == "" {
+		return nil
+	}
+	return &r.batch.FileExtension
+}
+
+func (r *rewriteBatchResolver) State() string { return r.batch.State }
+
+func (r *rewriteBatchResolver) Branch() *string {
+	if r.batch.Branch == "" {
+		return nil
+	}
+	return &r.batch.Branch
+}
+
+func (r *rewriteBatchResolver) CommitMessage() *string {
+	if r.batch.CommitMessage == "" {
+		return nil
+	}
+	return &r.batch.CommitMessage
+}
+
+func (r *rewriteBatchResolver) Creator(ctx context.Context) (*UserResolver, error) {
+	return UserByIDInt32(ctx, r.batch.UserID)
+}
+
+func (r *rewriteBatchResolver) CreatedAt() string { return r.batch.CreatedAt.Format(time.RFC3339) }
+
+func (r *rewriteBatchResolver) UpdatedAt() string { return r.batch.UpdatedAt.Format(time.RFC3339) }
+
+func (r *rewriteBatchResolver) Repositories(ctx context.Context) ([]*rewriteBatchRepositoryResolver, error) {
+	repos, err := db.RewriteBatches.ListRepos(ctx, r.batch.ID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*rewriteBatchRepositoryResolver, len(repos))
+	for i, repo := range repos {
+		resolvers[i] = &rewriteBatchRepositoryResolver{repo: repo}
+	}
+	return resolvers, nil
+}
+
+type rewriteBatchRepositoryResolver struct {
+	repo *db.RewriteBatchRepo
+}
+
+func (r *rewriteBatchRepositoryResolver) Repository(ctx context.Context) (*repositoryResolver, error) {
+	return repositoryByIDInt32(ctx, r.repo.RepoID)
+}
+
+func (r *rewriteBatchRepositoryResolver) Rev() string { return r.repo.Rev }
+
+func (r *rewriteBatchRepositoryResolver) BaseCommit() *gitObjectID {
+	if r.repo.BaseCommit == "" {
+		return nil
+	}
+	oid := gitObjectID(r.repo.BaseCommit)
+	return &oid
+}
+
+func (r *rewriteBatchRepositoryResolver) Diff() *string {
+	if r.repo.Diff == "" {
+		return nil
+	}
+	return &r.repo.Diff
+}
+
+func (r *rewriteBatchRepositoryResolver) State() string { return r.repo.State }
+
+func (r *rewriteBatchRepositoryResolver) Error() *string {
+	if r.repo.Error == "" {
+		return nil
+	}
+	return &r.repo.Error
+}
+
+func (r *rewriteBatchRepositoryResolver) CommitRef() *string {
+	if r.repo.CommitRef == "" {
+		return nil
+	}
+	return &r.repo.CommitRef
+}
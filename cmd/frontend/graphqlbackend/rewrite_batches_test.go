@@ -0,0 +1,16 @@
+package graphqlbackend
+
+import "testing"
+
+func TestValidateRewriteBatchBranch(t *testing.T) {
+	for _, branch := range []string{"rewrite", "sprint-cleanup", "feature/rewrite-1", "v1.2"} {
+		if err := validateRewriteBatchBranch(branch); err != nil {
+			t.Errorf("%q: unexpected error: %s", branch, err)
+		}
+	}
+	for _, branch := range []string{"", "-rewrite", "/rewrite", "rewrite/", "rewrite.", "rewrite.lock", "a..b", "a//b", "a@{1}", "a b", "a~1", "a^", "a:b", "a?", "a*", "a[b", `a\b`, "a\tb", "@", ".rewrite", "a/.b", "a/b.lock/c"} {
+		if err := validateRewriteBatchBranch(branch); err == nil {
+			t.Errorf("%q: expected error", branch)
+		}
+	}
+}
@@ -8,11 +8,15 @@ import (
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/sourcegraph/jsonx"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/query-runner/queryrunnerapi"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/jsonc"
 	"github.com/sourcegraph/sourcegraph/pkg/randstring"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 type savedQueryResolver struct {
@@ -22,6 +26,7 @@ type savedQueryResolver struct {
 	description                         string
 	query                               string
 	showOnHomepage, notify, notifySlack bool
+	notifyWebhook                       *schema.SavedQueryWebhook
 }
 
 func savedQueryByID(ctx context.Context, id graphql.ID) (*savedQueryResolver, error) {
@@ -52,6 +57,10 @@ func savedQueryByID(ctx context.Context, id graphql.ID) (*savedQueryResolver, er
 }
 
 func (r savedQueryResolver) ID() graphql.ID {
+	return marshalSavedQueryID(r.spec())
+}
+
+func (r savedQueryResolver) spec() api.SavedQueryIDSpec {
 	var subject api.SettingsSubject
 	switch {
 	case r.subject.user != nil:
@@ -61,10 +70,10 @@ func (r savedQueryResolver) ID() graphql.ID {
 	case r.subject.site != nil:
 		subject.Site = true
 	}
-	return marshalSavedQueryID(api.SavedQueryIDSpec{
+	return api.SavedQueryIDSpec{
 		Subject: subject,
 		Key:     r.key,
-	})
+	}
 }
 
 func marshalSavedQueryID(spec api.SavedQueryIDSpec) graphql.ID {
@@ -112,6 +121,8 @@ func toSavedQueryResolver(index int, subject *settingsSubject, entry api.ConfigS
 		query:       entry.Query,
 		notify:      entry.Notify,
 		notifySlack: entry.NotifySlack,
+
+		notifyWebhook: entry.NotifyWebhook,
 	}
 }
 
@@ -287,10 +298,41 @@ func (r *settingsMutation) DeleteSavedQuery(ctx context.Context, args *struct {
 	if err != nil {
 		return nil, err
 	}
+	if err := db.SavedQueryWebhookSecrets.Set(ctx, spec, ""); err != nil {
+		return nil, err
+	}
 	go queryrunnerapi.Client.SavedQueryWasDeleted(context.Background(), spec, args.DisableSubscriptionNotifications)
 	return &EmptyResponse{}, nil
 }
 
+func (r *settingsMutation) SetSavedQueryWebhookSecret(ctx context.Context, args *struct {
+	ID     graphql.ID
+	Secret *string
+}) (*EmptyResponse, error) {
+	spec, err := unmarshalSavedQueryID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 🚨 SECURITY: Check that args.ID's encoded subject is the same as the configurationMutation
+	// resolver's subject, which we've already checked permissions for.
+	if err := r.checkArgHasSameSubject(spec.Subject); err != nil {
+		return nil, err
+	}
+	if _, err := r.getSavedQueryIndex(ctx, spec.Key); err != nil {
+		return nil, err
+	}
+
+	var secret string
+	if args.Secret != nil {
+		secret = *args.Secret
+	}
+	if err := db.SavedQueryWebhookSecrets.Set(ctx, spec, secret); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
+
 func generateUniqueSavedQueryKey(existing []api.ConfigSavedQuery) string {
 	// Avoid collisions.
 	used := make(map[string]struct{}, len(existing))
@@ -315,6 +357,10 @@ func generateUniqueSavedQueryKey(existing []api.ConfigSavedQuery) string {
 func (r *schemaResolver) SendSavedSearchTestNotification(ctx context.Context, args *struct {
 	ID graphql.ID
 }) (*EmptyResponse, error) {
+	if err := backend.CheckActorHasScope(ctx, authz.ScopeSavedSearchesWrite, authz.ScopeSettingsWrite); err != nil {
+		return nil, err
+	}
+
 	// 🚨 SECURITY: Look it up to ensure the actor has access to it.
 	if _, err := savedQueryByID(ctx, args.ID); err != nil {
 		return nil, err
@@ -0,0 +1,102 @@
+package graphqlbackend
+
+import (
+	"context"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+// LastRunDelta returns the matches that were added and removed between the last
+// two runs of the saved query by the query-runner.
+func (r savedQueryResolver) LastRunDelta(ctx context.Context) (*savedQueryRunDeltaResolver, error) {
+	run, err := db.QueryRunnerState.GetLastRun(ctx, r.query)
+	if run == nil || err != nil {
+		return nil, err
+	}
+
+	// 🚨 SECURITY: The query-runner runs the search as an internal actor, so
+	// only return the matches in repositories that the current user can
+	// access.
+	added, err := filterSavedQueryMatches(ctx, run.Added)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := filterSavedQueryMatches(ctx, run.Removed)
+	if err != nil {
+		return nil, err
+	}
+	return &savedQueryRunDeltaResolver{executedAt: run.ExecutedAt, added: added, removed: removed}, nil
+}
+
+// filterSavedQueryMatches returns the matches in repositories that the current
+// user can access.
+func filterSavedQueryMatches(ctx context.Context, matches []api.SavedQueryMatch) ([]*savedQueryMatchResolver, error) {
+	canAccess := map[api.RepoName]bool{}
+	resolvers := make([]*savedQueryMatchResolver, 0, len(matches))
+	for _, m := range matches {
+		ok, checked := canAccess[m.Repository]
+		if !checked {
+			_, err := db.Repos.GetByName(ctx, m.Repository)
+			if err != nil && !errcode.IsNotFound(err) {
+				return nil, err
+			}
+			ok = err == nil
+			canAccess[m.Repository] = ok
+		}
+		if ok {
+			resolvers = append(resolvers, &savedQueryMatchResolver{match: m})
+		}
+	}
+	return resolvers, nil
+}
+
+type savedQueryRunDeltaResolver struct {
+	executedAt     time.Time
+	added, removed []*savedQueryMatchResolver
+}
+
+func (r *savedQueryRunDeltaResolver) ExecutedAt() string {
+	return r.executedAt.Format(time.RFC3339)
+}
+
+func (r *savedQueryRunDeltaResolver) Added() []*savedQueryMatchResolver { return r.added }
+
+func (r *savedQueryRunDeltaResolver) Removed() []*savedQueryMatchResolver { return r.removed }
+
+type savedQueryMatchResolver struct {
+	match api.SavedQueryMatch
+}
+
+func (r *savedQueryMatchResolver) Repository() string { return string(r.match.Repository) }
+
+func (r *savedQueryMatchResolver) Commit() *string {
+	if r.match.Commit == "" {
+		return nil
+	}
+	commit := string(r.match.Commit)
+	return &commit
+}
+
+func (r *savedQueryMatchResolver) Path() *string {
+	if r.match.Path == "" {
+		return nil
+	}
+	return &r.match.Path
+}
+
+func (r *savedQueryMatchResolver) LineNumber() *int32 {
+	if r.match.LineNumber == 0 {
+		return nil
+	}
+	return &r.match.LineNumber
+}
+
+func (r *savedQueryMatchResolver) Preview() *string {
+	if r.match.Preview == "" {
+		return nil
+	}
+	return &r.match.Preview
+}
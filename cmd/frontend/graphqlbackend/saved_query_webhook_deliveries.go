@@ -0,0 +1,78 @@
+package graphqlbackend
+
+import (
+	"context"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+func (r savedQueryResolver) WebhookURL() *string {
+	if r.notifyWebhook == nil {
+		return nil
+	}
+	return &r.notifyWebhook.Url
+}
+
+func (r savedQueryResolver) WebhookSecretIsSet(ctx context.Context) (bool, error) {
+	secret, err := db.SavedQueryWebhookSecrets.Get(ctx, r.spec())
+	return secret != "", err
+}
+
+// WebhookDeliveries returns the most recent webhook deliveries of the saved
+// query's notifications, newest first.
+func (r savedQueryResolver) WebhookDeliveries(ctx context.Context, args *struct {
+	First int32
+}) ([]*savedQueryWebhookDeliveryResolver, error) {
+	// 🚨 SECURITY: Only the viewer's own saved queries (and those of the orgs
+	// they are a member of and the site) are resolvable, because they are read
+	// from the viewer's settings cascade.
+	deliveries, err := db.SavedQueryWebhookDeliveries.ListBySavedQuery(ctx, r.spec(), int(args.First))
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*savedQueryWebhookDeliveryResolver, len(deliveries))
+	for i, d := range deliveries {
+		resolvers[i] = &savedQueryWebhookDeliveryResolver{delivery: d}
+	}
+	return resolvers, nil
+}
+
+type savedQueryWebhookDeliveryResolver struct {
+	delivery *api.SavedQueryWebhookDelivery
+}
+
+func (r *savedQueryWebhookDeliveryResolver) Event() string { return r.delivery.Event }
+
+func (r *savedQueryWebhookDeliveryResolver) URL() string { return r.delivery.URL }
+
+func (r *savedQueryWebhookDeliveryResolver) StatusCode() *int32 {
+	if r.delivery.StatusCode == 0 {
+		return nil
+	}
+	statusCode := int32(r.delivery.StatusCode)
+	return &statusCode
+}
+
+func (r *savedQueryWebhookDeliveryResolver) ResponseBody() *string {
+	if r.delivery.ResponseBody == "" {
+		return nil
+	}
+	return &r.delivery.ResponseBody
+}
+
+func (r *savedQueryWebhookDeliveryResolver) Error() *string {
+	if r.delivery.Error == "" {
+		return nil
+	}
+	return &r.delivery.Error
+}
+
+func (r *savedQueryWebhookDeliveryResolver) Attempts() int32 { return int32(r.delivery.Attempts) }
+
+func (r *savedQueryWebhookDeliveryResolver) Succeeded() bool { return r.delivery.Error == "" }
+
+func (r *savedQueryWebhookDeliveryResolver) CreatedAt() string {
+	return r.delivery.CreatedAt.Format(time.RFC3339)
+}
@@ -25,6 +25,10 @@ interface Node {
 # A valid JSON value.
 scalar JSONValue
 
+# An integer that may be larger than the 32-bit Int type allows (such as a size in bytes). It is
+# represented as a string of decimal digits.
+scalar BigInt
+
 # A mutation.
 type Mutation {
     # Updates the user profile information for the user with the given ID.
@@ -56,6 +60,23 @@ type Mutation {
     #
     # Only site admins may perform this mutation.
     setAllRepositoriesEnabled(enabled: Boolean!): EmptyResponse
+    # Creates a rewrite batch, which runs a code rewrite across all repositories matched by the search
+    # query. The diff of each repository is computed in the background; query the rewrite batch (by its
+    # ID) to review the diffs.
+    #
+    # Only site admins may perform this mutation.
+    createRewriteBatch(input: CreateRewriteBatchInput!): RewriteBatch!
+    # Commits the diffs of a previewed rewrite batch on a new branch in each repository whose diff is
+    # nonempty. The commits are created in the background and pushed to the new branch on the code host
+    # (which must not exist yet).
+    #
+    # Only site admins may perform this mutation.
+    applyRewriteBatch(rewriteBatch: ID!, branch: String!, commitMessage: String!): RewriteBatch!
+    # Creates a search export, which runs the search query to completion in the background (in each
+    # matched repository separately, so the result limit and timeout of a search don't apply to the whole
+    # export) and writes all matches to a file in the given format. The user is notified by email when
+    # the export is finished; query the search export (by its ID) to get its download URL.
+    createSearchExport(query: String!, format: SearchExportFormat!): SearchExport!
     # Tests the connection to a mirror repository's original source repository. This is an
     # expensive and slow operation, so it should only be used for interactive diagnostics.
     #
@@ -141,11 +162,24 @@ type Mutation {
     # The supported scopes are:
     #
     # - "user:all": Full control of all resources accessible to the user account.
+    # - "user:read": Read-only access to all resources accessible to the user account (such as searching and
+    #   browsing repositories).
+    # - "settings:write": Ability to edit settings (and saved searches) that the user can administer.
+    # - "saved-searches:write": Ability to create, update, and delete saved searches.
+    # - "extensions:publish": Ability to create, update, publish, and delete extensions in the extension registry.
+    # - "external-services:write": Ability to add, update, and delete external services (if the user is a site
+    #   admin).
     # - "site-admin:sudo": Ability to perform any action as any other user. (Only site admins may create tokens
     #   with this scope.)
     #
+    # All access tokens grant read-only access to the resources accessible to the user account, and all access
+    # tokens must have at least one scope other than "site-admin:sudo". Access tokens without the "user:all"
+    # scope may not perform mutations other than those permitted by their scopes.
+    #
+    # If expiresAt (an RFC 3339 date) is given, the access token is valid only until that date.
+    #
     # Only the user or site admins may perform this mutation.
-    createAccessToken(user: ID!, scopes: [String!]!, note: String!): CreateAccessTokenResult!
+    createAccessToken(user: ID!, scopes: [String!]!, note: String!, expiresAt: String): CreateAccessTokenResult!
     # Deletes and immediately revokes the specified access token, specified by either its ID or by the token
     # itself.
     #
@@ -324,6 +358,19 @@ input UpdateExternalServiceInput {
     config: String
 }
 
+# A new rewrite batch.
+input CreateRewriteBatchInput {
+    # The search query that matches the repositories (and revisions) to rewrite. Only the repo: and
+    # repogroup: filters (and other filters on repositories) are used.
+    query: String!
+    # The template that expresses what code to match, e.g. "fmt.Sprintf(:[args])".
+    matchTemplate: String!
+    # The template that expresses how matches are rewritten, e.g. "fmt.Sprint(:[args])".
+    rewriteTemplate: String!
+    # If set, only files with this extension (e.g. ".go") are rewritten.
+    fileExtension: String
+}
+
 # A selection within a file.
 input DiscussionThreadTargetRepoSelectionInput {
     # The line that the selection started on (zero-based, inclusive).
@@ -536,6 +583,10 @@ type SettingsMutation {
     ): SavedQuery!
     # Delete the saved query with the given ID in the settings.
     deleteSavedQuery(id: ID!, disableSubscriptionNotifications: Boolean = false): EmptyResponse
+    # Set the secret that the webhook notifications of the saved query with the given ID are signed with
+    # (see the X-Sourcegraph-Signature header). A null or empty secret removes it. The secret is not stored in
+    # the settings (which other users may be able to read), and it can't be read back.
+    setSavedQueryWebhookSecret(id: ID!, secret: String): EmptyResponse
 }
 
 # An edit to a JSON property in a settings JSON object. The JSON property to edit can be nested.
@@ -926,6 +977,40 @@ type SearchResults {
     elapsedMilliseconds: Int!
     # Dynamic filters generated by the search results
     dynamicFilters: [SearchFilter!]!
+    # Exact match counts of all results of the search, grouped in various ways. Unlike dynamicFilters, the
+    # counts are not limited to the results that were returned: if the search hit its result limit, it is run
+    # again (with a much higher limit) to compute them. This can be slow for searches with many results.
+    aggregations: SearchAggregations!
+}
+
+# Match counts of search results, grouped in various ways. A file match counts each of its line matches (or
+# 1 if it has none). Repository and commit results count 1.
+type SearchAggregations {
+    # Match counts by repository.
+    byRepository: [SearchAggregationBucket!]!
+    # Match counts of file matches by language (determined by the file name).
+    byLanguage: [SearchAggregationBucket!]!
+    # Match counts of file matches by top-level directory (e.g., "cmd/"). Files in the root directory are
+    # counted as "/".
+    byDirectory: [SearchAggregationBucket!]!
+    # Match counts of commit and diff results by author (in the form "Full Name <user@example.com>").
+    byAuthor: [SearchAggregationBucket!]!
+    # Match counts of commit and diff results by the month of the author date (in the form "2006-01", in UTC),
+    # ordered chronologically.
+    byMonth: [SearchAggregationBucket!]!
+    # Whether the search had more results than could be aggregated, in which case the counts are lower bounds.
+    limitHit: Boolean!
+}
+
+# The match count of a group of search results.
+type SearchAggregationBucket {
+    # The value that the results are grouped by (e.g., a repository name or language).
+    value: String!
+    # A search query filter that restricts the search to this group of results (e.g., "lang:go"), or null
+    # if there is none.
+    filter: String
+    # The number of matches in the group.
+    count: Int!
 }
 
 # Statistics about search results.
@@ -986,6 +1071,65 @@ type SavedQuery {
     notify: Boolean!
     # Whether or not to notify on Slack.
     notifySlack: Boolean!
+    # The matches that were added and removed between the last two runs of this saved query, or null
+    # if it has not run yet. Saved queries are only run if they notify by email, Slack, or a webhook.
+    lastRunDelta: SavedQueryRunDelta
+    # The URL of the webhook that is notified of new and removed results, or null if there is none.
+    webhookURL: String
+    # Whether the webhook notifications are signed with a secret (see setSavedQueryWebhookSecret).
+    webhookSecretIsSet: Boolean!
+    # The most recent deliveries of this saved query's webhook notifications, newest first.
+    webhookDeliveries(
+        # Returns the first n deliveries from the list.
+        first: Int = 20
+    ): [SavedQueryWebhookDelivery!]!
+}
+
+# A delivery (or failed delivery) of a saved query's webhook notification.
+type SavedQueryWebhookDelivery {
+    # The event of the notification, such as "saved_search.results" or "saved_search.test".
+    event: String!
+    # The URL that the notification was sent to.
+    url: String!
+    # The HTTP status code of the last response, or null if no response was received.
+    statusCode: Int
+    # The beginning of the body of the last response, if any.
+    responseBody: String
+    # The error of the last attempt, or null if the delivery succeeded.
+    error: String
+    # The number of attempts that were made to deliver the notification.
+    attempts: Int!
+    # Whether the notification was delivered successfully.
+    succeeded: Boolean!
+    # The time when the delivery was completed.
+    createdAt: String!
+}
+
+# The difference between the matches of the last two runs of a saved query.
+type SavedQueryRunDelta {
+    # The time of the last run.
+    executedAt: String!
+    # The matches found by the last run but not by the previous run. For commit and diff searches,
+    # these are the commits that are newer than the results of the previous run. On the first run of
+    # other searches, this is empty.
+    added: [SavedQueryMatch!]!
+    # The matches found by the previous run but not by the last run. This is always empty for commit
+    # and diff searches, and if the search of the last run hit its result limit.
+    removed: [SavedQueryMatch!]!
+}
+
+# A match in the search results of a saved query.
+type SavedQueryMatch {
+    # The name of the repository.
+    repository: String!
+    # The commit ID of a commit or diff match.
+    commit: String
+    # The path of the file of a file match.
+    path: String
+    # The 1-based line number of a line match.
+    lineNumber: Int
+    # The matched line, or the subject of the commit message of a commit or diff match.
+    preview: String
 }
 
 # A search query description.
@@ -1101,6 +1245,80 @@ type ExternalService implements Node {
     updatedAt: String!
 }
 
+# The state of a rewrite batch.
+enum RewriteBatchState {
+    # The diffs of the repositories are being computed.
+    PREVIEWING
+    # The diffs are ready to be reviewed and committed.
+    PREVIEWED
+    # The diffs are being committed.
+    COMMITTING
+    # The diffs were committed.
+    COMMITTED
+    # Previewing or committing the diffs was interrupted (e.g., by a restart of Sourcegraph).
+    ERRORED
+}
+
+# A code rewrite that is run across all repositories matched by a search query.
+type RewriteBatch implements Node {
+    # The unique ID of the rewrite batch.
+    id: ID!
+    # The search query that matched the repositories.
+    query: String!
+    # The template that expresses what code to match.
+    matchTemplate: String!
+    # The template that expresses how matches are rewritten.
+    rewriteTemplate: String!
+    # The extension of the rewritten files, if any.
+    fileExtension: String
+    # The state of the rewrite batch.
+    state: RewriteBatchState!
+    # The branch that the diffs are committed on, once the rewrite batch is applied.
+    branch: String
+    # The message of the commits, once the rewrite batch is applied.
+    commitMessage: String
+    # The user who created the rewrite batch.
+    creator: User!
+    # When the rewrite batch was created.
+    createdAt: String!
+    # When the rewrite batch was last updated.
+    updatedAt: String!
+    # The repositories in the rewrite batch.
+    repositories: [RewriteBatchRepository!]!
+}
+
+# The state of a repository in a rewrite batch.
+enum RewriteBatchRepositoryState {
+    # The diff has not been computed yet.
+    PENDING
+    # The diff is ready to be reviewed.
+    PREVIEWED
+    # The rewrite did not change any file in the repository.
+    NO_CHANGES
+    # The diff was committed.
+    COMMITTED
+    # Computing or committing the diff failed.
+    ERRORED
+}
+
+# A repository in a rewrite batch.
+type RewriteBatchRepository {
+    # The repository.
+    repository: Repository!
+    # The revision of the repository matched by the query (empty for the default branch).
+    rev: String!
+    # The commit that the revision resolved to, which the diff applies to.
+    baseCommit: GitObjectID
+    # The unified diff of the rewrite, if any file was changed.
+    diff: String
+    # The state of the repository.
+    state: RewriteBatchRepositoryState!
+    # The error that occurred while computing or committing the diff, if any.
+    error: String
+    # The ref of the commit created from the diff (refs/heads/{branch}), once committed.
+    commitRef: String
+}
+
 # A list of repositories.
 type RepositoryConnection {
     # A list of repositories.
@@ -1267,6 +1485,14 @@ type MirrorRepositoryInfo {
     cloned: Boolean!
     # When the repository was last successfully updated from the remote source repository..
     updatedAt: String
+    # The size of the repository's clone on disk in bytes, as of the last time it was measured, or null if
+    # it is not cloned or has not been measured yet.
+    diskSizeBytes: BigInt
+    # When the repository was last accessed on the server (for example, by a search or a code view). It
+    # is updated at most about once a minute. Null if it has not been accessed since it was cloned.
+    lastAccessedAt: String
+    # If the repository is not cloned because it exceeded its configured size limit, the reason why.
+    sizeLimitError: String
     # The state of this repository in the update schedule.
     updateSchedule: UpdateSchedule
     # The state of this repository in the update queue.
@@ -2036,6 +2262,61 @@ type FileMatch {
     lineMatches: [LineMatch!]!
     # Whether or not the limit was hit.
     limitHit: Boolean!
+    # The revisions (revspecs and names of Git refs) whose commits contain this file with the same
+    # contents. It is only set when the search specifies multiple revisions or ref globs for the
+    # repository (e.g., repo:^foo$@*refs/heads/release-*); otherwise it is empty. The file field refers
+    # to the commit of the first of these revisions.
+    refs: [String!]!
+}
+
+# The format of a search export.
+enum SearchExportFormat {
+    # Comma-separated values, with the header row: type,repository,commit,path,line,preview
+    CSV
+    # JSON Lines, with one JSON object (with the same fields as the CSV columns) per match.
+    JSONL
+}
+
+# The state of a search export.
+enum SearchExportState {
+    # The search is waiting to be run.
+    QUEUED
+    # The search is running.
+    RUNNING
+    # The export is ready to be downloaded.
+    COMPLETED
+    # The search failed.
+    ERRORED
+}
+
+# A search whose matches are exported to a file (see Mutation.createSearchExport). Each match is a row
+# with the match type (line, symbol, file, commit, diff, or repository), repository, commit, file path,
+# line number (1-based), and preview.
+type SearchExport implements Node {
+    # The unique ID of the search export.
+    id: ID!
+    # The search query.
+    query: String!
+    # The format of the export.
+    format: SearchExportFormat!
+    # The state of the search export.
+    state: SearchExportState!
+    # The number of matches in the export.
+    rowCount: Int!
+    # Whether some matches are missing from the export, because the export reached its maximum number
+    # of matches (100,000) or some repositories could not be searched (e.g., because they timed out or
+    # are being cloned).
+    incomplete: Boolean!
+    # The error, if the search failed.
+    error: String
+    # The URL from which the export can be downloaded, once it is completed.
+    url: String
+    # The user who created the search export.
+    creator: User!
+    # When the search export was created.
+    createdAt: String!
+    # When the search export finished, if it has finished.
+    finishedAt: String
 }
 
 # A line match.
@@ -2046,6 +2327,11 @@ type LineMatch {
     lineNumber: Int!
     # Tuples of [offset, length] measured in characters (not bytes).
     offsetAndLengths: [[Int!]!]!
+    # The ranges of the matches that start on this line. Unlike offsetAndLengths, a range may end on a
+    # later line (for multiline regexp and structural searches). A match that spans multiple lines is
+    # included in the offsetAndLengths of each line it spans, but only in the ranges of the line it
+    # starts on.
+    ranges: [Range!]!
     # Whether or not the limit was hit.
     limitHit: Boolean!
 }
@@ -2148,6 +2434,10 @@ type User implements Node & SettingsSubject {
         # Returns the first n external accounts from the list.
         first: Int
     ): ExternalAccountConnection!
+    # The user's search exports, most recent first. Search exports are deleted 7 days after they finish.
+    #
+    # Only the user and site admins can access this field.
+    searchExports: [SearchExport!]!
     # The user's currently active session.
     #
     # Only the currently authenticated user can access this field. Site admins are not able to access sessions for
@@ -2194,6 +2484,8 @@ type AccessToken implements Node {
     createdAt: String!
     # The date when the access token was last used to authenticate a request.
     lastUsedAt: String
+    # The date after which the access token is no longer valid, or null if it never expires.
+    expiresAt: String
 }
 
 # A list of access tokens.
@@ -2207,6 +2499,48 @@ type AccessTokenConnection {
     pageInfo: PageInfo!
 }
 
+# A list of audit log entries.
+type AuditLogEntryConnection {
+    # A list of audit log entries.
+    nodes: [AuditLogEntry!]!
+    # The total count of audit log entries in the connection. This total count may be larger than the number of
+    # nodes in this object when the result is paginated.
+    totalCount: Int!
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# An entry in the audit log, which records a security-sensitive action performed on the site.
+type AuditLogEntry {
+    # The unique ID of the audit log entry.
+    id: ID!
+    # The date when the action was performed.
+    createdAt: String!
+    # The user who performed the action, or null if it was performed by an anonymous or internal actor or if the
+    # user has since been deleted.
+    actor: User
+    # The ID of the user who performed the action (which is available even if the user has since been deleted),
+    # or null if it was performed by an anonymous or internal actor.
+    actorUserID: Int
+    # The action that was performed (such as "site_config.update" or "access_token.create").
+    action: String!
+    # The type of the target of the action (such as "site_config", "settings", "user", "access_token", or
+    # "external_service").
+    targetType: String!
+    # The ID of the target of the action (such as the user ID for actions on users). It is empty if not applicable.
+    targetID: String!
+    # The IP address of the client that performed the action (which may be a proxy), or empty if unknown.
+    ip: String!
+    # The X-Forwarded-For HTTP request header of the client that performed the action. It may be spoofed by
+    # clients.
+    forwardedFor: String!
+    # The changes made to the target, as a list of {path, before, after} objects (where path is the list of
+    # property names leading to the changed value, and a missing before or after value means that the value was
+    # added or removed, respectively). The values of properties that may contain secrets are redacted. It is null
+    # if the action does not have a diff.
+    diff: JSONValue
+}
+
 # A list of authentication providers.
 type AuthProviderConnection {
     # A list of authentication providers.
@@ -2701,6 +3035,23 @@ type Site implements SettingsSubject {
         # Returns the first n access tokens from the list.
         first: Int
     ): AccessTokenConnection!
+    # The audit log of security-sensitive actions performed on this site (such as changes to the site
+    # configuration, access tokens, site admins, and external services), most recent first. Entries are deleted
+    # after the retention period (the "auditLog.retentionDays" site configuration property).
+    #
+    # Only site admins may view the audit log.
+    auditLog(
+        # Returns the first n entries from the list.
+        first: Int
+        # Include only entries of actions performed by this user.
+        actor: ID
+        # Include only entries with this action (such as "site_config.update").
+        action: String
+        # Include only entries with this target type (such as "user").
+        targetType: String
+        # Include only entries with this target ID.
+        targetID: String
+    ): AuditLogEntryConnection!
     # A list of all authentication providers. This information is visible to all viewers and does not contain any
     # secret information.
     authProviders: AuthProviderConnection!
@@ -13,7 +13,6 @@ import (
 	"time"
 
 	otlog "github.com/opentracing/opentracing-go/log"
-	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
@@ -153,23 +152,28 @@ func (r *searchResolver) maxResults() int32 {
 
 var mockResolveRepoGroups func() (map[string][]*types.Repo, error)
 
-func resolveRepoGroups(ctx context.Context) (map[string][]*types.Repo, error) {
+// resolveRepoGroups returns the repositories of the repo groups with the given
+// names. Only the rules of those groups are evaluated (see
+// resolveRepoGroupRules). Names of groups that don't exist are omitted from
+// the result.
+func resolveRepoGroups(ctx context.Context, names []string) (map[string][]*types.Repo, error) {
 	if mockResolveRepoGroups != nil {
 		return mockResolveRepoGroups()
 	}
 
 	groups := map[string][]*types.Repo{}
 
-	// Repo groups can be defined in the search.repoGroups settings field.
-	merged, err := viewerFinalSettings(ctx)
+	settings, err := repoGroupSettings(ctx)
 	if err != nil {
 		return nil, err
 	}
-	var settings schema.Settings
-	if err := json.Unmarshal([]byte(merged.Contents()), &settings); err != nil {
-		return nil, err
-	}
-	for name, repoPaths := range settings.SearchRepositoryGroups {
+
+	// Repo groups can be defined in the search.repoGroups settings field.
+	for _, name := range names {
+		repoPaths, ok := settings.SearchRepositoryGroups[name]
+		if !ok {
+			continue
+		}
 		repos := make([]*types.Repo, len(repoPaths))
 		for i, repoPath := range repoPaths {
 			repos[i] = &types.Repo{Name: api.RepoName(repoPath)}
@@ -177,17 +181,85 @@ func resolveRepoGroups(ctx context.Context) (map[string][]*types.Repo, error) {
 		groups[name] = repos
 	}
 
+	// Repo groups can also be defined by rules in the
+	// search.repositoryGroupRules settings field. A group that is defined both
+	// ways contains the repositories of both definitions.
+	ruleGroups, err := resolveRepoGroupRules(ctx, settings, names)
+	if err != nil {
+		return nil, err
+	}
+	for name, repos := range ruleGroups {
+		seen := make(map[api.RepoName]struct{}, len(groups[name]))
+		for _, repo := range groups[name] {
+			seen[repo.Name] = struct{}{}
+		}
+		for _, repo := range repos {
+			if _, ok := seen[repo.Name]; !ok {
+				groups[name] = append(groups[name], repo)
+			}
+		}
+	}
+
 	if envvar.SourcegraphDotComMode() {
-		sampleRepos, err := getSampleRepos(ctx)
-		if err != nil {
-			return nil, err
+		for _, name := range names {
+			if name != "sample" {
+				continue
+			}
+			sampleRepos, err := getSampleRepos(ctx)
+			if err != nil {
+				return nil, err
+			}
+			groups["sample"] = sampleRepos
+			break
 		}
-		groups["sample"] = sampleRepos
 	}
 
 	return groups, nil
 }
 
+// repoGroupNames returns the names of all repo groups, in no particular
+// order. It does not evaluate any repo group rules.
+func repoGroupNames(ctx context.Context) ([]string, error) {
+	settings, err := repoGroupSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{}
+	var names []string
+	add := func(name string) {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	for name := range settings.SearchRepositoryGroups {
+		add(name)
+	}
+	for name, rule := range settings.SearchRepositoryGroupRules {
+		if rule != nil {
+			add(name)
+		}
+	}
+	if envvar.SourcegraphDotComMode() {
+		add("sample")
+	}
+	return names, nil
+}
+
+// repoGroupSettings returns the viewer's final settings, which define the repo
+// groups.
+func repoGroupSettings(ctx context.Context) (*schema.Settings, error) {
+	merged, err := viewerFinalSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var settings schema.Settings
+	if err := json.Unmarshal([]byte(merged.Contents()), &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
 var (
 	sampleReposMu sync.Mutex
 	sampleRepos   []*types.Repo
@@ -396,7 +468,7 @@ func resolveRepositories(ctx context.Context, op resolveRepoOp) (repoRevisions,
 	// groups and the set of repos specified with repo:. (If none are specified
 	// with repo:, then include all from the group.)
 	if groupNames := op.repoGroupFilters; len(groupNames) > 0 {
-		groups, err := resolveRepoGroups(ctx)
+		groups, err := resolveRepoGroups(ctx, groupNames)
 		if err != nil {
 			return nil, nil, nil, false, err
 		}
@@ -670,24 +742,9 @@ func sortSearchSuggestions(s []*searchSuggestionResolver) {
 // and -lang: filter values in a search query. For example, a query containing "lang:go" should
 // include files whose paths match /\.go$/.
 func langIncludeExcludePatterns(values, negatedValues []string) (includePatterns, excludePatterns []string, err error) {
-	lookup := func(value string) *filelang.Language {
-		value = strings.ToLower(value)
-		for _, lang := range filelang.Langs {
-			if strings.ToLower(lang.Name) == value {
-				return lang
-			}
-			for _, alias := range lang.Aliases {
-				if alias == value {
-					return lang
-				}
-			}
-		}
-		return nil
-	}
-
 	do := func(values []string, patterns *[]string) error {
 		for _, value := range values {
-			lang := lookup(value)
+			lang := lookupLanguage(value)
 			if lang == nil {
 				return fmt.Errorf("unknown language: %q", value)
 			}
@@ -710,6 +767,23 @@ func langIncludeExcludePatterns(values, negatedValues []string) (includePatterns
 	return includePatterns, excludePatterns, nil
 }
 
+// lookupLanguage returns the language with the given name or alias (e.g., the
+// value of a lang: filter), ignoring case, or nil if there is none.
+func lookupLanguage(value string) *filelang.Language {
+	value = strings.ToLower(value)
+	for _, lang := range filelang.Langs {
+		if strings.ToLower(lang.Name) == value {
+			return lang
+		}
+		for _, alias := range lang.Aliases {
+			if alias == value {
+				return lang
+			}
+		}
+	}
+	return nil
+}
+
 // handleRepoSearchResult handles the limitHit and searchErr returned by a search function,
 // updating common as to reflect that new information. If searchErr is a fatal error,
 // it returns a non-nil error; otherwise, if searchErr == nil or a non-fatal error, it returns a
@@ -737,5 +811,3 @@ func handleRepoSearchResult(common *searchResultsCommon, repoRev search.Reposito
 	}
 	return nil
 }
-
-var errMultipleRevsNotSupported = errors.New("not yet supported: searching multiple revs in the same repo")
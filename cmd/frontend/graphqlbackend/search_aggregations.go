@@ -0,0 +1,169 @@
+package graphqlbackend
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/inventory/filelang"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+)
+
+// maxSearchAggregationResults is the maximum number of results that are
+// aggregated. If a search has more results, the aggregations are incomplete.
+const maxSearchAggregationResults = 50000
+
+var languagesByFilename = filelang.Langs.CompileByFilename()
+
+// Aggregations returns the match counts of all results of the search, not
+// just of the results that were returned. If the search hit its result limit,
+// it is run again with a higher limit to compute the aggregations.
+func (sr *searchResultsResolver) Aggregations(ctx context.Context) (*searchAggregationsResolver, error) {
+	if !sr.limitHit || sr.query == nil {
+		return aggregateSearchResults(sr.results, sr.limitHit), nil
+	}
+
+	q, err := query.WithFields(sr.query, map[string]string{query.FieldCount: strconv.Itoa(maxSearchAggregationResults)})
+	if err != nil {
+		return nil, err
+	}
+	all, err := (&searchResolver{query: q}).doResults(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return aggregateSearchResults(all.results, all.limitHit), nil
+}
+
+// aggregateSearchResults computes the match counts of the results. A file
+// match counts each of its line matches; repository and commit results count
+// once.
+func aggregateSearchResults(results []*searchResultResolver, limitHit bool) *searchAggregationsResolver {
+	var (
+		byRepository = searchAggregation{}
+		byLanguage   = searchAggregation{}
+		byDirectory  = searchAggregation{}
+		byAuthor     = searchAggregation{}
+		byMonth      = searchAggregation{}
+	)
+	for _, result := range results {
+		count := result.resultCount()
+		switch {
+		case result.fileMatch != nil:
+			fm := result.fileMatch
+			byRepository.add(string(fm.repo.Name), "repo:^"+regexp.QuoteMeta(string(fm.repo.Name))+"$", count)
+			if langs := languagesByFilename(path.Base(fm.JPath)); len(langs) > 0 {
+				byLanguage.add(langs[0].Name, "lang:"+strings.ToLower(langs[0].Name), count)
+			}
+			if i := strings.Index(fm.JPath, "/"); i >= 0 {
+				dir := fm.JPath[:i+1]
+				byDirectory.add(dir, "file:^"+regexp.QuoteMeta(dir), count)
+			} else {
+				byDirectory.add("/", "file:^[^/]+$", count) // files in the root directory
+			}
+		case result.repo != nil:
+			name := string(result.repo.repo.Name)
+			byRepository.add(name, "repo:^"+regexp.QuoteMeta(name)+"$", count)
+		case result.diff != nil:
+			commit := result.diff.commit
+			if commit.repo != nil {
+				name := string(commit.repo.repo.Name)
+				byRepository.add(name, "repo:^"+regexp.QuoteMeta(name)+"$", count)
+			}
+			if person := commit.author.person; person != nil {
+				label := person.name
+				if person.email != "" {
+					label = fmt.Sprintf("%s <%s>", person.name, person.email)
+				}
+				filter := ""
+				if person.email != "" {
+					filter = "author:" + regexp.QuoteMeta("<"+person.email+">")
+				}
+				byAuthor.add(label, filter, count)
+			}
+			if date := commit.author.date; !date.IsZero() {
+				byMonth.add(date.UTC().Format("2006-01"), "", count)
+			}
+		}
+	}
+
+	return &searchAggregationsResolver{
+		byRepository: byRepository.buckets(false),
+		byLanguage:   byLanguage.buckets(false),
+		byDirectory:  byDirectory.buckets(false),
+		byAuthor:     byAuthor.buckets(false),
+		byMonth:      byMonth.buckets(true),
+		limitHit:     limitHit,
+	}
+}
+
+// searchAggregation counts matches by value.
+type searchAggregation map[string]*searchAggregationBucketResolver
+
+func (a searchAggregation) add(value, filter string, count int32) {
+	b, ok := a[value]
+	if !ok {
+		b = &searchAggregationBucketResolver{value: value}
+		if filter != "" {
+			b.filter = &filter
+		}
+		a[value] = b
+	}
+	b.count += count
+}
+
+// buckets returns the buckets sorted by descending count, or by value if
+// byValue is true.
+func (a searchAggregation) buckets(byValue bool) []*searchAggregationBucketResolver {
+	buckets := make([]*searchAggregationBucketResolver, 0, len(a))
+	for _, b := range a {
+		buckets = append(buckets, b)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if !byValue && buckets[i].count != buckets[j].count {
+			return buckets[i].count > buckets[j].count
+		}
+		return buckets[i].value < buckets[j].value
+	})
+	return buckets
+}
+
+type searchAggregationsResolver struct {
+	byRepository, byLanguage, byDirectory, byAuthor, byMonth []*searchAggregationBucketResolver
+	limitHit                                                 bool
+}
+
+func (r *searchAggregationsResolver) ByRepository() []*searchAggregationBucketResolver {
+	return r.byRepository
+}
+
+func (r *searchAggregationsResolver) ByLanguage() []*searchAggregationBucketResolver {
+	return r.byLanguage
+}
+
+func (r *searchAggregationsResolver) ByDirectory() []*searchAggregationBucketResolver {
+	return r.byDirectory
+}
+
+func (r *searchAggregationsResolver) ByAuthor() []*searchAggregationBucketResolver {
+	return r.byAuthor
+}
+
+func (r *searchAggregationsResolver) ByMonth() []*searchAggregationBucketResolver {
+	return r.byMonth
+}
+
+func (r *searchAggregationsResolver) LimitHit() bool { return r.limitHit }
+
+type searchAggregationBucketResolver struct {
+	value  string
+	filter *string
+	count  int32
+}
+
+func (r *searchAggregationBucketResolver) Value() string   { return r.value }
+func (r *searchAggregationBucketResolver) Filter() *string { return r.filter }
+func (r *searchAggregationBucketResolver) Count() int32    { return r.count }
@@ -0,0 +1,104 @@
+package graphqlbackend
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+func TestAggregateSearchResults(t *testing.T) {
+	a := &types.Repo{Name: "github.com/a/a"}
+	b := &types.Repo{Name: "github.com/b/b"}
+	fileMatch := func(repo *types.Repo, path string, lineMatches int) *searchResultResolver {
+		fm := &fileMatchResolver{repo: repo, JPath: path}
+		for i := 0; i < lineMatches; i++ {
+			fm.JLineMatches = append(fm.JLineMatches, &lineMatch{})
+		}
+		return &searchResultResolver{fileMatch: fm}
+	}
+	commit := func(repo *types.Repo, name, email string, date time.Time) *searchResultResolver {
+		return &searchResultResolver{diff: &commitSearchResultResolver{commit: &gitCommitResolver{
+			repo:   &repositoryResolver{repo: repo},
+			author: signatureResolver{person: &personResolver{name: name, email: email}, date: date},
+		}}}
+	}
+
+	results := []*searchResultResolver{
+		fileMatch(a, "cmd/main.go", 3),
+		fileMatch(a, "README.md", 1),
+		fileMatch(b, "cmd/b/b.go", 2),
+		fileMatch(b, "web/index.ts", 0),
+		{repo: &repositoryResolver{repo: b}},
+		commit(a, "Alice", "alice@example.com", time.Date(2019, 5, 31, 23, 0, 0, 0, time.UTC)),
+		commit(a, "Alice", "alice@example.com", time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)),
+		commit(b, "Bob", "bob@example.com", time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	got := aggregateSearchResults(results, true)
+
+	type bucket struct {
+		Value, Filter string
+		Count         int32
+	}
+	toBuckets := func(bs []*searchAggregationBucketResolver) []bucket {
+		var buckets []bucket
+		for _, b := range bs {
+			filter := ""
+			if b.filter != nil {
+				filter = *b.filter
+			}
+			buckets = append(buckets, bucket{b.value, filter, b.count})
+		}
+		return buckets
+	}
+	tests := map[string]struct {
+		got, want []bucket
+	}{
+		"byRepository": {
+			got: toBuckets(got.ByRepository()),
+			want: []bucket{
+				{"github.com/a/a", `repo:^github\.com/a/a$`, 6},
+				{"github.com/b/b", `repo:^github\.com/b/b$`, 5},
+			},
+		},
+		"byLanguage": {
+			got: toBuckets(got.ByLanguage()),
+			want: []bucket{
+				{"Go", "lang:go", 5},
+				{"Markdown", "lang:markdown", 1},
+				{"TypeScript", "lang:typescript", 1},
+			},
+		},
+		"byDirectory": {
+			got: toBuckets(got.ByDirectory()),
+			want: []bucket{
+				{"cmd/", "file:^cmd/", 5},
+				{"/", "file:^[^/]+$", 1},
+				{"web/", "file:^web/", 1},
+			},
+		},
+		"byAuthor": {
+			got: toBuckets(got.ByAuthor()),
+			want: []bucket{
+				{"Alice <alice@example.com>", `author:<alice@example\.com>`, 2},
+				{"Bob <bob@example.com>", `author:<bob@example\.com>`, 1},
+			},
+		},
+		"byMonth": {
+			got: toBuckets(got.ByMonth()),
+			want: []bucket{
+				{"2019-04", "", 1},
+				{"2019-05", "", 2},
+			},
+		},
+	}
+	for name, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", name, test.got, test.want)
+		}
+	}
+	if !got.LimitHit() {
+		t.Error("got limitHit false, want true")
+	}
+}
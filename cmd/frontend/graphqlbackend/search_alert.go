@@ -344,6 +344,13 @@ func (r *searchResolver) alertForMissingRepoRevs(missingRepoRevs []*search.Repos
 	}
 }
 
+func alertForBooleanPatternResultType(resultType string) *searchAlert {
+	return &searchAlert{
+		title:       fmt.Sprintf("type:%s does not support and/or/not operators", resultType),
+		description: fmt.Sprintf("Results of type:%s can't be matched against a boolean expression yet. Remove the and/or/not operators, parentheses and negated terms from your query, or search for a different type of result.", resultType),
+	}
+}
+
 func omitQueryFields(r *searchResolver, field string) string {
 	return syntax.ExprString(omitQueryExprWithField(r.query, field))
 }
@@ -0,0 +1,560 @@
+package graphqlbackend
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/neelance/parallel"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	"github.com/sourcegraph/sourcegraph/pkg/txemail"
+	"github.com/sourcegraph/sourcegraph/pkg/txemail/txtypes"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// maxSearchExportRows is the maximum number of matches in a search export. If
+// a search has more matches, the export is incomplete.
+const maxSearchExportRows = 100000
+
+// maxSearchExportDuration is the maximum amount of time that running the
+// search of an export may take.
+const maxSearchExportDuration = time.Hour
+
+// searchExportRetention is how long search exports are kept after they finish.
+const searchExportRetention = 7 * 24 * time.Hour
+
+// searchExportRepoConcurrency is the number of repositories that are searched
+// at the same time for an export.
+const searchExportRepoConcurrency = 8
+
+// searchExportWorkers is the number of search exports that each frontend
+// process runs at the same time.
+const searchExportWorkers = 2
+
+const (
+	// searchExportHeartbeatInterval is how often the worker running an
+	// export records that it is still running.
+	searchExportHeartbeatInterval = time.Minute
+
+	// searchExportStaleAfter is how long after its last heartbeat a running
+	// export is considered interrupted (because the process running it died)
+	// and is run again.
+	searchExportStaleAfter = 5 * searchExportHeartbeatInterval
+
+	// searchExportMaxAttempts is how many times an export is run before it
+	// fails, if it is interrupted every time.
+	searchExportMaxAttempts = 3
+)
+
+// searchExportQueued wakes up the worker when an export is created, so that it
+// doesn't have to wait for its next poll.
+var searchExportQueued = make(chan struct{}, 1)
+
+func (r *schemaResolver) CreateSearchExport(ctx context.Context, args *struct {
+	Query  string
+	Format string
+}) (*searchExportResolver, error) {
+	// 🚨 SECURITY: Only signed-in users may create search exports. The search
+	// runs as the same user, so it only exports results that the user can see.
+	user, err := db.Users.GetByCurrentAuthUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Format != db.SearchExportFormatCSV && args.Format != db.SearchExportFormatJSONL {
+		return nil, fmt.Errorf("invalid search export format %q", args.Format)
+	}
+	if _, err := query.ParseAndCheck(args.Query); err != nil {
+		return nil, err
+	}
+
+	export, err := db.SearchExports.Create(ctx, &db.SearchExport{
+		UserID: user.ID,
+		Query:  args.Query,
+		Format: args.Format,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case searchExportQueued <- struct{}{}:
+	default:
+	}
+	return &searchExportResolver{export: export}, nil
+}
+
+// StartSearchExportWorker should be invoked only after the DB has been
+// initialized. It runs queued search exports (including exports that were
+// interrupted by a restart of the frontend process that ran them) and deletes
+// expired exports.
+//
+// It should be invoked in a separate goroutine.
+func StartSearchExportWorker() {
+	ctx := context.Background()
+	sem := make(chan struct{}, searchExportWorkers)
+	for {
+		sem <- struct{}{}
+		export, err := db.SearchExports.Dequeue(ctx, searchExportStaleAfter, searchExportMaxAttempts)
+		if err != nil || export == nil {
+			<-sem
+			if err != nil {
+				log15.Error("Unable to dequeue search export.", "error", err)
+			}
+			if err := db.SearchExports.DeleteFinishedBefore(ctx, time.Now().Add(-searchExportRetention)); err != nil {
+				log15.Warn("Unable to delete expired search exports.", "error", err)
+			}
+			select {
+			case <-searchExportQueued:
+			case <-time.After(30 * time.Second):
+			}
+			continue
+		}
+
+		goroutine.Go(func() {
+			defer func() { <-sem }()
+			runSearchExport(ctx, export)
+		})
+	}
+}
+
+// runSearchExport runs the search of the export as the user who created it,
+// stores its matches, and notifies the user.
+func runSearchExport(ctx context.Context, export *db.SearchExport) {
+	// 🚨 SECURITY: The search must run as the user who created the export,
+	// so that it only exports results that the user can see.
+	ctx = actor.WithActor(ctx, actor.FromUser(export.UserID))
+
+	searchCtx, cancel := context.WithTimeout(ctx, maxSearchExportDuration)
+	defer cancel()
+
+	// Record that the export is still running, so that it isn't run again by
+	// another worker. If the export was deleted, stop searching.
+	done := make(chan struct{})
+	defer close(done)
+	goroutine.Go(func() {
+		ticker := time.NewTicker(searchExportHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := db.SearchExports.Heartbeat(ctx, export.ID); err != nil {
+					log15.Warn("Unable to record search export heartbeat.", "id", export.ID, "error", err)
+					if errcode.IsNotFound(err) {
+						cancel()
+					}
+				}
+			}
+		}
+	})
+
+	var (
+		rows       []*searchExportRow
+		incomplete bool
+		data       []byte
+	)
+	q, err := query.ParseAndCheck(export.Query)
+	if err == nil {
+		rows, incomplete, err = searchExportRows(searchCtx, q)
+	}
+	if err == nil {
+		data, err = encodeSearchExport(export.Format, rows)
+	}
+	if err != nil {
+		if err := db.SearchExports.Fail(ctx, export.ID, err.Error()); err != nil {
+			log15.Error("Unable to record failed search export.", "id", export.ID, "error", err)
+			return
+		}
+	} else if err := db.SearchExports.Complete(ctx, export.ID, data, int32(len(rows)), incomplete); err != nil {
+		log15.Error("Unable to store search export.", "id", export.ID, "error", err)
+		return
+	}
+
+	export, err = db.SearchExports.GetByID(ctx, export.ID)
+	if err != nil {
+		log15.Error("Unable to get finished search export.", "id", export.ID, "error", err)
+		return
+	}
+	if err := notifySearchExportFinished(ctx, export); err != nil {
+		log15.Warn("Unable to notify user of finished search export.", "id", export.ID, "error", err)
+	}
+}
+
+// searchExportRows runs the search in each repository that the query matches
+// (so that the result limit and timeout of a search apply to each repository
+// separately), searchExportRepoConcurrency repositories at a time, and
+// returns all matches in the order of the repositories. If some matches are
+// missing (e.g., because of limits or timeouts), incomplete is true.
+func searchExportRows(ctx context.Context, q *query.Query) (rows []*searchExportRow, incomplete bool, err error) {
+	repoRevs, _, _, overLimit, err := (&searchResolver{query: q}).resolveRepositories(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if overLimit {
+		return nil, false, errors.New("the query matches too many repositories; add repo: filters to the query to narrow it")
+	}
+
+	// Stop searching more repositories once there are enough rows.
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		repoRows = make([][]*searchExportRow, len(repoRevs))
+		run      = parallel.NewRun(searchExportRepoConcurrency)
+		mu       sync.Mutex
+		numRows  int
+	)
+	for i, repoRev := range repoRevs {
+		i, repoRev := i, repoRev
+		rq, err := searchExportRepoQuery(q, repoRev.Repo.Name)
+		if err != nil {
+			return nil, false, err
+		}
+		run.Acquire()
+		if searchCtx.Err() != nil {
+			run.Release()
+			mu.Lock()
+			incomplete = true
+			mu.Unlock()
+			break
+		}
+		goroutine.Go(func() {
+			defer run.Release()
+			results, err := (&searchResolver{query: rq}).doResults(searchCtx, "")
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if _, ok := err.(*badRequestError); ok {
+					run.Error(err)
+					cancel()
+					return
+				}
+				log15.Warn("Search export failed to search repository.", "repo", repoRev.Repo.Name, "error", err)
+				incomplete = true
+				return
+			}
+			if results.LimitHit() || len(results.timedout) > 0 || len(results.cloning) > 0 || len(results.missing) > 0 {
+				incomplete = true
+			}
+			for _, result := range results.results {
+				repoRows[i] = append(repoRows[i], searchExportRowsForResult(result)...)
+			}
+			numRows += len(repoRows[i])
+			if numRows >= maxSearchExportRows {
+				cancel()
+			}
+		})
+	}
+	if err := run.Wait(); err != nil {
+		return nil, false, err
+	}
+
+	for _, r := range repoRows {
+		rows = append(rows, r...)
+	}
+	if len(rows) > maxSearchExportRows {
+		rows = rows[:maxSearchExportRows]
+		incomplete = true
+	}
+	return rows, incomplete, nil
+}
+
+// searchExportRepoQuery returns the query restricted to the repository, with
+// the maximum result count and timeout.
+func searchExportRepoQuery(q *query.Query, repo api.RepoName) (*query.Query, error) {
+	return query.WithFields(q, map[string]string{
+		query.FieldRepo:    "^" + regexp.QuoteMeta(string(repo)) + "$",
+		query.FieldCount:   strconv.Itoa(maxSearchExportRows),
+		query.FieldTimeout: maxTimeout.String(),
+	})
+}
+
+// A searchExportRow is a single match in a search export.
+type searchExportRow struct {
+	Type       string `json:"type"` // "line", "symbol", "file", "commit", "diff", or "repository"
+	Repository string `json:"repository"`
+	Commit     string `json:"commit,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Line       int    `json:"line,omitempty"` // 1-based
+	Preview    string `json:"preview,omitempty"`
+}
+
+var searchExportCSVHeader = []string{"type", "repository", "commit", "path", "line", "preview"}
+
+// searchExportRowsForResult returns the rows for each match of a search
+// result: a row for each line and symbol of a file match, and a single row
+// for other results.
+func searchExportRowsForResult(result *searchResultResolver) []*searchExportRow {
+	switch {
+	case result.fileMatch != nil:
+		fm := result.fileMatch
+		base := searchExportRow{Repository: string(fm.repo.Name), Commit: string(fm.commitID), Path: fm.JPath}
+		var rows []*searchExportRow
+		for _, lm := range fm.JLineMatches {
+			row := base
+			row.Type, row.Line, row.Preview = "line", int(lm.JLineNumber)+1, lm.JPreview
+			rows = append(rows, &row)
+		}
+		for _, s := range fm.symbols {
+			row := base
+			row.Type, row.Line, row.Preview = "symbol", s.symbol.Line, s.symbol.Name
+			rows = append(rows, &row)
+		}
+		if len(rows) == 0 {
+			row := base
+			row.Type = "file"
+			rows = append(rows, &row)
+		}
+		return rows
+
+	case result.repo != nil:
+		return []*searchExportRow{{Type: "repository", Repository: string(result.repo.repo.Name)}}
+
+	case result.diff != nil:
+		commit := result.diff.commit
+		row := &searchExportRow{Type: "commit", Commit: string(commit.oid)}
+		if commit.repo != nil {
+			row.Repository = string(commit.repo.repo.Name)
+		}
+		switch {
+		case result.diff.diffPreview != nil:
+			row.Type, row.Preview = "diff", result.diff.diffPreview.value
+		case result.diff.messagePreview != nil:
+			row.Preview = result.diff.messagePreview.value
+		default:
+			row.Preview = commit.message
+		}
+		return []*searchExportRow{row}
+	}
+	return nil
+}
+
+// encodeSearchExport encodes the rows in the format of a search export.
+func encodeSearchExport(format string, rows []*searchExportRow) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case db.SearchExportFormatCSV:
+		w := csv.NewWriter(&buf)
+		_ = w.Write(searchExportCSVHeader)
+		for _, row := range rows {
+			var line string
+			if row.Line > 0 {
+				line = strconv.Itoa(row.Line)
+			}
+			_ = w.Write([]string{row.Type, csvSafe(row.Repository), row.Commit, csvSafe(row.Path), line, csvSafe(row.Preview)})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, err
+		}
+	case db.SearchExportFormatJSONL:
+		enc := json.NewEncoder(&buf)
+		for _, row := range rows {
+			if err := enc.Encode(row); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid search export format %q", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// csvSafe prefixes a CSV cell value with a single quote if spreadsheet
+// applications would interpret it as a formula (CSV injection). The value of
+// a cell comes from the searched code, so it can't be trusted.
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+// notifySearchExportFinished emails the user who created the export (if the
+// user has a verified email address).
+func notifySearchExportFinished(ctx context.Context, export *db.SearchExport) error {
+	if !conf.CanSendEmail() {
+		return nil
+	}
+	email, verified, err := db.UserEmails.GetPrimaryEmail(ctx, export.UserID)
+	if err != nil {
+		if errcode.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !verified {
+		return nil
+	}
+
+	return txemail.Send(ctx, txemail.Message{
+		To:       []string{email},
+		Template: searchExportEmailTemplates,
+		Data: struct {
+			Query      string
+			Completed  bool
+			RowCount   int32
+			Incomplete bool
+			Error      string
+			URL        string
+		}{
+			Query:      export.Query,
+			Completed:  export.State == db.SearchExportStateCompleted,
+			RowCount:   export.RowCount,
+			Incomplete: export.Incomplete,
+			Error:      export.Error,
+			URL:        globals.ExternalURL.ResolveReference(searchExportURL(export.ID)).String(),
+		},
+	})
+}
+
+var searchExportEmailTemplates = txemail.MustValidate(txtypes.Templates{
+	Subject: `{{if .Completed}}Your search export is ready{{else}}Your search export failed{{end}}`,
+	Text: `
+{{if .Completed}}The export of your search for:
+
+  {{.Query}}
+
+is ready. It contains {{.RowCount}} matches{{if .Incomplete}} (some matches are missing because of limits or timeouts){{end}}.
+
+To download it, follow this link:
+
+  {{.URL}}
+{{else}}The export of your search for:
+
+  {{.Query}}
+
+failed: {{.Error}}
+{{end}}`,
+	HTML: `
+{{if .Completed}}
+<p>The export of your search for <code>{{.Query}}</code> is ready. It contains {{.RowCount}} matches{{if .Incomplete}} (some matches are missing because of limits or timeouts){{end}}.</p>
+
+<p><strong><a href="{{.URL}}">Download the export</a></strong></p>
+{{else}}
+<p>The export of your search for <code>{{.Query}}</code> failed: {{.Error}}</p>
+{{end}}
+`,
+})
+
+// searchExportURL returns the URL (relative to the external URL) from which
+// the completed search export can be downloaded.
+func searchExportURL(id int64) *url.URL {
+	return &url.URL{Path: "/.api/search/exports/" + strconv.FormatInt(id, 10)}
+}
+
+func (r *UserResolver) SearchExports(ctx context.Context) ([]*searchExportResolver, error) {
+	// 🚨 SECURITY: Only site admins and the user can list a user's search
+	// exports.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, r.user.ID); err != nil {
+		return nil, err
+	}
+
+	exports, err := db.SearchExports.ListByUser(ctx, r.user.ID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*searchExportResolver, len(exports))
+	for i, export := range exports {
+		resolvers[i] = &searchExportResolver{export: export}
+	}
+	return resolvers, nil
+}
+
+type searchExportResolver struct {
+	export *db.SearchExport
+}
+
+const searchExportIDKind = "SearchExport"
+
+func searchExportByID(ctx context.Context, id graphql.ID) (*searchExportResolver, error) {
+	exportID, err := unmarshalSearchExportID(id)
+	if err != nil {
+		return nil, err
+	}
+	export, err := db.SearchExports.GetByID(ctx, exportID)
+	if err != nil {
+		return nil, err
+	}
+	// 🚨 SECURITY: Only site admins and the user who created the search
+	// export may view it.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, export.UserID); err != nil {
+		return nil, err
+	}
+	return &searchExportResolver{export: export}, nil
+}
+
+func marshalSearchExportID(id int64) graphql.ID {
+	return relay.MarshalID(searchExportIDKind, id)
+}
+
+func unmarshalSearchExportID(id graphql.ID) (exportID int64, err error) {
+	if kind := relay.UnmarshalKind(id); kind != searchExportIDKind {
+		err = fmt.Errorf("expected graphql ID to have kind %q; got %q", searchExportIDKind, kind)
+		return
+	}
+	err = relay.UnmarshalSpec(id, &exportID)
+	return
+}
+
+func (r *searchExportResolver) ID() graphql.ID { return marshalSearchExportID(r.export.ID) }
+
+func (r *searchExportResolver) Query() string { return r.export.Query }
+
+func (r *searchExportResolver) Format() string { return r.export.Format }
+
+func (r *searchExportResolver) State() string { return r.export.State }
+
+func (r *searchExportResolver) RowCount() int32 { return r.export.RowCount }
+
+func (r *searchExportResolver) Incomplete() bool { return r.export.Incomplete }
+
+func (r *searchExportResolver) Error() *string {
+	if r.export.Error == "" {
+		return nil
+	}
+	return &r.export.Error
+}
+
+func (r *searchExportResolver) URL() *string {
+	if r.export.State != db.SearchExportStateCompleted {
+		return nil
+	}
+	u := searchExportURL(r.export.ID).String()
+	return &u
+}
+
+func (r *searchExportResolver) Creator(ctx context.Context) (*UserResolver, error) {
+	return UserByIDInt32(ctx, r.export.UserID)
+}
+
+func (r *searchExportResolver) CreatedAt() string { return r.export.CreatedAt.Format(time.RFC3339) }
+
+func (r *searchExportResolver) FinishedAt() *string {
+	if r.export.FinishedAt == nil {
+		return nil
+	}
+	s := r.export.FinishedAt.Format(time.RFC3339)
+	return &s
+}
@@ -0,0 +1,90 @@
+package graphqlbackend
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query/syntax"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/symbols/protocol"
+)
+
+func TestSearchExportRepoQuery(t *testing.T) {
+	tests := map[string]string{
+		"repo:foo count:10 timeout:3s bar": `repo:foo bar count:100000 repo:^github\.com/foo/bar$ timeout:1m0s`,
+		"foo or bar":                       `(foo or bar) count:100000 repo:^github\.com/foo/bar$ timeout:1m0s`,
+		"repo:foo (a or b) -c max:5":       `repo:foo (a or b) -c count:100000 repo:^github\.com/foo/bar$ timeout:1m0s`,
+	}
+	for input, want := range tests {
+		q, err := query.ParseAndCheck(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := searchExportRepoQuery(q, "github.com/foo/bar")
+		if err != nil {
+			t.Fatalf("%q: %s", input, err)
+		}
+		if syntax.ExprString(got.Syntax.Expr) != want {
+			t.Errorf("%q: got %q, want %q", input, syntax.ExprString(got.Syntax.Expr), want)
+		}
+		if (got.Pattern == nil) != (q.Pattern == nil) {
+			t.Errorf("%q: the boolean pattern expression was not preserved", input)
+		}
+	}
+}
+
+func TestEncodeSearchExport(t *testing.T) {
+	repo := &types.Repo{Name: "r"}
+	results := []*searchResultResolver{
+		{fileMatch: &fileMatchResolver{
+			JPath:        "a.go",
+			JLineMatches: []*lineMatch{{JPreview: `x := "y, z"`, JLineNumber: 9}},
+			repo:         repo,
+			commitID:     "c",
+		}},
+		{fileMatch: &fileMatchResolver{
+			JPath:    "b.go",
+			symbols:  []*searchSymbolResult{{symbol: protocol.Symbol{Name: "F", Line: 3}}},
+			repo:     repo,
+			commitID: "c",
+		}},
+		{fileMatch: &fileMatchResolver{JPath: "c.go", repo: repo, commitID: "c"}},
+		{fileMatch: &fileMatchResolver{
+			JPath:        "-d.csv",
+			JLineMatches: []*lineMatch{{JPreview: `=HYPERLINK("http://example.com")`, JLineNumber: 0}},
+			repo:         repo,
+			commitID:     "c",
+		}},
+		{repo: &repositoryResolver{repo: repo}},
+	}
+	var rows []*searchExportRow
+	for _, result := range results {
+		rows = append(rows, searchExportRowsForResult(result)...)
+	}
+
+	tests := map[string]string{
+		db.SearchExportFormatCSV: `type,repository,commit,path,line,preview
+line,r,c,a.go,10,"x := ""y, z"""
+symbol,r,c,b.go,3,F
+file,r,c,c.go,,
+line,r,c,'-d.csv,1,"'=HYPERLINK(""http://example.com"")"
+repository,r,,,,
+`,
+		db.SearchExportFormatJSONL: `{"type":"line","repository":"r","commit":"c","path":"a.go","line":10,"preview":"x := \"y, z\""}
+{"type":"symbol","repository":"r","commit":"c","path":"b.go","line":3,"preview":"F"}
+{"type":"file","repository":"r","commit":"c","path":"c.go"}
+{"type":"line","repository":"r","commit":"c","path":"-d.csv","line":1,"preview":"=HYPERLINK(\"http://example.com\")"}
+{"type":"repository","repository":"r"}
+`,
+	}
+	for format, want := range tests {
+		data, err := encodeSearchExport(format, rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s: got %q, want %q", format, data, want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package graphqlbackend
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/neelance/parallel"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/trace"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// The orders of search results that can be chosen with the sort: field.
+const (
+	sortLexical   = "lexical"   // by repository name and file path (the default)
+	sortRelevance = "relevance" // by the score of the ranking signals
+)
+
+// resultSortOrder returns the order of search results specified by the sort:
+// field of the query.
+func (r *searchResolver) resultSortOrder() (string, error) {
+	switch order, _ := r.query.StringValue(query.FieldSort); order {
+	case "", sortLexical:
+		return sortLexical, nil
+	case sortRelevance:
+		return sortRelevance, nil
+	default:
+		return "", fmt.Errorf("invalid sort:%q (valid values are: %s, %s)", order, sortRelevance, sortLexical)
+	}
+}
+
+// A rankingSignal scores search results by a single measure of relevance.
+type rankingSignal struct {
+	name   string
+	weight float64
+
+	// topN, if non-zero, limits the signal to the N results that rank highest
+	// by the preceding signals, because the signal is expensive to compute.
+	topN int
+
+	// score returns a score between 0 and 1 (best) for each of the results.
+	score func(ctx context.Context, results []*searchResultResolver) []float64
+}
+
+// rankingSignals are the signals that rank search results by relevance. The
+// score of a result is the weighted sum of the scores of all signals.
+var rankingSignals = []rankingSignal{
+	{name: "definition", weight: 4, score: scoreDefinitionMatch},
+	{name: "non-test-vendor", weight: 2, score: scoreNonTestVendor},
+	{name: "path-depth", weight: 1, score: scorePathDepth},
+	{name: "stars", weight: 1, score: scoreStars},
+	{name: "recency", weight: 1, topN: 100, score: scoreRecency},
+}
+
+// rankResults sorts the results by relevance. Diff and commit results are
+// placed last in their original order, because they are already ordered by
+// date.
+func rankResults(ctx context.Context, results []*searchResultResolver) {
+	tr, ctx := trace.New(ctx, "rankResults", "")
+	defer tr.Finish()
+
+	var ranked, diffs []*searchResultResolver
+	for _, result := range results {
+		if result.diff != nil {
+			diffs = append(diffs, result)
+		} else {
+			ranked = append(ranked, result)
+		}
+	}
+
+	scores := make(map[*searchResultResolver]float64, len(ranked))
+	sortByScore := func() {
+		sort.SliceStable(ranked, func(i, j int) bool {
+			if si, sj := scores[ranked[i]], scores[ranked[j]]; si != sj {
+				return si > sj
+			}
+			return compareSearchResults(ranked[i], ranked[j])
+		})
+	}
+	for _, signal := range rankingSignals {
+		rs := ranked
+		if signal.topN > 0 {
+			sortByScore()
+			if len(rs) > signal.topN {
+				rs = rs[:signal.topN]
+			}
+		}
+		for i, score := range signal.score(ctx, rs) {
+			scores[rs[i]] += signal.weight * score
+		}
+		tr.LazyPrintf("signal %s: scored %d results", signal.name, len(rs))
+	}
+	sortByScore()
+
+	copy(results, ranked)
+	copy(results[len(ranked):], diffs)
+}
+
+// definitionPrefixPattern matches the text on a line before a match if the
+// match is (probably) the name of a definition, such as "func " or "class ".
+var definitionPrefixPattern = regexp.MustCompile(`(^|\W)(func|def|class|type|interface|struct|enum|trait|impl|fn|module|namespace|function|var|const|let|val)\b[^=()]*(\([^)]*\)\s*)?$`)
+
+// scoreDefinitionMatch scores file matches that match a symbol definition.
+func scoreDefinitionMatch(ctx context.Context, results []*searchResultResolver) []float64 {
+	scores := make([]float64, len(results))
+	for i, result := range results {
+		if result.fileMatch == nil {
+			continue
+		}
+		if len(result.fileMatch.symbols) > 0 {
+			scores[i] = 1
+			continue
+		}
+	lines:
+		for _, lm := range result.fileMatch.JLineMatches {
+			preview := []rune(lm.JPreview)
+			for _, ol := range lm.JOffsetAndLengths {
+				if offset := int(ol[0]); offset <= len(preview) && definitionPrefixPattern.MatchString(string(preview[:offset])) {
+					scores[i] = 1
+					break lines
+				}
+			}
+		}
+	}
+	return scores
+}
+
+// testOrVendorPathPattern matches the paths of test, vendored, generated, and
+// minified files.
+var testOrVendorPathPattern = regexp.MustCompile(`(^|/)(tests?|__tests__|spec|testdata|fixtures|vendor|node_modules|third_party|bower_components)/|(_test|\.test|\.spec|_spec|Test|Tests|\.min|\.pb|_generated)\.[^/]*$|(^|/)test_[^/]*$`)
+
+// scoreNonTestVendor scores file matches that are not in test, vendored,
+// generated, or minified files.
+func scoreNonTestVendor(ctx context.Context, results []*searchResultResolver) []float64 {
+	scores := make([]float64, len(results))
+	for i, result := range results {
+		if result.fileMatch != nil && !testOrVendorPathPattern.MatchString(result.fileMatch.JPath) {
+			scores[i] = 1
+		}
+	}
+	return scores
+}
+
+// scorePathDepth scores file matches by the depth of their path, preferring
+// files closer to the repository root.
+func scorePathDepth(ctx context.Context, results []*searchResultResolver) []float64 {
+	scores := make([]float64, len(results))
+	for i, result := range results {
+		if result.fileMatch != nil {
+			scores[i] = 1 / float64(1+strings.Count(result.fileMatch.JPath, "/"))
+		}
+	}
+	return scores
+}
+
+// scoreStars scores results by the number of stars of their repository on the
+// code host, on a logarithmic scale that reaches 1 at 100,000 stars.
+func scoreStars(ctx context.Context, results []*searchResultResolver) []float64 {
+	scores := make([]float64, len(results))
+	repoIDs := map[api.RepoID]struct{}{}
+	for _, result := range results {
+		if repo := searchResultRepo(result); repo != nil {
+			repoIDs[repo.ID] = struct{}{}
+		}
+	}
+	if len(repoIDs) == 0 {
+		return scores
+	}
+	ids := make([]api.RepoID, 0, len(repoIDs))
+	for id := range repoIDs {
+		ids = append(ids, id)
+	}
+	counts, err := db.Repos.StarCounts(ctx, ids)
+	if err != nil {
+		log15.Warn("Unable to get star counts to rank search results.", "error", err)
+		return scores
+	}
+	for i, result := range results {
+		if repo := searchResultRepo(result); repo != nil {
+			scores[i] = math.Min(1, math.Log10(1+float64(counts[repo.ID]))/5)
+		}
+	}
+	return scores
+}
+
+// searchResultRepo returns the repository of a file match or repository
+// result.
+func searchResultRepo(result *searchResultResolver) *types.Repo {
+	switch {
+	case result.fileMatch != nil:
+		return result.fileMatch.repo
+	case result.repo != nil:
+		return result.repo.repo
+	}
+	return nil
+}
+
+// rankingRecencyTimeout is the time budget for determining when the files of
+// search results were last changed.
+const rankingRecencyTimeout = time.Second
+
+// scoreRecency scores file matches by when the file was last changed, with a
+// half-life of 30 days. Files whose last change can't be determined within
+// rankingRecencyTimeout score 0.
+func scoreRecency(ctx context.Context, results []*searchResultResolver) []float64 {
+	ctx, cancel := context.WithTimeout(ctx, rankingRecencyTimeout)
+	defer cancel()
+
+	var (
+		scores = make([]float64, len(results))
+		now    = time.Now()
+		run    = parallel.NewRun(8)
+	)
+	for i, result := range results {
+		fm := result.fileMatch
+		if fm == nil {
+			continue
+		}
+		i := i
+		run.Acquire()
+		goroutine.Go(func() {
+			defer run.Release()
+			cachedRepo, err := backend.CachedGitRepo(ctx, fm.repo)
+			if err != nil {
+				return
+			}
+			rev := string(fm.commitID)
+			if rev == "" {
+				rev = "HEAD"
+			}
+			commits, err := git.Commits(ctx, *cachedRepo, git.CommitsOptions{Range: rev, Path: fm.JPath, N: 1})
+			if err != nil || len(commits) == 0 {
+				return
+			}
+			date := commits[0].Author.Date
+			if commits[0].Committer != nil {
+				date = commits[0].Committer.Date
+			}
+			days := math.Max(0, now.Sub(date).Hours()/24)
+			scores[i] = math.Pow(0.5, days/30)
+		})
+	}
+	_ = run.Wait()
+	return scores
+}
@@ -0,0 +1,129 @@
+package graphqlbackend
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+func TestSearchResolver_resultSortOrder(t *testing.T) {
+	tests := map[string]struct {
+		want    string
+		wantErr bool
+	}{
+		"foo":                {want: sortLexical},
+		"foo sort:lexical":   {want: sortLexical},
+		"foo sort:relevance": {want: sortRelevance},
+		"foo sort:stars":     {wantErr: true},
+	}
+	for input, test := range tests {
+		q, err := query.ParseAndCheck(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := (&searchResolver{query: q}).resultSortOrder()
+		if (err != nil) != test.wantErr {
+			t.Errorf("%q: got error %v, want error %v", input, err, test.wantErr)
+		}
+		if got != test.want {
+			t.Errorf("%q: got %q, want %q", input, got, test.want)
+		}
+	}
+}
+
+func TestDefinitionPrefixPattern(t *testing.T) {
+	tests := map[string]bool{
+		"func ":                     true,
+		"func (r *searchResolver) ": true,
+		"\tclass ":                  true,
+		"export function ":          true,
+		"type ":                     true,
+		"const x = ":                false,
+		"return ":                   false,
+		"default: ":                 false,
+		"x := ":                     false,
+	}
+	for prefix, want := range tests {
+		if got := definitionPrefixPattern.MatchString(prefix); got != want {
+			t.Errorf("%q: got %v, want %v", prefix, got, want)
+		}
+	}
+}
+
+func TestTestOrVendorPathPattern(t *testing.T) {
+	tests := map[string]bool{
+		"main.go":                      false,
+		"pkg/search/search.go":         false,
+		"pkg/search/search_test.go":    true,
+		"src/app.spec.ts":              true,
+		"src/test/java/FooTest.java":   true,
+		"vendor/github.com/a/b/b.go":   true,
+		"web/node_modules/x/index.js":  true,
+		"static/jquery.min.js":         true,
+		"pkg/testutil/testutil.go":     false,
+		"internal/latest/version.go":   false,
+		"python/tests/test_parser.py":  true,
+		"cmd/frontend/contest/main.go": false,
+	}
+	for path, want := range tests {
+		if got := testOrVendorPathPattern.MatchString(path); got != want {
+			t.Errorf("%q: got %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestRankResults(t *testing.T) {
+	// Don't compute the recency signal, which requires gitserver.
+	defer func(signals []rankingSignal) { rankingSignals = signals }(rankingSignals)
+	rankingSignals = rankingSignals[:len(rankingSignals)-1]
+
+	db.Mocks.Repos.StarCounts = func(ctx context.Context, ids []api.RepoID) (map[api.RepoID]int, error) {
+		return map[api.RepoID]int{2: 10000}, nil
+	}
+	defer func() { db.Mocks.Repos.StarCounts = nil }()
+
+	a := &types.Repo{ID: 1, Name: "a"}
+	b := &types.Repo{ID: 2, Name: "b"}
+	fileMatch := func(repo *types.Repo, path string, lines ...*lineMatch) *searchResultResolver {
+		return &searchResultResolver{fileMatch: &fileMatchResolver{repo: repo, JPath: path, JLineMatches: lines}}
+	}
+	diff := &searchResultResolver{diff: &commitSearchResultResolver{}}
+
+	results := []*searchResultResolver{
+		fileMatch(a, "a/b/c/d.go"),
+		diff,
+		fileMatch(a, "vendor/x/x.go", &lineMatch{JPreview: "func Foo() {", JOffsetAndLengths: [][2]int32{{5, 3}}}),
+		fileMatch(a, "foo.go", &lineMatch{JPreview: "func Foo() {", JOffsetAndLengths: [][2]int32{{5, 3}}}),
+		fileMatch(a, "foo_test.go"),
+		fileMatch(b, "a/b/c/d.go"),
+		{repo: &repositoryResolver{repo: a}},
+	}
+	rankResults(context.Background(), results)
+
+	var got []string
+	for _, result := range results {
+		if result.diff != nil {
+			got = append(got, "diff")
+			continue
+		}
+		repo, path := getSearchResultURIs(result)
+		got = append(got, repo+":"+path)
+	}
+	want := []string{
+		"a:foo.go",        // definition, non-test, top-level
+		"a:vendor/x/x.go", // definition
+		"b:a/b/c/d.go",    // non-test, starred repository
+		"a:a/b/c/d.go",    // non-test
+		"a:foo_test.go",   // top-level
+		"a:",              // repository match
+		"diff",            // diffs are last
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -14,7 +14,8 @@ var repoIcon = "data:image/svg+xml;base64,PHN2ZyB2ZXJzaW9uPSIxLjEiIGlkPSJMYXllcl
 // searchRepositories searches for repositories by name.
 //
 // For a repository to match a query, the repository's name must match all of the repo: patterns AND the
-// default patterns (i.e., the patterns that are not prefixed with any search field).
+// default patterns (i.e., the patterns that are not prefixed with any search field). If the default
+// patterns form a boolean expression, the repository's name must satisfy the expression.
 func searchRepositories(ctx context.Context, args *search.Args, limit int32) (res []*searchResultResolver, common *searchResultsCommon, err error) {
 	if mockSearchRepositories != nil {
 		return mockSearchRepositories(args)
@@ -31,6 +32,7 @@ func searchRepositories(ctx context.Context, args *search.Args, limit int32) (re
 		query.FieldTimeout:   {},
 		query.FieldFork:      {},
 		query.FieldArchived:  {},
+		query.FieldSort:      {},
 	}
 	// Don't return repo results if the search contains fields that aren't on the whitelist.
 	// Matching repositories based whether they contain files at a certain path (etc.) is not yet implemented.
@@ -40,7 +42,7 @@ func searchRepositories(ctx context.Context, args *search.Args, limit int32) (re
 		}
 	}
 
-	pattern, err := regexp.Compile(args.Pattern.Pattern)
+	match, err := compileRepoNameMatcher(args.Pattern)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -52,9 +54,57 @@ func searchRepositories(ctx context.Context, args *search.Args, limit int32) (re
 			common.limitHit = true
 			break
 		}
-		if pattern.MatchString(string(repo.Repo.Name)) {
+		if match(string(repo.Repo.Name)) {
 			results = append(results, &searchResultResolver{repo: &repositoryResolver{repo: repo.Repo, icon: repoIcon}})
 		}
 	}
 	return results, common, nil
 }
+
+// compileRepoNameMatcher returns a function that reports whether a repository
+// name matches the search pattern p.
+func compileRepoNameMatcher(p *search.PatternInfo) (func(name string) bool, error) {
+	if p.PatternExpr != nil {
+		return compilePatternExprMatcher(p.PatternExpr)
+	}
+	pattern, err := regexp.Compile(p.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	return pattern.MatchString, nil
+}
+
+// compilePatternExprMatcher returns a function that reports whether a string
+// satisfies the boolean pattern expression e.
+func compilePatternExprMatcher(e *search.PatternExpr) (func(string) bool, error) {
+	var match func(string) bool
+	if e.Op == "" {
+		pattern, err := regexp.Compile(e.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		match = pattern.MatchString
+	} else {
+		operands := make([]func(string) bool, len(e.Operands))
+		for i, o := range e.Operands {
+			var err error
+			operands[i], err = compilePatternExprMatcher(o)
+			if err != nil {
+				return nil, err
+			}
+		}
+		or := e.Op == search.PatternOpOr
+		match = func(s string) bool {
+			for _, m := range operands {
+				if m(s) == or {
+					return or
+				}
+			}
+			return !or
+		}
+	}
+	if e.Not {
+		return func(s string) bool { return !match(s) }, nil
+	}
+	return match, nil
+}
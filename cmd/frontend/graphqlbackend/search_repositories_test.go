@@ -0,0 +1,46 @@
+package graphqlbackend
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search"
+)
+
+func TestCompilePatternExprMatcher(t *testing.T) {
+	leaf := func(pattern string, not bool) *search.PatternExpr {
+		return &search.PatternExpr{Pattern: pattern, Not: not}
+	}
+	tests := map[string]struct {
+		expr *search.PatternExpr
+		want map[string]bool
+	}{
+		"foo -bar": {
+			expr: &search.PatternExpr{Op: search.PatternOpAnd, Operands: []*search.PatternExpr{leaf("foo", false), leaf("bar", true)}},
+			want: map[string]bool{"github.com/a/foo": true, "github.com/a/foobar": false, "github.com/a/baz": false},
+		},
+		"foo or bar": {
+			expr: &search.PatternExpr{Op: search.PatternOpOr, Operands: []*search.PatternExpr{leaf("foo", false), leaf("bar", false)}},
+			want: map[string]bool{"github.com/a/foo": true, "github.com/a/bar": true, "github.com/a/baz": false},
+		},
+		"-(foo or bar) a": {
+			expr: &search.PatternExpr{Op: search.PatternOpAnd, Operands: []*search.PatternExpr{
+				{Op: search.PatternOpOr, Not: true, Operands: []*search.PatternExpr{leaf("foo", false), leaf("bar", false)}},
+				leaf("a", false),
+			}},
+			want: map[string]bool{"github.com/a/foo": false, "github.com/a/baz": true},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			match, err := compilePatternExprMatcher(test.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for s, want := range test.want {
+				if got := match(s); got != want {
+					t.Errorf("%q: got %v, want %v", s, got, want)
+				}
+			}
+		})
+	}
+}
@@ -25,6 +25,8 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/inventory/filelang"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query/syntax"
+	searchquerytypes "github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query/types"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
@@ -148,7 +150,8 @@ type searchResultsResolver struct {
 	results []*searchResultResolver
 	searchResultsCommon
 	alert *searchAlert
-	start time.Time // when the results started being computed
+	start time.Time    // when the results started being computed
+	query *query.Query // the query that the results are for (used to compute aggregations)
 }
 
 func (sr *searchResultsResolver) Results() []*searchResultResolver {
@@ -579,21 +582,36 @@ type getPatternInfoOptions struct {
 
 // getPatternInfo gets the search pattern info for the query in the resolver.
 func (r *searchResolver) getPatternInfo(opts *getPatternInfoOptions) (*search.PatternInfo, error) {
-	var patternsToCombine []string
+	var (
+		patternsToCombine []string
+		patternExpr       *search.PatternExpr
+		isStructuralPat   bool
+	)
+	switch patternType, _ := r.query.StringValue(query.FieldPatternType); patternType {
+	case "", "regexp":
+	case "structural":
+		isStructuralPat = opts == nil || !opts.forceFileSearch
+	default:
+		return nil, fmt.Errorf("invalid patterntype:%q (valid values are: regexp, structural)", patternType)
+	}
+	if isStructuralPat && r.query.Pattern != nil {
+		return nil, errors.New("patterntype:structural may not be combined with and/or/not operators or negated terms")
+	}
 	if opts == nil || !opts.forceFileSearch {
-		for _, v := range r.query.Values(query.FieldDefault) {
-			// Treat quoted strings as literal strings to match, not regexps.
-			var pattern string
-			switch {
-			case v.String != nil:
-				pattern = regexp.QuoteMeta(*v.String)
-			case v.Regexp != nil:
-				pattern = v.Regexp.String()
-			}
-			if pattern == "" {
-				continue
+		if r.query.Pattern != nil {
+			// The query uses boolean operators (or negated terms), so its
+			// patterns are evaluated as an expression over each file's
+			// content.
+			patternExpr = toPatternExpr(r.query.Pattern)
+			patternsToCombine = positivePatterns(patternExpr, false)
+		} else {
+			for _, v := range r.query.Values(query.FieldDefault) {
+				pattern := patternValueRegexp(v)
+				if pattern == "" {
+					continue
+				}
+				patternsToCombine = append(patternsToCombine, pattern)
 			}
-			patternsToCombine = append(patternsToCombine, pattern)
 		}
 	} else {
 		// TODO: We must have some pattern that always matches here, or else
@@ -609,7 +627,11 @@ func (r *searchResolver) getPatternInfo(opts *getPatternInfoOptions) (*search.Pa
 
 	if opts != nil && opts.forceFileSearch {
 		for _, v := range r.query.Values(query.FieldDefault) {
-			includePatterns = append(includePatterns, asString(v))
+			if v.Not() {
+				excludePatterns = append(excludePatterns, asString(v))
+			} else {
+				includePatterns = append(includePatterns, asString(v))
+			}
 		}
 	}
 
@@ -626,16 +648,87 @@ func (r *searchResolver) getPatternInfo(opts *getPatternInfoOptions) (*search.Pa
 		IsCaseSensitive:              r.query.IsCaseSensitive(),
 		FileMatchLimit:               r.maxResults(),
 		Pattern:                      regexpPatternMatchingExprsInOrder(patternsToCombine),
+		PatternExpr:                  patternExpr,
 		IncludePatterns:              includePatterns,
 		PathPatternsAreRegExps:       true,
 		PathPatternsAreCaseSensitive: r.query.IsCaseSensitive(),
 	}
+	var structuralTerms []string
+	if isStructuralPat {
+		for _, v := range r.query.Values(query.FieldDefault) {
+			structuralTerms = append(structuralTerms, asString(v))
+		}
+	}
+	if len(structuralTerms) > 0 {
+		// The terms of a structural search form a single template, and they
+		// are not regexps. Structural search is always case sensitive.
+		patternInfo.IsRegExp = false
+		patternInfo.IsStructuralPat = true
+		patternInfo.IsCaseSensitive = true
+		patternInfo.Pattern = strings.Join(structuralTerms, " ")
+	}
+	if patternInfo.IsRegExp && r.query.IsMultiline() {
+		// Structural patterns are always matched against whole file
+		// contents, so multiline:yes only affects regexp patterns.
+		patternInfo.IsMultiline = true
+	}
+	if patternExpr != nil {
+		// Any of the non-negated patterns may be the reason that a file
+		// matches, so all of them are used to find matching lines.
+		patternInfo.Pattern = unionRegExps(patternsToCombine)
+	}
 	if len(excludePatterns) > 0 {
 		patternInfo.ExcludePattern = unionRegExps(excludePatterns)
 	}
 	return patternInfo, nil
 }
 
+// patternValueRegexp returns the regexp pattern that matches the search
+// pattern value v. Quoted strings are treated as literal strings to match,
+// not regexps.
+func patternValueRegexp(v *searchquerytypes.Value) string {
+	switch {
+	case v.String != nil:
+		return regexp.QuoteMeta(*v.String)
+	case v.Regexp != nil:
+		return v.Regexp.String()
+	}
+	return ""
+}
+
+// toPatternExpr converts the boolean expression tree of a query's search
+// patterns to the form used by the search backends.
+func toPatternExpr(n *searchquerytypes.Node) *search.PatternExpr {
+	if n.IsLeaf() {
+		return &search.PatternExpr{Not: n.Not, Pattern: patternValueRegexp(n.Value)}
+	}
+	e := &search.PatternExpr{Op: search.PatternOpAnd, Not: n.Not, Operands: make([]*search.PatternExpr, len(n.Operands))}
+	if n.Op == syntax.OpOr {
+		e.Op = search.PatternOpOr
+	}
+	for i, o := range n.Operands {
+		e.Operands[i] = toPatternExpr(o)
+	}
+	return e
+}
+
+// positivePatterns returns the patterns of the leaf nodes in e that are not
+// negated (by themselves or an odd number of their ancestors). The parameter
+// not is whether e's ancestors negate e.
+func positivePatterns(e *search.PatternExpr, not bool) (patterns []string) {
+	not = not != e.Not
+	if e.Op == "" {
+		if !not && e.Pattern != "" {
+			patterns = append(patterns, e.Pattern)
+		}
+		return patterns
+	}
+	for _, o := range e.Operands {
+		patterns = append(patterns, positivePatterns(o, not)...)
+	}
+	return patterns
+}
+
 var (
 	// The default timeout to use for queries.
 	defaultTimeout = 10 * time.Second
@@ -678,6 +771,11 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 
 	start := time.Now()
 
+	sortOrder, err := r.resultSortOrder()
+	if err != nil {
+		return nil, &badRequestError{err}
+	}
+
 	ctx, cancel, err := r.withTimeout(ctx)
 	if err != nil {
 		return nil, err
@@ -725,19 +823,39 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 	} else {
 		resultTypes, _ = r.query.StringValues(query.FieldType)
 		if len(resultTypes) == 0 {
-			resultTypes = []string{"file", "path", "repo", "ref"}
+			if len(r.query.Values(query.FieldKind)) > 0 || len(r.query.Values(query.FieldContainer)) > 0 {
+				// The kind: and container: filters only apply to symbols.
+				resultTypes = []string{"symbol"}
+			} else {
+				resultTypes = []string{"file", "path", "repo", "ref"}
+			}
+		}
+	}
+	if args.Pattern.PatternExpr != nil {
+		for _, resultType := range resultTypes {
+			switch resultType {
+			case "symbol", "diff", "commit":
+				// Only file content, path and repository name matching
+				// evaluate boolean pattern expressions.
+				return &searchResultsResolver{alert: alertForBooleanPatternResultType(resultType), start: start}, nil
+			}
 		}
 	}
 	seenResultTypes := make(map[string]struct{}, len(resultTypes))
 	for _, resultType := range resultTypes {
 		if resultType == "file" {
 			args.Pattern.PatternMatchesContent = true
-		} else if resultType == "path" {
+		} else if resultType == "path" && !args.Pattern.IsStructuralPat {
+			// Structural patterns only match file contents.
 			args.Pattern.PatternMatchesPath = true
 		}
 	}
 	tr.LazyPrintf("resultTypes: %v", resultTypes)
 
+	// stream, if non-nil, receives results and progress as each search
+	// backend responds (see StreamSearch).
+	stream := searchStreamFromContext(ctx)
+
 	var (
 		requiredWg sync.WaitGroup
 		optionalWg sync.WaitGroup
@@ -796,6 +914,7 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 					common.update(*repoCommon)
 					commonMu.Unlock()
 				}
+				stream.update(repoResults, repoCommon)
 			})
 		case "symbol":
 			wg := waitGroup(len(resultTypes) == 1)
@@ -828,6 +947,7 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 					common.update(*symbolsCommon)
 					commonMu.Unlock()
 				}
+				stream.update(fileMatchesToSearchResults(symbolFileMatches), symbolsCommon)
 			})
 		case "file", "path":
 			if searchedFileContentsOrPaths {
@@ -868,6 +988,9 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 					common.update(*fileCommon)
 					commonMu.Unlock()
 				}
+				// searchFilesInRepos already streamed the file matches as they
+				// were found.
+				stream.update(nil, fileCommon)
 			})
 		case "diff":
 			wg := waitGroup(len(resultTypes) == 1)
@@ -891,6 +1014,7 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 					common.update(*diffCommon)
 					commonMu.Unlock()
 				}
+				stream.update(diffResults, diffCommon)
 			})
 		case "commit":
 			wg := waitGroup(len(resultTypes) == 1)
@@ -915,6 +1039,7 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 					common.update(*commitCommon)
 					commonMu.Unlock()
 				}
+				stream.update(commitResults, commitCommon)
 			})
 		}
 	}
@@ -949,13 +1074,18 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 		multiErr = nil
 	}
 
-	sortResults(results)
+	if sortOrder == sortRelevance {
+		rankResults(ctx, results)
+	} else {
+		sortResults(results)
+	}
 
 	resultsResolver := searchResultsResolver{
 		start:               start,
 		searchResultsCommon: common,
 		results:             results,
 		alert:               alert,
+		query:               r.query,
 	}
 
 	return &resultsResolver, multiErr.ErrorOrNil()
@@ -124,6 +124,29 @@ func TestSearchResults(t *testing.T) {
 			t.Error("calledSearchSymbols")
 		}
 	})
+
+	t.Run("boolean pattern with type:commit", func(t *testing.T) {
+		db.Mocks.Repos.List = func(_ context.Context, op db.ReposListOptions) ([]*types.Repo, error) {
+			return []*types.Repo{{Name: "repo"}}, nil
+		}
+		defer func() { db.Mocks = db.MockStores{} }()
+		db.Mocks.Repos.MockGetByName(t, "repo", 1)
+
+		r, err := (&schemaResolver{}).Search(&struct{ Query string }{Query: `type:commit foo -bar`})
+		if err != nil {
+			t.Fatal("Search:", err)
+		}
+		results, err := r.Results(context.Background())
+		if err != nil {
+			t.Fatal("Results:", err)
+		}
+		if len(results.results) != 0 {
+			t.Errorf("got %d results, want none", len(results.results))
+		}
+		if want := "type:commit does not support and/or/not operators"; results.alert == nil || results.alert.title != want {
+			t.Errorf("got alert %+v, want title %q", results.alert, want)
+		}
+	})
 }
 
 func TestRegexpPatternMatchingExprsInOrder(t *testing.T) {
@@ -220,6 +243,49 @@ func TestSearchResolver_getPatternInfo(t *testing.T) {
 			PathPatternsAreRegExps: true,
 			ExcludePattern:         `f|(\.graphql$|\.gql$)`,
 		},
+		"(p1 or p2) -p3 file:f": {
+			Pattern:  "p1|p2",
+			IsRegExp: true,
+			PatternExpr: &search.PatternExpr{Op: search.PatternOpAnd, Operands: []*search.PatternExpr{
+				{Op: search.PatternOpOr, Operands: []*search.PatternExpr{{Pattern: "p1"}, {Pattern: "p2"}}},
+				{Not: true, Pattern: "p3"},
+			}},
+			PathPatternsAreRegExps: true,
+			IncludePatterns:        []string{"f"},
+		},
+		`p1 or "p.2"`: {
+			Pattern:  `p1|p\.2`,
+			IsRegExp: true,
+			PatternExpr: &search.PatternExpr{Op: search.PatternOpOr, Operands: []*search.PatternExpr{
+				{Pattern: "p1"},
+				{Pattern: `p\.2`},
+			}},
+			PathPatternsAreRegExps: true,
+		},
+		`defer.*\n\s*return`: {
+			Pattern:                `defer.*\n\s*return`,
+			IsRegExp:               true,
+			PathPatternsAreRegExps: true,
+		},
+		`multiline:yes defer.*\n\s*return`: {
+			Pattern:                `defer.*\n\s*return`,
+			IsRegExp:               true,
+			IsMultiline:            true,
+			PathPatternsAreRegExps: true,
+		},
+		"patterntype:structural foo(:[args]) bar": {
+			Pattern:                "foo(:[args]) bar",
+			IsStructuralPat:        true,
+			IsCaseSensitive:        true,
+			PathPatternsAreRegExps: true,
+		},
+		`patterntype:structural "f(:[a], :[b])" file:f`: {
+			Pattern:                "f(:[a], :[b])",
+			IsStructuralPat:        true,
+			IsCaseSensitive:        true,
+			PathPatternsAreRegExps: true,
+			IncludePatterns:        []string{"f"},
+		},
 	}
 	for queryStr, want := range tests {
 		t.Run(queryStr, func(t *testing.T) {
@@ -0,0 +1,191 @@
+package graphqlbackend
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/neelance/parallel"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// maxSearchRevCommits is the maximum number of distinct commits that are
+// searched in a single repository when the search specifies multiple revisions
+// or ref globs (e.g., repo:foo@*refs/heads/release-*). If the revisions
+// resolve to more commits, only the first are searched and the repository is
+// reported as partially searched.
+const maxSearchRevCommits = 50
+
+// A searchRevCommit is a commit to search and the revisions (revspecs and
+// names of refs matched by ref globs) that resolve to it.
+type searchRevCommit struct {
+	commit api.CommitID
+	revs   []string
+}
+
+// isMultiRevSearch reports whether the revisions of repoRev must be searched
+// with searchMultipleRevs, because there are multiple revisions or any ref
+// globs (which may match any number of refs).
+func isMultiRevSearch(repoRev *search.RepositoryRevisions) bool {
+	if len(repoRev.Revs) > 1 {
+		return true
+	}
+	for _, rev := range repoRev.Revs {
+		if rev.RefGlob != "" || rev.ExcludeRefGlob != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSearchRevCommits resolves the revspecs and ref globs of repoRev to the
+// distinct commits to search, in the order of the revspecs followed by the
+// matched refs (sorted by name).
+func resolveSearchRevCommits(ctx context.Context, repoRev *search.RepositoryRevisions) (commits []*searchRevCommit, limitHit bool, err error) {
+	gitserverRepo := repoRev.GitserverRepo()
+	byCommit := map[api.CommitID]*searchRevCommit{}
+	add := func(rev string, commit api.CommitID) {
+		if c, ok := byCommit[commit]; ok {
+			c.revs = append(c.revs, rev)
+			return
+		}
+		if len(commits) == maxSearchRevCommits {
+			limitHit = true
+			return
+		}
+		c := &searchRevCommit{commit: commit, revs: []string{rev}}
+		byCommit[commit] = c
+		commits = append(commits, c)
+	}
+
+	var include, exclude []string
+	for _, rev := range repoRev.Revs {
+		switch {
+		case rev.RefGlob != "":
+			include = append(include, rev.RefGlob)
+		case rev.ExcludeRefGlob != "":
+			exclude = append(exclude, rev.ExcludeRefGlob)
+		case strings.HasPrefix(rev.RevSpec, "^"):
+			// Excluding the commits that are reachable from a revision only
+			// applies to commit and diff searches.
+			continue
+		default:
+			// Do not trigger a repo-updater lookup (see searchFilesInRepo).
+			commit, err := git.ResolveRevision(ctx, gitserverRepo, nil, rev.RevSpec, &git.ResolveRevisionOptions{NoEnsureRevision: true})
+			if err != nil {
+				return nil, false, err
+			}
+			add(rev.RevSpec, commit)
+		}
+	}
+	if len(include) > 0 {
+		refs, err := git.ListRefs(ctx, gitserverRepo, include, exclude)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, ref := range refs {
+			add(ref.Name, ref.CommitID)
+		}
+	}
+	return commits, limitHit, nil
+}
+
+// searchMultipleRevs searches each distinct commit that the revisions of
+// repoRev resolve to with searchCommit, which is called with the first
+// revision that resolved to the commit. Files with the same path and contents
+// in multiple commits are returned once, labeled with the revisions of all of
+// those commits.
+func searchMultipleRevs(ctx context.Context, repoRev *search.RepositoryRevisions, searchCommit func(ctx context.Context, rev string, commit api.CommitID) ([]*fileMatchResolver, bool, error)) (matches []*fileMatchResolver, limitHit bool, err error) {
+	commits, limitHit, err := resolveSearchRevCommits(ctx, repoRev)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var (
+		results = make([][]*fileMatchResolver, len(commits))
+		run     = parallel.NewRun(8)
+		mu      sync.Mutex
+	)
+	for i, c := range commits {
+		i, c := i, c
+		run.Acquire()
+		goroutine.Go(func() {
+			defer run.Release()
+			fileMatches, commitLimitHit, searchErr := searchCommit(ctx, c.revs[0], c.commit)
+			mu.Lock()
+			defer mu.Unlock()
+			if searchErr != nil {
+				if err == nil {
+					err = searchErr
+				}
+				return
+			}
+			if commitLimitHit {
+				limitHit = true
+			}
+			results[i] = fileMatches
+		})
+	}
+	_ = run.Wait()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return mergeFileMatchesAcrossRevs(ctx, repoRev.GitserverRepo(), commits, results), limitHit, nil
+}
+
+// mergeFileMatchesAcrossRevs labels the file matches in results (the results
+// of searching each of commits) with the revisions of their commit, and merges
+// the file matches of files with the same path and blob in multiple commits.
+func mergeFileMatchesAcrossRevs(ctx context.Context, gitserverRepo gitserver.Repo, commits []*searchRevCommit, results [][]*fileMatchResolver) []*fileMatchResolver {
+	// Only files whose path matched in more than one commit can be duplicates,
+	// so only look up the blobs of those.
+	pathCounts := map[string]int{}
+	for _, fileMatches := range results {
+		for _, fm := range fileMatches {
+			pathCounts[fm.JPath]++
+		}
+	}
+	blobs := make([]map[string]string, len(results))
+	for i, fileMatches := range results {
+		var paths []string
+		for _, fm := range fileMatches {
+			if pathCounts[fm.JPath] > 1 {
+				paths = append(paths, fm.JPath)
+			}
+		}
+		if len(paths) == 0 {
+			continue
+		}
+		oids, err := git.BlobOIDs(ctx, gitserverRepo, commits[i].commit, paths)
+		if err != nil {
+			// The file matches of this commit are not merged with others.
+			log15.Warn("Unable to list blobs to merge search results across revisions.", "repo", gitserverRepo.Name, "commit", commits[i].commit, "error", err)
+			continue
+		}
+		blobs[i] = oids
+	}
+
+	var merged []*fileMatchResolver
+	byBlob := map[string]*fileMatchResolver{}
+	for i, fileMatches := range results {
+		for _, fm := range fileMatches {
+			fm.refs = append([]string(nil), commits[i].revs...)
+			if oid, ok := blobs[i][fm.JPath]; ok {
+				key := fm.JPath + "\x00" + oid
+				if first, ok := byBlob[key]; ok {
+					first.refs = append(first.refs, fm.refs...)
+					continue
+				}
+				byBlob[key] = fm
+			}
+			merged = append(merged, fm)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,101 @@
+package graphqlbackend
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+)
+
+func TestIsMultiRevSearch(t *testing.T) {
+	tests := map[string]struct {
+		revs []search.RevisionSpecifier
+		want bool
+	}{
+		"default branch": {revs: []search.RevisionSpecifier{{RevSpec: ""}}, want: false},
+		"revspec":        {revs: []search.RevisionSpecifier{{RevSpec: "v1"}}, want: false},
+		"revspecs":       {revs: []search.RevisionSpecifier{{RevSpec: "v1"}, {RevSpec: "v2"}}, want: true},
+		"ref glob":       {revs: []search.RevisionSpecifier{{RefGlob: "refs/heads/*"}}, want: true},
+	}
+	for name, test := range tests {
+		if got := isMultiRevSearch(&search.RepositoryRevisions{Revs: test.revs}); got != test.want {
+			t.Errorf("%s: got %v, want %v", name, got, test.want)
+		}
+	}
+}
+
+func TestSearchMultipleRevs(t *testing.T) {
+	git.Mocks.ResolveRevision = func(spec string, opt *git.ResolveRevisionOptions) (api.CommitID, error) {
+		if spec != "v1" {
+			t.Fatalf("unexpected revspec %q", spec)
+		}
+		return "c1", nil
+	}
+	git.Mocks.ListRefs = func(include, exclude []string) ([]git.Ref, error) {
+		if want := []string{"refs/heads/release-*"}; !reflect.DeepEqual(include, want) {
+			t.Errorf("got include %v, want %v", include, want)
+		}
+		if want := []string{"refs/heads/release-old"}; !reflect.DeepEqual(exclude, want) {
+			t.Errorf("got exclude %v, want %v", exclude, want)
+		}
+		return []git.Ref{
+			{Name: "refs/heads/release-1", CommitID: "c1"},
+			{Name: "refs/heads/release-2", CommitID: "c2"},
+			{Name: "refs/heads/release-3", CommitID: "c3"},
+		}, nil
+	}
+	blobs := map[api.CommitID]map[string]string{
+		"c1": {"a.go": "blob-a", "b.go": "blob-b1"},
+		"c2": {"a.go": "blob-a", "b.go": "blob-b2"},
+		"c3": {"c.go": "blob-c"},
+	}
+	git.Mocks.BlobOIDs = func(commit api.CommitID, paths []string) (map[string]string, error) {
+		return blobs[commit], nil
+	}
+	defer git.ResetMocks()
+
+	repoRev := &search.RepositoryRevisions{
+		Repo: &types.Repo{Name: "foo"},
+		Revs: []search.RevisionSpecifier{
+			{RevSpec: "v1"},
+			{RefGlob: "refs/heads/release-*"},
+			{ExcludeRefGlob: "refs/heads/release-old"},
+		},
+	}
+	matches, limitHit, err := searchMultipleRevs(context.Background(), repoRev, func(ctx context.Context, rev string, commit api.CommitID) ([]*fileMatchResolver, bool, error) {
+		var fileMatches []*fileMatchResolver
+		for path := range blobs[commit] {
+			fileMatches = append(fileMatches, &fileMatchResolver{JPath: path, commitID: commit, inputRev: &rev})
+		}
+		return fileMatches, false, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limitHit {
+		t.Error("unexpected limitHit")
+	}
+
+	type match struct {
+		path     string
+		commitID api.CommitID
+		inputRev string
+	}
+	got := map[match][]string{}
+	for _, fm := range matches {
+		got[match{fm.JPath, fm.commitID, *fm.inputRev}] = fm.Refs()
+	}
+	want := map[match][]string{
+		{"a.go", "c1", "v1"}:                   {"v1", "refs/heads/release-1", "refs/heads/release-2"},
+		{"b.go", "c1", "v1"}:                   {"v1", "refs/heads/release-1"},
+		{"b.go", "c2", "refs/heads/release-2"}: {"refs/heads/release-2"},
+		{"c.go", "c3", "refs/heads/release-3"}: {"refs/heads/release-3"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
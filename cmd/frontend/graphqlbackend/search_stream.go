@@ -0,0 +1,298 @@
+package graphqlbackend
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+// SearchEvent is an event emitted by StreamSearch.
+type SearchEvent struct {
+	Name string      // the event name ("matches", "progress", or "done")
+	Data interface{} // the event data, which is JSON-encodable
+}
+
+// StreamSearch runs the search query and calls send with the results and
+// progress of the search as each search backend responds, instead of waiting
+// for all of them as (*searchResolver).Results does. It sends "matches" events
+// with the results found since the previous "matches" event (see
+// toStreamMatch), "progress" events with the result and repository counters so
+// far (see streamProgress), and one final "done" event (see streamDone) once
+// the search is complete.
+//
+// Calls to send are serialized. If the search fails, the error is returned
+// and no "done" event is sent.
+func StreamSearch(ctx context.Context, q string, send func(SearchEvent)) error {
+	start := time.Now()
+	parsed, err := query.ParseAndCheck(q)
+	if err != nil {
+		return err
+	}
+	r := &searchResolver{query: parsed}
+
+	s := newSearchStream(send)
+	rr, err := r.doResults(withSearchStream(ctx, s), "")
+	if err != nil {
+		return err
+	}
+	s.done(rr, time.Since(start))
+	return nil
+}
+
+type searchStreamKey struct{}
+
+// withSearchStream returns a copy of ctx that causes searches run with it to
+// report their results to s as they arrive.
+func withSearchStream(ctx context.Context, s *searchStream) context.Context {
+	return context.WithValue(ctx, searchStreamKey{}, s)
+}
+
+// searchStreamFromContext returns the search stream that searches run with ctx
+// should report their results to, or nil if there is none. All searchStream
+// methods may be called on a nil *searchStream.
+func searchStreamFromContext(ctx context.Context) *searchStream {
+	s, _ := ctx.Value(searchStreamKey{}).(*searchStream)
+	return s
+}
+
+// searchStream sends the results and progress reported by concurrent search
+// backends as search events. Repositories are deduplicated by name, so the
+// same searchResultsCommon data may be reported more than once.
+type searchStream struct {
+	mu   sync.Mutex
+	send func(SearchEvent)
+
+	resultCount int32
+	limitHit    bool
+	searched    map[api.RepoName]struct{}
+	indexed     map[api.RepoName]struct{}
+	cloning     map[api.RepoName]struct{}
+	missing     map[api.RepoName]struct{}
+	timedout    map[api.RepoName]struct{}
+}
+
+func newSearchStream(send func(SearchEvent)) *searchStream {
+	return &searchStream{
+		send:     send,
+		searched: make(map[api.RepoName]struct{}),
+		indexed:  make(map[api.RepoName]struct{}),
+		cloning:  make(map[api.RepoName]struct{}),
+		missing:  make(map[api.RepoName]struct{}),
+		timedout: make(map[api.RepoName]struct{}),
+	}
+}
+
+// update sends the results (if any) and then the updated progress (if common
+// changed it). The result count is computed from results, not from
+// common.resultCount.
+func (s *searchStream) update(results []*searchResultResolver, common *searchResultsCommon) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(results) > 0 {
+		matches := make([]interface{}, len(results))
+		for i, result := range results {
+			matches[i] = toStreamMatch(result)
+			s.resultCount += result.resultCount()
+		}
+		s.send(SearchEvent{Name: "matches", Data: matches})
+	}
+
+	progress := streamProgress{
+		Cloning:  []string{},
+		Missing:  []string{},
+		Timedout: []string{},
+	}
+	changed := len(results) > 0
+	if common != nil {
+		if common.limitHit && !s.limitHit {
+			s.limitHit = true
+			changed = true
+		}
+		addRepos := func(set map[api.RepoName]struct{}, repos []*types.Repo, added *[]string) {
+			for _, repo := range repos {
+				if _, ok := set[repo.Name]; ok {
+					continue
+				}
+				set[repo.Name] = struct{}{}
+				changed = true
+				if added != nil {
+					*added = append(*added, string(repo.Name))
+				}
+			}
+		}
+		addRepos(s.searched, common.searched, nil)
+		addRepos(s.indexed, common.indexed, nil)
+		addRepos(s.cloning, common.cloning, &progress.Cloning)
+		addRepos(s.missing, common.missing, &progress.Missing)
+		addRepos(s.timedout, common.timedout, &progress.Timedout)
+	}
+	if !changed {
+		return
+	}
+	progress.ResultCount = s.resultCount
+	progress.LimitHit = s.limitHit
+	progress.RepositoriesSearched = len(s.searched)
+	progress.IndexedRepositoriesSearched = len(s.indexed)
+	progress.CloningCount = len(s.cloning)
+	progress.MissingCount = len(s.missing)
+	progress.TimedoutCount = len(s.timedout)
+	s.send(SearchEvent{Name: "progress", Data: progress})
+}
+
+// done sends the final event of a search whose complete results are rr.
+func (s *searchStream) done(rr *searchResultsResolver, elapsed time.Duration) {
+	if s == nil {
+		return
+	}
+	// Report anything that only the aggregated results know about (e.g.,
+	// repositories with missing revisions).
+	s.update(nil, &rr.searchResultsCommon)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	done := streamDone{
+		ResultCount:         s.resultCount,
+		LimitHit:            s.limitHit || rr.LimitHit(),
+		ElapsedMilliseconds: int32(elapsed.Nanoseconds() / int64(time.Millisecond)),
+	}
+	if rr.alert != nil {
+		done.Alert = &streamAlert{Title: rr.alert.title, Description: rr.alert.description}
+	}
+	s.send(SearchEvent{Name: "done", Data: done})
+}
+
+// streamProgress is the data of a "progress" event. The counts are totals so
+// far. Cloning, Missing, and Timedout only list the repositories that were
+// added since the previous "progress" event.
+type streamProgress struct {
+	ResultCount                 int32    `json:"resultCount"`
+	LimitHit                    bool     `json:"limitHit"`
+	RepositoriesSearched        int      `json:"repositoriesSearched"`
+	IndexedRepositoriesSearched int      `json:"indexedRepositoriesSearched"`
+	CloningCount                int      `json:"cloningCount"`
+	MissingCount                int      `json:"missingCount"`
+	TimedoutCount               int      `json:"timedoutCount"`
+	Cloning                     []string `json:"cloning"`
+	Missing                     []string `json:"missing"`
+	Timedout                    []string `json:"timedout"`
+}
+
+// streamDone is the data of a "done" event.
+type streamDone struct {
+	ResultCount         int32        `json:"resultCount"`
+	LimitHit            bool         `json:"limitHit"`
+	ElapsedMilliseconds int32        `json:"elapsedMilliseconds"`
+	Alert               *streamAlert `json:"alert,omitempty"`
+}
+
+type streamAlert struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// streamFileMatch is the JSON representation of a file match in a "matches"
+// event. A file may be reported more than once (e.g., once with symbol
+// matches and once with line matches).
+type streamFileMatch struct {
+	Type        string             `json:"type"` // "file"
+	Repository  string             `json:"repository"`
+	Commit      string             `json:"commit,omitempty"` // empty for the default branch
+	Path        string             `json:"path"`
+	LineMatches []*streamLineMatch `json:"lineMatches,omitempty"`
+	Symbols     []*streamSymbol    `json:"symbols,omitempty"`
+	LimitHit    bool               `json:"limitHit"`
+}
+
+type streamLineMatch struct {
+	Preview          string     `json:"preview"`
+	LineNumber       int32      `json:"lineNumber"`
+	OffsetAndLengths [][2]int32 `json:"offsetAndLengths"`
+}
+
+type streamSymbol struct {
+	Name          string `json:"name"`
+	ContainerName string `json:"containerName,omitempty"`
+	Kind          string `json:"kind"`
+	Line          int    `json:"line"`
+}
+
+// streamRepositoryMatch is the JSON representation of a repository match in a
+// "matches" event.
+type streamRepositoryMatch struct {
+	Type       string `json:"type"` // "repo"
+	Repository string `json:"repository"`
+}
+
+// streamCommitMatch is the JSON representation of a commit or diff match in a
+// "matches" event.
+type streamCommitMatch struct {
+	Type       string `json:"type"` // "commit"
+	Repository string `json:"repository"`
+	Commit     string `json:"commit"`
+	URL        string `json:"url"`
+	Label      string `json:"label"`
+	Detail     string `json:"detail"`
+}
+
+func toStreamMatch(result *searchResultResolver) interface{} {
+	if fm, ok := result.ToFileMatch(); ok {
+		m := &streamFileMatch{
+			Type:       "file",
+			Repository: string(fm.repo.Name),
+			Commit:     string(fm.commitID),
+			Path:       fm.JPath,
+			LimitHit:   fm.LimitHit(),
+		}
+		for _, lm := range fm.LineMatches() {
+			m.LineMatches = append(m.LineMatches, &streamLineMatch{
+				Preview:          lm.JPreview,
+				LineNumber:       lm.JLineNumber,
+				OffsetAndLengths: lm.JOffsetAndLengths,
+			})
+		}
+		for _, sym := range fm.symbols {
+			symbol := toSymbolResolver(sym.symbol, sym.baseURI, sym.lang, sym.commit)
+			s := &streamSymbol{
+				Name: symbol.Name(),
+				Kind: symbol.Kind(),
+				Line: sym.symbol.Line,
+			}
+			if containerName := symbol.ContainerName(); containerName != nil {
+				s.ContainerName = *containerName
+			}
+			m.Symbols = append(m.Symbols, s)
+		}
+		return m
+	}
+	if repo, ok := result.ToRepository(); ok {
+		return &streamRepositoryMatch{Type: "repo", Repository: string(repo.repo.Name)}
+	}
+	if c, ok := result.ToCommitSearchResult(); ok {
+		return &streamCommitMatch{
+			Type:       "commit",
+			Repository: string(c.commit.repo.repo.Name),
+			Commit:     string(c.commit.oid),
+			URL:        c.URL(),
+			Label:      c.Label().Text(),
+			Detail:     c.Detail().Text(),
+		}
+	}
+	return nil
+}
+
+// fileMatchesToSearchResults wraps file matches in search results.
+func fileMatchesToSearchResults(fileMatches []*fileMatchResolver) []*searchResultResolver {
+	results := make([]*searchResultResolver, len(fileMatches))
+	for i, fm := range fileMatches {
+		results[i] = &searchResultResolver{fileMatch: fm}
+	}
+	return results
+}
@@ -0,0 +1,70 @@
+package graphqlbackend
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+func TestSearchStream(t *testing.T) {
+	var events []SearchEvent
+	s := newSearchStream(func(e SearchEvent) { events = append(events, e) })
+
+	repoA := &types.Repo{ID: 1, Name: "a"}
+	repoB := &types.Repo{ID: 2, Name: "b"}
+	repoC := &types.Repo{ID: 3, Name: "c"}
+
+	fm := &fileMatchResolver{
+		JPath:        "main.go",
+		JLineMatches: []*lineMatch{{JPreview: "foo", JLineNumber: 4, JOffsetAndLengths: [][2]int32{{0, 3}}}},
+		repo:         repoA,
+	}
+	s.update(fileMatchesToSearchResults([]*fileMatchResolver{fm}), &searchResultsCommon{searched: []*types.Repo{repoA}})
+	// Already reported repositories must not be reported again.
+	s.update(nil, &searchResultsCommon{searched: []*types.Repo{repoA}})
+	s.update(nil, &searchResultsCommon{
+		searched: []*types.Repo{repoA, repoB},
+		cloning:  []*types.Repo{repoC},
+		limitHit: true,
+	})
+	s.done(&searchResultsResolver{
+		searchResultsCommon: searchResultsCommon{searched: []*types.Repo{repoA, repoB}, cloning: []*types.Repo{repoC}},
+	}, 0)
+
+	want := []SearchEvent{
+		{Name: "matches", Data: []interface{}{&streamFileMatch{
+			Type:        "file",
+			Repository:  "a",
+			Path:        "main.go",
+			LineMatches: []*streamLineMatch{{Preview: "foo", LineNumber: 4, OffsetAndLengths: [][2]int32{{0, 3}}}},
+		}}},
+		{Name: "progress", Data: streamProgress{
+			ResultCount:          1,
+			RepositoriesSearched: 1,
+			Cloning:              []string{},
+			Missing:              []string{},
+			Timedout:             []string{},
+		}},
+		{Name: "progress", Data: streamProgress{
+			ResultCount:          1,
+			LimitHit:             true,
+			RepositoriesSearched: 2,
+			CloningCount:         1,
+			Cloning:              []string{"c"},
+			Missing:              []string{},
+			Timedout:             []string{},
+		}},
+		{Name: "done", Data: streamDone{ResultCount: 1, LimitHit: true}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("got events %+v, want %+v", events, want)
+	}
+}
+
+func TestSearchStream_nil(t *testing.T) {
+	// Searches that are not streamed have a nil stream.
+	var s *searchStream
+	s.update(nil, &searchResultsCommon{})
+	s.done(&searchResultsResolver{}, 0)
+}
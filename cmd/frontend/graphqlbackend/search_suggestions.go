@@ -105,6 +105,10 @@ func (r *searchResolver) Suggestions(ctx context.Context, args *searchSuggestion
 		if err != nil {
 			return nil, err
 		}
+		if p.PatternExpr != nil {
+			// Symbol search doesn't evaluate boolean pattern expressions.
+			return nil, nil
+		}
 
 		ctx, cancel := context.WithTimeout(ctx, 1*time.Second)
 		defer cancel()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
 
@@ -78,14 +79,14 @@ func searchSymbols(ctx context.Context, args *search.Args, limit int) (res []*fi
 		run.Acquire()
 		goroutine.Go(func() {
 			defer run.Release()
-			repoSymbols, repoErr := searchSymbolsInRepo(ctx, repoRevs, args.Pattern, args.Query, limit)
+			repoSymbols, repoLimitHit, repoErr := searchSymbolsInRepo(ctx, repoRevs, args.Pattern, args.Query, limit)
 			if repoErr != nil {
 				tr.LogFields(otlog.String("repo", string(repoRevs.Repo.Name)), otlog.String("repoErr", repoErr.Error()), otlog.Bool("timeout", errcode.IsTimeout(repoErr)), otlog.Bool("temporary", errcode.IsTemporary(repoErr)))
 			}
 			mu.Lock()
 			defer mu.Unlock()
 			limitHit := len(res) > limit
-			repoErr = handleRepoSearchResult(common, *repoRevs, limitHit, false, repoErr)
+			repoErr = handleRepoSearchResult(common, *repoRevs, limitHit || repoLimitHit, false, repoErr)
 			if repoErr != nil {
 				if ctx.Err() == nil || errors.Cause(repoErr) != ctx.Err() {
 					// Only record error if it's not directly caused by a context error.
@@ -111,7 +112,9 @@ func searchSymbols(ctx context.Context, args *search.Args, limit int) (res []*fi
 	return res, common, err
 }
 
-func searchSymbolsInRepo(ctx context.Context, repoRevs *search.RepositoryRevisions, patternInfo *search.PatternInfo, query *query.Query, limit int) (res []*fileMatchResolver, err error) {
+// searchSymbolsInRepo searches the revisions of a single repository for symbols. limitHit is
+// true if not all of the revisions matched by a revision glob were searched.
+func searchSymbolsInRepo(ctx context.Context, repoRevs *search.RepositoryRevisions, patternInfo *search.PatternInfo, query *query.Query, limit int) (res []*fileMatchResolver, limitHit bool, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Search symbols in repo")
 	defer func() {
 		if err != nil {
@@ -122,6 +125,13 @@ func searchSymbolsInRepo(ctx context.Context, repoRevs *search.RepositoryRevisio
 	}()
 	span.SetTag("repo", string(repoRevs.Repo.Name))
 
+	if isMultiRevSearch(repoRevs) {
+		return searchMultipleRevs(ctx, repoRevs, func(ctx context.Context, rev string, commitID api.CommitID) ([]*fileMatchResolver, bool, error) {
+			fileMatches, err := searchSymbolsInCommit(ctx, repoRevs, rev, commitID, patternInfo, query, limit)
+			return fileMatches, false, err
+		})
+	}
+
 	inputRev := repoRevs.RevSpecs()[0]
 	span.SetTag("rev", inputRev)
 	// Do not trigger a repo-updater lookup (e.g.,
@@ -130,15 +140,22 @@ func searchSymbolsInRepo(ctx context.Context, repoRevs *search.RepositoryRevisio
 	// repo is not on gitserver.
 	commitID, err := git.ResolveRevision(ctx, repoRevs.GitserverRepo(), nil, inputRev, nil)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	span.SetTag("commit", string(commitID))
+	res, err = searchSymbolsInCommit(ctx, repoRevs, inputRev, commitID, patternInfo, query, limit)
+	return res, false, err
+}
+
+// searchSymbolsInCommit searches the commit that inputRev resolved to for
+// symbols.
+func searchSymbolsInCommit(ctx context.Context, repoRevs *search.RepositoryRevisions, inputRev string, commitID api.CommitID, patternInfo *search.PatternInfo, query *query.Query, limit int) (res []*fileMatchResolver, err error) {
 	baseURI, err := gituri.Parse("git://" + string(repoRevs.Repo.Name) + "?" + url.QueryEscape(inputRev))
 	if err != nil {
 		return nil, err
 	}
 
-	symbols, err := backend.Symbols.ListTags(ctx, protocol.SearchArgs{
+	searchArgs := protocol.SearchArgs{
 		Repo:            repoRevs.Repo.Name,
 		CommitID:        commitID,
 		Query:           patternInfo.Pattern,
@@ -147,7 +164,11 @@ func searchSymbolsInRepo(ctx context.Context, repoRevs *search.RepositoryRevisio
 		IncludePatterns: patternInfo.IncludePatterns,
 		ExcludePattern:  patternInfo.ExcludePattern,
 		First:           limit,
-	})
+	}
+	if query != nil {
+		addSymbolFilters(&searchArgs, query)
+	}
+	symbols, err := backend.Symbols.ListTags(ctx, searchArgs)
 	fileMatchesByURI := make(map[string]*fileMatchResolver)
 	fileMatches := make([]*fileMatchResolver, 0)
 	for _, symbol := range symbols {
@@ -185,6 +206,37 @@ func searchSymbolsInRepo(ctx context.Context, repoRevs *search.RepositoryRevisio
 	return fileMatches, err
 }
 
+// addSymbolFilters sets the fields of args that filter symbols by kind,
+// language, and container from the kind:, lang:, and container: filters in q.
+func addSymbolFilters(args *protocol.SearchArgs, q *query.Query) {
+	kinds, excludeKinds := q.StringValues(query.FieldKind)
+	for _, kind := range kinds {
+		args.Kinds = append(args.Kinds, ctagsKindsForKindFilter(kind)...)
+	}
+	for _, kind := range excludeKinds {
+		args.ExcludeKinds = append(args.ExcludeKinds, ctagsKindsForKindFilter(kind)...)
+	}
+
+	// The lang: filter values are also turned into file path patterns (see
+	// langIncludeExcludePatterns). Here, they additionally select the
+	// symbols in that language, which matters for files with extensions
+	// that are shared between languages. The names of languages in ctags
+	// sometimes differ from ours, so aliases are included.
+	langs, _ := q.StringValues(query.FieldLang)
+	for _, value := range langs {
+		if lang := lookupLanguage(value); lang != nil {
+			args.Languages = append(args.Languages, lang.Name)
+			args.Languages = append(args.Languages, lang.Aliases...)
+		}
+	}
+
+	includeContainerPatterns, excludeContainerPatterns := q.RegexpPatterns(query.FieldContainer)
+	args.IncludeContainerPatterns = includeContainerPatterns
+	if len(excludeContainerPatterns) > 0 {
+		args.ExcludeContainerPattern = unionRegExps(excludeContainerPatterns)
+	}
+}
+
 // makeFileMatchURIFromSymbol makes a git://repo?rev#path URI from a symbol
 // search result to use in a fileMatchResolver
 func makeFileMatchURIFromSymbol(symbolResult *searchSymbolResult, inputRev string) string {
@@ -218,62 +270,103 @@ func ctagsSymbolCharacter(s protocol.Symbol) int {
 	return 0
 }
 
+// ctagsKinds maps ctags kinds to LSP symbol kinds. Ctags kinds are determined by
+// the parser and do not (in general) match LSP symbol kinds.
+var ctagsKinds = map[string]lsp.SymbolKind{
+	"file":            lsp.SKFile,
+	"module":          lsp.SKModule,
+	"namespace":       lsp.SKNamespace,
+	"package":         lsp.SKPackage,
+	"packageName":     lsp.SKPackage,
+	"subprogspec":     lsp.SKPackage,
+	"class":           lsp.SKClass,
+	"type":            lsp.SKClass,
+	"service":         lsp.SKClass,
+	"typedef":         lsp.SKClass,
+	"union":           lsp.SKClass,
+	"section":         lsp.SKClass,
+	"subtype":         lsp.SKClass,
+	"component":       lsp.SKClass,
+	"method":          lsp.SKMethod,
+	"methodSpec":      lsp.SKMethod,
+	"property":        lsp.SKProperty,
+	"field":           lsp.SKField,
+	"member":          lsp.SKField,
+	"anonMember":      lsp.SKField,
+	"constructor":     lsp.SKConstructor,
+	"enum":            lsp.SKEnum,
+	"enumerator":      lsp.SKEnum,
+	"interface":       lsp.SKInterface,
+	"function":        lsp.SKFunction,
+	"func":            lsp.SKFunction,
+	"subroutine":      lsp.SKFunction,
+	"macro":           lsp.SKFunction,
+	"subprogram":      lsp.SKFunction,
+	"procedure":       lsp.SKFunction,
+	"command":         lsp.SKFunction,
+	"singletonMethod": lsp.SKFunction,
+	"variable":        lsp.SKVariable,
+	"var":             lsp.SKVariable,
+	"functionVar":     lsp.SKVariable,
+	"define":          lsp.SKVariable,
+	"alias":           lsp.SKVariable,
+	"constant":        lsp.SKConstant,
+	"const":           lsp.SKConstant,
+	"string":          lsp.SKString,
+	"message":         lsp.SKString,
+	"heredoc":         lsp.SKString,
+	"number":          lsp.SKNumber,
+	"bool":            lsp.SKBoolean,
+	"boolean":         lsp.SKBoolean,
+	"array":           lsp.SKArray,
+	"object":          lsp.SKObject,
+	"literal":         lsp.SKObject,
+	"map":             lsp.SKObject,
+	"key":             lsp.SKKey,
+	"label":           lsp.SKKey,
+	"target":          lsp.SKKey,
+	"selector":        lsp.SKKey,
+	"id":              lsp.SKKey,
+	"tag":             lsp.SKKey,
+	"null":            lsp.SKNull,
+	"enum member":     lsp.SKEnumMember,
+	"enumConstant":    lsp.SKEnumMember,
+	"struct":          lsp.SKStruct,
+	"event":           lsp.SKEvent,
+	"operator":        lsp.SKOperator,
+	"type parameter":  lsp.SKTypeParameter,
+	"annotation":      lsp.SKTypeParameter,
+}
+
 func ctagsKindToLSPSymbolKind(kind string) lsp.SymbolKind {
-	// Ctags kinds are determined by the parser and do not (in general) match LSP symbol kinds.
-	switch kind {
-	case "file":
-		return lsp.SKFile
-	case "module":
-		return lsp.SKModule
-	case "namespace":
-		return lsp.SKNamespace
-	case "package", "packageName", "subprogspec":
-		return lsp.SKPackage
-	case "class", "type", "service", "typedef", "union", "section", "subtype", "component":
-		return lsp.SKClass
-	case "method", "methodSpec":
-		return lsp.SKMethod
-	case "property":
-		return lsp.SKProperty
-	case "field", "member", "anonMember":
-		return lsp.SKField
-	case "constructor":
-		return lsp.SKConstructor
-	case "enum", "enumerator":
-		return lsp.SKEnum
-	case "interface":
-		return lsp.SKInterface
-	case "function", "func", "subroutine", "macro", "subprogram", "procedure", "command", "singletonMethod":
-		return lsp.SKFunction
-	case "variable", "var", "functionVar", "define", "alias":
-		return lsp.SKVariable
-	case "constant", "const":
-		return lsp.SKConstant
-	case "string", "message", "heredoc":
-		return lsp.SKString
-	case "number":
-		return lsp.SKNumber
-	case "bool", "boolean":
-		return lsp.SKBoolean
-	case "array":
-		return lsp.SKArray
-	case "object", "literal", "map":
-		return lsp.SKObject
-	case "key", "label", "target", "selector", "id", "tag":
-		return lsp.SKKey
-	case "null":
-		return lsp.SKNull
-	case "enum member", "enumConstant":
-		return lsp.SKEnumMember
-	case "struct":
-		return lsp.SKStruct
-	case "event":
-		return lsp.SKEvent
-	case "operator":
-		return lsp.SKOperator
-	case "type parameter", "annotation":
-		return lsp.SKTypeParameter
+	if sk, ok := ctagsKinds[kind]; ok {
+		return sk
 	}
 	log15.Debug("Unknown ctags kind", "kind", kind)
 	return 0
 }
+
+// ctagsKindsForKindFilter returns the ctags kinds of the symbols that match the
+// value of a kind: filter. These are all ctags kinds with the same LSP symbol
+// kind as the value (e.g., "func" and "subroutine" for "function"), or the value
+// itself if it isn't a known ctags kind.
+func ctagsKindsForKindFilter(value string) []string {
+	var sk lsp.SymbolKind
+	for kind, kindSK := range ctagsKinds {
+		if strings.EqualFold(kind, value) {
+			sk = kindSK
+			break
+		}
+	}
+	if sk == 0 {
+		return []string{value}
+	}
+	var kinds []string
+	for kind, kindSK := range ctagsKinds {
+		if kindSK == sk {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
@@ -1,9 +1,11 @@
 package graphqlbackend
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/pkg/gituri"
 	"github.com/sourcegraph/sourcegraph/pkg/symbols/protocol"
@@ -41,3 +43,36 @@ func TestMakeFileMatchURIFromSymbol(t *testing.T) {
 		}
 	}
 }
+
+func TestAddSymbolFilters(t *testing.T) {
+	q, err := query.ParseAndCheck(`kind:Struct -kind:func container:^Server$ -container:Test lang:go foo`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var args protocol.SearchArgs
+	addSymbolFilters(&args, q)
+
+	want := protocol.SearchArgs{
+		Kinds:                    []string{"struct"},
+		ExcludeKinds:             []string{"command", "func", "function", "macro", "procedure", "singletonMethod", "subprogram", "subroutine"},
+		Languages:                []string{"Go", "golang"},
+		IncludeContainerPatterns: []string{"^Server$"},
+		ExcludeContainerPattern:  "Test",
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("got %+v, want %+v", args, want)
+	}
+}
+
+func TestCtagsKindsForKindFilter(t *testing.T) {
+	tests := map[string][]string{
+		"interface": {"interface"},
+		"CONST":     {"const", "constant"},
+		"unknown":   {"unknown"},
+	}
+	for value, want := range tests {
+		if got := ctagsKindsForKindFilter(value); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %q, want %q", value, got, want)
+		}
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"time"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/cmd/query-runner/queryrunnerapi"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/auditlog"
+	log15 "gopkg.in/inconshreveable/log15.v2"
 )
 
 type settingsResolver struct {
@@ -58,12 +61,32 @@ func settingsCreateIfUpToDate(ctx context.Context, subject *settingsSubject, las
 		return nil, err
 	}
 
+	// Read current global settings (for the audit log).
+	var oldGlobalSettings string
+	if subject.site != nil {
+		settings, err := subject.LatestSettings(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if settings != nil {
+			oldGlobalSettings = settings.Contents()
+		}
+	}
+
 	// Update settings.
 	latestSettings, err := db.Settings.CreateIfUpToDate(ctx, subject.toSubject(), lastID, &authorUserID, contents)
 	if err != nil {
 		return nil, err
 	}
 
+	if subject.site != nil {
+		diff, err := auditlog.DiffJSONC(oldGlobalSettings, latestSettings.Contents)
+		if err != nil {
+			log15.Warn("Unable to compute audit log diff of global settings.", "error", err)
+		}
+		backend.LogAuditEvent(ctx, db.AuditActionGlobalSettingsUpdate, db.AuditTargetSettings, "global", diff)
+	}
+
 	// Read new saved queries.
 	var newSavedQueries api.PartialConfigSavedQueries
 	if err := subject.readSettings(ctx, &newSavedQueries); err != nil {
@@ -113,11 +113,12 @@ func (r *settingsCascade) Merged(ctx context.Context) (*configurationResolver, e
 // deeply merged field, the merged settings would be {"a":[2]}. If "a" IS a deeply merged field with
 // depth >= 1, then the merged settings would be {"a":[1,2].}
 var deeplyMergedSettingsFields = map[string]int{
-	"search.scopes":           1,
-	"search.savedQueries":     1,
-	"search.repositoryGroups": 1,
-	"motd":                    1,
-	"extensions":              1,
+	"search.scopes":               1,
+	"search.savedQueries":         1,
+	"search.repositoryGroups":     1,
+	"search.repositoryGroupRules": 1,
+	"motd":                        1,
+	"extensions":                  1,
 }
 
 // mergeSettings merges the specified JSON settings documents together to produce a single JSON
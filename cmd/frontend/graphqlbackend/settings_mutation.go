@@ -9,6 +9,8 @@ import (
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/jsonx"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
@@ -43,6 +45,12 @@ type settingsMutation struct {
 func (r *schemaResolver) SettingsMutation(ctx context.Context, args *struct {
 	Input *settingsMutationGroupInput
 }) (*settingsMutation, error) {
+	// 🚨 SECURITY: Access tokens must have a scope that permits editing settings. Fields of
+	// settingsMutation that edit arbitrary settings check for the settings:write scope.
+	if err := backend.CheckActorHasScope(ctx, authz.ScopeSettingsWrite, authz.ScopeSavedSearchesWrite); err != nil {
+		return nil, err
+	}
+
 	subject, err := settingsSubjectByID(ctx, args.Input.Subject)
 	if err != nil {
 		return nil, err
@@ -137,6 +145,11 @@ func (r *settingsMutation) EditConfiguration(ctx context.Context, args *struct {
 }
 
 func (r *settingsMutation) editSettings(ctx context.Context, keyPath jsonx.Path, value interface{}, remove bool) (*updateSettingsPayload, error) {
+	// 🚨 SECURITY: Only access tokens with the settings:write scope may edit arbitrary settings.
+	if err := backend.CheckActorHasScope(ctx, authz.ScopeSettingsWrite); err != nil {
+		return nil, err
+	}
+
 	_, err := r.doUpdateSettings(ctx, func(oldSettings string) (edits []jsonx.Edit, err error) {
 		if remove {
 			edits, _, err = jsonx.ComputePropertyRemoval(oldSettings, keyPath, conf.FormatOptions)
@@ -154,6 +167,11 @@ func (r *settingsMutation) editSettings(ctx context.Context, keyPath jsonx.Path,
 func (r *settingsMutation) OverwriteSettings(ctx context.Context, args *struct {
 	Contents string
 }) (*updateSettingsPayload, error) {
+	// 🚨 SECURITY: Only access tokens with the settings:write scope may overwrite settings.
+	if err := backend.CheckActorHasScope(ctx, authz.ScopeSettingsWrite); err != nil {
+		return nil, err
+	}
+
 	_, err := settingsCreateIfUpToDate(ctx, r.subject, r.input.LastID, actor.FromContext(ctx).UID, args.Contents)
 	if err != nil {
 		return nil, err
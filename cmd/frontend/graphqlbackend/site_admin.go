@@ -3,6 +3,7 @@ package graphqlbackend
 import (
 	"context"
 	"errors"
+	"strconv"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
@@ -88,5 +89,12 @@ func (*schemaResolver) SetUserIsSiteAdmin(ctx context.Context, args *struct {
 	if err := db.Users.SetIsSiteAdmin(ctx, userID, args.SiteAdmin); err != nil {
 		return nil, err
 	}
+
+	action := db.AuditActionSiteAdminRevoke
+	if args.SiteAdmin {
+		action = db.AuditActionSiteAdminGrant
+	}
+	backend.LogAuditEvent(ctx, action, db.AuditTargetUser, strconv.Itoa(int(userID)), nil)
+
 	return &EmptyResponse{}, nil
 }
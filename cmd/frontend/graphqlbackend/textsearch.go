@@ -23,6 +23,7 @@ import (
 
 	"github.com/google/zoekt"
 	zoektquery "github.com/google/zoekt/query"
+	"github.com/sourcegraph/go-langserver/pkg/lsp"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
@@ -57,14 +58,21 @@ type fileMatchResolver struct {
 	JPath        string       `json:"Path"`
 	JLineMatches []*lineMatch `json:"LineMatches"`
 	JLimitHit    bool         `json:"LimitHit"`
-	symbols      []*searchSymbolResult
-	uri          string
-	repo         *types.Repo
-	commitID     api.CommitID // or empty for default branch
+	// JMultilineMatches is only set by multiline and structural searches (see
+	// addMultilineRanges).
+	JMultilineMatches []*multilineMatch `json:"MultilineMatches"`
+	symbols           []*searchSymbolResult
+	uri               string
+	repo              *types.Repo
+	commitID          api.CommitID // or empty for default branch
 	// inputRev is the Git revspec that the user originally requested to search. It is used to
 	// preserve the original revision specifier from the user instead of navigating them to the
 	// absolute commit ID when they select a result.
 	inputRev *string
+	// refs is the revisions (revspecs and ref names) of the commits that
+	// contain the file with the same contents. It is only set when the search
+	// spans multiple revisions (see searchMultipleRevs).
+	refs []string
 }
 
 func (fm *fileMatchResolver) Key() string {
@@ -110,12 +118,28 @@ func (fm *fileMatchResolver) LimitHit() bool {
 	return fm.JLimitHit
 }
 
+func (fm *fileMatchResolver) Refs() []string {
+	refs := make([]string, len(fm.refs))
+	for i, ref := range fm.refs {
+		if ref == "" {
+			ref = "HEAD" // the default branch
+		}
+		refs[i] = ref
+	}
+	return refs
+}
+
 // LineMatch is the struct used by vscode to receive search results for a line
 type lineMatch struct {
 	JPreview          string     `json:"Preview"`
 	JOffsetAndLengths [][2]int32 `json:"OffsetAndLengths"`
 	JLineNumber       int32      `json:"LineNumber"`
 	JLimitHit         bool       `json:"LimitHit"`
+
+	// multilineRanges, if non-nil, are the ranges of the matches that start
+	// on this line, which may end on later lines. Otherwise, the ranges are
+	// those in JOffsetAndLengths.
+	multilineRanges []*rangeResolver
 }
 
 func (lm *lineMatch) Preview() string {
@@ -134,10 +158,59 @@ func (lm *lineMatch) OffsetAndLengths() [][]int32 {
 	return r
 }
 
+func (lm *lineMatch) Ranges() []*rangeResolver {
+	if lm.multilineRanges != nil {
+		return lm.multilineRanges
+	}
+	r := make([]*rangeResolver, len(lm.JOffsetAndLengths))
+	for i, ol := range lm.JOffsetAndLengths {
+		line := int(lm.JLineNumber)
+		r[i] = &rangeResolver{lsp.Range{
+			Start: lsp.Position{Line: line, Character: int(ol[0])},
+			End:   lsp.Position{Line: line, Character: int(ol[0] + ol[1])},
+		}}
+	}
+	return r
+}
+
 func (lm *lineMatch) LimitHit() bool {
 	return lm.JLimitHit
 }
 
+// multilineMatch is a match that may span multiple lines. It is the subset of
+// the searcher's protocol.MultilineMatch that is used by the frontend.
+type multilineMatch struct {
+	Start, End struct {
+		Line   int
+		Column int
+	}
+}
+
+// addMultilineRanges sets the ranges of fm's line matches from its multiline
+// matches (if any). Each multiline match becomes a range of the line match of
+// the line that it starts on.
+func (fm *fileMatchResolver) addMultilineRanges() {
+	if len(fm.JMultilineMatches) == 0 {
+		return
+	}
+	byLine := make(map[int32]*lineMatch, len(fm.JLineMatches))
+	for _, lm := range fm.JLineMatches {
+		lm.multilineRanges = []*rangeResolver{}
+		byLine[lm.JLineNumber] = lm
+	}
+	for _, mm := range fm.JMultilineMatches {
+		lm, ok := byLine[int32(mm.Start.Line)]
+		if !ok {
+			// The line match was omitted because a limit was hit.
+			continue
+		}
+		lm.multilineRanges = append(lm.multilineRanges, &rangeResolver{lsp.Range{
+			Start: lsp.Position{Line: mm.Start.Line, Character: mm.Start.Column},
+			End:   lsp.Position{Line: mm.End.Line, Character: mm.End.Column},
+		}})
+	}
+}
+
 // textSearch searches repo@commit with p.
 // Note: the returned matches do not set fileMatch.uri
 func textSearch(ctx context.Context, repo gitserver.Repo, commit api.CommitID, p *search.PatternInfo, fetchTimeout time.Duration) (matches []*fileMatchResolver, limitHit bool, err error) {
@@ -186,6 +259,19 @@ func textSearch(ctx context.Context, repo gitserver.Repo, commit api.CommitID, p
 	if p.IsCaseSensitive {
 		q.Set("IsCaseSensitive", "true")
 	}
+	if p.IsStructuralPat {
+		q.Set("IsStructuralPat", "true")
+	}
+	if p.IsMultiline {
+		q.Set("IsMultiline", "true")
+	}
+	if p.PatternExpr != nil {
+		expr, err := json.Marshal(p.PatternExpr)
+		if err != nil {
+			return nil, false, err
+		}
+		q.Set("PatternExpr", string(expr))
+	}
 	if p.PathPatternsAreRegExps {
 		q.Set("PathPatternsAreRegExps", "true")
 	}
@@ -301,6 +387,9 @@ func textSearchURL(ctx context.Context, url string) ([]*fileMatchResolver, bool,
 	if err != nil {
 		return nil, false, errors.Wrap(err, "searcher response invalid")
 	}
+	for _, fm := range r.Matches {
+		fm.addMultilineRanges()
+	}
 	if r.DeadlineHit {
 		err = context.DeadlineExceeded
 	}
@@ -340,6 +429,19 @@ func searchFilesInRepo(ctx context.Context, repo *types.Repo, gitserverRepo gits
 		return nil, false, err
 	}
 
+	return searchFilesInRepoCommit(ctx, repo, gitserverRepo, rev, commit, info, fetchTimeout)
+}
+
+// searchFilesInRepoRevs searches all of the revisions of repoRev, which may
+// include ref globs.
+func searchFilesInRepoRevs(ctx context.Context, repoRev *search.RepositoryRevisions, info *search.PatternInfo, fetchTimeout time.Duration) (matches []*fileMatchResolver, limitHit bool, err error) {
+	return searchMultipleRevs(ctx, repoRev, func(ctx context.Context, rev string, commit api.CommitID) ([]*fileMatchResolver, bool, error) {
+		return searchFilesInRepoCommit(ctx, repoRev.Repo, repoRev.GitserverRepo(), rev, commit, info, fetchTimeout)
+	})
+}
+
+// searchFilesInRepoCommit searches the commit that rev resolved to.
+func searchFilesInRepoCommit(ctx context.Context, repo *types.Repo, gitserverRepo gitserver.Repo, rev string, commit api.CommitID, info *search.PatternInfo, fetchTimeout time.Duration) (matches []*fileMatchResolver, limitHit bool, err error) {
 	matches, limitHit, err = textSearch(ctx, gitserverRepo, commit, info, fetchTimeout)
 
 	workspace := fileMatchURI(repo.Name, rev, "")
@@ -605,7 +707,13 @@ func queryToZoektQuery(query *search.PatternInfo) (zoektquery.Q, error) {
 		return parseRe(pattern, true)
 	}
 
-	if query.IsRegExp {
+	if query.PatternExpr != nil {
+		q, err := patternExprToZoektQuery(query.PatternExpr, parseRe)
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, q)
+	} else if query.IsRegExp {
 		q, err := parseRe(query.Pattern, false)
 		if err != nil {
 			return nil, err
@@ -645,15 +753,49 @@ func queryToZoektQuery(query *search.PatternInfo) (zoektquery.Q, error) {
 	return zoektquery.Simplify(zoektquery.NewAnd(and...)), nil
 }
 
+// patternExprToZoektQuery converts a boolean pattern expression to the
+// equivalent zoekt query, using parseRe to convert its patterns.
+func patternExprToZoektQuery(e *search.PatternExpr, parseRe func(pattern string, filenameOnly bool) (zoektquery.Q, error)) (zoektquery.Q, error) {
+	var q zoektquery.Q
+	if e.Op == "" {
+		var err error
+		q, err = parseRe(e.Pattern, false)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		operands := make([]zoektquery.Q, len(e.Operands))
+		for i, o := range e.Operands {
+			var err error
+			operands[i], err = patternExprToZoektQuery(o, parseRe)
+			if err != nil {
+				return nil, err
+			}
+		}
+		switch e.Op {
+		case search.PatternOpAnd:
+			q = zoektquery.NewAnd(operands...)
+		case search.PatternOpOr:
+			q = zoektquery.NewOr(operands...)
+		default:
+			return nil, fmt.Errorf("unrecognized pattern expression operator %q", e.Op)
+		}
+	}
+	if e.Not {
+		q = &zoektquery.Not{Child: q}
+	}
+	return q, nil
+}
+
 func zoektIndexedRepos(ctx context.Context, repos []*search.RepositoryRevisions) (indexed, unindexed []*search.RepositoryRevisions, err error) {
 	if !Search().Index.Enabled() {
 		return nil, repos, nil
 	}
 	for _, repoRev := range repos {
-		// We search HEAD using zoekt
+		// We search HEAD using zoekt. Other revisions and ref globs (which
+		// may match HEAD and other refs) are searched with searcher.
 		if revspecs := repoRev.RevSpecs(); len(revspecs) > 0 {
-			// TODO(sqs): search all revspecs
-			if revspecs[0] == "" {
+			if revspecs[0] == "" && !isMultiRevSearch(repoRev) {
 				indexed = append(indexed, repoRev)
 			} else {
 				unindexed = append(unindexed, repoRev)
@@ -711,6 +853,10 @@ func searchFilesInRepos(ctx context.Context, args *search.Args) (res []*fileMatc
 
 	common = &searchResultsCommon{partial: make(map[api.RepoName]struct{})}
 
+	// stream, if non-nil, receives each repository's matches as soon as its
+	// search is done (see StreamSearch).
+	stream := searchStreamFromContext(ctx)
+
 	zoektRepos, searcherRepos, err := zoektIndexedRepos(ctx, args.Repos)
 	if err != nil {
 		// Don't hard fail if index is not available yet.
@@ -759,6 +905,13 @@ func searchFilesInRepos(ctx context.Context, args *search.Args) (res []*fileMatc
 		}
 	}
 
+	if args.Pattern.IsStructuralPat || args.Pattern.IsMultiline {
+		// Indexed search does not support structural or multiline patterns.
+		tr.LazyPrintf("structural or multiline search, using searcher for %d indexed repos", len(zoektRepos))
+		searcherRepos = append(searcherRepos, zoektRepos...)
+		zoektRepos = nil
+	}
+
 	var (
 		// TODO: convert wg to an errgroup
 		wg                sync.WaitGroup
@@ -768,8 +921,18 @@ func searchFilesInRepos(ctx context.Context, args *search.Args) (res []*fileMatc
 		overLimitCanceled bool // canceled because we were over the limit
 	)
 
-	// addMatches assumes the caller holds mu.
-	addMatches := func(matches []*fileMatchResolver) {
+	// addMatches assumes the caller holds mu. It returns the matches that
+	// may still be streamed, so that no more than FileMatchLimit matches
+	// are streamed in total.
+	addMatches := func(matches []*fileMatchResolver) (streamable []*fileMatchResolver) {
+		if n := int(args.Pattern.FileMatchLimit) - flattenedSize; n < len(matches) {
+			if n < 0 {
+				n = 0
+			}
+			streamable = matches[:n:n]
+		} else {
+			streamable = matches
+		}
 		if len(matches) > 0 {
 			common.resultCount += int32(len(matches))
 			sort.Slice(matches, func(i, j int) bool {
@@ -789,6 +952,7 @@ func searchFilesInRepos(ctx context.Context, args *search.Args) (res []*fileMatc
 				cancel()
 			}
 		}
+		return streamable
 	}
 
 	var fetchTimeout time.Duration
@@ -811,21 +975,26 @@ func searchFilesInRepos(ctx context.Context, args *search.Args) (res []*fileMatc
 		if len(repoRev.Revs) == 0 {
 			continue
 		}
-		if len(repoRev.Revs) >= 2 {
-			return nil, common, errMultipleRevsNotSupported
-		}
 
 		wg.Add(1)
 		go func(repoRev search.RepositoryRevisions) {
 			defer wg.Done()
-			rev := repoRev.RevSpecs()[0] // TODO(sqs): search multiple revs
-			matches, repoLimitHit, searchErr := searchFilesInRepo(ctx, repoRev.Repo, repoRev.GitserverRepo(), rev, args.Pattern, fetchTimeout)
+			var (
+				matches      []*fileMatchResolver
+				repoLimitHit bool
+				searchErr    error
+			)
+			if isMultiRevSearch(&repoRev) {
+				matches, repoLimitHit, searchErr = searchFilesInRepoRevs(ctx, &repoRev, args.Pattern, fetchTimeout)
+			} else {
+				rev := repoRev.RevSpecs()[0]
+				matches, repoLimitHit, searchErr = searchFilesInRepo(ctx, repoRev.Repo, repoRev.GitserverRepo(), rev, args.Pattern, fetchTimeout)
+			}
 			if searchErr != nil {
 				tr.LogFields(otlog.String("repo", string(repoRev.Repo.Name)), otlog.String("searchErr", searchErr.Error()), otlog.Bool("timeout", errcode.IsTimeout(searchErr)), otlog.Bool("temporary", errcode.IsTemporary(searchErr)))
 				log15.Warn("searchFilesInRepo failed", "error", searchErr, "repo", repoRev.Repo.Name)
 			}
 			mu.Lock()
-			defer mu.Unlock()
 			if ctx.Err() == nil {
 				common.searched = append(common.searched, repoRev.Repo)
 			}
@@ -841,13 +1010,26 @@ func searchFilesInRepos(ctx context.Context, args *search.Args) (res []*fileMatc
 					// handle this here, not in handleRepoSearchResult, because different callers of
 					// handleRepoSearchResult (for different result types) currently all need to
 					// handle cancellations differently.
+					mu.Unlock()
 					return
 				}
 				err = errors.Wrapf(searchErr, "failed to search %s", repoRev.String())
 				tr.LazyPrintf("cancel due to error: %v", err)
 				cancel()
 			}
-			addMatches(matches)
+			streamable := addMatches(matches)
+			mu.Unlock()
+
+			// Stream outside of mu, so that a slow client doesn't block the
+			// other searches.
+			if stream != nil {
+				repoCommon := searchResultsCommon{limitHit: len(streamable) < len(matches)}
+				if ctx.Err() == nil {
+					repoCommon.searched = []*types.Repo{repoRev.Repo}
+				}
+				_ = handleRepoSearchResult(&repoCommon, repoRev, repoLimitHit, false, searchErr)
+				stream.update(fileMatchesToSearchResults(streamable), &repoCommon)
+			}
 		}(*repoRev)
 	}
 
@@ -860,7 +1042,6 @@ func searchFilesInRepos(ctx context.Context, args *search.Args) (res []*fileMatc
 		opts := zoektSearchOpts(k, query)
 		matches, limitHit, reposLimitHit, searchErr := zoektSearchHEAD(ctx, query, zoektRepos, args.UseFullDeadline, Search().Index.Client, opts, time.Since)
 		mu.Lock()
-		defer mu.Unlock()
 		if ctx.Err() == nil {
 			for _, repo := range zoektRepos {
 				common.searched = append(common.searched, repo.Repo)
@@ -883,7 +1064,15 @@ func searchFilesInRepos(ctx context.Context, args *search.Args) (res []*fileMatc
 			tr.LazyPrintf("cancel indexed search due to error: %v", err)
 			cancel()
 		}
-		addMatches(matches)
+		streamable := addMatches(matches)
+		indexed := append([]*types.Repo(nil), common.indexed...)
+		mu.Unlock()
+
+		stream.update(fileMatchesToSearchResults(streamable), &searchResultsCommon{
+			limitHit: limitHit || len(streamable) < len(matches),
+			searched: indexed,
+			indexed:  indexed,
+		})
 	}()
 
 	wg.Wait()
@@ -91,6 +91,22 @@ func TestQueryToZoektQuery(t *testing.T) {
 			},
 			Query: `foo case:yes f:\.go$ f:\.yaml$ -f:\bvendor\b`,
 		},
+		{
+			Name: "boolean",
+			Pattern: &search.PatternInfo{
+				IsRegExp:        true,
+				IsCaseSensitive: false,
+				Pattern:         "foo|bar",
+				PatternExpr: &search.PatternExpr{Op: search.PatternOpAnd, Operands: []*search.PatternExpr{
+					{Op: search.PatternOpOr, Operands: []*search.PatternExpr{{Pattern: "foo"}, {Pattern: "bar"}}},
+					{Not: true, Pattern: "baz"},
+				}},
+				IncludePatterns:              []string{`\.go$`},
+				PathPatternsAreRegExps:       true,
+				PathPatternsAreCaseSensitive: false,
+			},
+			Query: `(foo or bar) -baz case:no f:\.go$`,
+		},
 	}
 	for _, tt := range cases {
 		t.Run(tt.Name, func(t *testing.T) {
@@ -206,6 +222,41 @@ func TestSearchFilesInRepos(t *testing.T) {
 	}
 }
 
+func TestSearchFilesInRepos_streamLimit(t *testing.T) {
+	mockSearchFilesInRepo = func(ctx context.Context, repo *types.Repo, gitserverRepo gitserver.Repo, rev string, info *search.PatternInfo, fetchTimeout time.Duration) (matches []*fileMatchResolver, limitHit bool, err error) {
+		return []*fileMatchResolver{
+			{uri: "git://" + string(repo.Name) + "?" + rev + "#" + "a.go"},
+			{uri: "git://" + string(repo.Name) + "?" + rev + "#" + "b.go"},
+		}, false, nil
+	}
+	defer func() { mockSearchFilesInRepo = nil }()
+
+	q, err := query.ParseAndCheck("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := &search.Args{
+		Pattern: &search.PatternInfo{
+			FileMatchLimit: 3,
+			Pattern:        "foo",
+		},
+		Repos: makeRepositoryRevisions("foo/one", "foo/two", "foo/three"),
+		Query: q,
+	}
+	var streamed int // calls to send are serialized
+	s := newSearchStream(func(e SearchEvent) {
+		if e.Name == "matches" {
+			streamed += len(e.Data.([]interface{}))
+		}
+	})
+	if _, _, err := searchFilesInRepos(withSearchStream(context.Background(), s), args); err != nil {
+		t.Fatal(err)
+	}
+	if streamed != 3 {
+		t.Errorf("got %d streamed matches, want 3 (the FileMatchLimit)", streamed)
+	}
+}
+
 func makeRepositoryRevisions(repos ...string) []*search.RepositoryRevisions {
 	r := make([]*search.RepositoryRevisions, len(repos))
 	for i, repospec := range repos {
@@ -354,3 +405,36 @@ func Test_zoektSearchHEAD(t *testing.T) {
 		})
 	}
 }
+
+func TestFileMatchResolver_addMultilineRanges(t *testing.T) {
+	fm := &fileMatchResolver{
+		JLineMatches: []*lineMatch{
+			{JPreview: "defer f()", JLineNumber: 3, JOffsetAndLengths: [][2]int32{{0, 9}}},
+			{JPreview: "return", JLineNumber: 4, JOffsetAndLengths: [][2]int32{{0, 6}}},
+		},
+		JMultilineMatches: []*multilineMatch{{}},
+	}
+	fm.JMultilineMatches[0].Start.Line, fm.JMultilineMatches[0].Start.Column = 3, 0
+	fm.JMultilineMatches[0].End.Line, fm.JMultilineMatches[0].End.Column = 4, 6
+	fm.addMultilineRanges()
+
+	ranges := func(lm *lineMatch) [][4]int32 {
+		var r [][4]int32
+		for _, rr := range lm.Ranges() {
+			r = append(r, [4]int32{rr.Start().Line(), rr.Start().Character(), rr.End().Line(), rr.End().Character()})
+		}
+		return r
+	}
+	if got, want := ranges(fm.JLineMatches[0]), [][4]int32{{3, 0, 4, 6}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got ranges %v, want %v", got, want)
+	}
+	if got := ranges(fm.JLineMatches[1]); got != nil {
+		t.Errorf("got ranges %v for a line that no match starts on, want none", got)
+	}
+
+	// Without multiline matches, the ranges are the offsets and lengths.
+	lm := &lineMatch{JLineNumber: 2, JOffsetAndLengths: [][2]int32{{1, 2}, {5, 1}}}
+	if got, want := ranges(lm), [][4]int32{{2, 1, 2, 3}, {2, 5, 2, 6}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got ranges %v, want %v", got, want)
+	}
+}
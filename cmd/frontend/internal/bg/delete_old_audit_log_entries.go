@@ -0,0 +1,22 @@
+package bg
+
+import (
+	"context"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"gopkg.in/inconshreveable/log15.v2"
+)
+
+// DeleteOldAuditLogEntries periodically deletes the audit log entries that are older than the
+// retention period (configured by the "auditLog.retentionDays" site configuration property). It
+// never returns.
+func DeleteOldAuditLogEntries(ctx context.Context) {
+	for {
+		if err := db.AuditLog.DeleteCreatedBefore(ctx, time.Now().Add(-conf.AuditLogRetention())); err != nil {
+			log15.Error("Unable to delete old audit log entries.", "error", err)
+		}
+		time.Sleep(time.Hour)
+	}
+}
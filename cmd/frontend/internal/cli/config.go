@@ -11,9 +11,11 @@ import (
 	"os/user"
 
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/auditlog"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/conf/conftypes"
 	"github.com/sourcegraph/sourcegraph/pkg/db/confdb"
@@ -149,9 +151,27 @@ func (c configurationSource) Write(ctx context.Context, input conftypes.RawUnifi
 	if err != nil {
 		return errors.Wrap(err, "confdb.SiteCreateIfUpToDate")
 	}
+
+	logConfigurationAuditEvent(ctx, "critical", critical.Contents, input.Critical)
+	logConfigurationAuditEvent(ctx, "site", site.Contents, input.Site)
 	return nil
 }
 
+// logConfigurationAuditEvent records a change to the critical or site configuration (which is
+// identified by targetID) in the audit log, if its contents changed.
+func logConfigurationAuditEvent(ctx context.Context, targetID, before, after string) {
+	if before == after {
+		return
+	}
+	diff, err := auditlog.DiffJSONC(before, after)
+	if err != nil {
+		// The configuration has already been validated, so this should never happen. Record the
+		// change without the diff.
+		log15.Warn("Unable to compute audit log diff of configuration.", "configuration", targetID, "error", err)
+	}
+	backend.LogAuditEvent(ctx, db.AuditActionSiteConfigUpdate, db.AuditTargetSiteConfig, targetID, diff)
+}
+
 func postgresDSN() string {
 	username := ""
 	if user, err := user.Current(); err == nil {
@@ -22,6 +22,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/session"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/requestclient"
 	tracepkg "github.com/sourcegraph/sourcegraph/pkg/trace"
 	"github.com/sourcegraph/sourcegraph/pkg/version"
 )
@@ -40,7 +41,7 @@ func newExternalHTTPHandler(ctx context.Context) (http.Handler, error) {
 	// X-Requested-With header). Doing so would open it up to CSRF attacks.
 	apiHandler = session.CookieMiddlewareWithCSRFSafety(apiHandler, corsAllowHeader, isTrustedOrigin) // API accepts cookies with special header
 	apiHandler = httpapi.AccessTokenAuthMiddleware(apiHandler)                                        // API accepts access tokens
-	apiHandler = gziphandler.GzipHandler(apiHandler)
+	apiHandler = gzipAPIHandler(apiHandler)
 
 	// App handler (HTML pages).
 	appHandler := app.NewHandler()
@@ -67,6 +68,7 @@ func newExternalHTTPHandler(ctx context.Context) (http.Handler, error) {
 		h = hooks.PreAuthMiddleware(h)
 	}
 	h = tracepkg.Middleware(h)
+	h = requestclient.HTTPMiddleware(h)
 	h = middleware.SourcegraphComGoGetHandler(h)
 	h = middleware.BlackHole(h)
 	h = secureHeadersMiddleware(h)
@@ -87,6 +89,25 @@ func healthCheckMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// searchStreamPath is the path of the search streaming API endpoint (see
+// httpapi/router.SearchStream).
+const searchStreamPath = "/.api/search/stream"
+
+// gzipAPIHandler compresses the responses of the HTTP API handler, except for
+// the search streaming endpoint. The gzip handler buffers output (and ignores
+// flushes) until it has enough data to decide whether to compress, which
+// would hold back the stream's Server-Sent Events.
+func gzipAPIHandler(h http.Handler) http.Handler {
+	gz := gziphandler.GzipHandler(h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == searchStreamPath {
+			h.ServeHTTP(w, r)
+			return
+		}
+		gz.ServeHTTP(w, r)
+	})
+}
+
 // newInternalHTTPHandler creates and returns the HTTP handler for the internal API (accessible to
 // other internal services).
 func newInternalHTTPHandler() http.Handler {
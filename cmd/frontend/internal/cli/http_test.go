@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGzipAPIHandler_searchStream(t *testing.T) {
+	done := make(chan struct{})
+	h := gzipAPIHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "event: progress\ndata: {}\n\n")
+		w.(http.Flusher).Flush()
+		<-done // the search has not finished yet
+	}))
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	defer close(done) // lets the handler return before the server is closed
+
+	req, err := http.NewRequest("GET", ts.URL+searchStreamPath+"?q=x", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Encoding"); got != "" {
+		t.Errorf("got Content-Encoding %q, want none", got)
+	}
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "event: progress\n"; line != want {
+		t.Errorf("got first line %q, want %q", line, want)
+	}
+}
+
+func TestGzipAPIHandler_compresses(t *testing.T) {
+	h := gzipAPIHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("x", 2000))
+	}))
+	req := httptest.NewRequest("GET", "/.api/graphql", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got, want := rec.Header().Get("Content-Encoding"), "gzip"; got != want {
+		t.Errorf("got Content-Encoding %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	"github.com/sourcegraph/sourcegraph/pkg/repoupdater"
+	"github.com/sourcegraph/sourcegraph/pkg/repoupdater/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/routevar"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// serveGitInfoRefs and serveGitUploadPack serve repositories over the
+// read-only git smart HTTP protocol, by proxying the requests to gitserver. This
+// lets clients (such as CI jobs) clone and fetch from Sourcegraph's mirror of a
+// repository instead of its code host:
+//
+//	git clone https://sourcegraph.example.com/.api/git/github.com/foo/bar
+func serveGitInfoRefs(w http.ResponseWriter, r *http.Request) error {
+	return serveGitHTTP(w, r, "/info/refs", true)
+}
+
+func serveGitUploadPack(w http.ResponseWriter, r *http.Request) error {
+	return serveGitHTTP(w, r, "/git-upload-pack", false)
+}
+
+func serveGitHTTP(w http.ResponseWriter, r *http.Request, suffix string, checkCloned bool) error {
+	// 🚨 SECURITY: getGitHTTPRepo only returns the repository if the actor is
+	// permitted to access it.
+	repo, err := getGitHTTPRepo(r.Context(), routevar.ToRepo(mux.Vars(r)))
+	if err != nil {
+		if errcode.IsNotFound(err) && !actor.FromContext(r.Context()).IsAuthenticated() {
+			// Let git prompt for credentials, because the repository might be
+			// accessible to an authenticated user.
+			w.Header().Set("WWW-Authenticate", `Basic realm="Sourcegraph"`)
+			return &errcode.HTTPErr{Status: http.StatusUnauthorized, Err: errors.New("authentication required")}
+		}
+		return err
+	}
+
+	if checkCloned {
+		cloned, err := gitserver.DefaultClient.IsRepoCloned(r.Context(), repo.Name)
+		if err != nil {
+			return err
+		}
+		if !cloned {
+			if err := enqueueGitHTTPClone(r.Context(), repo); err != nil {
+				log15.Warn("Failed to enqueue clone of repository requested over git HTTP.", "repo", repo.Name, "error", err)
+			}
+			w.Header().Set("Retry-After", "60")
+			http.Error(w, "The repository is being cloned. Try again later.", http.StatusServiceUnavailable)
+			return nil
+		}
+	}
+
+	// The handler wrapper sets a JSON content type, but the response of
+	// gitserver has its own.
+	w.Header().Del("Content-Type")
+	gitserver.DefaultClient.ProxyGitHTTP(w, r, repo.Name, suffix)
+	return nil
+}
+
+// getGitHTTPRepo returns the repository with the given name. Git clients
+// commonly append ".git" to the URL of a repository, so that suffix is also
+// accepted.
+func getGitHTTPRepo(ctx context.Context, name api.RepoName) (*types.Repo, error) {
+	repo, err := backend.Repos.GetByName(ctx, name)
+	if errcode.IsNotFound(err) && strings.HasSuffix(string(name), ".git") {
+		repo, err = backend.Repos.GetByName(ctx, api.RepoName(strings.TrimSuffix(string(name), ".git")))
+	}
+	return repo, err
+}
+
+// enqueueGitHTTPClone asks repo-updater to clone the repository (see
+// serveRepoRefresh).
+func enqueueGitHTTPClone(ctx context.Context, repo *types.Repo) error {
+	repoMeta, err := repoupdater.DefaultClient.RepoLookup(ctx, protocol.RepoLookupArgs{
+		Repo:         repo.Name,
+		ExternalRepo: repo.ExternalRepo,
+	})
+	if err != nil {
+		return err
+	}
+	if repoMeta.Repo == nil {
+		return errors.New("repository not found by repo-updater")
+	}
+	_, err = repoupdater.DefaultClient.EnqueueRepoUpdate(ctx, gitserver.Repo{
+		Name: repo.Name,
+		URL:  repoMeta.Repo.VCS.URL,
+	})
+	return err
+}
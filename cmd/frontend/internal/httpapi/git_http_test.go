@@ -0,0 +1,56 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+func TestServeGitHTTP_authRequired(t *testing.T) {
+	c := newTest()
+
+	backend.Mocks.Repos.GetByName = func(ctx context.Context, name api.RepoName) (*types.Repo, error) {
+		return nil, &errcode.Mock{Message: "repo not found", IsNotFound: true}
+	}
+	defer func() { backend.Mocks.Repos.GetByName = nil }()
+
+	resp, err := c.Get("/git/github.com/gorilla/mux/info/refs?service=git-upload-pack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+	if got, want := resp.Header.Get("WWW-Authenticate"), `Basic realm="Sourcegraph"`; got != want {
+		t.Errorf("got WWW-Authenticate %q, want %q", got, want)
+	}
+}
+
+func TestGetGitHTTPRepo(t *testing.T) {
+	backend.Mocks.Repos.GetByName = func(ctx context.Context, name api.RepoName) (*types.Repo, error) {
+		if name == "github.com/gorilla/mux" {
+			return &types.Repo{ID: 2, Name: name}, nil
+		}
+		return nil, &errcode.Mock{Message: "repo not found", IsNotFound: true}
+	}
+	defer func() { backend.Mocks.Repos.GetByName = nil }()
+
+	for _, name := range []api.RepoName{"github.com/gorilla/mux", "github.com/gorilla/mux.git"} {
+		repo, err := getGitHTTPRepo(context.Background(), name)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if repo.ID != 2 {
+			t.Errorf("%s: got repo %d, want 2", name, repo.ID)
+		}
+	}
+	if _, err := getGitHTTPRepo(context.Background(), "github.com/gorilla/context.git"); err == nil {
+		t.Error("expected error for nonexistent repo")
+	}
+}
@@ -1,12 +1,16 @@
 package httpapi
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/graph-gophers/graphql-go/relay"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
 )
 
 var relayHandler = &relay.Handler{Schema: graphqlbackend.GraphQLSchema}
@@ -18,6 +22,27 @@ func serveGraphQL(w http.ResponseWriter, r *http.Request) (err error) {
 		return errors.New("method must be POST")
 	}
 
+	// 🚨 SECURITY: Actors authenticated with a scope-restricted access token may only perform the
+	// mutations that their scopes permit.
+	if actor.FromContext(r.Context()).Scopes != nil {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		var params struct {
+			Query string `json:"query"`
+		}
+		if err := json.Unmarshal(body, &params); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil
+		}
+		if err := graphqlbackend.CheckAccessTokenScopes(r.Context(), params.Query); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return nil
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
 	relayHandler.ServeHTTP(w, r)
 	return nil
 }
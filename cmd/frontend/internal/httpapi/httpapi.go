@@ -10,6 +10,8 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/schema"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/app/pkg/updatecheck"
 	apirouter "github.com/sourcegraph/sourcegraph/cmd/frontend/internal/httpapi/router"
@@ -33,9 +35,9 @@ func NewHandler(m *mux.Router) http.Handler {
 	m.StrictSlash(true)
 
 	// Set handlers for the installed routes.
-	m.Get(apirouter.RepoShield).Handler(trace.TraceRoute(handler(serveRepoShield)))
+	m.Get(apirouter.RepoShield).Handler(trace.TraceRoute(handler(requireScope(serveRepoShield, readScopes...))))
 
-	m.Get(apirouter.RepoRefresh).Handler(trace.TraceRoute(handler(serveRepoRefresh)))
+	m.Get(apirouter.RepoRefresh).Handler(trace.TraceRoute(handler(requireScope(serveRepoRefresh, authz.ScopeUserAll))))
 
 	m.Get(apirouter.Telemetry).Handler(trace.TraceRoute(telemetryHandler))
 
@@ -45,7 +47,16 @@ func NewHandler(m *mux.Router) http.Handler {
 
 	m.Get(apirouter.GraphQL).Handler(trace.TraceRoute(handler(serveGraphQL)))
 
-	m.Get(apirouter.Registry).Handler(trace.TraceRoute(handler(registry.HandleRegistry)))
+	m.Get(apirouter.SearchStream).Handler(trace.TraceRoute(handler(requireScope(serveSearchStream, readScopes...))))
+
+	m.Get(apirouter.SearchExport).Handler(trace.TraceRoute(handler(requireScope(serveSearchExport, readScopes...))))
+
+	m.Get(apirouter.Webhooks).Handler(trace.TraceRoute(handler(serveWebhook)))
+
+	m.Get(apirouter.GitInfoRefs).Handler(trace.TraceRoute(handler(requireScope(serveGitInfoRefs, readScopes...))))
+	m.Get(apirouter.GitUploadPack).Handler(trace.TraceRoute(handler(requireScope(serveGitUploadPack, readScopes...))))
+
+	m.Get(apirouter.Registry).Handler(trace.TraceRoute(handler(requireScope(registry.HandleRegistry, readScopes...))))
 
 	m.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("API no route: %s %s from %s", r.Method, r.URL, r.Referer())
@@ -80,6 +91,11 @@ func NewInternalHandler(m *mux.Router) http.Handler {
 	m.Get(apirouter.SavedQueriesGetInfo).Handler(trace.TraceRoute(handler(serveSavedQueriesGetInfo)))
 	m.Get(apirouter.SavedQueriesSetInfo).Handler(trace.TraceRoute(handler(serveSavedQueriesSetInfo)))
 	m.Get(apirouter.SavedQueriesDeleteInfo).Handler(trace.TraceRoute(handler(serveSavedQueriesDeleteInfo)))
+	m.Get(apirouter.SavedQueriesGetLastRun).Handler(trace.TraceRoute(handler(serveSavedQueriesGetLastRun)))
+	m.Get(apirouter.SavedQueriesAddRun).Handler(trace.TraceRoute(handler(serveSavedQueriesAddRun)))
+	m.Get(apirouter.SavedQueriesAddWebhookDelivery).Handler(trace.TraceRoute(handler(serveSavedQueriesAddWebhookDelivery)))
+	m.Get(apirouter.SavedQueriesGetWebhookSecret).Handler(trace.TraceRoute(handler(serveSavedQueriesGetWebhookSecret)))
+	m.Get(apirouter.SavedQueriesFilterMatches).Handler(trace.TraceRoute(handler(serveSavedQueriesFilterMatches)))
 	m.Get(apirouter.OrgsListUsers).Handler(trace.TraceRoute(handler(serveOrgsListUsers)))
 	m.Get(apirouter.OrgsGetByName).Handler(trace.TraceRoute(handler(serveOrgsGetByName)))
 	m.Get(apirouter.UsersGetByUsername).Handler(trace.TraceRoute(handler(serveUsersGetByUsername)))
@@ -115,6 +131,27 @@ func handler(h func(http.ResponseWriter, *http.Request) error) http.Handler {
 	}
 }
 
+// readScopes are the access token scopes that permit read-only API requests. All access tokens
+// that grant access to the user's account may read (as with GraphQL queries).
+var readScopes = authz.UserScopes
+
+// requireScope wraps an API handler so that actors authenticated with a scope-restricted access
+// token may only call it if the token has one of the given scopes. Handlers that modify state must
+// require a scope that permits the modification (or authz.ScopeUserAll).
+//
+// 🚨 SECURITY: All handlers served to actors that may have been authenticated with an access token
+// must be wrapped in requireScope (except serveGraphQL, which checks the scopes of each mutation,
+// and serveWebhook, which does not act on behalf of the actor).
+func requireScope(h func(http.ResponseWriter, *http.Request) error, scopes ...string) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if err := backend.CheckActorHasScope(r.Context(), scopes...); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return nil
+		}
+		return h(w, r)
+	}
+}
+
 var schemaDecoder = schema.NewDecoder()
 
 func init() {
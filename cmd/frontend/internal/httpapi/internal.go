@@ -13,8 +13,10 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
 	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
 	"github.com/sourcegraph/sourcegraph/pkg/jsonc"
 	"github.com/sourcegraph/sourcegraph/pkg/txemail"
@@ -310,6 +312,97 @@ func serveSavedQueriesDeleteInfo(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
 
+func serveSavedQueriesGetLastRun(w http.ResponseWriter, r *http.Request) error {
+	var query string
+	err := json.NewDecoder(r.Body).Decode(&query)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	run, err := db.QueryRunnerState.GetLastRun(r.Context(), query)
+	if err != nil {
+		return errors.Wrap(err, "QueryRunnerState.GetLastRun")
+	}
+	if err := json.NewEncoder(w).Encode(run); err != nil {
+		return errors.Wrap(err, "Encode")
+	}
+	return nil
+}
+
+func serveSavedQueriesAddRun(w http.ResponseWriter, r *http.Request) error {
+	var run *api.SavedQueryRun
+	err := json.NewDecoder(r.Body).Decode(&run)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	if err := db.QueryRunnerState.AddRun(r.Context(), run); err != nil {
+		return errors.Wrap(err, "QueryRunnerState.AddRun")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+	return nil
+}
+
+func serveSavedQueriesAddWebhookDelivery(w http.ResponseWriter, r *http.Request) error {
+	var delivery *api.SavedQueryWebhookDelivery
+	err := json.NewDecoder(r.Body).Decode(&delivery)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	if err := db.SavedQueryWebhookDeliveries.Create(r.Context(), delivery); err != nil {
+		return errors.Wrap(err, "SavedQueryWebhookDeliveries.Create")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+	return nil
+}
+
+func serveSavedQueriesGetWebhookSecret(w http.ResponseWriter, r *http.Request) error {
+	var spec api.SavedQueryIDSpec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+	secret, err := db.SavedQueryWebhookSecrets.Get(r.Context(), spec)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueryWebhookSecrets.Get")
+	}
+	if err := json.NewEncoder(w).Encode(secret); err != nil {
+		return errors.Wrap(err, "Encode")
+	}
+	return nil
+}
+
+func serveSavedQueriesFilterMatches(w http.ResponseWriter, r *http.Request) error {
+	var args api.SavedQueriesFilterMatchesRequest
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+
+	// 🚨 SECURITY: Look up the repositories as the user (instead of the
+	// internal actor of this request), so that only those that the user can
+	// access are found.
+	ctx := actor.WithActor(r.Context(), actor.FromUser(args.UserID))
+	canAccess := map[api.RepoName]bool{}
+	filtered := make([]api.SavedQueryMatch, 0, len(args.Matches))
+	for _, m := range args.Matches {
+		ok, checked := canAccess[m.Repository]
+		if !checked {
+			_, err := db.Repos.GetByName(ctx, m.Repository)
+			if err != nil && !errcode.IsNotFound(err) {
+				return errors.Wrap(err, "Repos.GetByName")
+			}
+			ok = err == nil
+			canAccess[m.Repository] = ok
+		}
+		if ok {
+			filtered = append(filtered, m)
+		}
+	}
+	if err := json.NewEncoder(w).Encode(filtered); err != nil {
+		return errors.Wrap(err, "Encode")
+	}
+	return nil
+}
+
 func serveSettingsGetForSubject(w http.ResponseWriter, r *http.Request) error {
 	var subject api.SettingsSubject
 	if err := json.NewDecoder(r.Body).Decode(&subject); err != nil {
@@ -11,14 +11,27 @@ const (
 
 	Registry = "registry"
 
-	RepoShield  = "repo.shield"
-	RepoRefresh = "repo.refresh"
-	Telemetry   = "telemetry"
+	RepoShield   = "repo.shield"
+	RepoRefresh  = "repo.refresh"
+	Telemetry    = "telemetry"
+	SearchStream = "search.stream"
+	SearchExport = "search.export"
+	Webhooks     = "webhooks"
+
+	GitInfoRefs   = "git.info-refs"
+	GitUploadPack = "git.upload-pack"
 
 	SavedQueriesListAll    = "internal.saved-queries.list-all"
 	SavedQueriesGetInfo    = "internal.saved-queries.get-info"
 	SavedQueriesSetInfo    = "internal.saved-queries.set-info"
 	SavedQueriesDeleteInfo = "internal.saved-queries.delete-info"
+	SavedQueriesGetLastRun = "internal.saved-queries.get-last-run"
+	SavedQueriesAddRun     = "internal.saved-queries.add-run"
+
+	SavedQueriesAddWebhookDelivery = "internal.saved-queries.add-webhook-delivery"
+	SavedQueriesGetWebhookSecret   = "internal.saved-queries.get-webhook-secret"
+	SavedQueriesFilterMatches      = "internal.saved-queries.filter-matches"
+
 	SettingsGetForSubject  = "internal.settings.get-for-subject"
 	OrgsListUsers          = "internal.orgs.list-users"
 	OrgsGetByName          = "internal.orgs.get-by-name"
@@ -58,6 +71,16 @@ func New(base *mux.Router) *mux.Router {
 	addGraphQLRoute(base)
 	addTelemetryRoute(base)
 
+	base.Path("/search/stream").Methods("GET").Name(SearchStream)
+	base.Path("/search/exports/{ID:[0-9]+}").Methods("GET").Name(SearchExport)
+	base.Path("/webhooks/{Kind}").Methods("POST").Name(Webhooks)
+
+	// Git smart HTTP (read-only), so that repositories can be cloned from
+	// https://sourcegraph.example.com/.api/git/github.com/foo/bar.
+	gitPath := `/git/` + routevar.Repo
+	base.Path(gitPath + "/info/refs").Methods("GET").Name(GitInfoRefs)
+	base.Path(gitPath + "/git-upload-pack").Methods("POST").Name(GitUploadPack)
+
 	// repo contains routes that are NOT specific to a revision. In these routes, the URL may not contain a revspec after the repo (that is, no "github.com/foo/bar@myrevspec").
 	repoPath := `/repos/` + routevar.Repo
 
@@ -82,6 +105,11 @@ func NewInternal(base *mux.Router) *mux.Router {
 	base.Path("/saved-queries/get-info").Methods("POST").Name(SavedQueriesGetInfo)
 	base.Path("/saved-queries/set-info").Methods("POST").Name(SavedQueriesSetInfo)
 	base.Path("/saved-queries/delete-info").Methods("POST").Name(SavedQueriesDeleteInfo)
+	base.Path("/saved-queries/get-last-run").Methods("POST").Name(SavedQueriesGetLastRun)
+	base.Path("/saved-queries/add-run").Methods("POST").Name(SavedQueriesAddRun)
+	base.Path("/saved-queries/add-webhook-delivery").Methods("POST").Name(SavedQueriesAddWebhookDelivery)
+	base.Path("/saved-queries/get-webhook-secret").Methods("POST").Name(SavedQueriesGetWebhookSecret)
+	base.Path("/saved-queries/filter-matches").Methods("POST").Name(SavedQueriesFilterMatches)
 	base.Path("/settings/get-for-subject").Methods("POST").Name(SettingsGetForSubject)
 	base.Path("/orgs/list-users").Methods("POST").Name(OrgsListUsers)
 	base.Path("/orgs/get-by-name").Methods("POST").Name(OrgsGetByName)
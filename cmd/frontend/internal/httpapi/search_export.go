@@ -0,0 +1,43 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+)
+
+// serveSearchExport serves the contents of a completed search export (see
+// the GraphQL createSearchExport mutation) as a file download.
+func serveSearchExport(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.ParseInt(mux.Vars(r)["ID"], 10, 64)
+	if err != nil {
+		return &errcode.HTTPErr{Status: http.StatusBadRequest, Err: err}
+	}
+	export, err := db.SearchExports.GetByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	// 🚨 SECURITY: Only site admins and the user who created the search export
+	// may download it.
+	if err := backend.CheckSiteAdminOrSameUser(r.Context(), export.UserID); err != nil {
+		return err
+	}
+	data, err := db.SearchExports.GetData(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	contentType, ext := "text/csv; charset=utf-8", "csv"
+	if export.Format == db.SearchExportFormatJSONL {
+		contentType, ext = "application/x-ndjson", "jsonl"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="search-export-%d.%s"`, id, ext))
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,54 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// serveSearchStream runs the search query given by the "q" URL query
+// parameter and streams its results and progress to the client as
+// Server-Sent Events (see graphqlbackend.StreamSearch). If the search fails,
+// an "error" event with the error message is sent instead of a "done" event.
+func serveSearchStream(w http.ResponseWriter, r *http.Request) error {
+	q := r.URL.Query().Get("q")
+	if q == "" {
+		return &errcode.HTTPErr{Status: http.StatusBadRequest, Err: errors.New("missing search query (q)")}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return errors.New("streaming is not supported by the response writer")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(event graphqlbackend.SearchEvent) {
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			log15.Error("search stream: failed to marshal event", "event", event.Name, "error", err)
+			return
+		}
+		// Write errors mean the client went away, which cancels the request
+		// context and thus the search, so they can be ignored.
+		_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Name, data)
+		flusher.Flush()
+	}
+
+	if err := graphqlbackend.StreamSearch(r.Context(), q, send); err != nil {
+		send(graphqlbackend.SearchEvent{
+			Name: "error",
+			Data: struct {
+				Message string `json:"message"`
+			}{Message: err.Error()},
+		})
+	}
+	return nil
+}
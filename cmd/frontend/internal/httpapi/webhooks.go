@@ -0,0 +1,35 @@
+package httpapi
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sourcegraph/sourcegraph/pkg/repoupdater"
+)
+
+// maxWebhookPayloadSize is the maximum size of webhook payloads that are
+// forwarded to repo-updater.
+const maxWebhookPayloadSize = 25 * 1024 * 1024
+
+// serveWebhook forwards webhook requests sent by code hosts (e.g. for pushes)
+// to repo-updater, which validates them with the webhook secrets of the
+// external services and updates the affected repositories.
+//
+// 🚨 SECURITY: This handler is accessible to anonymous users (see
+// auth.AllowAnonymousRequest), so it MUST NOT act on requests itself.
+func serveWebhook(w http.ResponseWriter, r *http.Request) error {
+	payload, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookPayloadSize))
+	if err != nil {
+		return err
+	}
+
+	status, body, err := repoupdater.DefaultClient.Webhook(r.Context(), mux.Vars(r)["Kind"], r.Header, payload)
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(status)
+	_, err = w.Write(body)
+	return err
+}
@@ -0,0 +1,135 @@
+// Package replacer is a client for the replacer service, which rewrites the
+// code in a repository at a commit.
+package replacer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/opentracing-contrib/go-stdlib/nethttp"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/endpoint"
+	"github.com/sourcegraph/sourcegraph/pkg/env"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+var replacerURL = env.Get("REPLACER_URL", "k8s+http://replacer:3185", "replacer server URL")
+
+var (
+	replacerURLsOnce sync.Once
+	replacerURLs     *endpoint.Map
+)
+
+// maxDiffLineSize is the maximum size of a line of the replacer's output (the
+// diff of a single file).
+const maxDiffLineSize = 10 * 1024 * 1024
+
+// fileDiff is a line of the replacer's JSON lines output.
+type fileDiff struct {
+	URI  string `json:"uri"`  // the path of the rewritten file
+	Diff string `json:"diff"` // the unified diff of the rewrites in the file
+}
+
+// Diff runs the rewrite specified by req on the repository at the commit and
+// returns the unified diff (in a form that can be applied with `git apply`) of
+// the rewritten files. If no file was rewritten, the diff is empty.
+func Diff(ctx context.Context, req protocol.Request) (string, error) {
+	replacerURLsOnce.Do(func() {
+		if len(strings.Fields(replacerURL)) == 0 {
+			replacerURLs = endpoint.Empty(errors.New("a replacer service has not been configured"))
+		} else {
+			replacerURLs = endpoint.New(replacerURL)
+		}
+	})
+
+	// Replacer caches the archive of repo@commit, so use consistent hashing
+	// to increase cache hits.
+	u, err := replacerURLs.Get(string(req.Repo)+"@"+string(req.Commit), nil)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"Repo":            []string{string(req.Repo)},
+		"URL":             []string{req.URL},
+		"Commit":          []string{string(req.Commit)},
+		"FetchTimeout":    []string{req.FetchTimeout},
+		"MatchTemplate":   []string{req.MatchTemplate},
+		"RewriteTemplate": []string{req.RewriteTemplate},
+		"FileExtension":   []string{req.FileExtension},
+	}
+	hreq, err := http.NewRequest("POST", u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	hreq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	hreq = hreq.WithContext(ctx)
+
+	hreq, ht := nethttp.TraceRequest(opentracing.GlobalTracer(), hreq,
+		nethttp.OperationName("Replacer Client"),
+		nethttp.ClientTrace(false))
+	defer ht.Finish()
+
+	resp, err := ctxhttp.Do(hreq.Context(), nil, hreq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// best-effort inclusion of body in error message
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 200))
+		return "", errors.Errorf("replacer: http status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return readDiffs(resp.Body)
+}
+
+// readDiffs reads the replacer's JSON lines output and returns the combined
+// diff of all files.
+func readDiffs(r io.Reader) (string, error) {
+	var patch strings.Builder
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxDiffLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var d fileDiff
+		if err := json.Unmarshal(line, &d); err != nil {
+			return "", errors.Wrap(err, "invalid replacer output")
+		}
+		if d.URI == "" || d.Diff == "" {
+			continue
+		}
+		patch.WriteString(gitDiff(d.URI, d.Diff))
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return patch.String(), nil
+}
+
+// gitDiff returns the unified diff of the file at path in the form produced
+// by `git diff` (with a/ and b/ path prefixes). The replacer's diff headers
+// name the file in other ways, so they are replaced.
+func gitDiff(path, diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for len(lines) > 0 && !strings.HasPrefix(lines[0], "@@") {
+		lines = lines[1:] // skip the --- and +++ headers
+	}
+	s := "diff --git a/" + path + " b/" + path + "\n--- a/" + path + "\n+++ b/" + path + "\n" + strings.Join(lines, "")
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	return s
+}
@@ -0,0 +1,41 @@
+package replacer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadDiffs(t *testing.T) {
+	output := `{"uri":"main.go","diff":"--- main.go\n+++ main.go\n@@ -1,3 +1,3 @@\n package main\n-var x = foo()\n+var x = bar()\n"}
+
+{"uri":"README.md","diff":""}
+{"uri":"dir/a.go","diff":"@@ -1 +1 @@\n-foo()\n\\ No newline at end of file\n+bar()\n\\ No newline at end of file"}
+`
+	got, err := readDiffs(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ package main
+-var x = foo()
++var x = bar()
+diff --git a/dir/a.go b/dir/a.go
+--- a/dir/a.go
++++ b/dir/a.go
+@@ -1 +1 @@
+-foo()
+\ No newline at end of file
++bar()
+\ No newline at end of file
+`
+	if got != want {
+		t.Errorf("got diff\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := readDiffs(strings.NewReader("not json\n")); err == nil {
+		t.Error("expected error for invalid output")
+	}
+}
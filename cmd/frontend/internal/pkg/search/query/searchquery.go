@@ -3,6 +3,8 @@
 package query
 
 import (
+	"sort"
+
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query/syntax"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query/types"
 )
@@ -11,6 +13,7 @@ import (
 const (
 	FieldDefault   = ""
 	FieldCase      = "case"
+	FieldMultiline = "multiline"
 	FieldRepo      = "repo"
 	FieldRepoGroup = "repogroup"
 	FieldFile      = "file"
@@ -18,6 +21,11 @@ const (
 	FieldArchived  = "archived"
 	FieldLang      = "lang"
 	FieldType      = "type"
+	FieldSort      = "sort"
+
+	// For symbol search only:
+	FieldKind      = "kind"
+	FieldContainer = "container"
 
 	// For diff and commit search only:
 	FieldBefore    = "before"
@@ -27,10 +35,11 @@ const (
 	FieldMessage   = "message"
 
 	// Temporary experimental fields:
-	FieldIndex   = "index"
-	FieldCount   = "count" // Searches that specify `count:` will fetch at least that number of results, or the full result set
-	FieldMax     = "max"   // Deprecated alias for count
-	FieldTimeout = "timeout"
+	FieldIndex       = "index"
+	FieldCount       = "count" // Searches that specify `count:` will fetch at least that number of results, or the full result set
+	FieldMax         = "max"   // Deprecated alias for count
+	FieldTimeout     = "timeout"
+	FieldPatternType = "patterntype"
 )
 
 var (
@@ -39,8 +48,9 @@ var (
 
 	conf = types.Config{
 		FieldTypes: map[string]types.FieldType{
-			FieldDefault:   {Literal: types.RegexpType, Quoted: types.StringType},
+			FieldDefault:   {Literal: types.RegexpType, Quoted: types.StringType, Negatable: true},
 			FieldCase:      {Literal: types.BoolType, Quoted: types.BoolType, Singular: true},
+			FieldMultiline: {Literal: types.BoolType, Quoted: types.BoolType, Singular: true},
 			FieldRepo:      regexpNegatableFieldType,
 			FieldRepoGroup: {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldFile:      regexpNegatableFieldType,
@@ -48,6 +58,10 @@ var (
 			FieldArchived:  {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldLang:      {Literal: types.StringType, Quoted: types.StringType, Negatable: true},
 			FieldType:      stringFieldType,
+			FieldSort:      {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+
+			FieldKind:      {Literal: types.StringType, Quoted: types.StringType, Negatable: true},
+			FieldContainer: regexpNegatableFieldType,
 
 			FieldBefore:    stringFieldType,
 			FieldAfter:     stringFieldType,
@@ -60,6 +74,8 @@ var (
 			FieldCount:   {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldMax:     {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldTimeout: {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+
+			FieldPatternType: {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 		},
 		FieldAliases: map[string]string{
 			"r":        FieldRepo,
@@ -88,6 +104,51 @@ func ParseAndCheck(input string) (*Query, error) {
 	return parseAndCheck(&conf, input)
 }
 
+// Check typechecks the query expressions using the default query type
+// configuration. It is used to build a query from the expressions of another
+// query (e.g., with more fields appended) without formatting and reparsing
+// them.
+func Check(expr []*syntax.Expr) (*Query, error) {
+	checkedQuery, err := conf.Check(&syntax.Query{Input: syntax.ExprString(expr), Expr: expr})
+	if err != nil {
+		return nil, err
+	}
+	return &Query{conf: &conf, Query: checkedQuery}, nil
+}
+
+// WithFields returns the query with an expression for each of the given fields
+// and values added. A field that may only be used once (such as count:)
+// replaces any existing expressions for it (or its aliases, or max: for
+// count:); other fields (such as repo:) are ANDed with the existing ones.
+func WithFields(q *Query, fields map[string]string) (*Query, error) {
+	replaced := func(field string) bool {
+		if resolved, ok := q.conf.FieldAliases[field]; ok {
+			field = resolved
+		}
+		if field == FieldMax {
+			field = FieldCount
+		}
+		_, ok := fields[field]
+		return ok && q.conf.FieldTypes[field].Singular
+	}
+	expr := make([]*syntax.Expr, 0, len(q.Syntax.Expr)+len(fields))
+	for _, e := range q.Syntax.Expr {
+		if e.Field != "" && replaced(e.Field) {
+			continue
+		}
+		expr = append(expr, e)
+	}
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+	for _, field := range names {
+		expr = append(expr, &syntax.Expr{Field: field, Value: fields[field], ValueType: syntax.TokenLiteral})
+	}
+	return Check(expr)
+}
+
 func parseAndCheck(conf *types.Config, input string) (*Query, error) {
 	syntaxQuery, err := syntax.Parse(input)
 	if err != nil {
@@ -118,6 +179,13 @@ func (q *Query) IsCaseSensitive() bool {
 	return q.BoolValue(FieldCase)
 }
 
+// IsMultiline reports whether the query's regexp patterns are matched against
+// whole file contents (so that they may match across lines), instead of
+// against each line.
+func (q *Query) IsMultiline() bool {
+	return q.BoolValue(FieldMultiline)
+}
+
 // Values returns the values for the given field.
 func (q *Query) Values(field string) []*types.Value {
 	if _, ok := q.conf.FieldTypes[field]; !ok {
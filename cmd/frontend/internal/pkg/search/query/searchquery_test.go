@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query/syntax"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/search/query/types"
 )
 
@@ -142,3 +143,54 @@ func checkPanic(t *testing.T, msg string, f func()) {
 	}()
 	f()
 }
+
+func TestCheck(t *testing.T) {
+	q, err := ParseAndCheck("foo or bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expr := append(append([]*syntax.Expr{}, q.Syntax.Expr...), &syntax.Expr{Field: FieldCount, Value: "5", ValueType: syntax.TokenLiteral})
+	q2, err := Check(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q2.Syntax.Input, "(foo or bar) count:5"; got != want {
+		t.Errorf("got input %q, want %q", got, want)
+	}
+	if got, _ := q2.StringValue(FieldCount); got != "5" {
+		t.Errorf("got count %q, want %q", got, "5")
+	}
+	if q2.Pattern == nil {
+		t.Error("got nil pattern, want the or expression")
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	tests := map[string]struct {
+		query  string
+		fields map[string]string
+		want   string
+	}{
+		"add":             {query: "foo", fields: map[string]string{FieldCount: "5"}, want: "foo count:5"},
+		"replace":         {query: "count:1 foo timeout:2s", fields: map[string]string{FieldCount: "5"}, want: "foo timeout:2s count:5"},
+		"replace max":     {query: "foo max:1", fields: map[string]string{FieldCount: "5"}, want: "foo count:5"},
+		"and":             {query: "r:a foo", fields: map[string]string{FieldRepo: "^b$"}, want: "r:a foo repo:^b$"},
+		"multiple fields": {query: "foo timeout:1s", fields: map[string]string{FieldTimeout: "1m", FieldCount: "5"}, want: "foo count:5 timeout:1m"},
+		"or pattern":      {query: "foo or bar", fields: map[string]string{FieldCount: "5"}, want: "(foo or bar) count:5"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, err := ParseAndCheck(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q2, err := WithFields(q, test.fields)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := q2.Syntax.Input; got != test.want {
+				t.Errorf("got input %q, want %q", got, test.want)
+			}
+		})
+	}
+}
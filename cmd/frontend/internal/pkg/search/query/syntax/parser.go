@@ -1,6 +1,9 @@
 package syntax
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // ParseError describes an error in query parsing.
 type ParseError struct {
@@ -27,11 +30,18 @@ type context struct {
 //
 // BNF-ish query syntax:
 //
-//   exprList  := {exprSign} | exprSign (sep exprSign)*
-//   exprSign  := {"-"} expr
+//   exprList  := {orExpr} | orExpr
+//   orExpr    := andList ("or" andList)*
+//   andList   := exprSign ({sep} ["and" sep] exprSign)*
+//   exprSign  := {"-" | "not" sep} (group | expr)
+//   group     := "(" orExpr ")"
 //   expr      := fieldExpr | lit | quoted | pattern
 //   fieldExpr := lit ":" value
 //   value     := lit | quoted
+//
+// The operator keywords "and", "or", and "not" are matched case-insensitively.
+// They are only treated as operators when they have operands; otherwise they
+// are literals (so that the query "or" still searches for "or").
 func Parse(input string) (*Query, error) {
 	tokens := Scan(input)
 	p := parser{tokens: tokens}
@@ -68,56 +78,183 @@ func (p *parser) next() Token {
 	return Token{Type: TokenEOF}
 }
 
-// exprList := {exprSign} | exprSign (sep exprSign)*
+// exprList := {orExpr} | orExpr
 func (p *parser) parseExprList(ctx context) (exprList []*Expr, err error) {
-	if p.peek().Type == TokenEOF {
+	if p.skipSep().Type == TokenEOF {
 		return nil, nil
 	}
 
+	lists, err := p.parseOrLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.skipSep(); tok.Type != TokenEOF {
+		return nil, &ParseError{Pos: tok.Pos, Msg: fmt.Sprintf("got %s, want expr", tok.Type)}
+	}
+	if len(lists) == 1 {
+		// The common case: no "or" operator at the top level, so the top
+		// level is an (implicitly ANDed) list of expressions.
+		return lists[0], nil
+	}
+	return []*Expr{newCompound(OpOr, lists)}, nil
+}
+
+// orExpr := andList ("or" andList)*
+//
+// The operands of the "or" are returned as separate lists.
+func (p *parser) parseOrLists(ctx context) (lists [][]*Expr, err error) {
 	for {
-		tok := p.peek()
-		if tok.Type == TokenEOF {
-			break
+		list, err := p.parseAndList(ctx)
+		if err != nil {
+			return nil, err
 		}
-		if tok.Type == TokenSep {
-			p.next()
-			continue
+		lists = append(lists, list)
+		if !p.acceptOperator("or") {
+			return lists, nil
 		}
+	}
+}
 
+// andList := exprSign ({sep} ["and" sep] exprSign)*
+func (p *parser) parseAndList(ctx context) (list []*Expr, err error) {
+	for {
 		expr, err := p.parseExprSign(ctx)
 		if err != nil {
 			return nil, err
 		}
-		exprList = append(exprList, expr)
-	}
+		list = append(list, expr)
 
-	return exprList, nil
+		p.acceptOperator("and")
+		switch tok := p.skipSep(); {
+		case tok.Type == TokenEOF, tok.Type == TokenRParen, p.isOperator("or"):
+			return list, nil
+		}
+	}
 }
 
-// exprSign := {"-"} expr
+// exprSign := {"-" | "not" sep} (group | expr)
 func (p *parser) parseExprSign(ctx context) (*Expr, error) {
-	tok := p.next()
-	switch tok.Type {
-	case TokenMinus:
-		// consume token
-	default:
-		tok = Token{Type: TokenEOF}
-		p.backup()
+	tok := p.skipSep()
+	var not bool
+	switch {
+	case tok.Type == TokenMinus:
+		p.next()
+		not = true
+	case p.isOperator("not"):
+		p.next()
+		p.skipSep()
+		not = true
 	}
 
-	expr, err := p.parseExpr(ctx)
+	var (
+		expr *Expr
+		err  error
+	)
+	if p.peek().Type == TokenLParen {
+		expr, err = p.parseGroup(ctx)
+	} else {
+		expr, err = p.parseExpr(ctx)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	switch tok.Type {
-	case TokenMinus:
-		expr.Not = true
+	if not {
+		expr.Not = !expr.Not
 	}
 
 	return expr, nil
 }
 
+// group := "(" orExpr ")"
+func (p *parser) parseGroup(ctx context) (*Expr, error) {
+	lparen := p.next()
+	if tok := p.skipSep(); tok.Type == TokenRParen {
+		return nil, &ParseError{Pos: tok.Pos, Msg: fmt.Sprintf("got %s, want expr", tok.Type)}
+	}
+	lists, err := p.parseOrLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.next(); tok.Type != TokenRParen {
+		return nil, &ParseError{Pos: tok.Pos, Msg: fmt.Sprintf("got %s, want %s", tok.Type, TokenRParen)}
+	}
+	var expr *Expr
+	if len(lists) == 1 {
+		expr = newAnd(lists[0])
+	} else {
+		expr = newCompound(OpOr, lists)
+	}
+	if expr.IsCompound() {
+		expr.Pos = lparen.Pos
+	}
+	return expr, nil
+}
+
+// newAnd returns the single expression in list, or else a compound expression
+// that ANDs the expressions in list.
+func newAnd(list []*Expr) *Expr {
+	if len(list) == 1 {
+		return list[0]
+	}
+	return &Expr{Pos: list[0].Pos, Op: OpAnd, Operands: list}
+}
+
+// newCompound returns a compound expression whose operands are formed from
+// the given lists of expressions.
+func newCompound(op Operator, lists [][]*Expr) *Expr {
+	operands := make([]*Expr, len(lists))
+	for i, list := range lists {
+		operands[i] = newAnd(list)
+	}
+	return &Expr{Pos: operands[0].Pos, Op: op, Operands: operands}
+}
+
+// skipSep consumes any separator tokens and returns the next token without
+// consuming it.
+func (p *parser) skipSep() Token {
+	for p.peek().Type == TokenSep {
+		p.next()
+	}
+	return p.peek()
+}
+
+// isOperator reports whether the next token is the given operator keyword.
+// The keyword is only considered to be an operator if it is followed by a
+// separator and another expression.
+func (p *parser) isOperator(keyword string) bool {
+	tok := p.peek()
+	if tok.Type != TokenLiteral || !strings.EqualFold(tok.Value, keyword) {
+		return false
+	}
+	if p.pos+1 >= len(p.tokens) || p.tokens[p.pos+1].Type != TokenSep {
+		return false
+	}
+	for _, next := range p.tokens[p.pos+1:] {
+		switch next.Type {
+		case TokenSep:
+			continue
+		case TokenEOF, TokenRParen:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// acceptOperator consumes the given operator keyword (and any separators
+// surrounding it) if it is next in the token stream. It reports whether the
+// operator was consumed.
+func (p *parser) acceptOperator(keyword string) bool {
+	p.skipSep()
+	if !p.isOperator(keyword) {
+		return false
+	}
+	p.next()
+	p.skipSep()
+	return true
+}
+
 // expr := exprField | lit | quoted | pattern
 func (p *parser) parseExpr(ctx context) (*Expr, error) {
 	tok := p.next()
@@ -129,29 +266,43 @@ func (p *parser) parseExpr(ctx context) (*Expr, error) {
 			valueTok := p.next()
 			switch valueTok.Type {
 			case TokenLiteral, TokenQuoted:
-				if tok3 := p.next(); tok3.Type != TokenSep && tok3.Type != TokenEOF {
+				if tok3 := p.next(); !p.endExpr(tok3) {
 					return nil, &ParseError{Pos: tok3.Pos, Msg: fmt.Sprintf("got %s, want separator or EOF", tok3.Type)}
 				}
 				return &Expr{Pos: tok.Pos, Field: tok.Value, Value: valueTok.Value, ValueType: valueTok.Type}, nil
-			case TokenSep, TokenEOF:
+			case TokenSep, TokenEOF, TokenRParen:
+				p.endExpr(valueTok)
 				return &Expr{Pos: tok.Pos, Field: tok.Value, Value: "", ValueType: TokenLiteral}, nil
 			default:
 				return nil, &ParseError{Pos: valueTok.Pos, Msg: fmt.Sprintf("got %s, want value", valueTok.Type)}
 			}
-		case TokenSep, TokenEOF:
+		case TokenSep, TokenEOF, TokenRParen:
+			p.endExpr(tok2)
 			return &Expr{Pos: tok.Pos, Value: tok.Value, ValueType: tok.Type}, nil
 		default:
 			panic("unreachable")
 		}
 	case TokenQuoted, TokenPattern:
 		tok2 := p.next()
-		switch tok2.Type {
-		case TokenSep, TokenEOF:
+		if p.endExpr(tok2) {
 			return &Expr{Pos: tok.Pos, Value: tok.Value, ValueType: tok.Type}, nil
-		default:
-			return nil, &ParseError{Pos: tok2.Pos, Msg: fmt.Sprintf("got %s, want separator or EOF", tok2.Type)}
 		}
+		return nil, &ParseError{Pos: tok2.Pos, Msg: fmt.Sprintf("got %s, want separator or EOF", tok2.Type)}
 	}
 
 	return nil, &ParseError{Pos: tok.Pos, Msg: fmt.Sprintf("got %s, want expr", tok.Type)}
 }
+
+// endExpr reports whether tok (which has just been consumed) ends an
+// expression. A closing parenthesis is put back so that the enclosing group
+// can consume it.
+func (p *parser) endExpr(tok Token) bool {
+	switch tok.Type {
+	case TokenSep, TokenEOF:
+		return true
+	case TokenRParen:
+		p.backup()
+		return true
+	}
+	return false
+}
@@ -67,6 +67,104 @@ func TestParser(t *testing.T) {
 				{Field: "b", Value: "", ValueType: TokenLiteral},
 			},
 		},
+		"a or b": {
+			wantExpr: []*Expr{
+				{Op: OpOr, Operands: []*Expr{
+					{Value: "a", ValueType: TokenLiteral},
+					{Value: "b", ValueType: TokenLiteral},
+				}},
+			},
+			wantString: "(a or b)",
+		},
+		"a AND b": {
+			wantExpr: []*Expr{
+				{Value: "a", ValueType: TokenLiteral},
+				{Value: "b", ValueType: TokenLiteral},
+			},
+			wantString: "a b",
+		},
+		"a b or c": {
+			wantExpr: []*Expr{
+				{Op: OpOr, Operands: []*Expr{
+					{Op: OpAnd, Operands: []*Expr{
+						{Value: "a", ValueType: TokenLiteral},
+						{Value: "b", ValueType: TokenLiteral},
+					}},
+					{Value: "c", ValueType: TokenLiteral},
+				}},
+			},
+			wantString: "((a b) or c)",
+		},
+		"(a or b) -c": {
+			wantExpr: []*Expr{
+				{Op: OpOr, Operands: []*Expr{
+					{Value: "a", ValueType: TokenLiteral},
+					{Value: "b", ValueType: TokenLiteral},
+				}},
+				{Not: true, Value: "c", ValueType: TokenLiteral},
+			},
+		},
+		`f:\.go$ (Open OR Create)`: {
+			wantExpr: []*Expr{
+				{Field: "f", Value: `\.go$`, ValueType: TokenLiteral},
+				{Op: OpOr, Operands: []*Expr{
+					{Value: "Open", ValueType: TokenLiteral},
+					{Value: "Create", ValueType: TokenLiteral},
+				}},
+			},
+			wantString: `f:\.go$ (Open or Create)`,
+		},
+		"not a b": {
+			wantExpr: []*Expr{
+				{Not: true, Value: "a", ValueType: TokenLiteral},
+				{Value: "b", ValueType: TokenLiteral},
+			},
+			wantString: "-a b",
+		},
+		"not (a b)": {
+			wantExpr: []*Expr{
+				{Not: true, Op: OpAnd, Operands: []*Expr{
+					{Value: "a", ValueType: TokenLiteral},
+					{Value: "b", ValueType: TokenLiteral},
+				}},
+			},
+			wantString: "-(a b)",
+		},
+		`("a b" /c d/)`: {
+			wantExpr: []*Expr{
+				{Op: OpAnd, Operands: []*Expr{
+					{Value: `"a b"`, ValueType: TokenQuoted},
+					{Value: "c d", ValueType: TokenPattern},
+				}},
+			},
+		},
+		"(a f(x)) or b": {
+			wantExpr: []*Expr{
+				{Op: OpOr, Operands: []*Expr{
+					{Op: OpAnd, Operands: []*Expr{
+						{Value: "a", ValueType: TokenLiteral},
+						{Value: "f(x)", ValueType: TokenLiteral},
+					}},
+					{Value: "b", ValueType: TokenLiteral},
+				}},
+			},
+			wantString: "((a f(x)) or b)",
+		},
+		"(a|b)c": {
+			wantExpr: []*Expr{{Value: "(a|b)c", ValueType: TokenLiteral}},
+		},
+		"or": {
+			wantExpr: []*Expr{{Value: "or", ValueType: TokenLiteral}},
+		},
+		"a or": {
+			wantExpr: []*Expr{
+				{Value: "a", ValueType: TokenLiteral},
+				{Value: "or", ValueType: TokenLiteral},
+			},
+		},
+		"( )": {
+			wantErr: &ParseError{Pos: 2, Msg: "got TokenRParen, want expr"},
+		},
 		"--": {
 			wantErr: &ParseError{Pos: 1, Msg: "got TokenMinus, want expr"},
 		},
@@ -97,7 +195,7 @@ func TestParser(t *testing.T) {
 				query.Expr = []*Expr{}
 			}
 			for _, expr := range query.Expr {
-				expr.Pos = 0
+				expr.Walk(func(e *Expr) { e.Pos = 0 })
 			}
 			if !reflect.DeepEqual(query.Expr, test.wantExpr) {
 				t.Errorf("expr: %s\ngot  %v\nwant %v", input, query.Expr, test.wantExpr)
@@ -108,6 +206,21 @@ func TestParser(t *testing.T) {
 			if exprString := ExprString(query.Expr); exprString != test.wantString {
 				t.Errorf("expr string: %s\ngot  %s\nwant %s", input, exprString, test.wantString)
 			}
+
+			// The expr string must parse to the same expressions.
+			reparsed, err := Parse(ExprString(query.Expr))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, expr := range reparsed.Expr {
+				expr.Walk(func(e *Expr) { e.Pos = 0 })
+			}
+			if len(reparsed.Expr) == 0 {
+				reparsed.Expr = []*Expr{}
+			}
+			if !reflect.DeepEqual(reparsed.Expr, query.Expr) {
+				t.Errorf("reparsed expr: %s\ngot  %v\nwant %v", input, reparsed.Expr, query.Expr)
+			}
 		})
 	}
 }
@@ -8,16 +8,48 @@ import (
 // A Query contains the parse tree of a query.
 type Query struct {
 	Input string  // the original input query string
-	Expr  []*Expr // expressions in this query
+	Expr  []*Expr // expressions in this query (implicitly ANDed together)
 }
 
+// Operator is a boolean operator that combines the operands of a compound
+// expression.
+type Operator int
+
+// All Operator values.
+const (
+	OpNone Operator = iota // not a compound expression
+	OpAnd                  // all operands must match ("a and b", "(a b)")
+	OpOr                   // at least one operand must match ("a or b")
+)
+
 // An Expr describes an expression in a query.
 type Expr struct {
 	Pos       int       // the starting character position of the query expression
-	Not       bool      // the expression is negated (e.g., -term or -field:term)
+	Not       bool      // the expression is negated (e.g., -term, -field:term, not term, or -(a or b))
 	Field     string    // the field that this expression applies to
 	Value     string    // the raw field value
 	ValueType TokenType // the type of the value
+
+	// Op and Operands are set for compound expressions, which are formed by
+	// the "and" and "or" operators and by parenthesized groups. Compound
+	// expressions have no Field, Value, or ValueType.
+	Op       Operator
+	Operands []*Expr
+}
+
+// IsCompound reports whether e combines other expressions with a boolean
+// operator.
+func (e Expr) IsCompound() bool {
+	return e.Op != OpNone
+}
+
+// Walk calls f for e and for each expression nested in e, in depth-first
+// order.
+func (e *Expr) Walk(f func(*Expr)) {
+	f(e)
+	for _, o := range e.Operands {
+		o.Walk(f)
+	}
 }
 
 func (e Expr) String() string {
@@ -25,6 +57,12 @@ func (e Expr) String() string {
 	if e.Not {
 		buf.WriteByte('-')
 	}
+	if e.IsCompound() {
+		buf.WriteByte('(')
+		buf.WriteString(e.operandsString())
+		buf.WriteByte(')')
+		return buf.String()
+	}
 	if e.Field != "" {
 		buf.WriteString(e.Field)
 		buf.WriteByte(':')
@@ -39,7 +77,21 @@ func (e Expr) String() string {
 	return buf.String()
 }
 
-// ExprString returns the query string that parses to expr.
+func (e Expr) operandsString() string {
+	sep := " "
+	if e.Op == OpOr {
+		sep = " or "
+	}
+	s := make([]string, len(e.Operands))
+	for i, o := range e.Operands {
+		s[i] = o.String()
+	}
+	return strings.Join(s, sep)
+}
+
+// ExprString returns the query string that parses to expr. Compound
+// expressions are always parenthesized, so other expressions may be appended
+// to the result without changing its meaning.
 func ExprString(expr []*Expr) string {
 	s := make([]string, len(expr))
 	for i, e := range expr {
@@ -21,7 +21,9 @@ const (
 	TokenPattern
 	TokenColon
 	TokenMinus
-	TokenSep // separator (like a semicolon)
+	TokenSep    // separator (like a semicolon)
+	TokenLParen // opening parenthesis of a group
+	TokenRParen // closing parenthesis of a group
 )
 
 var singleCharTokens = map[rune]TokenType{
@@ -54,6 +56,7 @@ type scanner struct {
 	pos     int
 	prevPos int
 	start   int
+	depth   int // number of currently open groups
 }
 
 func (s *scanner) next() rune {
@@ -111,6 +114,18 @@ func scanDefault(s *scanner) stateFn {
 			s.emit(typ)
 			return scanDefault
 		}
+		if r == '(' && isGroup(s.input[s.pos+1:]) {
+			s.next()
+			s.emit(TokenLParen)
+			s.depth++
+			return scanDefault
+		}
+		if r == ')' && s.depth > 0 && endsTerm(s.input[s.pos+1:]) {
+			s.next()
+			s.emit(TokenRParen)
+			s.depth--
+			return scanDefault
+		}
 
 		if r == '"' || r == '\'' {
 			return scanQuoted
@@ -146,6 +161,8 @@ func scanText(s *scanner) stateFn {
 			return scanValue
 		}
 		if !strings.ContainsRune(preColonChars, r) {
+			// Let scanLiteral see the character, in case it closes a group.
+			s.backup()
 			return scanLiteral
 		}
 	}
@@ -169,6 +186,9 @@ func scanValue(s *scanner) stateFn {
 }
 
 func scanLiteral(s *scanner) stateFn {
+	// Parentheses opened within the literal (such as in the regexp "f(x)")
+	// must be closed before a ")" may close the enclosing group.
+	var parens int
 	for {
 		if s.eof() {
 			break
@@ -178,6 +198,26 @@ func scanLiteral(s *scanner) stateFn {
 			s.backup()
 			break
 		}
+		switch r {
+		case '\\':
+			if !s.eof() {
+				if r := s.peek(); r == '(' || r == ')' {
+					s.next()
+				}
+			}
+		case '(':
+			parens++
+		case ')':
+			if parens > 0 {
+				parens--
+			} else if s.depth > 0 && endsTerm(s.input[s.pos:]) {
+				s.backup()
+				if s.pos > s.start {
+					s.emit(TokenLiteral)
+				}
+				return scanDefault
+			}
+		}
 	}
 
 	s.emit(TokenLiteral)
@@ -257,3 +297,52 @@ func scanSpace(s *scanner) stateFn {
 	s.emit(TokenSep)
 	return scanDefault
 }
+
+// isGroup reports whether the input following a "(" forms a parenthesized
+// group of query expressions, instead of being part of a literal (such as the
+// regexp "(a|b)c"). It is a group if its matching ")" ends a term and the
+// parentheses enclose more than one term.
+func isGroup(input string) bool {
+	var (
+		depth     = 1
+		multiTerm bool
+		quote     rune // the opening quote character, if in a quoted string
+		escaped   bool
+		termStart = true // whether the previous character ends a term
+	)
+	for i, r := range input {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case (r == '"' || r == '\'') && termStart:
+			quote = r
+		case unicode.IsSpace(r):
+			multiTerm = true
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 {
+				return multiTerm && endsTerm(input[i+1:])
+			}
+		}
+		termStart = unicode.IsSpace(r) || r == '('
+	}
+	return false
+}
+
+// endsTerm reports whether the input following a ")" starts with whitespace,
+// another ")", or is empty.
+func endsTerm(input string) bool {
+	if input == "" {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(input)
+	return unicode.IsSpace(r) || r == ')'
+}
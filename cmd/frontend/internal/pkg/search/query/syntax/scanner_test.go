@@ -67,6 +67,14 @@ func TestScanner(t *testing.T) {
 		"a /b/ c":  {wantTypes: []TokenType{TokenLiteral, TokenSep, TokenPattern, TokenSep, TokenLiteral}, wantValues: []string{"a", " ", "b", " ", "c"}},
 		"a /b c":   {wantTypes: []TokenType{TokenLiteral, TokenSep, TokenPattern}, wantValues: []string{"a", " ", "b c"}},
 		"a /b c/":  {wantTypes: []TokenType{TokenLiteral, TokenSep, TokenPattern}, wantValues: []string{"a", " ", "b c"}},
+		"(a b)":    {wantTypes: []TokenType{TokenLParen, TokenLiteral, TokenSep, TokenLiteral, TokenRParen}, wantValues: []string{"(", "a", " ", "b", ")"}},
+		"(a)":      {wantTypes: []TokenType{TokenLiteral}, wantValues: []string{"(a)"}},
+		"(a b)c":   {wantTypes: []TokenType{TokenLiteral, TokenSep, TokenLiteral}, wantValues: []string{"(a", " ", "b)c"}},
+		"-(a:b c)": {wantTypes: []TokenType{TokenMinus, TokenLParen, TokenLiteral, TokenColon, TokenLiteral, TokenSep, TokenLiteral, TokenRParen}, wantValues: []string{"-", "(", "a", ":", "b", " ", "c", ")"}},
+		"(a f())":  {wantTypes: []TokenType{TokenLParen, TokenLiteral, TokenSep, TokenLiteral, TokenRParen}, wantValues: []string{"(", "a", " ", "f()", ")"}},
+		`(a \))`:   {wantTypes: []TokenType{TokenLParen, TokenLiteral, TokenSep, TokenLiteral, TokenRParen}, wantValues: []string{"(", "a", " ", `\)`, ")"}},
+		`("a)" b)`: {wantTypes: []TokenType{TokenLParen, TokenQuoted, TokenSep, TokenLiteral, TokenRParen}, wantValues: []string{"(", `"a)"`, " ", "b", ")"}},
+		"((a b))":  {wantTypes: []TokenType{TokenLParen, TokenLParen, TokenLiteral, TokenSep, TokenLiteral, TokenRParen, TokenRParen}},
 	}
 	for input, test := range tests {
 		t.Run(input, func(t *testing.T) {
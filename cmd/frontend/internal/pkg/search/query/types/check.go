@@ -44,7 +44,21 @@ func (c *Config) Check(query *syntax.Query) (*Query, error) {
 		Syntax: query,
 		Fields: map[string][]*Value{},
 	}
+	var (
+		patterns []*Node // top-level default field (search pattern) expressions
+		boolean  bool    // whether patterns must be evaluated as a boolean expression
+	)
 	for _, expr := range query.Expr {
+		if expr.IsCompound() {
+			node, err := c.checkCompoundExpr(expr, checkedQuery.Fields)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, node)
+			boolean = true
+			continue
+		}
+
 		field, fieldType, value, err := c.checkExpr(expr)
 		if err != nil {
 			return nil, err
@@ -53,10 +67,56 @@ func (c *Config) Check(query *syntax.Query) (*Query, error) {
 			return nil, &TypeError{Pos: expr.Pos, Err: fmt.Errorf("field %q may not be used more than once", field)}
 		}
 		checkedQuery.Fields[field] = append(checkedQuery.Fields[field], value)
+		if field == "" {
+			patterns = append(patterns, &Node{Not: expr.Not, Value: value})
+			boolean = boolean || expr.Not
+		}
+	}
+	if boolean {
+		pattern := newAndNode(patterns)
+		if !pattern.hasPositiveLeaf(false) {
+			return nil, &TypeError{Pos: query.Expr[0].Pos, Err: errors.New("query must contain at least one non-negated search pattern")}
+		}
+		checkedQuery.Pattern = pattern
 	}
 	return &checkedQuery, nil
 }
 
+// checkCompoundExpr typechecks a compound (and/or) expression and returns its
+// boolean expression tree. The leaf values are also added to fields.
+//
+// Only default field values (search patterns) may appear in compound
+// expressions, because other fields (such as repo: and file:) restrict the
+// set of searched files for the whole query.
+func (c *Config) checkCompoundExpr(expr *syntax.Expr, fields map[string][]*Value) (*Node, error) {
+	if !expr.IsCompound() {
+		field, _, value, err := c.checkExpr(expr)
+		if err != nil {
+			return nil, err
+		}
+		if field != "" {
+			return nil, &TypeError{Pos: expr.Pos, Err: fmt.Errorf("field %q may not be used in an and/or expression or a parenthesized group", field)}
+		}
+		fields[field] = append(fields[field], value)
+		return &Node{Not: expr.Not, Value: value}, nil
+	}
+
+	if expr.Not {
+		if _, _, err := c.resolveField("", true); err != nil {
+			return nil, &TypeError{Pos: expr.Pos, Err: err}
+		}
+	}
+	node := &Node{Op: expr.Op, Not: expr.Not, Operands: make([]*Node, len(expr.Operands))}
+	for i, operand := range expr.Operands {
+		var err error
+		node.Operands[i], err = c.checkCompoundExpr(operand, fields)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
 func (c *Config) resolveField(field string, not bool) (resolvedField string, typ FieldType, err error) {
 	// Resolve field alias, if any.
 	if resolvedField, ok := c.FieldAliases[field]; ok {
@@ -2,8 +2,10 @@ package types
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/leanovate/gopter"
@@ -221,3 +223,77 @@ func Test_autoFix(t *testing.T) {
 		props.TestingRun(t)
 	})
 }
+
+func TestCheck_Pattern(t *testing.T) {
+	conf := Config{
+		FieldTypes: map[string]FieldType{
+			"":  {Literal: RegexpType, Quoted: StringType, Negatable: true},
+			"r": {Literal: RegexpType, Quoted: RegexpType, Negatable: true},
+		},
+	}
+
+	// nodeString returns a string representation of n for comparison.
+	var nodeString func(n *Node) string
+	nodeString = func(n *Node) string {
+		var s string
+		if n.IsLeaf() {
+			s = fmt.Sprint(n.Value.Value())
+		} else {
+			operands := make([]string, len(n.Operands))
+			for i, o := range n.Operands {
+				operands[i] = nodeString(o)
+			}
+			op := " and "
+			if n.Op == syntax.OpOr {
+				op = " or "
+			}
+			s = "(" + strings.Join(operands, op) + ")"
+		}
+		if n.Not {
+			s = "not " + s
+		}
+		return s
+	}
+
+	tests := map[string]struct {
+		want    string // empty if the query has no boolean pattern
+		wantErr *TypeError
+	}{
+		"a b":                  {},
+		"r:a b":                {},
+		"a -b":                 {want: "(a and not b)"},
+		"(a or b) -c":          {want: "((a or b) and not c)"},
+		`r:a (b or "c") and d`: {want: "((b or c) and d)"},
+		"a or (b -c)":          {want: "(a or (b and not c))"},
+		"not (a or b) c":       {want: "(not (a or b) and c)"},
+		"-a":                   {wantErr: &TypeError{Pos: 1, Err: errors.New("query must contain at least one non-negated search pattern")}},
+		"-(a or b)":            {wantErr: &TypeError{Pos: 1, Err: errors.New("query must contain at least one non-negated search pattern")}},
+		"a or r:b":             {wantErr: &TypeError{Pos: 5, Err: errors.New(`field "r" may not be used in an and/or expression or a parenthesized group`)}},
+	}
+	for input, test := range tests {
+		t.Run(input, func(t *testing.T) {
+			syntaxQuery, err := syntax.Parse(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			query, err := conf.Check(syntaxQuery)
+			if err != nil && test.wantErr == nil {
+				t.Fatal(err)
+			} else if err == nil && test.wantErr != nil {
+				t.Fatalf("got err == nil, want %q", test.wantErr)
+			} else if test.wantErr != nil && err.Error() != test.wantErr.Error() {
+				t.Fatalf("got err == %q, want %q", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var got string
+			if query.Pattern != nil {
+				got = nodeString(query.Pattern)
+			}
+			if got != test.want {
+				t.Errorf("pattern\ngot  %s\nwant %s", got, test.want)
+			}
+		})
+	}
+}
@@ -10,6 +10,52 @@ import (
 type Query struct {
 	Syntax *syntax.Query       // the query syntax
 	Fields map[string][]*Value // map of field name -> values
+
+	// Pattern is the boolean expression formed by the default field values
+	// (search patterns). It is only set if the query uses the and/or/not
+	// operators, parenthesized groups, or negated search patterns. Otherwise
+	// the default field values are simply ANDed together and Pattern is nil.
+	Pattern *Node
+}
+
+// A Node is a node in the boolean expression tree of a query's search
+// patterns. A leaf node has a Value; other nodes combine their Operands with
+// Op.
+type Node struct {
+	Op       syntax.Operator // syntax.OpAnd or syntax.OpOr, or syntax.OpNone for a leaf node
+	Not      bool            // the node is negated
+	Value    *Value          // the search pattern value of a leaf node
+	Operands []*Node         // the operands of a non-leaf node
+}
+
+// IsLeaf reports whether n is a leaf node.
+func (n *Node) IsLeaf() bool {
+	return n.Op == syntax.OpNone
+}
+
+// newAndNode returns the single node in nodes, or else a node that ANDs the
+// nodes.
+func newAndNode(nodes []*Node) *Node {
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+	return &Node{Op: syntax.OpAnd, Operands: nodes}
+}
+
+// hasPositiveLeaf reports whether n has a leaf node that is not negated (by
+// itself or by an odd number of its ancestors). The parameter not is whether
+// n's ancestors negate n.
+func (n *Node) hasPositiveLeaf(not bool) bool {
+	not = not != n.Not
+	if n.IsLeaf() {
+		return !not
+	}
+	for _, o := range n.Operands {
+		if o.hasPositiveLeaf(not) {
+			return true
+		}
+	}
+	return false
 }
 
 // ValueType is the set of types of values in queries.
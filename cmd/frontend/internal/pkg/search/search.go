@@ -16,8 +16,21 @@ type PatternInfo struct {
 	IsCaseSensitive bool
 	FileMatchLimit  int32
 
-	// We do not support IsMultiline
-	//IsMultiline     bool
+	// IsStructuralPat is whether Pattern is a structural search template
+	// (e.g. "foo(:[args])") instead of a regexp. Only searcher supports
+	// structural patterns.
+	IsStructuralPat bool
+
+	// PatternExpr, if set, is a boolean expression over regexp patterns that
+	// a file's content must satisfy. Pattern is then the union of its
+	// non-negated patterns (and is used to find the matching lines).
+	PatternExpr *PatternExpr
+
+	// IsMultiline is whether Pattern is matched against whole file contents
+	// instead of line by line, so that it can match text spanning multiple
+	// lines. Only searcher supports multiline patterns.
+	IsMultiline bool
+
 	IncludePattern  string
 	IncludePatterns []string
 	ExcludePattern  string
@@ -29,6 +42,34 @@ type PatternInfo struct {
 	PatternMatchesPath    bool
 }
 
+// PatternOp is a boolean operator in a PatternExpr.
+type PatternOp string
+
+// All PatternOp values.
+const (
+	PatternOpAnd PatternOp = "and"
+	PatternOpOr  PatternOp = "or"
+)
+
+// PatternExpr is a boolean expression over regexp patterns. A leaf node has an
+// empty Op and matches content that its Pattern matches. Other nodes combine
+// their Operands with Op. Keep it in sync with
+// cmd/searcher/protocol.PatternExpr.
+type PatternExpr struct {
+	Op       PatternOp      `json:",omitempty"`
+	Not      bool           `json:",omitempty"` // negates the node
+	Pattern  string         `json:",omitempty"` // only set for leaf nodes
+	Operands []*PatternExpr `json:",omitempty"` // only set for non-leaf nodes
+}
+
+// Walk calls f for e and for each node nested in e, in depth-first order.
+func (e *PatternExpr) Walk(f func(*PatternExpr)) {
+	f(e)
+	for _, o := range e.Operands {
+		o.Walk(f)
+	}
+}
+
 func (p *PatternInfo) IsEmpty() bool {
 	return p.Pattern == "" && p.ExcludePattern == "" && len(p.IncludePatterns) == 0 && p.IncludePattern == ""
 }
@@ -39,6 +80,17 @@ func (p *PatternInfo) Validate() error {
 		if _, err := syntax.Parse(p.Pattern, syntax.Perl); err != nil {
 			return err
 		}
+		if p.PatternExpr != nil {
+			var err error
+			p.PatternExpr.Walk(func(e *PatternExpr) {
+				if err == nil && e.Op == "" {
+					_, err = syntax.Parse(e.Pattern, syntax.Perl)
+				}
+			})
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	if p.PathPatternsAreRegExps {
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/pkg/actor"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
@@ -177,7 +178,16 @@ func SetActor(w http.ResponseWriter, r *http.Request, actor *actor.Actor, expiry
 		}
 		value = &sessionInfo{Actor: actor, ExpiryPeriod: expiryPeriod, LastActive: time.Now()}
 	}
-	return SetData(w, r, "actor", value)
+	if err := SetData(w, r, "actor", value); err != nil {
+		return err
+	}
+
+	// The user just signed in, so their repository permissions on the code hosts may have changed
+	// since they were last synced.
+	if s := authz.GetPermsSyncer(); s != nil && actor != nil && actor.UID != 0 {
+		s.RequestSync(actor.UID)
+	}
+	return nil
 }
 
 func hasSessionCookie(r *http.Request) bool {
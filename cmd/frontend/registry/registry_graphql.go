@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
 	"github.com/sourcegraph/sourcegraph/pkg/registry"
@@ -73,6 +75,10 @@ func (r *extensionRegistryResolver) CreateExtension(ctx context.Context, args *g
 	if r.CreateExtensionFunc == nil {
 		return nil, errNoLocalExtensionRegistry
 	}
+	// 🚨 SECURITY: Access tokens must have the extensions:publish scope to modify extensions.
+	if err := backend.CheckActorHasScope(ctx, authz.ScopeExtensionsPublish); err != nil {
+		return nil, err
+	}
 	return r.CreateExtensionFunc(ctx, args)
 }
 
@@ -80,6 +86,10 @@ func (r *extensionRegistryResolver) UpdateExtension(ctx context.Context, args *g
 	if r.UpdateExtensionFunc == nil {
 		return nil, errNoLocalExtensionRegistry
 	}
+	// 🚨 SECURITY: Access tokens must have the extensions:publish scope to modify extensions.
+	if err := backend.CheckActorHasScope(ctx, authz.ScopeExtensionsPublish); err != nil {
+		return nil, err
+	}
 	return r.UpdateExtensionFunc(ctx, args)
 }
 
@@ -87,6 +97,10 @@ func (r *extensionRegistryResolver) PublishExtension(ctx context.Context, args *
 	if r.PublishExtensionFunc == nil {
 		return nil, errNoLocalExtensionRegistry
 	}
+	// 🚨 SECURITY: Access tokens must have the extensions:publish scope to modify extensions.
+	if err := backend.CheckActorHasScope(ctx, authz.ScopeExtensionsPublish); err != nil {
+		return nil, err
+	}
 	return r.PublishExtensionFunc(ctx, args)
 }
 
@@ -94,6 +108,10 @@ func (r *extensionRegistryResolver) DeleteExtension(ctx context.Context, args *g
 	if r.DeleteExtensionFunc == nil {
 		return nil, errNoLocalExtensionRegistry
 	}
+	// 🚨 SECURITY: Access tokens must have the extensions:publish scope to modify extensions.
+	if err := backend.CheckActorHasScope(ctx, authz.ScopeExtensionsPublish); err != nil {
+		return nil, err
+	}
 	return r.DeleteExtensionFunc(ctx, args)
 }
 
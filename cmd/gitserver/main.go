@@ -16,8 +16,10 @@ import (
 	"gopkg.in/inconshreveable/log15.v2"
 
 	"github.com/sourcegraph/sourcegraph/cmd/gitserver/server"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/debugserver"
 	"github.com/sourcegraph/sourcegraph/pkg/env"
+	gitserverclient "github.com/sourcegraph/sourcegraph/pkg/gitserver"
 	"github.com/sourcegraph/sourcegraph/pkg/tracer"
 )
 
@@ -26,6 +28,8 @@ var (
 	runRepoCleanup, _ = strconv.ParseBool(env.Get("SRC_RUN_REPO_CLEANUP", "", "Periodically remove inactive repositories."))
 	wantFreeG         = env.Get("SRC_REPOS_DESIRED_FREE_GB", "10", "How many gigabytes of space to keep free on the disk with the repos")
 	janitorInterval   = env.Get("SRC_REPOS_JANITOR_INTERVAL", "1m", "Interval between cleanup runs")
+	gitserverAddr     = env.Get("SRC_GITSERVER_ADDR", "", "Address of this gitserver as listed in SRC_GIT_SERVERS. If set (or if the address can be determined from the hostname), repositories that are placed on other gitservers are moved to them.")
+	rebalanceInterval = env.Get("SRC_REPOS_REBALANCE_INTERVAL", "10m", "Interval between runs that move repositories to the gitservers they are placed on")
 )
 
 func main() {
@@ -48,6 +52,10 @@ func main() {
 		ReposDir:                reposDir,
 		DeleteStaleRepositories: runRepoCleanup,
 		DesiredFreeDiskSpace:    uint64(wantFreeG2 * 1024 * 1024 * 1024),
+		Placement:               gitserverclient.Placement(conf.SrcGitServerPlacement),
+		Replicas:                conf.SrcGitServerReplicas,
+		Addr:                    gitserverAddr,
+		GitServerAddrs:          gitserverclient.DefaultClient.Addrs,
 	}
 	gitserver.RegisterMetrics()
 
@@ -75,6 +83,20 @@ func main() {
 		}
 	}()
 
+	rebalanceInterval2, err := time.ParseDuration(rebalanceInterval)
+	if err != nil {
+		log.Fatalf("parsing $SRC_REPOS_REBALANCE_INTERVAL: %v", err)
+	}
+	go func() {
+		// Rebalance right away, so that repositories that are placed on
+		// other gitservers (e.g. after changing the placement or adding a
+		// gitserver) are moved there as soon as possible.
+		for {
+			gitserver.Rebalance()
+			time.Sleep(rebalanceInterval2)
+		}
+	}()
+
 	port := "3178"
 	host := ""
 	if env.InsecureDev {
@@ -49,7 +49,8 @@ var reposRecloned = prometheus.NewCounter(prometheus.CounterOpts{
 // 1. Remove corrupt repos.
 // 2. Remove stale lock files.
 // 3. Remove inactive repos on sourcegraph.com
-// 4. Reclone repos after a while. (simulate git gc)
+// 4. Measure the size of repos, and remove repos that exceed their size limit.
+// 5. Reclone repos after a while. (simulate git gc)
 func (s *Server) cleanupRepos() {
 	bCtx, bCancel := s.serverContext()
 	defer bCancel()
@@ -74,6 +75,32 @@ func (s *Server) cleanupRepos() {
 		return false, setGitAttributes(gitDir)
 	}
 
+	maybeRemoveOversized := func(gitDir string) (done bool, err error) {
+		fetchOpts, err := readCloneOptions(gitDir)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to read clone options")
+		}
+		limit := maxSize(fetchOpts)
+		if limit <= 0 {
+			return false, nil
+		}
+		size, err := cachedRepoSize(gitDir, repoSizeMaxAge)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to measure repository size")
+		}
+		if size <= limit {
+			return false, nil
+		}
+
+		repo := protocol.NormalizeRepo(api.RepoName(strings.TrimPrefix(filepath.Dir(gitDir), s.ReposDir+"/")))
+		log15.Info("removing repo that exceeds its size limit", "repo", repo, "size", size, "limit", limit)
+		if err := s.removeRepoDirectory(gitDir); err != nil {
+			return true, err
+		}
+		recordSizeLimitError(repo, filepath.Dir(gitDir), &sizeLimitError{Size: size, Limit: limit, Evicted: true, Time: time.Now()})
+		return true, nil
+	}
+
 	maybeReclone := func(gitDir string) (done bool, err error) {
 		recloneTime, err := getRecloneTime(gitDir)
 		if err != nil {
@@ -98,7 +125,12 @@ func (s *Server) cleanupRepos() {
 			return false, errors.Wrap(err, "failed to get remote URL")
 		}
 
-		if _, err := s.cloneRepo(ctx, repo, remoteURL, &cloneOptions{Block: true, Overwrite: true}); err != nil {
+		fetchOpts, err := readCloneOptions(gitDir)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to read clone options")
+		}
+
+		if _, err := s.cloneRepo(ctx, repo, remoteURL, &cloneOptions{Block: true, Overwrite: true, Fetch: fetchOpts}); err != nil {
 			return true, err
 		}
 		reposRecloned.Inc()
@@ -154,6 +186,9 @@ func (s *Server) cleanupRepos() {
 		// We always want to have the same git attributes file at
 		// info/attributes.
 		{"ensure git attributes", ensureGitAttributes},
+		// Keep the recorded size of the repository up to date, and enforce
+		// the size limit (which may have been lowered since the last fetch).
+		{"maybe remove oversized", maybeRemoveOversized},
 	}
 	// Old git clones accumulate loose git objects that waste space and
 	// slow down git operations. Periodically do a fresh clone to avoid
@@ -290,16 +325,16 @@ func (s *Server) freeUpSpace(howManyBytesToFree int64) error {
 		return nil
 	}
 
-	// Get the git directories and their mod times.
+	// Get the git directories and the times they were last used.
 	gitDirs, err := s.findGitDirs(s.ReposDir)
 	if err != nil {
 		return errors.Wrap(err, "finding git dirs")
 	}
 	dirModTimes := make(map[string]time.Time, len(gitDirs))
 	for _, d := range gitDirs {
-		mt, err := gitDirModTime(d)
+		mt, err := gitDirLastUsed(d)
 		if err != nil {
-			return errors.Wrap(err, "computing mod time of git dir")
+			return errors.Wrap(err, "computing last use time of git dir")
 		}
 		dirModTimes[d] = mt
 	}
@@ -0,0 +1,263 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+)
+
+// cloneOptionsFile is the name of the file in the git directory of a
+// repository that stores the options the repository is cloned and fetched with
+// (see protocol.CloneOptions). It doesn't exist if the repository is a full
+// mirror.
+const cloneOptionsFile = "sg_cloneoptions"
+
+// readCloneOptions returns the options that the repository in gitDir is
+// cloned and fetched with, or nil if it is a full mirror.
+func readCloneOptions(gitDir string) (*protocol.CloneOptions, error) {
+	b, err := ioutil.ReadFile(filepath.Join(gitDir, cloneOptionsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var opts protocol.CloneOptions
+	if err := json.Unmarshal(b, &opts); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", cloneOptionsFile)
+	}
+	return &opts, nil
+}
+
+// writeCloneOptions stores the options that the repository in gitDir is
+// cloned and fetched with, so that later fetches and reclones use them.
+func writeCloneOptions(gitDir string, opts *protocol.CloneOptions) error {
+	path := filepath.Join(gitDir, cloneOptionsFile)
+	if opts.IsZero() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	b, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+	_, err = updateFileIfDifferent(path, b)
+	return err
+}
+
+// isPartialClone reports whether the repository in gitDir was cloned with an
+// object filter, which means that git commands may need to fetch missing
+// objects from the remote.
+func isPartialClone(gitDir string) bool {
+	opts, _ := readCloneOptions(gitDir)
+	return opts != nil && opts.Filter != ""
+}
+
+// cloneWithOptions clones the repository at url into the new bare repository
+// tmpPath, restricted by opts. It is used instead of `git clone --mirror`, which
+// can't restrict the refs that are cloned. The progress of the fetch is
+// written to progress. If the clone fails, the output of the failed command
+// is returned.
+func (s *Server) cloneWithOptions(ctx context.Context, url, tmpPath string, opts *protocol.CloneOptions, progress io.Writer) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", "init", "--bare", tmpPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return output, errors.Wrap(err, "failed to init repository")
+	}
+
+	cmd = exec.CommandContext(ctx, "git", "remote", "add", "--mirror=fetch", "origin", url)
+	cmd.Dir = tmpPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return output, errors.Wrap(err, "failed to add remote")
+	}
+
+	head, output, err := s.remoteDefaultBranch(ctx, tmpPath)
+	if err != nil {
+		return output, err
+	}
+	if head == "" && refsIncludeHEAD(opts) {
+		return nil, errors.New("remote does not advertise its default branch (HEAD)")
+	}
+
+	cmd = exec.CommandContext(ctx, "git", append([]string{"fetch", "--progress"}, fetchArgs(opts, head)...)...)
+	cmd.Dir = tmpPath
+	if output, err := s.runWithRemoteOpts(ctx, cmd, progress); err != nil {
+		return output, err
+	}
+
+	if head != "" {
+		cmd = exec.CommandContext(ctx, "git", "symbolic-ref", "HEAD", head)
+		cmd.Dir = tmpPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return output, errors.Wrap(err, "failed to set HEAD")
+		}
+	}
+
+	return nil, writeCloneOptions(tmpPath, opts)
+}
+
+// fetchArgs returns the arguments of `git fetch` (following "fetch") that
+// fetch the refs of opts from the origin remote. head is the remote's default
+// branch (such as "refs/heads/master"), which is fetched for the "HEAD" ref.
+func fetchArgs(opts *protocol.CloneOptions, head string) []string {
+	var args []string
+	if opts.Filter != "" {
+		args = append(args, "--filter="+opts.Filter)
+	}
+	if opts.Depth > 0 {
+		args = append(args, "--depth="+strconv.Itoa(opts.Depth))
+	}
+	if len(opts.Refs) == 0 {
+		return append(args, "origin", "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*", "+refs/pull/*:refs/pull/*")
+	}
+
+	// Don't follow tags that point into the fetched history but aren't in
+	// Refs.
+	args = append(args, "--no-tags", "origin")
+	for _, ref := range opts.Refs {
+		if ref == "HEAD" {
+			ref = head
+		}
+		args = append(args, "+"+ref+":"+ref)
+	}
+	return args
+}
+
+// refsIncludeHEAD reports whether opts restricts the fetched refs to a set
+// that includes the remote's default branch ("HEAD").
+func refsIncludeHEAD(opts *protocol.CloneOptions) bool {
+	for _, ref := range opts.Refs {
+		if ref == "HEAD" {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteDefaultBranch returns the ref that HEAD of the origin remote of the
+// repository in dir points to (such as "refs/heads/master"), or "" if the
+// remote doesn't advertise it. If the command fails, its output is returned.
+func (s *Server) remoteDefaultBranch(ctx context.Context, dir string) (string, []byte, error) {
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--symref", "origin", "HEAD")
+	cmd.Dir = dir
+	output, err := s.runWithRemoteOpts(ctx, cmd, nil)
+	if err != nil {
+		return "", output, errors.Wrap(err, "failed to determine default branch of remote")
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "ref: ") && strings.HasSuffix(line, "\tHEAD") {
+			return strings.TrimSuffix(strings.TrimPrefix(line, "ref: "), "\tHEAD"), nil, nil
+		}
+	}
+	return "", nil, nil
+}
+
+// lazyFetchEnv is the environment of git commands that run in a partial
+// clone. These may fetch missing objects from the remote on demand, which
+// must not prompt for credentials or SSH host keys (see runWithRemoteOpts).
+func lazyFetchEnv() []string {
+	return append(os.Environ(),
+		"GIT_ASKPASS=true",
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_SSH_COMMAND=ssh -o BatchMode=yes -o ConnectTimeout=30",
+	)
+}
+
+// prefetchArchiveObjects fetches the objects that the `git archive` command
+// with the given arguments needs and that are missing in the partial clone in
+// dir. Git would otherwise fetch each missing blob with a separate request,
+// which is very slow for archives of many files. It is best-effort: git still
+// fetches any objects that remain missing on demand.
+func (s *Server) prefetchArchiveObjects(ctx context.Context, dir string, args []string) error {
+	treeish, paths, literal := parseArchiveArgs(args)
+	if treeish == "" {
+		return nil
+	}
+
+	// List the missing objects of the tree (limited to the paths of the
+	// archive). rev-list doesn't fetch them with --missing.
+	revListArgs := []string{"rev-list", "--objects", "--missing=print", treeish + "^{tree}", "--"}
+	if literal {
+		revListArgs = append([]string{"--literal-pathspecs"}, revListArgs...)
+	}
+	cmd := exec.CommandContext(ctx, "git", append(revListArgs, paths...)...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return errors.Wrap(err, "failed to list missing objects")
+	}
+	var missing bytes.Buffer
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "?") {
+			missing.WriteString(line[1:])
+			missing.WriteByte('\n')
+		}
+	}
+	if missing.Len() == 0 {
+		return nil
+	}
+
+	// These arguments match those of the fetches that git runs for missing
+	// objects on demand.
+	cmd = exec.CommandContext(ctx, "git", "-c", "fetch.negotiationAlgorithm=noop", "fetch", "origin", "--no-tags", "--no-write-fetch-head", "--recurse-submodules=no", "--filter=blob:none", "--stdin")
+	cmd.Dir = dir
+	cmd.Stdin = &missing
+	if output, err := s.runWithRemoteOpts(ctx, cmd, nil); err != nil {
+		return errors.Wrapf(err, "failed to fetch missing objects: %s", output)
+	}
+	return nil
+}
+
+// parseArchiveArgs returns the tree-ish and the paths of the `git archive`
+// command with the given arguments (which start with "archive", possibly
+// preceded by global options), and whether the paths are literal rather than
+// pathspec patterns (--literal-pathspecs). The tree-ish is empty if the
+// arguments are not those of a `git archive` command.
+func parseArchiveArgs(args []string) (treeish string, paths []string, literal bool) {
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		literal = literal || args[0] == "--literal-pathspecs"
+		args = args[1:]
+	}
+	if len(args) == 0 || args[0] != "archive" {
+		return "", nil, false
+	}
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case treeish == "" && arg == "--":
+			return "", nil, literal
+		case treeish == "" && strings.HasPrefix(arg, "-"):
+			// All options that are used with git archive have the form
+			// --opt=value or -n.
+			continue
+		case treeish == "":
+			treeish = arg
+		case arg == "--":
+			continue
+		case arg != "" && arg != ".":
+			paths = append(paths, arg)
+		}
+	}
+	return treeish, paths, literal
+}
+
+// gitSubcommand returns the git subcommand of the arguments of a git command,
+// skipping any global options (such as --literal-pathspecs) that precede it.
+func gitSubcommand(args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			return arg
+		}
+	}
+	return ""
+}
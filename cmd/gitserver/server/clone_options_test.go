@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+)
+
+func TestCloneWithOptions(t *testing.T) {
+	workDir, cleanup := tmpDir(t)
+	defer cleanup()
+
+	run := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@a.com", "GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@a.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %s: %s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	src := filepath.Join(workDir, "src")
+	run(workDir, "init", src)
+	run(src, "config", "uploadpack.allowFilter", "true")
+	run(src, "config", "uploadpack.allowAnySHA1InWant", "true")
+	for _, name := range []string{"a", "b"} {
+		if err := ioutil.WriteFile(filepath.Join(src, name), []byte(name+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		run(src, "add", name)
+		run(src, "commit", "-m", name)
+	}
+	run(src, "tag", "v1")
+	run(src, "tag", "other")
+	run(src, "branch", "dev")
+	head := run(src, "symbolic-ref", "HEAD")
+
+	s := &Server{}
+	dst := filepath.Join(workDir, "dst", ".git")
+	opts := &protocol.CloneOptions{
+		Filter: "blob:none",
+		Refs:   []string{"HEAD", "refs/tags/v*"},
+		Depth:  1,
+	}
+	if output, err := s.cloneWithOptions(context.Background(), "file://"+src, dst, opts, ioutil.Discard); err != nil {
+		t.Fatalf("clone failed: %s: %s", err, output)
+	}
+
+	if got, want := run(dst, "for-each-ref", "--format=%(refname)"), head+"\nrefs/tags/v1"; got != want {
+		t.Errorf("got refs %q, want %q", got, want)
+	}
+	if got := run(dst, "symbolic-ref", "HEAD"); got != head {
+		t.Errorf("got HEAD %q, want %q", got, head)
+	}
+	if got := run(dst, "rev-list", "--count", "HEAD"); got != "1" {
+		t.Errorf("got %s commits, want 1 (shallow clone)", got)
+	}
+	if !isPartialClone(dst) {
+		t.Error("expected partial clone")
+	}
+	if got, err := readCloneOptions(dst); err != nil || !reflect.DeepEqual(got, opts) {
+		t.Errorf("got stored clone options %+v (error %v), want %+v", got, err, opts)
+	}
+
+	// The blobs are fetched on demand.
+	if got := run(dst, "rev-list", "--objects", "--missing=print", "HEAD^{tree}"); !strings.Contains(got, "?") {
+		t.Errorf("expected missing blobs, got %q", got)
+	}
+	if err := s.prefetchArchiveObjects(context.Background(), dst, []string{"archive", "--format=zip", "-0", "HEAD", "--", "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := run(dst, "rev-list", "--objects", "--missing=print", "HEAD^{tree}"); strings.Count(got, "?") != 1 {
+		t.Errorf("expected only blob b to be missing, got %q", got)
+	}
+	if got := run(dst, "show", "HEAD:b"); got != "b" {
+		t.Errorf("got content %q, want %q", got, "b")
+	}
+}
+
+func TestFetchArgs(t *testing.T) {
+	tests := []struct {
+		opts *protocol.CloneOptions
+		want []string
+	}{
+		{
+			opts: &protocol.CloneOptions{Filter: "blob:limit=1m"},
+			want: []string{"--filter=blob:limit=1m", "origin", "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*", "+refs/pull/*:refs/pull/*"},
+		},
+		{
+			opts: &protocol.CloneOptions{Refs: []string{"HEAD", "refs/tags/v*"}, Depth: 10},
+			want: []string{"--depth=10", "--no-tags", "origin", "+refs/heads/main:refs/heads/main", "+refs/tags/v*:refs/tags/v*"},
+		},
+	}
+	for _, test := range tests {
+		if got := fetchArgs(test.opts, "refs/heads/main"); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("fetchArgs(%+v) = %q, want %q", test.opts, got, test.want)
+		}
+	}
+}
+
+func TestParseArchiveArgs(t *testing.T) {
+	tests := []struct {
+		args        []string
+		wantTreeish string
+		wantPaths   []string
+		wantLiteral bool
+	}{
+		{args: []string{"archive", "--format=zip", "abc"}, wantTreeish: "abc"},
+		{args: []string{"archive", "--worktree-attributes", "--format=tar", "abc", "--"}, wantTreeish: "abc"},
+		{args: []string{"archive", "--format=zip", "-0", "abc", "--", "a/b", "c"}, wantTreeish: "abc", wantPaths: []string{"a/b", "c"}},
+		{args: []string{"archive", "--format=zip", "abc", "."}, wantTreeish: "abc"},
+		{args: []string{"archive", "--", "abc"}},
+		{args: []string{"--literal-pathspecs", "archive", "--format=tar", "abc", "--", "*.go"}, wantTreeish: "abc", wantPaths: []string{"*.go"}, wantLiteral: true},
+		{args: []string{"--literal-pathspecs", "rev-parse", "abc"}},
+	}
+	for _, test := range tests {
+		treeish, paths, literal := parseArchiveArgs(test.args)
+		if treeish != test.wantTreeish || !reflect.DeepEqual(paths, test.wantPaths) || literal != test.wantLiteral {
+			t.Errorf("parseArchiveArgs(%q) = %q, %q, %v, want %q, %q, %v", test.args, treeish, paths, literal, test.wantTreeish, test.wantPaths, test.wantLiteral)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/repotrackutil"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// uploadPackTimeout is the maximum duration of a git upload-pack request,
+// which is long because it may send the packfile of a large repository.
+const uploadPackTimeout = time.Hour
+
+// handleGitService serves the repositories on this gitserver over the
+// read-only git smart HTTP protocol (version 0, 1, and 2), so that they can be
+// cloned and fetched with git. The URL path is /git/{repo}/info/refs (the ref
+// advertisement) or /git/{repo}/git-upload-pack (the negotiation and packfile).
+// Pushes (git-receive-pack) are not supported.
+//
+// 🚨 SECURITY: This handler does not perform authorization. The frontend must
+// check that the user is permitted to access the repository before it proxies
+// a request to it (see (*gitserver.Client).ProxyGitHTTP).
+func (s *Server) handleGitService(w http.ResponseWriter, r *http.Request) {
+	name, service, advertiseRefs, ok := parseGitServicePath(r)
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if service != "git-upload-pack" {
+		http.Error(w, fmt.Sprintf("unsupported git service %q (only git-upload-pack is supported)", service), http.StatusForbidden)
+		return
+	}
+
+	repo := protocol.NormalizeRepo(name)
+	dir, ok := s.repoDir(repo)
+	if !ok {
+		http.Error(w, fmt.Sprintf("invalid repo %q", repo), http.StatusBadRequest)
+		return
+	}
+	if !repoCloned(dir) {
+		http.Error(w, fmt.Sprintf("repo %s is not cloned", repo), http.StatusNotFound)
+		return
+	}
+
+	body := r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "malformed gzip request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer gzr.Close()
+		body = gzr
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), uploadPackTimeout)
+	defer cancel()
+
+	args := []string{"upload-pack", "--stateless-rpc", "--strict"}
+	if advertiseRefs {
+		args = append(args, "--advertise-refs")
+	}
+	gitDir := filepath.Join(dir, ".git")
+	args = append(args, gitDir)
+	if err := touchLastAccess(gitDir); err != nil {
+		log15.Warn("Failed to record last access time", "repo", repo, "error", err)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = os.Environ()
+	gitProtocol := r.Header.Get("Git-Protocol")
+	if validGitProtocolHeader(gitProtocol) {
+		cmd.Env = append(cmd.Env, "GIT_PROTOCOL="+gitProtocol)
+	}
+	var stderr bytes.Buffer
+	cmd.Stdin = body
+	cmd.Stderr = &stderr
+
+	w.Header().Set("Cache-Control", "no-cache")
+	if advertiseRefs {
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+		// The ref advertisement of protocol version 2 (which is only a
+		// capability advertisement) omits the service line.
+		if !strings.Contains(gitProtocol, "version=2") {
+			writePktLine(w, "# service=git-upload-pack\n")
+			io.WriteString(w, "0000")
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-result")
+	}
+
+	// Flush writes so that the client receives progress messages and the
+	// packfile as they are written.
+	var out io.Writer = w
+	if fw := newFlushingResponseWriter(w); fw != nil {
+		out = fw
+		defer fw.Close()
+	}
+	cmd.Stdout = out
+
+	trackedRepo := repotrackutil.GetTrackedRepo(repo)
+	start := time.Now()
+	execRunning.WithLabelValues("upload-pack", trackedRepo).Inc()
+	_, err := runCommand(ctx, cmd)
+	execRunning.WithLabelValues("upload-pack", trackedRepo).Dec()
+	status := "success"
+	if err != nil {
+		status = "error"
+		// We have likely already written part of the response, so we can't
+		// respond with an error status.
+		log15.Error("git upload-pack failed", "repo", repo, "advertiseRefs", advertiseRefs, "error", err, "stderr", stderr.String())
+	}
+	execDuration.WithLabelValues("upload-pack", trackedRepo, status).Observe(time.Since(start).Seconds())
+}
+
+// parseGitServicePath parses the URL path of a git smart HTTP request that
+// is handled by handleGitService. It returns the name of the repository, the
+// git service that is requested, and whether the request is for the ref
+// advertisement.
+func parseGitServicePath(r *http.Request) (repo api.RepoName, service string, advertiseRefs, ok bool) {
+	path := strings.TrimPrefix(r.URL.Path, "/git/")
+	if path == r.URL.Path {
+		return "", "", false, false
+	}
+	switch {
+	case r.Method == "GET" && strings.HasSuffix(path, "/info/refs"):
+		// Only smart HTTP is supported, which requires the service query
+		// parameter. (Dumb HTTP clients would need direct access to the
+		// files of the repository.)
+		service = r.URL.Query().Get("service")
+		if service == "" {
+			return "", "", false, false
+		}
+		return api.RepoName(strings.TrimSuffix(path, "/info/refs")), service, true, true
+	case r.Method == "POST" && strings.HasSuffix(path, "/git-upload-pack"):
+		return api.RepoName(strings.TrimSuffix(path, "/git-upload-pack")), "git-upload-pack", false, true
+	case r.Method == "POST" && strings.HasSuffix(path, "/git-receive-pack"):
+		return api.RepoName(strings.TrimSuffix(path, "/git-receive-pack")), "git-receive-pack", false, true
+	}
+	return "", "", false, false
+}
+
+// validGitProtocolHeader reports whether the value of the Git-Protocol HTTP
+// header (such as "version=2") may be passed to git in the GIT_PROTOCOL
+// environment variable.
+func validGitProtocolHeader(v string) bool {
+	if v == "" || len(v) > 256 {
+		return false
+	}
+	for _, c := range v {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("=:._-", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// writePktLine writes s to w in the git pkt-line format.
+func writePktLine(w io.Writer, s string) {
+	fmt.Fprintf(w, "%04x%s", len(s)+4, s)
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitService(t *testing.T) {
+	reposDir, cleanup := tmpDir(t)
+	defer cleanup()
+	workDir, cleanupWork := tmpDir(t)
+	defer cleanupWork()
+
+	run := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@a.com", "GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@a.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %s: %s", strings.Join(args, " "), err, out)
+		}
+		return string(out)
+	}
+
+	src := filepath.Join(workDir, "src")
+	run(workDir, "init", src)
+	run(src, "commit", "--allow-empty", "-m", "foo")
+	head := run(src, "rev-parse", "HEAD")
+	run(workDir, "clone", "--bare", src, filepath.Join(reposDir, "example.com/foo/.git"))
+
+	s := &Server{ReposDir: reposDir}
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	for _, version := range []string{"0", "2"} {
+		t.Run("protocol version "+version, func(t *testing.T) {
+			dst := filepath.Join(workDir, "dst"+version)
+			run(workDir, "-c", "protocol.version="+version, "clone", ts.URL+"/git/example.com/foo", dst)
+			if got := run(dst, "rev-parse", "HEAD"); got != head {
+				t.Errorf("got HEAD %q, want %q", got, head)
+			}
+		})
+	}
+
+	t.Run("push", func(t *testing.T) {
+		cmd := exec.Command("git", "push", ts.URL+"/git/example.com/foo", "HEAD:refs/heads/bar")
+		cmd.Dir = src
+		if out, err := cmd.CombinedOutput(); err == nil {
+			t.Errorf("expected push to fail: %s", out)
+		}
+	})
+
+	t.Run("not cloned", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/git/example.com/bar/info/refs?service=git-upload-pack")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusNotFound)
+		}
+	})
+}
+
+func TestValidGitProtocolHeader(t *testing.T) {
+	for v, want := range map[string]bool{
+		"":                             false,
+		"version=2":                    true,
+		"version=2:object-format=sha1": true,
+		"version=2\nfoo=bar":           false,
+		"version=2 foo":                false,
+	} {
+		if got := validGitProtocolHeader(v); got != want {
+			t.Errorf("validGitProtocolHeader(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
@@ -172,6 +172,23 @@ func (s *Server) handleCreateCommitFromPatch(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if req.Push != nil {
+		// Push the commit before creating the ref locally, so that a failed
+		// push can be retried. An empty expected value in --force-with-lease
+		// makes the push fail if the ref already exists on the remote.
+		cmd = exec.CommandContext(ctx, "git", "push", "--force-with-lease="+req.TargetRef+":", req.Push.RemoteURL, cmtHash+":"+req.TargetRef)
+		cmd.Dir = repoGitDir
+
+		if out, err := s.runWithRemoteOpts(ctx, cmd, nil); err != nil {
+			// 🚨 SECURITY: The remote URL may contain credentials.
+			redactor := newURLRedactor(req.Push.RemoteURL)
+			log15.Error("Failed to push commit.", "ref", req.TargetRef, "commit", cmtHash, "output", redactor.redact(string(out)))
+
+			http.Error(w, "gitserver: pushing commit - "+redactor.redact(err.Error())+" - "+redactor.redact(string(out)), http.StatusInternalServerError)
+			return
+		}
+	}
+
 	cmd = exec.CommandContext(ctx, "git", "update-ref", req.TargetRef, cmtHash)
 	cmd.Dir = repoGitDir
 
@@ -0,0 +1,404 @@
+package server
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+var (
+	reposMigrated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "gitserver",
+		Name:      "repos_migrated",
+		Help:      "number of repos sent to the gitservers that they are placed on",
+	})
+	reposReceived = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "gitserver",
+		Name:      "repos_received",
+		Help:      "number of repos received from other gitservers",
+	})
+	reposMigrationSkipped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "gitserver",
+		Name:      "repos_migration_skipped",
+		Help:      "number of times a repo was not sent to the gitservers that it is placed on because it was busy",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(reposMigrated)
+	prometheus.MustRegister(reposReceived)
+	prometheus.MustRegister(reposMigrationSkipped)
+}
+
+// errRepoBusy is returned when a repository can't be moved to another
+// gitserver because its lock is held (e.g. because it is being cloned).
+var errRepoBusy = errors.New("repository is busy (e.g. being cloned)")
+
+// peerHTTPClient is used to send repositories to other gitservers. It has no
+// timeout, because sending a large repository can take a long time.
+var peerHTTPClient = &http.Client{}
+
+// rebalancingEnabled reports whether s knows enough about the other
+// gitservers to rebalance repositories (see Rebalance).
+func (s *Server) rebalancingEnabled() bool {
+	return s.GitServerAddrs != nil
+}
+
+// selfAddr returns the address of s in addrs, the addresses of all
+// gitservers. It is s.Addr if set, and otherwise the address whose host is
+// this machine's hostname (or the first label of it, e.g. "gitserver-0" in
+// "gitserver-0.gitserver:3178"). It returns "" if the address can't be
+// determined.
+func (s *Server) selfAddr(addrs []string) string {
+	if s.Addr != "" {
+		return s.Addr
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return ""
+	}
+	var self string
+	for _, addr := range addrs {
+		host := addr
+		if h, _, err := net.SplitHostPort(addr); err == nil {
+			host = h
+		}
+		if host != hostname && strings.SplitN(host, ".", 2)[0] != hostname {
+			continue
+		}
+		if self != "" {
+			// Ambiguous.
+			return ""
+		}
+		self = addr
+	}
+	return self
+}
+
+// owns reports whether self is one of the gitservers that repo is placed on,
+// given the addresses of all gitservers.
+func (s *Server) owns(repo api.RepoName, self string, addrs []string) bool {
+	for _, addr := range gitserver.AddrsForRepo(repo, addrs, s.Placement, s.Replicas) {
+		if addr == self {
+			return true
+		}
+	}
+	return false
+}
+
+// Rebalance sends the repositories on this gitserver that are placed on other
+// gitservers (e.g. because gitservers were added) to those gitservers, and
+// then removes them from this gitserver. This avoids recloning them from
+// their code hosts. It does nothing unless s.GitServerAddrs is set and the
+// address of this gitserver is known (see selfAddr).
+func (s *Server) Rebalance() {
+	if !s.rebalancingEnabled() {
+		return
+	}
+	ctx, cancel := s.serverContext()
+	defer cancel()
+
+	addrs := s.GitServerAddrs(ctx)
+	self := s.selfAddr(addrs)
+	if self == "" {
+		log15.Debug("Not rebalancing repositories because this gitserver's address is unknown. Set SRC_GITSERVER_ADDR to enable rebalancing.", "addrs", addrs)
+		return
+	}
+	if !containsString(addrs, self) {
+		// Moving every repository away would be very expensive if this is
+		// just a misconfiguration, so be conservative.
+		log15.Warn("Not rebalancing repositories because this gitserver's address is not in the list of gitserver addresses.", "addr", self, "addrs", addrs)
+		return
+	}
+
+	gitDirs, err := s.findGitDirs(s.ReposDir)
+	if err != nil {
+		log15.Error("failed to find repositories to rebalance", "error", err)
+		return
+	}
+	var migrated, skipped int
+	defer func() {
+		if migrated > 0 || skipped > 0 {
+			log15.Info("rebalanced repositories", "migrated", migrated, "skipped", skipped)
+		}
+	}()
+	for _, gitDir := range gitDirs {
+		if ctx.Err() != nil {
+			return
+		}
+		name, err := filepath.Rel(s.ReposDir, filepath.Dir(gitDir))
+		if err != nil {
+			log15.Error("failed to determine repository name", "dir", gitDir, "error", err)
+			continue
+		}
+		repo := api.RepoName(filepath.ToSlash(name))
+		if s.owns(repo, self, addrs) {
+			continue
+		}
+		owners := gitserver.AddrsForRepo(repo, addrs, s.Placement, s.Replicas)
+		if err := s.migrateRepo(ctx, repo, gitDir, owners); err == errRepoBusy {
+			log15.Debug("not migrating busy repository until the next run", "repo", repo, "to", owners)
+			reposMigrationSkipped.Inc()
+			skipped++
+			continue
+		} else if err != nil {
+			log15.Warn("failed to migrate repository", "repo", repo, "to", owners, "error", err)
+			continue
+		}
+		log15.Info("migrated repository", "repo", repo, "to", owners)
+		migrated++
+	}
+}
+
+// migrateRepo sends the repository in gitDir to each of the gitservers at
+// addrs and then removes it from this gitserver. It returns errRepoBusy
+// without doing anything if the repository is locked.
+func (s *Server) migrateRepo(ctx context.Context, repo api.RepoName, gitDir string, addrs []string) error {
+	dir := filepath.Dir(gitDir)
+	lock, ok := s.locker.TryAcquire(dir, "migrating to another gitserver")
+	if !ok {
+		return errRepoBusy
+	}
+	defer lock.Release()
+
+	for _, addr := range addrs {
+		if err := sendRepo(ctx, repo, gitDir, addr); err != nil {
+			return errors.Wrapf(err, "failed to send repository to %s", addr)
+		}
+	}
+	if err := s.removeRepoDirectory(gitDir); err != nil {
+		return errors.Wrap(err, "failed to remove migrated repository")
+	}
+	reposMigrated.Inc()
+	return nil
+}
+
+// sendRepo streams the repository in gitDir to the gitserver at addr (see
+// handleRepoReceive).
+func sendRepo(ctx context.Context, repo api.RepoName, gitDir, addr string) error {
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	go func() {
+		pw.CloseWithError(writeGitDirTar(pw, gitDir))
+	}()
+
+	req, err := http.NewRequest("POST", "http://"+addr+"/repo-receive?repo="+url.QueryEscape(string(repo)), pr)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-tar")
+	resp, err := peerHTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		// best-effort inclusion of body in error message
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("http status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// handleRepoReceive receives a repository sent by another gitserver (see
+// sendRepo). The request body is a tar archive of the repository's GIT_DIR.
+// If the repository is already cloned, the request body is ignored.
+func (s *Server) handleRepoReceive(w http.ResponseWriter, r *http.Request) {
+	repo := protocol.NormalizeRepo(api.RepoName(r.URL.Query().Get("repo")))
+	dir, ok := s.repoDir(repo)
+	if !ok {
+		http.Error(w, fmt.Sprintf("invalid repo %q", repo), http.StatusBadRequest)
+		return
+	}
+	if s.rebalancingEnabled() {
+		addrs := s.GitServerAddrs(r.Context())
+		if self := s.selfAddr(addrs); self != "" && !s.owns(repo, self, addrs) {
+			// The sender's list of gitservers differs from ours (e.g.
+			// because it is being updated), so it should try again later.
+			http.Error(w, fmt.Sprintf("repo %s is not placed on this gitserver", repo), http.StatusConflict)
+			return
+		}
+	}
+	if repoCloned(dir) {
+		return
+	}
+	lock, ok := s.locker.TryAcquire(dir, "receiving from another gitserver")
+	if !ok {
+		http.Error(w, fmt.Sprintf("repo %s is being cloned", repo), http.StatusConflict)
+		return
+	}
+	defer lock.Release()
+
+	if err := s.installGitDirTar(r.Body, dir); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	log15.Info("received repository from another gitserver", "repo", repo)
+	reposReceived.Inc()
+}
+
+// repoDir returns the directory that repo is stored in. It reports false if
+// repo is not a valid repository name.
+func (s *Server) repoDir(repo api.RepoName) (string, bool) {
+	dir := filepath.Join(s.ReposDir, string(repo))
+	if repo == "" || !strings.HasPrefix(dir, filepath.Clean(s.ReposDir)+string(filepath.Separator)) || s.ignorePath(dir) {
+		return "", false
+	}
+	return dir, true
+}
+
+// installGitDirTar extracts the tar archive written by writeGitDirTar from r
+// and moves it into place as the GIT_DIR of the repository in dir. The caller
+// must hold the lock for dir.
+func (s *Server) installGitDirTar(r io.Reader, dir string) error {
+	tmp, err := s.tempDir("receive-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	tmpGitDir := filepath.Join(tmp, ".git")
+	if err := extractGitDirTar(r, tmpGitDir); err != nil {
+		return errors.Wrap(err, "failed to extract repository archive")
+	}
+	if _, err := os.Stat(filepath.Join(tmpGitDir, "HEAD")); err != nil {
+		// We treat repositories missing HEAD to be corrupt (see cleanupRepos).
+		return errors.New("repository archive is missing HEAD")
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	return os.Rename(tmpGitDir, filepath.Join(dir, ".git"))
+}
+
+// writeGitDirTar writes a tar archive of the files in gitDir to w. File
+// modification times are preserved, since some of them are used as
+// timestamps (e.g. FETCH_HEAD for when the repository was last fetched).
+//
+// The objects directory is written last, so that a fetch running concurrently
+// can only add objects that are not referenced by the archived refs, rather
+// than leave refs that point to missing objects.
+func writeGitDirTar(w io.Writer, gitDir string) error {
+	tw := tar.NewWriter(w)
+	objectsDir := filepath.Join(gitDir, "objects")
+	walk := func(root string, skipObjects bool) error {
+		return filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if skipObjects && path == objectsDir {
+				return filepath.SkipDir
+			}
+			if path == gitDir || !(fi.Mode().IsRegular() || fi.IsDir()) {
+				return nil
+			}
+			name, err := filepath.Rel(gitDir, path)
+			if err != nil {
+				return err
+			}
+			hdr, err := tar.FileInfoHeader(fi, "")
+			if err != nil {
+				return err
+			}
+			hdr.Name = filepath.ToSlash(name)
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				return nil
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.CopyN(tw, f, fi.Size())
+			return err
+		})
+	}
+	if err := walk(gitDir, true); err != nil {
+		return err
+	}
+	if _, err := os.Stat(objectsDir); err == nil {
+		if err := walk(objectsDir, false); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// extractGitDirTar extracts the tar archive written by writeGitDirTar from r
+// to the new directory gitDir.
+func extractGitDirTar(r io.Reader, gitDir string) error {
+	if err := os.Mkdir(gitDir, os.ModePerm); err != nil {
+		return err
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(hdr.Name)
+		if filepath.IsAbs(name) || name != filepath.Clean(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path in archive: %q", hdr.Name)
+		}
+		path := filepath.Join(gitDir, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, os.ModePerm); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, os.FileMode(hdr.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+			if err := os.Chtimes(path, hdr.ModTime, hdr.ModTime); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported file type in archive: %q", hdr.Name)
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
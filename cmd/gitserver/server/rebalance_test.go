@@ -0,0 +1,163 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+)
+
+func TestRebalance(t *testing.T) {
+	dirA, cleanupA := tmpDir(t)
+	defer cleanupA()
+	dirB, cleanupB := tmpDir(t)
+	defer cleanupB()
+
+	a := &Server{ReposDir: dirA}
+	tsA := httptest.NewServer(a.Handler())
+	defer tsA.Close()
+	b := &Server{ReposDir: dirB}
+	tsB := httptest.NewServer(b.Handler())
+	defer tsB.Close()
+
+	addrA := strings.TrimPrefix(tsA.URL, "http://")
+	addrB := strings.TrimPrefix(tsB.URL, "http://")
+	addrs := []string{addrA, addrB}
+	for _, s := range []*Server{a, b} {
+		s.GitServerAddrs = func(context.Context) []string { return addrs }
+	}
+	a.Addr, b.Addr = addrA, addrB
+
+	// Find a repo that is placed on each gitserver.
+	var repoA, repoB api.RepoName
+	for i := 0; repoA == "" || repoB == ""; i++ {
+		repo := api.RepoName(fmt.Sprintf("example.com/repo-%d", i))
+		if gitserver.AddrsForRepo(repo, addrs, a.Placement, 1)[0] == addrA {
+			repoA = repo
+		} else {
+			repoB = repo
+		}
+	}
+
+	// Both repos start out on gitserver A.
+	for _, repo := range []api.RepoName{repoA, repoB} {
+		gitDir := filepath.Join(dirA, string(repo), ".git")
+		if out, err := exec.Command("git", "init", "--bare", gitDir).CombinedOutput(); err != nil {
+			t.Fatalf("git init failed: %s: %s", err, out)
+		}
+	}
+	fetchHead := filepath.Join(dirA, string(repoB), ".git", "FETCH_HEAD")
+	mkFiles(t, dirA, filepath.Join(string(repoB), ".git", "FETCH_HEAD"))
+	lastFetched := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(fetchHead, lastFetched, lastFetched); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Rebalance()
+
+	if !repoCloned(filepath.Join(dirA, string(repoA))) {
+		t.Errorf("expected %s to stay on gitserver A", repoA)
+	}
+	if repoCloned(filepath.Join(dirA, string(repoB))) {
+		t.Errorf("expected %s to be removed from gitserver A", repoB)
+	}
+	if !repoCloned(filepath.Join(dirB, string(repoB))) {
+		t.Fatalf("expected %s to be migrated to gitserver B", repoB)
+	}
+	fi, err := os.Stat(filepath.Join(dirB, string(repoB), ".git", "FETCH_HEAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.ModTime().Equal(lastFetched) {
+		t.Errorf("got FETCH_HEAD modification time %s, want %s", fi.ModTime(), lastFetched)
+	}
+
+	// Gitserver B does not accept repos that are not placed on it.
+	if err := sendRepo(context.Background(), repoA, filepath.Join(dirA, string(repoA), ".git"), addrB); err == nil {
+		t.Error("expected gitserver B to reject a repo that is placed on gitserver A")
+	}
+}
+
+func TestMigrateRepo_busy(t *testing.T) {
+	dir, cleanup := tmpDir(t)
+	defer cleanup()
+
+	s := &Server{ReposDir: dir}
+	s.Handler() // Handler as a side-effect sets up Server
+
+	repo := api.RepoName("example.com/foo")
+	gitDir := filepath.Join(dir, string(repo), ".git")
+	if out, err := exec.Command("git", "init", "--bare", gitDir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %s: %s", err, out)
+	}
+	lock, ok := s.locker.TryAcquire(filepath.Dir(gitDir), "cloning")
+	if !ok {
+		t.Fatal("failed to lock repo")
+	}
+	defer lock.Release()
+
+	// The address is never contacted, because the repo is busy.
+	if err := s.migrateRepo(context.Background(), repo, gitDir, []string{"127.0.0.1:0"}); err != errRepoBusy {
+		t.Errorf("got error %v, want %v", err, errRepoBusy)
+	}
+	if !repoCloned(filepath.Dir(gitDir)) {
+		t.Error("expected busy repo to not be removed")
+	}
+}
+
+func TestExtractGitDirTar_invalidPath(t *testing.T) {
+	dir, cleanup := tmpDir(t)
+	defer cleanup()
+
+	// Create an archive with a path that escapes the destination directory.
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	body := []byte("ref: refs/heads/master\n")
+	if err := tw.WriteHeader(&tar.Header{Name: "../HEAD", Mode: 0600, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(body); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := extractGitDirTar(&buf, filepath.Join(dir, "dst")); err == nil {
+		t.Fatal("expected error for path outside of the destination directory")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "HEAD")); !os.IsNotExist(err) {
+		t.Errorf("expected file outside of the destination directory to not be written, got %v", err)
+	}
+}
+
+func TestServer_selfAddr(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip("no hostname:", err)
+	}
+	addrs := []string{"other-0.gitserver:3178", hostname + ".gitserver:3178", "other-1:3178"}
+
+	if got, want := (&Server{}).selfAddr(addrs), hostname+".gitserver:3178"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := (&Server{Addr: "other-1:3178"}).selfAddr(addrs), "other-1:3178"; got != want {
+		t.Errorf("with Addr set: got %q, want %q", got, want)
+	}
+	if got := (&Server{}).selfAddr(append(addrs, hostname+":3178")); got != "" {
+		t.Errorf("with an ambiguous hostname: got %q, want \"\"", got)
+	}
+	if got := (&Server{}).selfAddr(addrs[:1]); got != "" {
+		t.Errorf("with no matching address: got %q, want \"\"", got)
+	}
+}
@@ -52,6 +52,23 @@ func (s *Server) repoInfo(ctx context.Context, repo api.RepoName) (*protocol.Rep
 		} else {
 			resp.LastChanged = &lastChanged
 		}
+
+		gitDir := filepath.Join(dir, ".git")
+		if size, err := repoSize(gitDir); err != nil {
+			log15.Warn("error getting repo size", "repo", repo, "err", err)
+		} else {
+			resp.Size = size
+		}
+
+		if lastAccessed, err := repoLastAccessed(gitDir); err != nil {
+			log15.Warn("error getting last accessed", "repo", repo, "err", err)
+		} else if !lastAccessed.IsZero() {
+			resp.LastAccessed = &lastAccessed
+		}
+	} else if e, err := readSizeLimitError(dir); err != nil {
+		log15.Warn("error getting size limit error", "repo", repo, "err", err)
+	} else if e != nil {
+		resp.SizeLimitError = e.Error()
 	}
 	return &resp, nil
 }
@@ -154,6 +171,10 @@ func (s *Server) deleteRepo(repo api.RepoName) error {
 	repo = protocol.NormalizeRepo(repo)
 	dir := filepath.Join(s.ReposDir, string(repo))
 
+	if err := removeSizeLimitError(dir); err != nil {
+		return err
+	}
+
 	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil && !os.IsNotExist(err) {
 		return err
 	} else if err == nil {
@@ -91,13 +91,24 @@ func TestServer_handleRepoInfo(t *testing.T) {
 		repoRemoteURL = func(context.Context, string) (string, error) { return "u", nil }
 		defer func() { repoRemoteURL = origRepoRemoteURL }()
 
+		origRepoSize := repoSize
+		repoSize = func(string) (int64, error) { return 1234, nil }
+		defer func() { repoSize = origRepoSize }()
+
+		lastAccessed := time.Date(1989, 1, 2, 3, 4, 5, 6, time.UTC)
+		origRepoLastAccessed := repoLastAccessed
+		repoLastAccessed = func(string) (time.Time, error) { return lastAccessed, nil }
+		defer func() { repoLastAccessed = origRepoLastAccessed }()
+
 		want := protocol.RepoInfoResponse{
 			Results: map[api.RepoName]*protocol.RepoInfo{
 				"x": {
-					Cloned:      true,
-					LastFetched: &lastFetched,
-					LastChanged: &lastChanged,
-					URL:         "u",
+					Cloned:       true,
+					LastFetched:  &lastFetched,
+					LastChanged:  &lastChanged,
+					URL:          "u",
+					Size:         1234,
+					LastAccessed: &lastAccessed,
 				},
 			},
 		}
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// sizeFile is the name of the file in the git directory of a repository that
+// stores the size in bytes of the git directory, as of the last time it was
+// measured by updateRepoSize.
+const sizeFile = "sg_size"
+
+// lastAccessFile is the name of the file in the git directory of a repository
+// whose mtime is the last time the repository was accessed (see
+// touchLastAccess).
+const lastAccessFile = "sg_lastaccess"
+
+// sizeLimitFile is the name of the file in the directory of a repository (the
+// parent of its git directory) that records why the repository was refused or
+// removed because it exceeded its size limit (see sizeLimitError). It is
+// outside of the git directory so that it outlives the removal of the clone.
+const sizeLimitFile = "sg_sizelimit"
+
+// lastAccessGranularity is how often the last access time of a repository is
+// updated at most, so that we don't write to disk for each git command.
+const lastAccessGranularity = time.Minute
+
+// sizeLimitCheckInterval is how often the size of a clone in progress is
+// compared to its size limit.
+var sizeLimitCheckInterval = 10 * time.Second
+
+var reposSizeLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "src",
+	Subsystem: "gitserver",
+	Name:      "repos_size_limited",
+	Help:      "number of repos that were not cloned (refused) or removed (evicted) because they exceeded their size limit",
+}, []string{"action"})
+
+func init() {
+	prometheus.MustRegister(reposSizeLimited)
+}
+
+// repoSizeMaxAge is how long the janitor relies on the size of a repository
+// stored in sizeFile before it measures the repository again. Cloning and
+// fetching always measure it, so it only goes stale when the repository
+// changes in other ways (such as by git gc).
+const repoSizeMaxAge = 6 * time.Hour
+
+// updateRepoSize measures the size of the git directory gitDir and stores it
+// in sizeFile, so that it can be reported without walking the directory. The
+// mtime of sizeFile is the last time the size was measured.
+func updateRepoSize(gitDir string) (int64, error) {
+	size, err := dirSize(gitDir)
+	if err != nil {
+		return 0, err
+	}
+	path := filepath.Join(gitDir, sizeFile)
+	updated, err := updateFileIfDifferent(path, []byte(strconv.FormatInt(size, 10)))
+	if err != nil {
+		return 0, err
+	}
+	if !updated {
+		now := time.Now()
+		if err := os.Chtimes(path, now, now); err != nil {
+			return 0, err
+		}
+	}
+	return size, nil
+}
+
+// cachedRepoSize returns the size in bytes of the git directory gitDir that
+// was stored by updateRepoSize, unless it was measured more than maxAge ago
+// (or never), in which case it measures it again.
+func cachedRepoSize(gitDir string, maxAge time.Duration) (int64, error) {
+	if fi, err := os.Stat(filepath.Join(gitDir, sizeFile)); err == nil && time.Since(fi.ModTime()) < maxAge {
+		if size, err := repoSize(gitDir); err == nil {
+			return size, nil
+		}
+	}
+	return updateRepoSize(gitDir)
+}
+
+// repoSize returns the size in bytes of the git directory gitDir that was
+// last stored by updateRepoSize, or 0 if it hasn't been measured yet.
+var repoSize = func(gitDir string) (int64, error) {
+	b, err := ioutil.ReadFile(filepath.Join(gitDir, sizeFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+}
+
+// touchLastAccess records that the repository in gitDir was accessed now. It
+// is cheap to call for each access: it only writes to disk if the last access
+// was recorded more than lastAccessGranularity ago.
+func touchLastAccess(gitDir string) error {
+	path := filepath.Join(gitDir, lastAccessFile)
+	now := time.Now()
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return ioutil.WriteFile(path, nil, 0600)
+	} else if err != nil {
+		return err
+	}
+	if now.Sub(fi.ModTime()) < lastAccessGranularity {
+		return nil
+	}
+	return os.Chtimes(path, now, now)
+}
+
+// repoLastAccessed returns the last time that the repository in gitDir was
+// accessed, or the zero time if it hasn't been accessed since it was cloned.
+var repoLastAccessed = func(gitDir string) (time.Time, error) {
+	fi, err := os.Stat(filepath.Join(gitDir, lastAccessFile))
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	return fi.ModTime(), nil
+}
+
+// gitDirLastUsed returns the time that the repository in gitDir was last
+// accessed or, if that is not known, last modified.
+func gitDirLastUsed(gitDir string) (time.Time, error) {
+	lastAccessed, err := repoLastAccessed(gitDir)
+	if err != nil || !lastAccessed.IsZero() {
+		return lastAccessed, err
+	}
+	return gitDirModTime(gitDir)
+}
+
+// maxSize returns the size limit of opts in bytes, or 0 if there is none.
+func maxSize(opts *protocol.CloneOptions) int64 {
+	if opts == nil {
+		return 0
+	}
+	return opts.MaxSize
+}
+
+// sizeLimitError is the reason why a repository was not cloned or was
+// removed: it exceeded its size limit.
+type sizeLimitError struct {
+	// Size is the size of the repository in bytes when it exceeded the
+	// limit. A clone is stopped as soon as it exceeds the limit, so the
+	// repository may be larger.
+	Size int64 `json:"size"`
+	// Limit is the size limit in bytes.
+	Limit int64 `json:"limit"`
+	// Evicted is whether the repository was removed because it grew larger
+	// than the limit after it was cloned.
+	Evicted bool `json:"evicted,omitempty"`
+	// Time is when the limit was exceeded.
+	Time time.Time `json:"time"`
+}
+
+func (e *sizeLimitError) Error() string {
+	action := "not cloned"
+	if e.Evicted {
+		action = "removed"
+	}
+	return fmt.Sprintf("repository %s because its size (at least %s) exceeds the size limit of %s", action, formatBytes(e.Size), formatBytes(e.Limit))
+}
+
+// readSizeLimitError returns the size limit error that was recorded for the
+// repository in dir, or nil if there is none.
+func readSizeLimitError(dir string) (*sizeLimitError, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, sizeLimitFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var e sizeLimitError
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s", sizeLimitFile)
+	}
+	return &e, nil
+}
+
+// removeSizeLimitError removes the size limit error that was recorded for the
+// repository in dir, if any.
+func removeSizeLimitError(dir string) error {
+	if err := os.Remove(filepath.Join(dir, sizeLimitFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// recordSizeLimitError records that the repository in dir exceeded its size
+// limit, so that the reason is visible in the repository's information and
+// the repository isn't cloned again until the limit is raised.
+func recordSizeLimitError(repo api.RepoName, dir string, e *sizeLimitError) {
+	action := "refused"
+	if e.Evicted {
+		action = "evicted"
+	}
+	reposSizeLimited.WithLabelValues(action).Inc()
+	log15.Warn("repository exceeds its size limit", "repo", repo, "action", action, "size", e.Size, "limit", e.Limit)
+
+	b, err := json.Marshal(e)
+	if err == nil {
+		if err = os.MkdirAll(dir, os.ModePerm); err == nil {
+			err = ioutil.WriteFile(filepath.Join(dir, sizeLimitFile), b, 0600)
+		}
+	}
+	if err != nil {
+		log15.Error("failed to record size limit error", "repo", repo, "error", err)
+	}
+}
+
+// checkSizeLimitBeforeClone returns the recorded size limit error of the
+// repository in dir if cloning it with opts would exceed the size limit
+// again, that is, if the limit has not been removed or raised above the size
+// that the repository had when it exceeded the limit. If opts is nil, the
+// recorded limit is used.
+func checkSizeLimitBeforeClone(dir string, opts *protocol.CloneOptions) error {
+	e, err := readSizeLimitError(dir)
+	if err != nil || e == nil {
+		return err
+	}
+	limit := e.Limit
+	if opts != nil {
+		limit = opts.MaxSize
+	}
+	if limit <= 0 || limit > e.Size {
+		return nil
+	}
+	return e
+}
+
+// watchCloneSize returns a context derived from ctx that is canceled when the
+// size of dir exceeds limit, which stops a clone into dir. The returned
+// function stops watching and returns the size of dir when it exceeded the
+// limit, or 0 if it didn't. If limit is not positive, dir isn't watched.
+func watchCloneSize(ctx context.Context, dir string, limit int64) (context.Context, func() int64) {
+	if limit <= 0 {
+		return ctx, func() int64 { return 0 }
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	var exceeded int64
+	go func() {
+		t := time.NewTicker(sizeLimitCheckInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+			if size, err := dirSize(dir); err == nil && size > limit {
+				atomic.StoreInt64(&exceeded, size)
+				cancel()
+				return
+			}
+		}
+	}()
+	return ctx, func() int64 {
+		close(done)
+		cancel()
+		return atomic.LoadInt64(&exceeded)
+	}
+}
+
+// formatBytes formats a number of bytes for humans, such as "1.5 GB" (where
+// 1 GB is 1024 MB).
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
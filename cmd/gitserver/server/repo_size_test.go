@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/mutablelimiter"
+)
+
+func TestCloneRepo_SizeLimit(t *testing.T) {
+	remote, cleanup1 := tmpDir(t)
+	defer cleanup1()
+
+	cmd := exec.Command("sh", "-c", "git init . && echo hello world > hello.txt && git add hello.txt && git commit -m hello")
+	cmd.Dir = remote
+	cmd.Env = append(os.Environ(), "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@a.com", "GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@a.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to create remote: %s: %s", err, out)
+	}
+
+	reposDir, cleanup2 := tmpDir(t)
+	defer cleanup2()
+
+	s := &Server{
+		ReposDir:         reposDir,
+		ctx:              context.Background(),
+		locker:           &RepositoryLocker{},
+		cloneLimiter:     mutablelimiter.New(1),
+		cloneableLimiter: mutablelimiter.New(1),
+	}
+	dir := filepath.Join(reposDir, "example.com/foo/bar")
+	clone := func(limit int64) error {
+		_, err := s.cloneRepo(context.Background(), "example.com/foo/bar", remote, &cloneOptions{Block: true, Fetch: &protocol.CloneOptions{MaxSize: limit}})
+		return err
+	}
+
+	// The clone is larger than 1 KB, so it is refused.
+	err := clone(1024)
+	if _, ok := errors.Cause(err).(*sizeLimitError); !ok {
+		t.Fatalf("got error %v, want size limit error", err)
+	}
+	if repoCloned(dir) {
+		t.Fatal("expected repo not to be cloned")
+	}
+	e, err := readSizeLimitError(dir)
+	if err != nil || e == nil {
+		t.Fatalf("expected size limit error to be recorded, got %v (error %v)", e, err)
+	}
+	if e.Size <= 1024 || e.Limit != 1024 || e.Evicted {
+		t.Errorf("unexpected size limit error %+v", e)
+	}
+
+	// It isn't cloned again unless the limit is raised above its size.
+	if err := clone(1024); err == nil || !strings.Contains(err.Error(), "not cloned because its size") {
+		t.Fatalf("got error %v, want size limit error", err)
+	}
+	if err := clone(0); err != nil {
+		t.Fatal(err)
+	}
+	if !repoCloned(dir) {
+		t.Fatal("expected repo to be cloned")
+	}
+	if e, err := readSizeLimitError(dir); err != nil || e != nil {
+		t.Errorf("expected size limit error to be removed, got %v (error %v)", e, err)
+	}
+	if size, err := repoSize(filepath.Join(dir, ".git")); err != nil || size <= 1024 {
+		t.Errorf("got repo size %d (error %v), want the size of the clone", size, err)
+	}
+}
+
+func TestCleanupOversized(t *testing.T) {
+	root, cleanup := tmpDir(t)
+	defer cleanup()
+
+	small, large, unlimited := filepath.Join(root, "small"), filepath.Join(root, "large"), filepath.Join(root, "unlimited")
+	for _, d := range []string{small, large, unlimited} {
+		if err := makeFakeRepo(d, 1000); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writeCloneOptions(filepath.Join(small, ".git"), &protocol.CloneOptions{MaxSize: 2000}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCloneOptions(filepath.Join(large, ".git"), &protocol.CloneOptions{MaxSize: 500}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Server{ReposDir: root}
+	s.Handler() // Handler as a side-effect sets up Server
+	s.cleanupRepos()
+
+	if size, err := repoSize(filepath.Join(small, ".git")); err != nil || size < 1000 {
+		t.Errorf("got size %d (error %v) of small repo, want at least 1000", size, err)
+	}
+	if repoCloned(large) {
+		t.Error("expected large repo to be removed")
+	}
+	if e, err := readSizeLimitError(large); err != nil || e == nil || !e.Evicted || e.Limit != 500 {
+		t.Errorf("got size limit error %+v (error %v), want recorded eviction", e, err)
+	}
+	// The size of a repository without a size limit is not measured.
+	if _, err := os.Stat(filepath.Join(unlimited, ".git", sizeFile)); !os.IsNotExist(err) {
+		t.Errorf("got error %v for size file of unlimited repo, want it not to exist", err)
+	}
+
+	// The stored size is used until it is older than repoSizeMaxAge.
+	if err := makeFakeRepo(small, 3000); err != nil {
+		t.Fatal(err)
+	}
+	s.cleanupRepos()
+	if !repoCloned(small) {
+		t.Fatal("expected small repo to be kept while its stored size is recent")
+	}
+	old := time.Now().Add(-2 * repoSizeMaxAge)
+	if err := os.Chtimes(filepath.Join(small, ".git", sizeFile), old, old); err != nil {
+		t.Fatal(err)
+	}
+	s.cleanupRepos()
+	if repoCloned(small) {
+		t.Error("expected small repo to be removed after its size was measured again")
+	}
+}
+
+func TestTouchLastAccess(t *testing.T) {
+	gitDir, cleanup := tmpDir(t)
+	defer cleanup()
+
+	if lastAccessed, err := repoLastAccessed(gitDir); err != nil || !lastAccessed.IsZero() {
+		t.Fatalf("got last access %v (error %v), want zero time", lastAccessed, err)
+	}
+
+	if err := touchLastAccess(gitDir); err != nil {
+		t.Fatal(err)
+	}
+	lastAccessed, err := repoLastAccessed(gitDir)
+	if err != nil || time.Since(lastAccessed) > time.Minute {
+		t.Fatalf("got last access %v (error %v), want now", lastAccessed, err)
+	}
+
+	// Accesses within lastAccessGranularity are not recorded.
+	old := time.Now().Add(-lastAccessGranularity / 2).Truncate(time.Second)
+	if err := os.Chtimes(filepath.Join(gitDir, lastAccessFile), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := touchLastAccess(gitDir); err != nil {
+		t.Fatal(err)
+	}
+	if lastAccessed, _ := repoLastAccessed(gitDir); !lastAccessed.Equal(old) {
+		t.Errorf("got last access %v, want %v", lastAccessed, old)
+	}
+
+	old = time.Now().Add(-2 * lastAccessGranularity)
+	if err := os.Chtimes(filepath.Join(gitDir, lastAccessFile), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := touchLastAccess(gitDir); err != nil {
+		t.Fatal(err)
+	}
+	if lastAccessed, _ := repoLastAccessed(gitDir); !lastAccessed.After(old) {
+		t.Errorf("got last access %v, want after %v", lastAccessed, old)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:                          "0 B",
+		1023:                       "1023 B",
+		1536:                       "1.5 KB",
+		100 * 1024 * 1024:          "100.0 MB",
+		5 * 1024 * 1024 * 1024 / 2: "2.5 GB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
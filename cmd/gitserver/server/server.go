@@ -34,6 +34,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/conf"
 	"github.com/sourcegraph/sourcegraph/pkg/env"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
 	"github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/pkg/honey"
 	"github.com/sourcegraph/sourcegraph/pkg/mutablelimiter"
@@ -114,6 +115,23 @@ type Server struct {
 	// DesiredFreeDiskSpace is how much space we need to keep free in bytes.
 	DesiredFreeDiskSpace uint64
 
+	// Addr is the address of this gitserver, as it appears in the addresses
+	// returned by GitServerAddrs. If empty, it is determined from the
+	// hostname if possible. Both are needed to rebalance repositories (see
+	// Rebalance).
+	Addr string
+
+	// GitServerAddrs returns the addresses of all gitservers.
+	GitServerAddrs func(ctx context.Context) []string
+
+	// Placement is how repositories are placed on gitservers (see
+	// gitserver.AddrsForRepo).
+	Placement gitserver.Placement
+
+	// Replicas is the number of gitservers that each repository is stored
+	// on (see gitserver.AddrsForRepo).
+	Replicas int
+
 	// skipCloneForTests is set by tests to avoid clones.
 	skipCloneForTests bool
 
@@ -229,6 +247,11 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/repo", s.handleDeprecatedRepoInfo) // TODO(slimsag): Remove this after 3.3 is released.
 	mux.HandleFunc("/repos", s.handleRepoInfo)
 	mux.HandleFunc("/delete", s.handleRepoDelete)
+	mux.HandleFunc("/repo-receive", s.handleRepoReceive)
+	mux.HandleFunc("/repo-archive", s.handleRepoArchive)
+	mux.HandleFunc("/repo-transfer", s.handleRepoTransfer)
+	mux.HandleFunc("/git/", s.handleGitService)
+	mux.HandleFunc("/drain", s.handleDrain)
 	mux.HandleFunc("/repo-update", s.handleRepoUpdate)
 	mux.HandleFunc("/getGitolitePhabricatorMetadata", s.handleGetGitolitePhabricatorMetadata)
 	mux.HandleFunc("/create-commit-from-patch", s.handleCreateCommitFromPatch)
@@ -399,7 +422,7 @@ func (s *Server) handleRepoUpdate(w http.ResponseWriter, r *http.Request) {
 		// optimistically, we assume that our cloning attempt might
 		// succeed.
 		resp.CloneInProgress = true
-		_, err := s.cloneRepo(ctx, req.Repo, req.URL, nil)
+		_, err := s.cloneRepo(ctx, req.Repo, req.URL, &cloneOptions{Fetch: req.CloneOptions})
 		if err != nil {
 			log15.Warn("error cloning repo", "repo", req.Repo, "err", err)
 			resp.Error = err.Error()
@@ -409,7 +432,7 @@ func (s *Server) handleRepoUpdate(w http.ResponseWriter, r *http.Request) {
 		var statusErr, updateErr error
 
 		if debounce(req.Repo, req.Since) {
-			updateErr = s.doRepoUpdate(ctx, req.Repo, req.URL)
+			updateErr = s.doRepoUpdate(ctx, req.Repo, req.URL, req.CloneOptions)
 		}
 
 		// attempts to acquire these values are not contingent on the success of
@@ -475,10 +498,7 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 	// Instrumentation
 	{
 		repo := repotrackutil.GetTrackedRepo(req.Repo)
-		cmd := ""
-		if len(req.Args) > 0 {
-			cmd = req.Args[0]
-		}
+		cmd := gitSubcommand(req.Args)
 		args := strings.Join(req.Args, " ")
 
 		var tr *trace.Trace
@@ -566,7 +586,7 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 			_ = json.NewEncoder(w).Encode(&protocol.NotFoundPayload{CloneInProgress: false})
 			return
 		}
-		cloneProgress, err := s.cloneRepo(ctx, req.Repo, req.URL, nil)
+		cloneProgress, err := s.cloneRepo(ctx, req.Repo, req.URL, &cloneOptions{Fetch: req.CloneOptions})
 		if err != nil {
 			log15.Debug("error cloning repo", "repo", req.Repo, "err", err)
 			status = "repo-not-found"
@@ -583,7 +603,7 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	didUpdate := s.ensureRevision(ctx, req.Repo, req.URL, req.EnsureRevision, dir)
+	didUpdate := s.ensureRevision(ctx, req.Repo, req.URL, req.CloneOptions, req.EnsureRevision, dir)
 	if didUpdate {
 		ensureRevisionStatus = "fetched"
 	} else {
@@ -612,9 +632,24 @@ func (s *Server) handleExec(w http.ResponseWriter, r *http.Request) {
 	stdoutW := &writeCounter{w: w}
 	stderrW := &writeCounter{w: &stderrBuf}
 
+	gitDir := filepath.Join(dir, ".git")
+	if err := touchLastAccess(gitDir); err != nil && !os.IsNotExist(err) {
+		log15.Warn("Failed to record last access time", "repo", req.Repo, "error", err)
+	}
+	partialClone := isPartialClone(gitDir)
+	if partialClone && gitSubcommand(req.Args) == "archive" {
+		if err := s.prefetchArchiveObjects(ctx, gitDir, req.Args); err != nil {
+			log15.Warn("Failed to prefetch missing objects for archive", "repo", req.Repo, "args", req.Args, "error", err)
+		}
+	}
+
 	cmdStart = time.Now()
 	cmd := exec.CommandContext(ctx, "git", req.Args...)
 	cmd.Dir = dir
+	if partialClone {
+		// The command may fetch missing objects from the remote.
+		cmd.Env = lazyFetchEnv()
+	}
 	cmd.Stdout = stdoutW
 	cmd.Stderr = stderrW
 
@@ -667,6 +702,11 @@ type cloneOptions struct {
 
 	// Overwrite will overwrite the existing clone.
 	Overwrite bool
+
+	// Fetch restricts the objects and refs that are cloned (and fetched by
+	// later updates), and limits the size of the clone. If it doesn't
+	// restrict the fetch, the repo is cloned as a full mirror.
+	Fetch *protocol.CloneOptions
 }
 
 // cloneRepo issues a git clone command for the given repo. It is
@@ -680,6 +720,17 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, url string, o
 		return progress, nil
 	}
 
+	var fetchOpts *protocol.CloneOptions
+	if opts != nil {
+		fetchOpts = opts.Fetch
+	}
+
+	// Don't clone a repo again that exceeded its size limit, unless the limit
+	// was raised or removed since.
+	if err := checkSizeLimitBeforeClone(dir, fetchOpts); err != nil {
+		return "", fmt.Errorf("error cloning repo: repo %s: %s", repo, err)
+	}
+
 	// isCloneable causes a network request, so we limit the number that can
 	// run at one time. We use a separate semaphore to cloning since these
 	// checks being blocked by a few slow clones will lead to poor feedback to
@@ -744,14 +795,41 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, url string, o
 		defer os.RemoveAll(tmpPath)
 		tmpPath = filepath.Join(tmpPath, ".git")
 
-		cmd := exec.CommandContext(ctx, "git", "clone", "--mirror", "--progress", url, tmpPath)
-		log15.Info("cloning repo", "repo", repo, "tmp", tmpPath, "dst", dstPath)
+		log15.Info("cloning repo", "repo", repo, "tmp", tmpPath, "dst", dstPath, "options", fetchOpts)
 
 		pr, pw := io.Pipe()
 		defer pw.Close()
 		go readCloneProgress(repo, url, lock, pr)
 
-		if output, err := s.runWithRemoteOpts(ctx, cmd, pw); err != nil {
+		// The clone is stopped if it exceeds the size limit.
+		cloneCtx, stopWatchingSize := watchCloneSize(ctx, tmpPath, maxSize(fetchOpts))
+		var output []byte
+		if !fetchOpts.RestrictsFetch() {
+			cmd := exec.CommandContext(cloneCtx, "git", "clone", "--mirror", "--progress", url, tmpPath)
+			output, err = s.runWithRemoteOpts(cloneCtx, cmd, pw)
+			if err == nil {
+				// Store the size limit for later fetches and reclones.
+				err = writeCloneOptions(tmpPath, fetchOpts)
+			}
+		} else {
+			output, err = s.cloneWithOptions(cloneCtx, url, tmpPath, fetchOpts, pw)
+		}
+		exceededSize := stopWatchingSize()
+		if err == nil {
+			size, err := updateRepoSize(tmpPath)
+			if err != nil {
+				return errors.Wrap(err, "failed to measure repository size")
+			}
+			if limit := maxSize(fetchOpts); limit > 0 && size > limit {
+				exceededSize = size
+			}
+		}
+		if exceededSize > 0 {
+			e := &sizeLimitError{Size: exceededSize, Limit: maxSize(fetchOpts), Time: time.Now()}
+			recordSizeLimitError(repo, dir, e)
+			return e
+		}
+		if err != nil {
 			return errors.Wrapf(err, "clone failed. Output: %s", string(output))
 		}
 
@@ -766,6 +844,13 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, url string, o
 		}
 
 		if overwrite {
+			// Keep the last access time of the current repo.
+			if lastAccessed, err := repoLastAccessed(dstPath); err == nil && !lastAccessed.IsZero() {
+				if err := ioutil.WriteFile(filepath.Join(tmpPath, lastAccessFile), nil, 0600); err == nil {
+					_ = os.Chtimes(filepath.Join(tmpPath, lastAccessFile), lastAccessed, lastAccessed)
+				}
+			}
+
 			// remove the current repo by putting it into our temporary directory
 			err := os.Rename(dstPath, filepath.Join(filepath.Dir(tmpPath), "old"))
 			if err != nil && !os.IsNotExist(err) {
@@ -779,6 +864,9 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, url string, o
 		if err := os.Rename(tmpPath, dstPath); err != nil {
 			return err
 		}
+		if err := removeSizeLimitError(dir); err != nil {
+			log15.Warn("failed to remove size limit error", "repo", repo, "error", err)
+		}
 
 		log15.Info("repo cloned", "repo", repo)
 		repoClonedCounter.Inc()
@@ -965,7 +1053,7 @@ func init() {
 
 var headBranchPattern = regexp.MustCompile(`HEAD branch: (.+?)\n`)
 
-func (s *Server) doRepoUpdate(ctx context.Context, repo api.RepoName, url string) error {
+func (s *Server) doRepoUpdate(ctx context.Context, repo api.RepoName, url string, opts *protocol.CloneOptions) error {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Server.doRepoUpdate")
 	span.SetTag("repo", repo)
 	span.SetTag("url", url)
@@ -999,7 +1087,7 @@ func (s *Server) doRepoUpdate(ctx context.Context, repo api.RepoName, url string
 			l.once = new(sync.Once) // Make new requests wait for next update.
 			s.repoUpdateLocksMu.Unlock()
 
-			err = s.doRepoUpdate2(repo, url)
+			err = s.doRepoUpdate2(repo, url, opts)
 		})
 	}()
 
@@ -1142,7 +1230,7 @@ func computeRefHash(dir string) ([]byte, error) {
 	return hash, nil
 }
 
-func (s *Server) doRepoUpdate2(repo api.RepoName, url string) error {
+func (s *Server) doRepoUpdate2(repo api.RepoName, url string, opts *protocol.CloneOptions) error {
 	// background context.
 	ctx, cancel1 := s.serverContext()
 	defer cancel1()
@@ -1190,7 +1278,39 @@ func (s *Server) doRepoUpdate2(repo api.RepoName, url string) error {
 		}
 	}
 
-	cmd := exec.CommandContext(ctx, "git", "fetch", "--prune", url, "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*", "+refs/pull/*:refs/pull/*")
+	// If opts is not set, we use the options that the repo was cloned with.
+	// Otherwise, we store them for later fetches and reclones.
+	gitDir := filepath.Join(dir, ".git")
+	if opts == nil {
+		opts, err = readCloneOptions(gitDir)
+		if err != nil {
+			log15.Error("Failed to read clone options", "repo", repo, "error", err)
+			return errors.Wrap(err, "failed to read clone options")
+		}
+	} else if err := writeCloneOptions(gitDir, opts); err != nil {
+		log15.Warn("Failed to store clone options", "repo", repo, "error", err)
+	}
+
+	var cmd *exec.Cmd
+	if !opts.RestrictsFetch() {
+		cmd = exec.CommandContext(ctx, "git", "fetch", "--prune", url, "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*", "+refs/pull/*:refs/pull/*")
+	} else {
+		// The fetch is from the origin remote (whose URL is url), because a
+		// partial clone stores its object filter in the remote's config.
+		var head string
+		if refsIncludeHEAD(opts) {
+			var output []byte
+			head, output, err = s.remoteDefaultBranch(ctx, dir)
+			if err == nil && head == "" {
+				err = errors.New("remote does not advertise its default branch (HEAD)")
+			}
+			if err != nil {
+				log15.Error("Failed to update", "repo", repo, "error", err, "output", string(output))
+				return errors.Wrap(err, "failed to update")
+			}
+		}
+		cmd = exec.CommandContext(ctx, "git", append([]string{"fetch", "--prune"}, fetchArgs(opts, head)...)...)
+	}
 	cmd.Dir = dir
 
 	// drop temporary pack files after a fetch. this function won't
@@ -1209,6 +1329,19 @@ func (s *Server) doRepoUpdate2(repo api.RepoName, url string) error {
 		log15.Warn("Failed to update last changed time", "repo", repo, "error", err)
 	}
 
+	// Remove the repo if the fetch made it exceed its size limit.
+	if size, err := updateRepoSize(gitDir); err != nil {
+		log15.Warn("Failed to measure repository size", "repo", repo, "error", err)
+	} else if limit := maxSize(opts); limit > 0 && size > limit {
+		e := &sizeLimitError{Size: size, Limit: limit, Evicted: true, Time: time.Now()}
+		if err := s.removeRepoDirectory(gitDir); err != nil {
+			log15.Error("Failed to remove repository that exceeds its size limit", "repo", repo, "error", err)
+			return errors.Wrap(err, "failed to remove repository that exceeds its size limit")
+		}
+		recordSizeLimitError(repo, dir, e)
+		return e
+	}
+
 	headBranch := "master"
 
 	// try to fetch HEAD from origin
@@ -1256,7 +1389,7 @@ func (s *Server) doRepoUpdate2(repo api.RepoName, url string) error {
 	return nil
 }
 
-func (s *Server) ensureRevision(ctx context.Context, repo api.RepoName, url, rev, repoDir string) (didUpdate bool) {
+func (s *Server) ensureRevision(ctx context.Context, repo api.RepoName, url string, opts *protocol.CloneOptions, rev, repoDir string) (didUpdate bool) {
 	if rev == "" || rev == "HEAD" {
 		return false
 	}
@@ -1271,7 +1404,7 @@ func (s *Server) ensureRevision(ctx context.Context, repo api.RepoName, url, rev
 		return false
 	}
 	// Revision not found, update before returning.
-	s.doRepoUpdate(ctx, repo, url)
+	s.doRepoUpdate(ctx, repo, url, opts)
 	return true
 }
 
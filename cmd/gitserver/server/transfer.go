@@ -0,0 +1,220 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// handleRepoArchive writes a tar archive of a repository's GIT_DIR (see
+// writeGitDirTar), so that another gitserver can copy it (see
+// handleRepoTransfer).
+func (s *Server) handleRepoArchive(w http.ResponseWriter, r *http.Request) {
+	repo := protocol.NormalizeRepo(api.RepoName(r.URL.Query().Get("repo")))
+	dir, ok := s.repoDir(repo)
+	if !ok {
+		http.Error(w, fmt.Sprintf("invalid repo %q", repo), http.StatusBadRequest)
+		return
+	}
+	gitDir := filepath.Join(dir, ".git")
+	if !repoCloned(dir) {
+		http.Error(w, fmt.Sprintf("repo %s is not cloned", repo), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	if err := writeGitDirTar(w, gitDir); err != nil {
+		// We have likely already written part of the archive, so we can't
+		// respond with an error status. The truncated archive will fail to
+		// extract.
+		log15.Error("failed to write repository archive", "repo", repo, "error", err)
+	}
+}
+
+// handleRepoTransfer copies a repository from another gitserver (see
+// handleRepoArchive), instead of cloning it from its code host. Unlike
+// handleRepoReceive, it does not check that the repository is placed on this
+// gitserver, since it is used to move repositories explicitly (e.g. to drain
+// a gitserver before it is removed).
+func (s *Server) handleRepoTransfer(w http.ResponseWriter, r *http.Request) {
+	var req protocol.RepoTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.From == "" {
+		http.Error(w, "from is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.transferRepo(r.Context(), req.Repo, req.From); err != nil {
+		log15.Error("failed to transfer repository", "repo", req.Repo, "from", req.From, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// transferRepo copies repo from the gitserver at addr. It does nothing if
+// repo is already cloned.
+func (s *Server) transferRepo(ctx context.Context, repo api.RepoName, addr string) error {
+	repo = protocol.NormalizeRepo(repo)
+	dir, ok := s.repoDir(repo)
+	if !ok {
+		return fmt.Errorf("invalid repo %q", repo)
+	}
+	if repoCloned(dir) {
+		return nil
+	}
+	lock, ok := s.locker.TryAcquire(dir, "transferring from another gitserver")
+	if !ok {
+		return fmt.Errorf("repo %s is being cloned", repo)
+	}
+	defer lock.Release()
+
+	req, err := http.NewRequest("GET", "http://"+addr+"/repo-archive?repo="+url.QueryEscape(string(repo)), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := peerHTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		// best-effort inclusion of body in error message
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("http status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if err := s.installGitDirTar(resp.Body, dir); err != nil {
+		return err
+	}
+	log15.Info("transferred repository from another gitserver", "repo", repo, "from", addr)
+	reposReceived.Inc()
+	return nil
+}
+
+// handleDrain moves all repositories off of this gitserver, to the other
+// gitservers that they are placed on when this gitserver is excluded. Each
+// repository is removed from this gitserver once it has been copied.
+//
+// To drain a gitserver for maintenance, first remove it from SRC_GIT_SERVERS
+// for all other services, so that it no longer receives requests. This
+// requires the address of this gitserver to be known (see selfAddr).
+func (s *Server) handleDrain(w http.ResponseWriter, r *http.Request) {
+	if !s.rebalancingEnabled() {
+		http.Error(w, "draining requires SRC_GITSERVER_ADDR to be set", http.StatusBadRequest)
+		return
+	}
+	allAddrs := s.GitServerAddrs(r.Context())
+	self := s.selfAddr(allAddrs)
+	if self == "" {
+		http.Error(w, "draining requires SRC_GITSERVER_ADDR to be set (it can't be determined from the hostname)", http.StatusBadRequest)
+		return
+	}
+	var addrs []string
+	for _, addr := range allAddrs {
+		if addr != self {
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 {
+		http.Error(w, "there are no other gitservers to move repositories to", http.StatusBadRequest)
+		return
+	}
+
+	gitDirs, err := s.findGitDirs(s.ReposDir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp := protocol.DrainResponse{Failed: map[api.RepoName]string{}}
+	for _, gitDir := range gitDirs {
+		if r.Context().Err() != nil {
+			break
+		}
+		name, err := filepath.Rel(s.ReposDir, filepath.Dir(gitDir))
+		if err != nil {
+			log15.Error("failed to determine repository name", "dir", gitDir, "error", err)
+			continue
+		}
+		repo := api.RepoName(filepath.ToSlash(name))
+		owners := gitserver.AddrsForRepo(repo, addrs, s.Placement, s.Replicas)
+		if err := s.drainRepo(r.Context(), repo, self, gitDir, owners); err == errRepoBusy {
+			log15.Info("not draining busy repository", "repo", repo, "to", owners)
+			reposMigrationSkipped.Inc()
+			resp.Skipped = append(resp.Skipped, repo)
+			continue
+		} else if err != nil {
+			log15.Warn("failed to drain repository", "repo", repo, "to", owners, "error", err)
+			resp.Failed[repo] = err.Error()
+			continue
+		}
+		log15.Info("drained repository", "repo", repo, "to", owners)
+		resp.Transferred = append(resp.Transferred, repo)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&resp); err != nil {
+		log15.Error("failed to encode drain response", "error", err)
+	}
+}
+
+// drainRepo asks each of the gitservers at addrs to copy the repository in
+// gitDir from this gitserver, and then removes it from this gitserver. It
+// returns errRepoBusy without doing anything if the repository is locked.
+func (s *Server) drainRepo(ctx context.Context, repo api.RepoName, self, gitDir string, addrs []string) error {
+	dir := filepath.Dir(gitDir)
+	lock, ok := s.locker.TryAcquire(dir, "draining to another gitserver")
+	if !ok {
+		return errRepoBusy
+	}
+	defer lock.Release()
+
+	for _, addr := range addrs {
+		if err := requestTransfer(ctx, addr, &protocol.RepoTransferRequest{Repo: repo, From: self}); err != nil {
+			return errors.Wrapf(err, "failed to transfer repository to %s", addr)
+		}
+	}
+	if err := s.removeRepoDirectory(gitDir); err != nil {
+		return errors.Wrap(err, "failed to remove transferred repository")
+	}
+	reposMigrated.Inc()
+	return nil
+}
+
+// requestTransfer asks the gitserver at addr to copy a repository from
+// another gitserver (see handleRepoTransfer).
+func requestTransfer(ctx context.Context, addr string, req *protocol.RepoTransferRequest) error {
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequest("POST", "http://"+addr+"/repo-transfer", bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	resp, err := peerHTTPClient.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		// best-effort inclusion of body in error message
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("http status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
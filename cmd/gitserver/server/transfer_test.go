@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+)
+
+func TestDrain(t *testing.T) {
+	dirA, cleanupA := tmpDir(t)
+	defer cleanupA()
+	dirB, cleanupB := tmpDir(t)
+	defer cleanupB()
+
+	a := &Server{ReposDir: dirA}
+	tsA := httptest.NewServer(a.Handler())
+	defer tsA.Close()
+	b := &Server{ReposDir: dirB}
+	tsB := httptest.NewServer(b.Handler())
+	defer tsB.Close()
+
+	addrA := strings.TrimPrefix(tsA.URL, "http://")
+	addrB := strings.TrimPrefix(tsB.URL, "http://")
+	addrs := []string{addrA, addrB}
+	for _, s := range []*Server{a, b} {
+		s.GitServerAddrs = func(context.Context) []string { return addrs }
+	}
+	a.Addr, b.Addr = addrA, addrB
+
+	repos := []api.RepoName{"example.com/foo", "example.com/bar"}
+	busyRepo := api.RepoName("example.com/busy")
+	for _, repo := range append(repos, busyRepo) {
+		gitDir := filepath.Join(dirA, string(repo), ".git")
+		if out, err := exec.Command("git", "init", "--bare", gitDir).CombinedOutput(); err != nil {
+			t.Fatalf("git init failed: %s: %s", err, out)
+		}
+	}
+	lock, ok := a.locker.TryAcquire(filepath.Join(dirA, string(busyRepo)), "cloning")
+	if !ok {
+		t.Fatal("failed to lock busy repo")
+	}
+	defer lock.Release()
+
+	resp, err := http.Post(tsA.URL+"/drain", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var got protocol.DrainResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	want := protocol.DrainResponse{
+		// findGitDirs walks the repos in lexical order.
+		Transferred: []api.RepoName{"example.com/bar", "example.com/foo"},
+		Skipped:     []api.RepoName{busyRepo},
+		Failed:      map[api.RepoName]string{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	for _, repo := range repos {
+		if repoCloned(filepath.Join(dirA, string(repo))) {
+			t.Errorf("expected %s to be removed from gitserver A", repo)
+		}
+		if !repoCloned(filepath.Join(dirB, string(repo))) {
+			t.Errorf("expected %s to be transferred to gitserver B", repo)
+		}
+	}
+	if !repoCloned(filepath.Join(dirA, string(busyRepo))) {
+		t.Errorf("expected busy repo %s to stay on gitserver A", busyRepo)
+	}
+}
+
+func TestRepoTransfer_notCloned(t *testing.T) {
+	dirA, cleanupA := tmpDir(t)
+	defer cleanupA()
+	dirB, cleanupB := tmpDir(t)
+	defer cleanupB()
+
+	a := &Server{ReposDir: dirA}
+	tsA := httptest.NewServer(a.Handler())
+	defer tsA.Close()
+	b := &Server{ReposDir: dirB}
+	b.Handler() // Handler as a side-effect sets up Server
+
+	err := b.transferRepo(context.Background(), "example.com/foo", strings.TrimPrefix(tsA.URL, "http://"))
+	if err == nil || !strings.Contains(err.Error(), "http status 404") {
+		t.Errorf("got error %v, want http status 404", err)
+	}
+	if repoCloned(filepath.Join(dirB, "example.com/foo")) {
+		t.Error("expected repo to not be cloned")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/management-console/assets"
 	"github.com/sourcegraph/sourcegraph/cmd/management-console/shared/internal/tlscertgen"
+	"github.com/sourcegraph/sourcegraph/pkg/auditlog"
 	"github.com/sourcegraph/sourcegraph/pkg/db/confdb"
 	"github.com/sourcegraph/sourcegraph/pkg/db/dbconn"
 	"github.com/sourcegraph/sourcegraph/pkg/db/globalstatedb"
@@ -217,6 +218,15 @@ func serveUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Get the previous contents for the audit log. If another edit is made in between, the update
+	// below fails (because lastID is out of date), so there is no need for a transaction.
+	previous, err := confdb.CriticalGetLatest(r.Context())
+	if err != nil {
+		logger.Error("confdb.CriticalGetLatest failed", "error", err)
+		httpError(w, errors.Wrap(err, "Error retrieving latest critical configuration").Error(), "internal_error")
+		return
+	}
+
 	critical, err := confdb.CriticalCreateIfUpToDate(r.Context(), &lastIDInt32, args.Contents)
 	if err != nil {
 		if err == confdb.ErrNewerEdit {
@@ -227,6 +237,7 @@ func serveUpdate(w http.ResponseWriter, r *http.Request) {
 		httpError(w, errors.Wrap(err, "Error updating latest critical configuration").Error(), "internal_error")
 		return
 	}
+	logCriticalConfigAuditEvent(r, previous.Contents, critical.Contents)
 
 	err = json.NewEncoder(w).Encode(&jsonConfiguration{
 		ID:       strconv.Itoa(int(critical.ID)),
@@ -238,6 +249,38 @@ func serveUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// logCriticalConfigAuditEvent records a change to the critical configuration in the audit log,
+// like the frontend does for changes made in the site admin area. The management console can't use
+// the frontend's db.AuditLog store (because it must work when the frontend is down), so it inserts
+// the entry itself. There is no Sourcegraph user associated with the change, so it has no actor.
+//
+// The change has already been made, so errors are logged instead of returned.
+func logCriticalConfigAuditEvent(r *http.Request, before, after string) {
+	if before == after {
+		return
+	}
+	logger := log15.New("route", "update")
+
+	var diff *string
+	if d, err := auditlog.DiffJSONC(before, after); err != nil {
+		logger.Warn("Unable to compute audit log diff of critical configuration.", "error", err)
+	} else {
+		s := string(d)
+		diff = &s
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	_, err = dbconn.Global.ExecContext(r.Context(),
+		"INSERT INTO audit_log(action, target_type, target_id, ip, forwarded_for, diff) VALUES('site_config.update', 'site_config', 'critical', $1, $2, $3)",
+		ip, r.Header.Get("X-Forwarded-For"), diff,
+	)
+	if err != nil {
+		logger.Error("Unable to record critical configuration change in audit log.", "error", err)
+	}
+}
+
 // HSTSMiddleware effectively instructs browsers to change all HTTP requests to
 // HTTPS.
 func HSTSMiddleware(h http.Handler) http.Handler {
@@ -230,5 +230,18 @@ func serveTestNotification(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if webhook := query.Config.NotifyWebhook; webhook != nil {
+		// Deliver the webhook notification asynchronously, because retries may
+		// take a while. The outcome is shown in the delivery history.
+		payload := newWebhookPayload(webhookEventTest, query.Spec, query.Config, query.Config.Query, nil, nil)
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+			if err := sendWebhookNotification(ctx, query.Spec, webhook, payload); err != nil {
+				log15.Error("Failed to send test webhook notification for saved search.", "spec", query.Spec, "error", err)
+			}
+		}()
+	}
+
 	log15.Info("saved query test notification sent", "spec", args.Spec, "key", key)
 }
@@ -43,24 +43,27 @@ func (n *notifier) emailNotify(ctx context.Context) {
 				ownership = "your organization's"
 			}
 
-			plural := ""
-			if n.results.Data.Search.Results.ApproximateResultCount != "1" {
-				plural = "s"
-			}
+			added, moreAdded, removed, moreRemoved := n.recipientNotificationMatches(ctx, recipient, utmSourceEmail)
 			if err := sendEmail(ctx, recipient.spec.userID, "results", newSearchResultsEmailTemplates, struct {
-				URL                    string
-				Description            string
-				Query                  string
-				ApproximateResultCount string
-				Ownership              string
-				PluralResults          string
+				URL         string
+				Description string
+				Query       string
+				Summary     string
+				Ownership   string
+				Added       []notificationMatch
+				MoreAdded   int
+				Removed     []notificationMatch
+				MoreRemoved int
 			}{
-				URL:                    searchURL(n.newQuery, utmSourceEmail),
-				Description:            n.query.Description,
-				Query:                  n.query.Query,
-				ApproximateResultCount: n.results.Data.Search.Results.ApproximateResultCount,
-				Ownership:              ownership,
-				PluralResults:          plural,
+				URL:         searchURL(n.newQuery, utmSourceEmail),
+				Description: n.query.Description,
+				Query:       n.query.Query,
+				Summary:     resultsSummary(len(n.added), len(n.removed)),
+				Ownership:   ownership,
+				Added:       added,
+				MoreAdded:   moreAdded,
+				Removed:     removed,
+				MoreRemoved: moreRemoved,
 			}); err != nil {
 				log15.Error("Failed to send email notification for new saved search results.", "userID", recipient.spec.userID, "error", err)
 			}
@@ -69,20 +72,47 @@ func (n *notifier) emailNotify(ctx context.Context) {
 }
 
 var newSearchResultsEmailTemplates = txemail.MustValidate(txtypes.Templates{
-	Subject: `[{{.ApproximateResultCount}} new result{{.PluralResults}}] {{.Description}}`,
+	Subject: `[{{.Summary}}] {{.Description}}`,
 	Text: `
-{{.ApproximateResultCount}} new search result{{.PluralResults}} found for {{.Ownership}} saved search:
+{{.Summary}} found for {{.Ownership}} saved search:
 
   "{{.Description}}"
-
-View the new result{{.PluralResults}} on Sourcegraph: {{.URL}}
+{{if .Added}}
+New results:
+{{range .Added}}
+  + {{.Label}}{{if .Preview}}
+    {{.Preview}}{{end}}
+    {{.URL}}
+{{end}}{{if .MoreAdded}}
+  ...and {{.MoreAdded}} more
+{{end}}{{end}}{{if .Removed}}
+Removed results:
+{{range .Removed}}
+  - {{.Label}}{{if .Preview}}
+    {{.Preview}}{{end}}
+{{end}}{{if .MoreRemoved}}
+  ...and {{.MoreRemoved}} more
+{{end}}{{end}}
+View the search on Sourcegraph: {{.URL}}
 `,
 	HTML: `
-<strong>{{.ApproximateResultCount}}</strong> new search result{{.PluralResults}} found for {{.Ownership}} saved search:
+<strong>{{.Summary}}</strong> found for {{.Ownership}} saved search:
 
 <p style="padding-left: 16px">&quot;{{.Description}}&quot;</p>
-
-<p><a href="{{.URL}}">View the new result{{.PluralResults}} on Sourcegraph</a></p>
+{{if .Added}}
+<p>New results:</p>
+<ul>
+{{range .Added}}<li><a href="{{.URL}}">{{.Label}}</a>{{if .Preview}}<br><code>{{.Preview}}</code>{{end}}</li>
+{{end}}{{if .MoreAdded}}<li>...and {{.MoreAdded}} more</li>
+{{end}}</ul>
+{{end}}{{if .Removed}}
+<p>Removed results:</p>
+<ul>
+{{range .Removed}}<li>{{.Label}}{{if .Preview}}<br><code>{{.Preview}}</code>{{end}}</li>
+{{end}}{{if .MoreRemoved}}<li>...and {{.MoreRemoved}} more</li>
+{{end}}</ul>
+{{end}}
+<p><a href="{{.URL}}">View the search on Sourcegraph</a></p>
 `,
 })
 
@@ -30,11 +30,18 @@ const gqlSearchQuery = `query Search(
 			approximateResultCount
 			limitHit
 			cloning { name }
+			missing { name }
 			timedout { name }
 			results {
 				__typename
 				... on FileMatch {
 					resource
+					repository {
+						name
+					}
+					file {
+						path
+					}
 					limitHit
 					lineMatches {
 						preview
@@ -91,6 +98,9 @@ const gqlSearchQuery = `query Search(
 						message
 					}
 				}
+				... on Repository {
+					name
+				}
 			}
 			alert {
 				title
@@ -113,7 +123,9 @@ type gqlSearchResponse struct {
 		Search struct {
 			Results struct {
 				ApproximateResultCount string
+				LimitHit               bool
 				Cloning                []*api.Repo
+				Missing                []*api.Repo
 				Timedout               []*api.Repo
 				Results                []interface{}
 			}
@@ -131,16 +131,10 @@ func (e *executorT) run(ctx context.Context) error {
 // runQuery runs the given query if an appropriate amount of time has elapsed
 // since it last ran.
 func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery) error {
-	if !query.Notify && !query.NotifySlack {
+	if !query.Notify && !query.NotifySlack && query.NotifyWebhook == nil {
 		// No need to run this query because there will be nobody to notify.
 		return nil
 	}
-	if !strings.Contains(query.Query, "type:diff") && !strings.Contains(query.Query, "type:commit") {
-		// TODO(slimsag): we temporarily do not support non-commit search
-		// queries, since those do not support the after:"time" operator.
-		return nil
-	}
-
 	info, err := api.InternalClient.SavedQueriesGetInfo(ctx, query.Query)
 	if err != nil {
 		return errors.Wrap(err, "SavedQueriesGetInfo")
@@ -168,18 +162,24 @@ func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, que
 		}
 	}
 
-	// Construct a new query which finds search results introduced after the
-	// last time we queried.
-	var latestKnownResult time.Time
-	if info != nil {
-		latestKnownResult = info.LatestResult
-	} else {
-		// We've never executed this search query before, so use the current
-		// time. We'll most certainly find nothing, which is okay.
-		latestKnownResult = time.Now()
+	// For commit and diff searches, construct a new query which finds search
+	// results introduced after the last time we queried. Other searches (which
+	// do not support the after:"time" operator) are run as-is, and their new
+	// results are determined by comparing their matches with those of the
+	// previous run.
+	newQuery := query.Query
+	if isCommitSearch(query.Query) {
+		var latestKnownResult time.Time
+		if info != nil {
+			latestKnownResult = info.LatestResult
+		} else {
+			// We've never executed this search query before, so use the current
+			// time. We'll most certainly find nothing, which is okay.
+			latestKnownResult = time.Now()
+		}
+		afterTime := latestKnownResult.UTC().Format(time.RFC3339)
+		newQuery = strings.Join([]string{query.Query, fmt.Sprintf(`after:"%s"`, afterTime)}, " ")
 	}
-	afterTime := latestKnownResult.UTC().Format(time.RFC3339)
-	newQuery := strings.Join([]string{query.Query, fmt.Sprintf(`after:"%s"`, afterTime)}, " ")
 	if debugPretendSavedQueryResultsExist {
 		debugPretendSavedQueryResultsExist = false
 		newQuery = query.Query
@@ -204,17 +204,48 @@ func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, que
 		return searchErr
 	}
 
+	// Record the matches of this run, and determine which were added or
+	// removed since the previous run.
+	added, removed, err := e.recordRun(ctx, query.Query, v)
+	if err != nil {
+		return err
+	}
+
 	// Send notifications for new search results in a separate goroutine, so
 	// that we don't block other search queries from running in sequence (which
 	// is done intentionally, to ensure no overloading of searcher/gitserver).
 	go func() {
-		if err := notify(context.Background(), spec, query, newQuery, v); err != nil {
+		if err := notify(context.Background(), spec, query, newQuery, added, removed); err != nil {
 			log15.Error("executor: failed to send notifications", "error", err)
 		}
 	}()
 	return nil
 }
 
+// recordRun stores the matches of the search results of a run of the query
+// and returns the matches that were added and removed since the previous run.
+func (e *executorT) recordRun(ctx context.Context, query string, v *gqlSearchResponse) (added, removed []api.SavedQueryMatch, err error) {
+	matches, err := searchMatches(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	prev, err := api.InternalClient.SavedQueriesGetLastRun(ctx, query)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "SavedQueriesGetLastRun")
+	}
+	runMatches, added, removed := runDelta(query, prev, matches, v.Data.Search.Results.LimitHit, unavailableRepos(v))
+	if err := api.InternalClient.SavedQueriesAddRun(ctx, &api.SavedQueryRun{
+		Query:      query,
+		ExecutedAt: time.Now(),
+		Matches:    runMatches,
+		Added:      added,
+		Removed:    removed,
+	}); err != nil {
+		return nil, nil, errors.Wrap(err, "SavedQueriesAddRun")
+	}
+	return added, removed, nil
+}
+
 func performSearch(ctx context.Context, query string) (v *gqlSearchResponse, execDuration time.Duration, err error) {
 	attempts := 0
 	for {
@@ -268,12 +299,12 @@ func latestResultTime(prevInfo *api.SavedQueryInfo, v *gqlSearchResponse, search
 
 var externalURL *url.URL
 
-// notify handles sending notifications for new search results.
-func notify(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery, newQuery string, results *gqlSearchResponse) error {
-	if len(results.Data.Search.Results.Results) == 0 {
+// notify handles sending notifications for added and removed search results.
+func notify(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery, newQuery string, added, removed []api.SavedQueryMatch) error {
+	if len(added) == 0 && len(removed) == 0 {
 		return nil
 	}
-	log15.Info("sending notifications", "new_results", len(results.Data.Search.Results.Results), "description", query.Description)
+	log15.Info("sending notifications", "added", len(added), "removed", len(removed), "description", query.Description)
 
 	// Determine which users to notify.
 	recipients, err := getNotificationRecipients(ctx, spec, query)
@@ -286,13 +317,15 @@ func notify(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSave
 		spec:       spec,
 		query:      query,
 		newQuery:   newQuery,
-		results:    results,
+		added:      added,
+		removed:    removed,
 		recipients: recipients,
 	}
 
-	// Send Slack and email notifications.
+	// Send Slack, email, and webhook notifications.
 	n.slackNotify(ctx)
 	n.emailNotify(ctx)
+	n.webhookNotify(ctx)
 	return nil
 }
 
@@ -300,37 +333,89 @@ type notifier struct {
 	spec       api.SavedQueryIDSpec
 	query      api.ConfigSavedQuery
 	newQuery   string
-	results    *gqlSearchResponse
+	added      []api.SavedQueryMatch
+	removed    []api.SavedQueryMatch
 	recipients recipients
 }
 
 const (
-	utmSourceEmail = "saved-search-email"
-	utmSourceSlack = "saved-search-slack"
+	utmSourceEmail   = "saved-search-email"
+	utmSourceSlack   = "saved-search-slack"
+	utmSourceWebhook = "saved-search-webhook"
 )
 
 func searchURL(query, utmSource string) string {
+	// Construct URL to the search query.
+	u := appURL("search")
+	if u == nil {
+		return ""
+	}
+	q := u.Query()
+	q.Set("q", query)
+	q.Set("utm_source", utmSource)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// matchURL returns the URL to the commit, file, or repository of the match.
+func matchURL(m api.SavedQueryMatch, utmSource string) string {
+	p := string(m.Repository)
+	var fragment string
+	switch {
+	case m.Commit != "":
+		p += "/-/commit/" + string(m.Commit)
+	case m.Path != "":
+		p += "/-/blob/" + m.Path
+		if m.LineNumber > 0 {
+			fragment = fmt.Sprintf("L%d", m.LineNumber)
+		}
+	}
+	u := appURL(p)
+	if u == nil {
+		return ""
+	}
+	u.RawQuery = url.Values{"utm_source": []string{utmSource}}.Encode()
+	u.Fragment = fragment
+	return u.String()
+}
+
+// appURL returns the URL of the given path on the Sourcegraph instance, or nil
+// if the external URL can't be determined.
+func appURL(path string) *url.URL {
 	if externalURL == nil {
 		// Determine the external URL.
 		externalURLStr, err := api.InternalClient.ExternalURL(context.Background())
 		if err != nil {
 			log15.Error("failed to get ExternalURL", err)
-			return ""
+			return nil
 		}
 		externalURL, err = url.Parse(externalURLStr)
 		if err != nil {
 			log15.Error("failed to parse ExternalURL", err)
-			return ""
+			return nil
 		}
 	}
+	return externalURL.ResolveReference(&url.URL{Path: path})
+}
 
-	// Construct URL to the search query.
-	u := externalURL.ResolveReference(&url.URL{Path: "search"})
-	q := u.Query()
-	q.Set("q", query)
-	q.Set("utm_source", utmSource)
-	u.RawQuery = q.Encode()
-	return u.String()
+// matchLabel returns a short description of the match, such as
+// "github.com/foo/bar › path/to/file.go:12".
+func matchLabel(m api.SavedQueryMatch) string {
+	label := string(m.Repository)
+	switch {
+	case m.Commit != "":
+		commit := string(m.Commit)
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		label += "@" + commit
+	case m.Path != "":
+		label += " › " + m.Path
+		if m.LineNumber > 0 {
+			label += fmt.Sprintf(":%d", m.LineNumber)
+		}
+	}
+	return label
 }
 
 func logEvent(userID int32, email, eventName, eventType string) {
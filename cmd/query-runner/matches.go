@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// isCommitSearch reports whether the query searches commits or diffs. Such
+// queries are run with after:"<latest result time>" to find only new results;
+// other queries are run as-is and their matches are compared with those of the
+// previous run.
+func isCommitSearch(query string) bool {
+	return strings.Contains(query, "type:diff") || strings.Contains(query, "type:commit")
+}
+
+// gqlSearchResult is the subset of the fields of a search result (as
+// requested by gqlSearchQuery) that identify its matches.
+type gqlSearchResult struct {
+	Typename string `json:"__typename"`
+
+	// FileMatch
+	Repository  struct{ Name api.RepoName }
+	File        struct{ Path string }
+	LineMatches []struct {
+		Preview    string
+		LineNumber int32
+	}
+
+	// CommitSearchResult
+	Commit struct {
+		Repository struct{ Name api.RepoName }
+		OID        api.CommitID
+		Message    string
+	}
+
+	// Repository
+	Name api.RepoName
+}
+
+// searchMatches returns the matches of the search results. A file match has a
+// match for each of its lines (or one match for the file if it matched only by
+// path), and a commit or repository result is a single match.
+func searchMatches(results *gqlSearchResponse) ([]api.SavedQueryMatch, error) {
+	var matches []api.SavedQueryMatch
+	for _, result := range results.Data.Search.Results.Results {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		var r gqlSearchResult
+		if err := json.Unmarshal(b, &r); err != nil {
+			return nil, errors.Wrap(err, "Unmarshal search result")
+		}
+
+		switch r.Typename {
+		case "FileMatch":
+			if len(r.LineMatches) == 0 {
+				matches = append(matches, api.SavedQueryMatch{
+					Fingerprint: fingerprint(string(r.Repository.Name), r.File.Path),
+					Repository:  r.Repository.Name,
+					Path:        r.File.Path,
+				})
+				continue
+			}
+			// Identify a line by its contents and by how many lines with the
+			// same contents precede it, not by its line number.
+			seen := map[string]int{}
+			for _, lm := range r.LineMatches {
+				n := seen[lm.Preview]
+				seen[lm.Preview]++
+				matches = append(matches, api.SavedQueryMatch{
+					Fingerprint: fingerprint(string(r.Repository.Name), r.File.Path, lm.Preview, strconv.Itoa(n)),
+					Repository:  r.Repository.Name,
+					Path:        r.File.Path,
+					LineNumber:  lm.LineNumber + 1, // LineMatch.lineNumber is 0-based
+					Preview:     lm.Preview,
+				})
+			}
+		case "CommitSearchResult":
+			subject := r.Commit.Message
+			if i := strings.Index(subject, "\n"); i >= 0 {
+				subject = subject[:i]
+			}
+			matches = append(matches, api.SavedQueryMatch{
+				Fingerprint: fingerprint(string(r.Commit.Repository.Name), string(r.Commit.OID)),
+				Repository:  r.Commit.Repository.Name,
+				Commit:      r.Commit.OID,
+				Preview:     subject,
+			})
+		case "Repository":
+			matches = append(matches, api.SavedQueryMatch{
+				Fingerprint: fingerprint(string(r.Name)),
+				Repository:  r.Name,
+			})
+		}
+	}
+	return matches, nil
+}
+
+// fingerprint returns a hash of the parts that identify a match.
+func fingerprint(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+// diffMatches returns the matches in new whose fingerprint is not in old, and
+// vice versa, in the order of new and old, respectively.
+func diffMatches(old, new []api.SavedQueryMatch) (added, removed []api.SavedQueryMatch) {
+	fingerprints := func(matches []api.SavedQueryMatch) map[string]struct{} {
+		m := make(map[string]struct{}, len(matches))
+		for _, match := range matches {
+			m[match.Fingerprint] = struct{}{}
+		}
+		return m
+	}
+	oldFingerprints, newFingerprints := fingerprints(old), fingerprints(new)
+	for _, match := range new {
+		if _, ok := oldFingerprints[match.Fingerprint]; !ok {
+			added = append(added, match)
+		}
+	}
+	for _, match := range old {
+		if _, ok := newFingerprints[match.Fingerprint]; !ok {
+			removed = append(removed, match)
+		}
+	}
+	return added, removed
+}
+
+// unavailableRepos returns the names of the repositories that the search
+// could not fully search because they were cloning, missing, or timed out.
+func unavailableRepos(results *gqlSearchResponse) map[api.RepoName]struct{} {
+	repos := map[api.RepoName]struct{}{}
+	for _, list := range [][]*api.Repo{results.Data.Search.Results.Cloning, results.Data.Search.Results.Missing, results.Data.Search.Results.Timedout} {
+		for _, repo := range list {
+			repos[repo.Name] = struct{}{}
+		}
+	}
+	return repos
+}
+
+// runDelta returns the matches to store for a run of the saved query with the
+// given matches, and the matches to notify about, given the previous run (if
+// any). The previous matches in repositories that were unavailable to the
+// search are neither reported as removed nor dropped from the stored run, so
+// that they aren't reported as added again once the repositories are
+// searchable.
+func runDelta(query string, prev *api.SavedQueryRun, matches []api.SavedQueryMatch, limitHit bool, unavailable map[api.RepoName]struct{}) (runMatches, added, removed []api.SavedQueryMatch) {
+	if isCommitSearch(query) {
+		// The search only found results after the latest result of the previous
+		// run, so all of them are new (except for any that were also found by
+		// the previous run), and none are removed.
+		var prevMatches []api.SavedQueryMatch
+		if prev != nil {
+			prevMatches = prev.Matches
+		}
+		added, _ = diffMatches(prevMatches, matches)
+		return matches, added, nil
+	}
+
+	if prev == nil {
+		// This is the first run, so there is nothing to compare against.
+		return matches, nil, nil
+	}
+	runMatches = matches
+	added, removed = diffMatches(prev.Matches, matches)
+	if len(unavailable) > 0 {
+		var stillRemoved []api.SavedQueryMatch
+		for _, m := range removed {
+			if _, ok := unavailable[m.Repository]; ok {
+				runMatches = append(runMatches, m)
+				continue
+			}
+			stillRemoved = append(stillRemoved, m)
+		}
+		removed = stillRemoved
+	}
+	if limitHit {
+		// Matches of the previous run may be missing only because the search
+		// hit its result limit.
+		removed = nil
+	}
+	return runMatches, added, removed
+}
+
+// maxNotificationMatches is the maximum number of added (and removed) matches
+// that are listed in a notification.
+const maxNotificationMatches = 10
+
+// A notificationMatch is a match as it is listed in a notification.
+type notificationMatch struct {
+	Label   string
+	URL     string
+	Preview string
+}
+
+// notificationMatches returns the first maxNotificationMatches of the matches
+// as they are listed in a notification, and the number of matches that are
+// not listed.
+func notificationMatches(matches []api.SavedQueryMatch, utmSource string) (listed []notificationMatch, more int) {
+	if len(matches) > maxNotificationMatches {
+		more = len(matches) - maxNotificationMatches
+		matches = matches[:maxNotificationMatches]
+	}
+	for _, m := range matches {
+		preview := strings.TrimSpace(m.Preview)
+		if r := []rune(preview); len(r) > 100 {
+			preview = string(r[:100]) + "…"
+		}
+		listed = append(listed, notificationMatch{
+			Label:   matchLabel(m),
+			URL:     matchURL(m, utmSource),
+			Preview: preview,
+		})
+	}
+	return listed, more
+}
+
+// recipientNotificationMatches returns the added and removed matches as they
+// are listed in a notification to the recipient.
+//
+// 🚨 SECURITY: The search was run as an internal actor, so only matches in
+// repositories that the recipient can access are listed. If that can't be
+// determined, no matches are listed, and the notification only includes the
+// number of results and a link to the search.
+func (n *notifier) recipientNotificationMatches(ctx context.Context, recipient *recipient, utmSource string) (added []notificationMatch, moreAdded int, removed []notificationMatch, moreRemoved int) {
+	addedMatches, err := filterMatchesForSubject(ctx, recipient.subject(), n.added)
+	if err != nil {
+		log15.Error("Failed to filter saved search notification matches for recipient.", "recipient", recipient, "error", err)
+		return nil, 0, nil, 0
+	}
+	removedMatches, err := filterMatchesForSubject(ctx, recipient.subject(), n.removed)
+	if err != nil {
+		log15.Error("Failed to filter saved search notification matches for recipient.", "recipient", recipient, "error", err)
+		return nil, 0, nil, 0
+	}
+	added, moreAdded = notificationMatches(addedMatches, utmSource)
+	removed, moreRemoved = notificationMatches(removedMatches, utmSource)
+	return added, moreAdded, removed, moreRemoved
+}
+
+// resultsSummary describes the number of added and removed results, such as
+// "3 new results" or "1 new and 2 removed results".
+func resultsSummary(added, removed int) string {
+	plural := ""
+	if added+removed != 1 {
+		plural = "s"
+	}
+	switch {
+	case removed == 0:
+		return fmt.Sprintf("%d new result%s", added, plural)
+	case added == 0:
+		return fmt.Sprintf("%d removed result%s", removed, plural)
+	default:
+		return fmt.Sprintf("%d new and %d removed result%s", added, removed, plural)
+	}
+}
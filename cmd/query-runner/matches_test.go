@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+func TestSearchMatches(t *testing.T) {
+	var v gqlSearchResponse
+	if err := json.Unmarshal([]byte(`{"data": {"search": {"results": {"results": [
+		{"__typename": "FileMatch", "repository": {"name": "r"}, "file": {"path": "a.go"}, "lineMatches": [
+			{"preview": "foo()", "lineNumber": 1},
+			{"preview": "bar()", "lineNumber": 4},
+			{"preview": "foo()", "lineNumber": 9}
+		]},
+		{"__typename": "FileMatch", "repository": {"name": "r"}, "file": {"path": "foo.go"}, "lineMatches": []},
+		{"__typename": "CommitSearchResult", "commit": {"repository": {"name": "r"}, "oid": "c1", "message": "Fix foo\n\nDetails"}},
+		{"__typename": "Repository", "name": "foo"}
+	]}}}}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	matches, err := searchMatches(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type match struct {
+		repo       api.RepoName
+		commit     api.CommitID
+		path       string
+		lineNumber int32
+		preview    string
+	}
+	var got []match
+	fingerprints := map[string]struct{}{}
+	for _, m := range matches {
+		got = append(got, match{m.Repository, m.Commit, m.Path, m.LineNumber, m.Preview})
+		fingerprints[m.Fingerprint] = struct{}{}
+	}
+	want := []match{
+		{repo: "r", path: "a.go", lineNumber: 2, preview: "foo()"},
+		{repo: "r", path: "a.go", lineNumber: 5, preview: "bar()"},
+		{repo: "r", path: "a.go", lineNumber: 10, preview: "foo()"},
+		{repo: "r", path: "foo.go"},
+		{repo: "r", commit: "c1", preview: "Fix foo"},
+		{repo: "foo"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if len(fingerprints) != len(matches) {
+		t.Errorf("got %d distinct fingerprints, want %d", len(fingerprints), len(matches))
+	}
+
+	// A line match's fingerprint does not depend on its line number.
+	v.Data.Search.Results.Results[0].(map[string]interface{})["lineMatches"].([]interface{})[1].(map[string]interface{})["lineNumber"] = 6
+	moved, err := searchMatches(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved[1].Fingerprint != matches[1].Fingerprint {
+		t.Error("fingerprint changed when line number changed")
+	}
+}
+
+func TestRunDelta(t *testing.T) {
+	a := api.SavedQueryMatch{Fingerprint: "a"}
+	b := api.SavedQueryMatch{Fingerprint: "b"}
+	c := api.SavedQueryMatch{Fingerprint: "c"}
+	d := api.SavedQueryMatch{Fingerprint: "d", Repository: "d"}
+	prev := &api.SavedQueryRun{Matches: []api.SavedQueryMatch{a, b}}
+
+	tests := map[string]struct {
+		query       string
+		prev        *api.SavedQueryRun
+		matches     []api.SavedQueryMatch
+		limitHit    bool
+		unavailable map[api.RepoName]struct{}
+		wantRun     []api.SavedQueryMatch // defaults to matches
+		wantAdded   []api.SavedQueryMatch
+		wantRemoved []api.SavedQueryMatch
+	}{
+		"first run": {
+			query:   "foo",
+			matches: []api.SavedQueryMatch{a},
+		},
+		"added and removed": {
+			query:       "foo",
+			prev:        prev,
+			matches:     []api.SavedQueryMatch{b, c},
+			wantAdded:   []api.SavedQueryMatch{c},
+			wantRemoved: []api.SavedQueryMatch{a},
+		},
+		"limit hit": {
+			query:     "foo",
+			prev:      prev,
+			matches:   []api.SavedQueryMatch{b, c},
+			limitHit:  true,
+			wantAdded: []api.SavedQueryMatch{c},
+		},
+		"unavailable repository": {
+			query:       "foo",
+			prev:        &api.SavedQueryRun{Matches: []api.SavedQueryMatch{a, d}},
+			matches:     []api.SavedQueryMatch{c},
+			unavailable: map[api.RepoName]struct{}{"d": {}},
+			wantRun:     []api.SavedQueryMatch{c, d},
+			wantAdded:   []api.SavedQueryMatch{c},
+			wantRemoved: []api.SavedQueryMatch{a},
+		},
+		"commit search first run": {
+			query:     "type:diff foo",
+			matches:   []api.SavedQueryMatch{a},
+			wantAdded: []api.SavedQueryMatch{a},
+		},
+		"commit search": {
+			query:     "type:diff foo",
+			prev:      prev,
+			matches:   []api.SavedQueryMatch{b, c},
+			wantAdded: []api.SavedQueryMatch{c},
+		},
+	}
+	for name, test := range tests {
+		runMatches, added, removed := runDelta(test.query, test.prev, test.matches, test.limitHit, test.unavailable)
+		wantRun := test.wantRun
+		if wantRun == nil {
+			wantRun = test.matches
+		}
+		if !reflect.DeepEqual(runMatches, wantRun) {
+			t.Errorf("%s: got run matches %v, want %v", name, runMatches, wantRun)
+		}
+		if !reflect.DeepEqual(added, test.wantAdded) {
+			t.Errorf("%s: got added %v, want %v", name, added, test.wantAdded)
+		}
+		if !reflect.DeepEqual(removed, test.wantRemoved) {
+			t.Errorf("%s: got removed %v, want %v", name, removed, test.wantRemoved)
+		}
+	}
+}
+
+func TestResultsSummary(t *testing.T) {
+	tests := []struct {
+		added, removed int
+		want           string
+	}{
+		{1, 0, "1 new result"},
+		{3, 0, "3 new results"},
+		{0, 2, "2 removed results"},
+		{1, 1, "1 new and 1 removed results"},
+	}
+	for _, test := range tests {
+		if got := resultsSummary(test.added, test.removed); got != test.want {
+			t.Errorf("resultsSummary(%d, %d): got %q, want %q", test.added, test.removed, got, test.want)
+		}
+	}
+}
+
+func TestRecipientNotificationMatches(t *testing.T) {
+	// User 1 can access repository a, and user 2 can't be checked.
+	api.MockSavedQueriesFilterMatches = func(userID int32, matches []api.SavedQueryMatch) ([]api.SavedQueryMatch, error) {
+		if userID != 1 {
+			return nil, errors.New("x")
+		}
+		var filtered []api.SavedQueryMatch
+		for _, m := range matches {
+			if m.Repository == "a" {
+				filtered = append(filtered, m)
+			}
+		}
+		return filtered, nil
+	}
+	externalURL, _ = url.Parse("https://sourcegraph.example.com")
+	defer func() {
+		api.MockSavedQueriesFilterMatches = nil
+		externalURL = nil
+	}()
+
+	n := &notifier{
+		added:   []api.SavedQueryMatch{{Repository: "a", Path: "x", Preview: "foo"}, {Repository: "b", Path: "x", Preview: "secret"}},
+		removed: []api.SavedQueryMatch{{Repository: "b", Path: "y", Preview: "secret"}},
+	}
+
+	added, moreAdded, removed, moreRemoved := n.recipientNotificationMatches(context.Background(), &recipient{spec: recipientSpec{userID: 1}}, utmSourceEmail)
+	if want := []notificationMatch{{Label: "a › x", URL: "https://sourcegraph.example.com/a/-/blob/x?utm_source=saved-search-email", Preview: "foo"}}; !reflect.DeepEqual(added, want) || moreAdded != 0 {
+		t.Errorf("user 1: got added %+v (%d more), want %+v", added, moreAdded, want)
+	}
+	if len(removed) != 0 || moreRemoved != 0 {
+		t.Errorf("user 1: got removed %+v (%d more), want none", removed, moreRemoved)
+	}
+
+	added, moreAdded, removed, moreRemoved = n.recipientNotificationMatches(context.Background(), &recipient{spec: recipientSpec{userID: 2}}, utmSourceEmail)
+	if len(added) != 0 || moreAdded != 0 || len(removed) != 0 || moreRemoved != 0 {
+		t.Errorf("user 2: got added %+v (%d more) and removed %+v (%d more), want none", added, moreAdded, removed, moreRemoved)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	log15 "gopkg.in/inconshreveable/log15.v2"
 
@@ -11,18 +12,17 @@ import (
 )
 
 func (n *notifier) slackNotify(ctx context.Context) {
-	plural := ""
-	if n.results.Data.Search.Results.ApproximateResultCount != "1" {
-		plural = "s"
-	}
-
-	text := fmt.Sprintf(`*%s* new result%s found for saved search <%s|"%s">`,
-		n.results.Data.Search.Results.ApproximateResultCount,
-		plural,
+	summary := fmt.Sprintf(`*%s* found for saved search <%s|"%s">`,
+		resultsSummary(len(n.added), len(n.removed)),
 		searchURL(n.newQuery, utmSourceSlack),
 		n.query.Description,
 	)
 	for _, recipient := range n.recipients {
+		if !recipient.slack {
+			continue
+		}
+		added, moreAdded, removed, moreRemoved := n.recipientNotificationMatches(ctx, recipient, utmSourceSlack)
+		text := summary + slackMatchList("New results", added, moreAdded) + slackMatchList("Removed results", removed, moreRemoved)
 		if err := slackNotify(ctx, recipient, text); err != nil {
 			log15.Error("Failed to post Slack notification message.", "recipient", recipient, "text", text, "error", err)
 		}
@@ -31,6 +31,29 @@ func (n *notifier) slackNotify(ctx context.Context) {
 	logEvent(0, "", "SavedSearchSlackNotificationSent", "results")
 }
 
+// slackEscaper escapes the characters that Slack interprets as control
+// characters, and backticks (which would end the code span of a preview).
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "`", "'")
+
+// slackMatchList formats the matches as a list in a Slack message, or returns
+// "" if there are none.
+func slackMatchList(title string, matches []notificationMatch, more int) string {
+	if len(matches) == 0 {
+		return ""
+	}
+	text := fmt.Sprintf("\n\n*%s:*", title)
+	for _, m := range matches {
+		text += fmt.Sprintf("\n• <%s|%s>", m.URL, m.Label)
+		if m.Preview != "" {
+			text += fmt.Sprintf(" `%s`", slackEscaper.Replace(m.Preview))
+		}
+	}
+	if more > 0 {
+		text += fmt.Sprintf("\n…and %d more", more)
+	}
+	return text
+}
+
 func slackNotifySubscribed(ctx context.Context, recipient *recipient, query api.SavedQuerySpecAndConfig) error {
 	text := fmt.Sprintf(`Slack notifications enabled for the saved search <%s|"%s">. Notifications will be sent here when new results are available.`,
 		searchURL(query.Config.Query, utmSourceSlack),
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/schema"
+	"golang.org/x/net/context/ctxhttp"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// The events of webhook notifications, sent in the X-Sourcegraph-Event header
+// and the "event" field of the payload.
+const (
+	webhookEventResults = "saved_search.results" // new or removed results
+	webhookEventTest    = "saved_search.test"    // a test notification
+)
+
+const (
+	// maxWebhookAttempts is the number of attempts that are made to deliver a
+	// webhook notification before giving up.
+	maxWebhookAttempts = 5
+
+	// maxWebhookMatches is the maximum number of added (and removed) matches
+	// that are included in the payload of a webhook notification.
+	maxWebhookMatches = 1000
+
+	// maxWebhookResponseBody is the number of bytes of the response body that
+	// are recorded in the delivery history.
+	maxWebhookResponseBody = 1024
+)
+
+var (
+	// webhookBackoff is the delay before the first retry of a failed webhook
+	// delivery. The delay doubles after each retry.
+	webhookBackoff = 2 * time.Second
+
+	// webhookClient is the client that webhook notifications are sent with.
+	//
+	// 🚨 SECURITY: It doesn't use a proxy and it refuses to connect to
+	// loopback, private, and link-local addresses, so that webhooks can't be
+	// used to make requests to services on the internal network. The addresses
+	// are checked when connecting (not when the URL is validated), so a host
+	// name that resolves to such an address is refused too, including after a
+	// redirect.
+	webhookClient = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{
+				Timeout: 5 * time.Second,
+				Control: webhookDialControl,
+			}).DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+	}
+
+	// isAllowedWebhookIP reports whether webhook notifications may be sent to
+	// the IP address. It is a variable so that tests can send notifications
+	// to local test servers.
+	isAllowedWebhookIP = isPublicIP
+)
+
+// webhookDialControl refuses connections to IP addresses that are not allowed
+// for webhook notifications.
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isAllowedWebhookIP(ip) {
+		return fmt.Errorf("refusing to send a webhook notification to the non-public address %s", host)
+	}
+	return nil
+}
+
+// nonPublicNetworks are the networks (besides loopback, link-local, and
+// multicast addresses) that webhook notifications are not sent to.
+var nonPublicNetworks = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",      // "this" network
+		"10.0.0.0/8",     // private
+		"100.64.0.0/10",  // carrier-grade NAT
+		"172.16.0.0/12",  // private
+		"192.168.0.0/16", // private
+		"fc00::/7",       // unique local
+	} {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+// isPublicIP reports whether ip is a public unicast address.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, n := range nonPublicNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// webhookPayload is the JSON payload of a webhook notification.
+type webhookPayload struct {
+	Event       string                `json:"event"`
+	SavedSearch webhookSavedSearch    `json:"savedSearch"`
+	Summary     string                `json:"summary,omitempty"`
+	URL         string                `json:"url"` // the URL of the search
+	Added       []webhookPayloadMatch `json:"added"`
+	Removed     []webhookPayloadMatch `json:"removed"`
+	Counts      webhookPayloadCounts  `json:"counts"`
+}
+
+type webhookSavedSearch struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	Query       string `json:"query"`
+}
+
+type webhookPayloadMatch struct {
+	Repository string `json:"repository"`
+	Commit     string `json:"commit,omitempty"`
+	Path       string `json:"path,omitempty"`
+	LineNumber int32  `json:"lineNumber,omitempty"`
+	Preview    string `json:"preview,omitempty"`
+	URL        string `json:"url"`
+}
+
+// webhookPayloadCounts are the total numbers of added and removed matches,
+// which may be more than the number of matches in the payload.
+type webhookPayloadCounts struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+// newWebhookPayload returns the payload of a webhook notification about the
+// added and removed matches of the saved query.
+func newWebhookPayload(event string, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery, newQuery string, added, removed []api.SavedQueryMatch) *webhookPayload {
+	payloadMatches := func(matches []api.SavedQueryMatch) []webhookPayloadMatch {
+		if len(matches) > maxWebhookMatches {
+			matches = matches[:maxWebhookMatches]
+		}
+		pms := make([]webhookPayloadMatch, 0, len(matches))
+		for _, m := range matches {
+			pms = append(pms, webhookPayloadMatch{
+				Repository: string(m.Repository),
+				Commit:     string(m.Commit),
+				Path:       m.Path,
+				LineNumber: m.LineNumber,
+				Preview:    m.Preview,
+				URL:        matchURL(m, utmSourceWebhook),
+			})
+		}
+		return pms
+	}
+
+	p := &webhookPayload{
+		Event: event,
+		SavedSearch: webhookSavedSearch{
+			Key:         spec.Key,
+			Description: query.Description,
+			Query:       query.Query,
+		},
+		URL:     searchURL(newQuery, utmSourceWebhook),
+		Added:   payloadMatches(added),
+		Removed: payloadMatches(removed),
+		Counts:  webhookPayloadCounts{Added: len(added), Removed: len(removed)},
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		p.Summary = resultsSummary(len(added), len(removed))
+	}
+	return p
+}
+
+// webhookSignature returns the value of the X-Sourcegraph-Signature header of
+// a webhook request with the given body.
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverWebhook sends the payload to the webhook (signed with the secret, if
+// any), retrying with exponential backoff if the request fails or the response
+// has a 5xx or 429 status code. It returns the delivery to record in the saved
+// query's delivery history.
+func deliverWebhook(ctx context.Context, webhook *schema.SavedQueryWebhook, secret string, payload *webhookPayload) *api.SavedQueryWebhookDelivery {
+	delivery := &api.SavedQueryWebhookDelivery{
+		Event: payload.Event,
+		URL:   webhook.Url,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	backoff := webhookBackoff
+	for {
+		delivery.Attempts++
+		retry := attemptWebhookDelivery(ctx, webhook, secret, payload.Event, body, delivery)
+		if !retry || delivery.Attempts == maxWebhookAttempts {
+			return delivery
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			delivery.Error = ctx.Err().Error()
+			return delivery
+		}
+		backoff *= 2
+	}
+}
+
+// attemptWebhookDelivery makes a single request to deliver the webhook
+// notification, records its outcome in delivery, and reports whether the
+// request should be retried.
+func attemptWebhookDelivery(ctx context.Context, webhook *schema.SavedQueryWebhook, secret, event string, body []byte, delivery *api.SavedQueryWebhookDelivery) (retry bool) {
+	delivery.StatusCode, delivery.ResponseBody, delivery.Error = 0, "", ""
+
+	req, err := http.NewRequest("POST", webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Sourcegraph-Webhook")
+	req.Header.Set("X-Sourcegraph-Event", event)
+	if secret != "" {
+		req.Header.Set("X-Sourcegraph-Signature", webhookSignature(secret, body))
+	}
+
+	resp, err := ctxhttp.Do(ctx, webhookClient, req)
+	if err != nil {
+		delivery.Error = err.Error()
+		return true
+	}
+	defer resp.Body.Close()
+	respBody, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBody))
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.ResponseBody = string(respBody)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false
+	}
+	delivery.Error = fmt.Sprintf("unexpected HTTP response status %d", resp.StatusCode)
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// sendWebhookNotification delivers the payload to the saved query's webhook
+// and records the delivery in the saved query's delivery history.
+func sendWebhookNotification(ctx context.Context, spec api.SavedQueryIDSpec, webhook *schema.SavedQueryWebhook, payload *webhookPayload) error {
+	secret, err := api.InternalClient.SavedQueriesGetWebhookSecret(ctx, spec)
+	if err != nil {
+		return errors.Wrap(err, "SavedQueriesGetWebhookSecret")
+	}
+	delivery := deliverWebhook(ctx, webhook, secret, payload)
+	delivery.Spec = spec
+	if err := api.InternalClient.SavedQueriesAddWebhookDelivery(ctx, delivery); err != nil {
+		log15.Error("Failed to record webhook delivery for saved search.", "spec", spec, "error", err)
+	}
+	if delivery.Error != "" {
+		return fmt.Errorf("webhook delivery to %s failed after %d attempts: %s", delivery.URL, delivery.Attempts, delivery.Error)
+	}
+	logEvent(0, "", "SavedSearchWebhookNotificationSent", payload.Event)
+	return nil
+}
+
+func (n *notifier) webhookNotify(ctx context.Context) {
+	if n.query.NotifyWebhook == nil {
+		return
+	}
+
+	// Deliver the webhook notification asynchronously, because retries may
+	// take a while.
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		if err := n.sendWebhookNotification(ctx); err != nil {
+			log15.Error("Failed to send webhook notification for saved search.", "spec", n.spec, "error", err)
+		}
+	}()
+}
+
+func (n *notifier) sendWebhookNotification(ctx context.Context) error {
+	// 🚨 SECURITY: The search was run as an internal actor, and the webhook
+	// URL can be set by anyone who can edit the saved query, so only send the
+	// matches in repositories that all of them can access.
+	added, err := filterMatchesForSubject(ctx, n.spec.Subject, n.added)
+	if err != nil {
+		return err
+	}
+	removed, err := filterMatchesForSubject(ctx, n.spec.Subject, n.removed)
+	if err != nil {
+		return err
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	payload := newWebhookPayload(webhookEventResults, n.spec, n.query, n.newQuery, added, removed)
+	return sendWebhookNotification(ctx, n.spec, n.query.NotifyWebhook, payload)
+}
+
+// filterMatchesForSubject returns the matches in repositories that can be
+// accessed by all users who can edit the settings of the subject: the user
+// themselves, or all members of the org. Global settings can only be edited by
+// site admins, who can access all repositories.
+func filterMatchesForSubject(ctx context.Context, subject api.SettingsSubject, matches []api.SavedQueryMatch) ([]api.SavedQueryMatch, error) {
+	switch {
+	case subject.User != nil:
+		return api.InternalClient.SavedQueriesFilterMatches(ctx, *subject.User, matches)
+	case subject.Org != nil:
+		members, err := api.InternalClient.OrgsListUsers(ctx, *subject.Org)
+		if err != nil {
+			return nil, errors.Wrap(err, "OrgsListUsers")
+		}
+		if len(members) == 0 {
+			return nil, nil
+		}
+		for _, userID := range members {
+			if matches, err = api.InternalClient.SavedQueriesFilterMatches(ctx, userID, matches); err != nil {
+				return nil, errors.Wrap(err, "SavedQueriesFilterMatches")
+			}
+		}
+		return matches, nil
+	default:
+		return matches, nil
+	}
+}
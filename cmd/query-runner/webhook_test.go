@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestNewWebhookPayload(t *testing.T) {
+	externalURL, _ = url.Parse("https://sourcegraph.example.com")
+	defer func() { externalURL = nil }()
+
+	added := []api.SavedQueryMatch{{Repository: "r", Path: "a.go", LineNumber: 3, Preview: "foo()"}}
+	removed := []api.SavedQueryMatch{{Repository: "r", Commit: "c1", Preview: "Fix foo"}}
+	p := newWebhookPayload(webhookEventResults, api.SavedQueryIDSpec{Key: "k"}, api.ConfigSavedQuery{Description: "d", Query: "foo"}, "foo", added, removed)
+
+	got, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"event":"saved_search.results","savedSearch":{"key":"k","description":"d","query":"foo"},"summary":"1 new and 1 removed results","url":"https://sourcegraph.example.com/search?q=foo\u0026utm_source=saved-search-webhook",` +
+		`"added":[{"repository":"r","path":"a.go","lineNumber":3,"preview":"foo()","url":"https://sourcegraph.example.com/r/-/blob/a.go?utm_source=saved-search-webhook#L3"}],` +
+		`"removed":[{"repository":"r","commit":"c1","preview":"Fix foo","url":"https://sourcegraph.example.com/r/-/commit/c1?utm_source=saved-search-webhook"}],` +
+		`"counts":{"added":1,"removed":1}}`
+	if string(got) != want {
+		t.Errorf("got payload\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDeliverWebhook(t *testing.T) {
+	defer func(d time.Duration) { webhookBackoff = d }(webhookBackoff)
+	webhookBackoff = time.Millisecond
+
+	payload := &webhookPayload{Event: webhookEventTest}
+
+	t.Run("refuse non-public addresses", func(t *testing.T) {
+		requested := false
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested = true
+		}))
+		defer s.Close()
+
+		d := deliverWebhook(context.Background(), &schema.SavedQueryWebhook{Url: s.URL}, "", payload)
+		if requested || !strings.Contains(d.Error, "non-public address") {
+			t.Errorf("got delivery %+v to loopback address, want it refused", d)
+		}
+	})
+
+	defer func(f func(net.IP) bool) { isAllowedWebhookIP = f }(isAllowedWebhookIP)
+	isAllowedWebhookIP = func(net.IP) bool { return true } // allow test servers
+
+	t.Run("signed", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			if got, want := r.Header.Get("X-Sourcegraph-Signature"), webhookSignature("s3cr3t", body); got != want {
+				t.Errorf("got signature %q, want %q", got, want)
+			}
+			if got, want := r.Header.Get("X-Sourcegraph-Event"), webhookEventTest; got != want {
+				t.Errorf("got event %q, want %q", got, want)
+			}
+			w.Write([]byte("ok"))
+		}))
+		defer s.Close()
+
+		d := deliverWebhook(context.Background(), &schema.SavedQueryWebhook{Url: s.URL}, "s3cr3t", payload)
+		if d.Error != "" || d.StatusCode != 200 || d.ResponseBody != "ok" || d.Attempts != 1 {
+			t.Errorf("got delivery %+v", d)
+		}
+	})
+
+	t.Run("retry server errors", func(t *testing.T) {
+		requests := 0
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Sourcegraph-Signature") != "" {
+				t.Error("unexpected signature without secret")
+			}
+			requests++
+			if requests < 3 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			}
+		}))
+		defer s.Close()
+
+		d := deliverWebhook(context.Background(), &schema.SavedQueryWebhook{Url: s.URL}, "", payload)
+		if d.Error != "" || d.StatusCode != 200 || d.Attempts != 3 {
+			t.Errorf("got delivery %+v", d)
+		}
+	})
+
+	t.Run("give up", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}))
+		defer s.Close()
+
+		d := deliverWebhook(context.Background(), &schema.SavedQueryWebhook{Url: s.URL}, "", payload)
+		if d.Error == "" || d.StatusCode != 500 || d.Attempts != maxWebhookAttempts {
+			t.Errorf("got delivery %+v", d)
+		}
+	})
+
+	t.Run("no retry for client errors", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad request", http.StatusBadRequest)
+		}))
+		defer s.Close()
+
+		d := deliverWebhook(context.Background(), &schema.SavedQueryWebhook{Url: s.URL}, "", payload)
+		if d.Error == "" || d.StatusCode != 400 || d.ResponseBody != "bad request\n" || d.Attempts != 1 {
+			t.Errorf("got delivery %+v", d)
+		}
+	})
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for ip, want := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.20.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"fd00::1":         false,
+		"fe80::1":         false,
+		"::ffff:10.0.0.1": false,
+	} {
+		if got := isPublicIP(net.ParseIP(ip)); got != want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", ip, got, want)
+		}
+	}
+}
+
+func TestFilterMatchesForSubject(t *testing.T) {
+	// User 1 can access repositories a and b, and user 2 can access b and c.
+	canAccess := map[int32]map[api.RepoName]bool{
+		1: {"a": true, "b": true},
+		2: {"b": true, "c": true},
+	}
+	api.MockSavedQueriesFilterMatches = func(userID int32, matches []api.SavedQueryMatch) ([]api.SavedQueryMatch, error) {
+		var filtered []api.SavedQueryMatch
+		for _, m := range matches {
+			if canAccess[userID][m.Repository] {
+				filtered = append(filtered, m)
+			}
+		}
+		return filtered, nil
+	}
+	api.MockOrgsListUsers = func(orgID int32) ([]int32, error) {
+		if orgID == 1 {
+			return []int32{1, 2}, nil
+		}
+		return nil, nil
+	}
+	defer func() {
+		api.MockSavedQueriesFilterMatches = nil
+		api.MockOrgsListUsers = nil
+	}()
+
+	matches := []api.SavedQueryMatch{{Repository: "a"}, {Repository: "b"}, {Repository: "c"}}
+	userID, orgID, emptyOrgID := int32(1), int32(1), int32(2)
+	tests := map[string]struct {
+		subject api.SettingsSubject
+		want    []api.SavedQueryMatch
+	}{
+		"user":      {api.SettingsSubject{User: &userID}, []api.SavedQueryMatch{{Repository: "a"}, {Repository: "b"}}},
+		"org":       {api.SettingsSubject{Org: &orgID}, []api.SavedQueryMatch{{Repository: "b"}}},
+		"empty org": {api.SettingsSubject{Org: &emptyOrgID}, nil},
+		"site":      {api.SettingsSubject{Site: true}, matches},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := filterMatchesForSubject(context.Background(), test.subject, matches)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/comby"
 	"github.com/sourcegraph/sourcegraph/pkg/store"
 	"gopkg.in/inconshreveable/log15.v2"
 
@@ -52,7 +53,7 @@ type ExternalTool struct {
 func (t *ExternalTool) command(spec *protocol.RewriteSpecification, zipPath string) (cmd *exec.Cmd, err error) {
 	switch t.Name {
 	case "comby":
-		return exec.Command(t.BinaryPath, spec.MatchTemplate, spec.RewriteTemplate, spec.FileExtension, "-zip", zipPath, "-json-lines"), nil
+		return exec.Command(t.BinaryPath, comby.RewriteArgs(spec.MatchTemplate, spec.RewriteTemplate, spec.FileExtension, zipPath)...), nil
 	default:
 		return nil, errors.Errorf("Unknown external replace tool %q", t.Name)
 	}
@@ -196,7 +197,7 @@ func (s *Service) replace(ctx context.Context, p *protocol.Request, w http.Respo
 
 	t := &ExternalTool{
 		Name:       "comby",
-		BinaryPath: "comby",
+		BinaryPath: comby.BinaryPath,
 	}
 
 	cmd, err := t.command(&p.RewriteSpecification, zipPath)
@@ -0,0 +1,149 @@
+package repos
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+	gitserverprotocol "github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// CloneOptions returns the options to clone and fetch the repo with, which are
+// configured in the `cloneOptions` and `maxRepositorySizeMB` of the external
+// services it belongs to. If
+// several of them configure options for the repo, the ones of the source with
+// the smallest ID win.
+//
+// The returned value is never nil, so that gitserver stops using options that
+// have been removed from the configuration.
+func (r *Repo) CloneOptions() *gitserverprotocol.CloneOptions {
+	ids := make([]string, 0, len(r.Sources))
+	for id, src := range r.Sources {
+		if src != nil && src.CloneOptions != nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return &gitserverprotocol.CloneOptions{}
+	}
+	sort.Strings(ids)
+	return r.Sources[ids[0]].CloneOptions
+}
+
+// setCloneOptions sets the CloneOptions of the given repos' SourceInfo for
+// each of the given external services that configures `cloneOptions` or
+// `maxRepositorySizeMB`.
+func setCloneOptions(svcs ExternalServices, repos []*Repo) error {
+	if len(repos) == 0 {
+		return nil
+	}
+
+	for _, svc := range svcs {
+		m, err := newCloneOptionsMatcher(svc)
+		if err != nil {
+			return errors.Wrapf(err, "external service id=%d", svc.ID)
+		}
+		if m == nil {
+			continue
+		}
+
+		urn := svc.URN()
+		for _, r := range repos {
+			if src := r.Sources[urn]; src != nil {
+				src.CloneOptions = m.match(r.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// cloneOptionsMatcher matches repository names against the `repos` of the
+// `cloneOptions` of an external service.
+type cloneOptionsMatcher struct {
+	items []cloneOptionsItem
+
+	// defaults are the options of repositories that match no item, or nil.
+	defaults *gitserverprotocol.CloneOptions
+}
+
+type cloneOptionsItem struct {
+	repos []*regexp.Regexp
+	opts  *gitserverprotocol.CloneOptions
+}
+
+// newCloneOptionsMatcher returns a matcher for the `cloneOptions` and
+// `maxRepositorySizeMB` of the given external service, or nil if it doesn't
+// configure any.
+func newCloneOptionsMatcher(svc *ExternalService) (*cloneOptionsMatcher, error) {
+	cfg, err := svc.Configuration()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		items     []*schema.CloneOptions
+		maxSizeMB int
+	)
+	switch c := cfg.(type) {
+	case *schema.AWSCodeCommitConnection:
+		items, maxSizeMB = c.CloneOptions, c.MaxRepositorySizeMB
+	case *schema.BitbucketServerConnection:
+		items, maxSizeMB = c.CloneOptions, c.MaxRepositorySizeMB
+	case *schema.GitHubConnection:
+		items, maxSizeMB = c.CloneOptions, c.MaxRepositorySizeMB
+	case *schema.GitLabConnection:
+		items, maxSizeMB = c.CloneOptions, c.MaxRepositorySizeMB
+	case *schema.GitoliteConnection:
+		items, maxSizeMB = c.CloneOptions, c.MaxRepositorySizeMB
+	case *schema.OtherExternalServiceConnection:
+		items, maxSizeMB = c.CloneOptions, c.MaxRepositorySizeMB
+	}
+	if len(items) == 0 && maxSizeMB == 0 {
+		return nil, nil
+	}
+
+	m := &cloneOptionsMatcher{}
+	if maxSizeMB > 0 {
+		m.defaults = &gitserverprotocol.CloneOptions{MaxSize: megabytes(maxSizeMB)}
+	}
+	m.items = make([]cloneOptionsItem, len(items))
+	for i, item := range items {
+		for _, pattern := range item.Repos {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cloneOptions[%d]", i)
+			}
+			m.items[i].repos = append(m.items[i].repos, re)
+		}
+		m.items[i].opts = &gitserverprotocol.CloneOptions{
+			Filter:  item.Filter,
+			Refs:    item.Refs,
+			Depth:   item.Depth,
+			MaxSize: megabytes(maxSizeMB),
+		}
+		if item.MaxSizeMB > 0 {
+			m.items[i].opts.MaxSize = megabytes(item.MaxSizeMB)
+		}
+	}
+	return m, nil
+}
+
+// match returns the options of the first item that matches the repository
+// name, or the defaults if none does.
+func (m *cloneOptionsMatcher) match(name string) *gitserverprotocol.CloneOptions {
+	for _, item := range m.items {
+		for _, re := range item.repos {
+			if re.MatchString(name) {
+				return item.opts
+			}
+		}
+	}
+	return m.defaults
+}
+
+// megabytes returns the number of bytes in n MB (where 1 MB is 1024 * 1024
+// bytes).
+func megabytes(n int) int64 {
+	return int64(n) * 1024 * 1024
+}
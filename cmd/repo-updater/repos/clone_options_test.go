@@ -0,0 +1,50 @@
+package repos
+
+import (
+	"reflect"
+	"testing"
+
+	gitserverprotocol "github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+)
+
+func TestSetCloneOptions(t *testing.T) {
+	svc := &ExternalService{
+		ID:   1,
+		Kind: "GITHUB",
+		Config: `{
+			"url": "https://github.com",
+			"token": "secret",
+			"repositoryQuery": ["none"],
+			"maxRepositorySizeMB": 1000,
+			"cloneOptions": [
+				// The first matching item wins.
+				{"repos": ["^github\\.com/foo/monorepo$"], "filter": "blob:limit=1m", "refs": ["HEAD", "refs/tags/v*"]},
+				{"repos": ["^github\\.com/foo/"], "depth": 10, "maxSizeMB": 5000}
+			]
+		}`,
+	}
+	newRepo := func(name string) *Repo {
+		return &Repo{
+			Name:    name,
+			Sources: map[string]*SourceInfo{svc.URN(): {ID: svc.URN(), CloneURL: "https://" + name}},
+		}
+	}
+	monorepo, foo, bar := newRepo("github.com/foo/monorepo"), newRepo("github.com/foo/foo"), newRepo("github.com/bar/bar")
+
+	if err := setCloneOptions(ExternalServices{svc}, []*Repo{monorepo, foo, bar}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		repo *Repo
+		want *gitserverprotocol.CloneOptions
+	}{
+		{monorepo, &gitserverprotocol.CloneOptions{Filter: "blob:limit=1m", Refs: []string{"HEAD", "refs/tags/v*"}, MaxSize: 1000 << 20}},
+		{foo, &gitserverprotocol.CloneOptions{Depth: 10, MaxSize: 5000 << 20}},
+		{bar, &gitserverprotocol.CloneOptions{MaxSize: 1000 << 20}},
+	} {
+		if got := tc.repo.CloneOptions(); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got clone options %+v, want %+v", tc.repo.Name, got, tc.want)
+		}
+	}
+}
@@ -116,6 +116,10 @@ type configuredRepo2 struct {
 	ID      uint32
 	Name    api.RepoName
 	Enabled bool
+
+	// CloneOptions are the options to clone and fetch the repo with. If nil,
+	// gitserver uses the options that the repo was cloned with.
+	CloneOptions *gitserverprotocol.CloneOptions
 }
 
 // sourceRepoMap is the set of repositories associated with a specific configuration source.
@@ -221,7 +225,7 @@ func (s *updateScheduler) runUpdateLoop(ctx context.Context) {
 
 // requestRepoUpdate sends a request to gitserver to request an update.
 var requestRepoUpdate = func(ctx context.Context, repo *configuredRepo2, since time.Duration) (*gitserverprotocol.RepoUpdateResponse, error) {
-	return gitserver.DefaultClient.RequestRepoUpdate(ctx, gitserver.Repo{Name: repo.Name, URL: repo.URL}, since)
+	return gitserver.DefaultClient.RequestRepoUpdate(ctx, gitserver.Repo{Name: repo.Name, URL: repo.URL, CloneOptions: repo.CloneOptions}, since)
 }
 
 // configuredLimiter returns a mutable limiter that is
@@ -281,9 +285,10 @@ func (s *updateScheduler) remove(r *Repo) {
 
 func configuredRepo2FromRepo(r *Repo) *configuredRepo2 {
 	repo := configuredRepo2{
-		ID:      r.ID,
-		Name:    api.RepoName(r.Name),
-		Enabled: r.Enabled,
+		ID:           r.ID,
+		Name:         api.RepoName(r.Name),
+		Enabled:      r.Enabled,
+		CloneOptions: r.CloneOptions(),
 	}
 
 	if urls := r.CloneURLs(); len(urls) > 0 {
@@ -329,11 +334,12 @@ func (s *updateScheduler) updateSource(source string, newList sourceRepoMap) {
 
 // UpdateOnce causes a single update of the given repository.
 // It neither adds nor removes the repo from the schedule.
-func (s *updateScheduler) UpdateOnce(id uint32, name api.RepoName, url string) {
+func (s *updateScheduler) UpdateOnce(id uint32, name api.RepoName, url string, opts *gitserverprotocol.CloneOptions) {
 	repo := &configuredRepo2{
-		ID:   id,
-		Name: name,
-		URL:  url,
+		ID:           id,
+		Name:         name,
+		URL:          url,
+		CloneOptions: opts,
 	}
 	schedManualFetch.Inc()
 	s.updateQueue.enqueue(repo, priorityHigh)
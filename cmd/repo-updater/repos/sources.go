@@ -153,6 +153,8 @@ func (srcs Sources) ListRepos(ctx context.Context) ([]*Repo, error) {
 			for _, src := range sources {
 				if repos, err := src.ListRepos(ctx); err != nil {
 					ch <- result{src: src, err: err}
+				} else if err := setCloneOptions(src.ExternalServices(), repos); err != nil {
+					ch <- result{src: src, err: err}
 				} else {
 					ch <- result{src: src, repos: repos}
 				}
@@ -277,6 +279,7 @@ func githubRepoToRepo(
 		URI:          string(reposource.GitHubRepoName("", conn.originalHostname, ghrepo.NameWithOwner)),
 		ExternalRepo: *github.ExternalRepoSpec(ghrepo, *conn.baseURL),
 		Description:  ghrepo.Description,
+		Language:     ghrepo.PrimaryLanguage.Name,
 		Fork:         ghrepo.IsFork,
 		Enabled:      true,
 		Archived:     ghrepo.IsArchived,
@@ -10,6 +10,7 @@ import (
 
 	"github.com/keegancsmith/sqlf"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc/awscodecommit"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
@@ -37,6 +38,8 @@ type StoreListReposArgs struct {
 	IDs []uint32
 	// Kinds of repos to list. When zero-valued, this is omitted from the predicate set.
 	Kinds []string
+	// ExternalRepos of repos to list. When zero-valued, this is omitted from the predicate set.
+	ExternalRepos []api.ExternalRepoSpec
 	// Limit the total number of repos returned. Zero means no limit
 	Limit int64
 	// PerPage determines the number of repos returned on each page. Zero means it defaults to 10000.
@@ -336,6 +339,15 @@ func listReposQuery(args StoreListReposArgs) paginatedQuery {
 			sqlf.Sprintf("LOWER(external_service_type) IN (%s)", sqlf.Join(ks, ",")))
 	}
 
+	if len(args.ExternalRepos) > 0 {
+		ers := make([]*sqlf.Query, 0, len(args.ExternalRepos))
+		for _, spec := range args.ExternalRepos {
+			ers = append(ers, sqlf.Sprintf("(%s, %s, %s)", spec.ServiceType, spec.ServiceID, spec.ID))
+		}
+		preds = append(preds,
+			sqlf.Sprintf("(external_service_type, external_service_id, external_id) IN (%s)", sqlf.Join(ers, ",")))
+	}
+
 	preds = append(preds, sqlf.Sprintf("deleted_at IS NULL"))
 
 	return func(cursor, limit int64) *sqlf.Query {
@@ -754,6 +754,17 @@ func testStoreListRepos(store repos.Store) func(*testing.T) {
 		repos: repos.Assert.ReposEqual(repositories[:2].Clone()...),
 	})
 
+	testCases = append(testCases, testCase{
+		name:   "returns repos by their external repo specs",
+		stored: repositories,
+		args: func(repos.Repos) repos.StoreListReposArgs {
+			return repos.StoreListReposArgs{
+				ExternalRepos: []api.ExternalRepoSpec{github.ExternalRepo, gitlab.ExternalRepo},
+			}
+		},
+		repos: repos.Assert.ReposEqual(&github, &gitlab),
+	})
+
 	testCases = append(testCases, testCase{
 		name:   "limits repos to the given kinds",
 		stored: repositories,
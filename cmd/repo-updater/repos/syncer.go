@@ -18,10 +18,11 @@ import (
 // A Syncer periodically synchronizes available repositories from all its given Sources
 // with the stored Repositories in Sourcegraph.
 type Syncer struct {
-	store   Store
-	sourcer Sourcer
-	diffs   chan Diff
-	now     func() time.Time
+	store     Store
+	sourcer   Sourcer
+	diffs     chan Diff
+	now       func() time.Time
+	triggered chan struct{}
 }
 
 // NewSyncer returns a new Syncer that syncs stored repos with
@@ -34,25 +35,42 @@ func NewSyncer(
 	now func() time.Time,
 ) *Syncer {
 	return &Syncer{
-		store:   store,
-		sourcer: sourcer,
-		diffs:   diffs,
-		now:     now,
+		store:     store,
+		sourcer:   sourcer,
+		diffs:     diffs,
+		now:       now,
+		triggered: make(chan struct{}, 1),
 	}
 }
 
 // Run runs the Sync at the specified interval for the given external service kinds.
+// A Sync also runs as soon as possible after TriggerSync is called.
 func (s *Syncer) Run(ctx context.Context, interval time.Duration, kinds ...string) error {
 	for ctx.Err() == nil {
 		if _, err := s.Sync(ctx, kinds...); err != nil {
 			log15.Error("Syncer", "error", err)
 		}
-		time.Sleep(interval)
+
+		select {
+		case <-time.After(interval):
+		case <-s.triggered:
+		case <-ctx.Done():
+		}
 	}
 
 	return ctx.Err()
 }
 
+// TriggerSync causes Run to sync again without waiting for the rest of its
+// interval (e.g. because a webhook reported that repositories were created or
+// renamed). Multiple triggers before the next sync result in a single sync.
+func (s *Syncer) TriggerSync() {
+	select {
+	case s.triggered <- struct{}{}:
+	default:
+	}
+}
+
 // Sync synchronizes the repositories of the given external service kinds.
 func (s *Syncer) Sync(ctx context.Context, kinds ...string) (diff Diff, err error) {
 	ctx, save := s.observe(ctx, "Syncer.Sync", strings.Join(kinds, " "))
@@ -194,13 +194,19 @@ func (s FakeStore) ListRepos(ctx context.Context, args StoreListReposArgs) ([]*R
 		ids[id] = true
 	}
 
+	externalRepos := make(map[api.ExternalRepoSpec]bool, len(args.ExternalRepos))
+	for _, spec := range args.ExternalRepos {
+		externalRepos[spec] = true
+	}
+
 	set := make(map[*Repo]bool, len(s.repoByID))
 	repos := make(Repos, 0, len(s.repoByID))
 	for _, r := range s.repoByID {
 		if !set[r] &&
 			(len(kinds) == 0 || kinds[strings.ToLower(r.ExternalRepo.ServiceType)]) &&
 			(len(names) == 0 || names[r.Name]) &&
-			(len(ids) == 0 || ids[r.ID]) {
+			(len(ids) == 0 || ids[r.ID]) &&
+			(len(externalRepos) == 0 || externalRepos[r.ExternalRepo]) {
 
 			repos = append(repos, r)
 			set[r] = true
@@ -18,6 +18,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitolite"
+	gitserverprotocol "github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/pkg/jsonc"
 	"github.com/sourcegraph/sourcegraph/schema"
 	"github.com/xeipuuv/gojsonschema"
@@ -559,6 +560,9 @@ type Repo struct {
 type SourceInfo struct {
 	ID       string
 	CloneURL string
+	// CloneOptions are the options to clone and fetch the repo with, as
+	// configured in the `cloneOptions` of the source's external service.
+	CloneOptions *gitserverprotocol.CloneOptions `json:",omitempty"`
 }
 
 // ExternalServiceID returns the ID of the external service this
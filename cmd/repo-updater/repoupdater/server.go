@@ -43,6 +43,7 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/enqueue-repo-update", s.handleEnqueueRepoUpdate)
 	mux.HandleFunc("/exclude-repo", s.handleExcludeRepo)
 	mux.HandleFunc("/sync-external-service", s.handleExternalServiceSync)
+	mux.HandleFunc("/webhooks/", s.handleWebhook)
 	return mux
 }
 
@@ -268,7 +269,7 @@ func (s *Server) handleEnqueueRepoUpdate(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	repos.Scheduler.UpdateOnce(repo.ID, req.Repo, req.URL)
+	repos.Scheduler.UpdateOnce(repo.ID, req.Repo, req.URL, repo.CloneOptions())
 
 	respond(w, http.StatusOK, &protocol.RepoUpdateResponse{
 		ID:   repo.ID,
@@ -424,7 +425,7 @@ func newRepoInfo(r *repos.Repo) (*protocol.RepoInfo, error) {
 		Description:  r.Description,
 		Fork:         r.Fork,
 		Archived:     r.Archived,
-		VCS:          protocol.VCSInfo{URL: urls[0]},
+		VCS:          protocol.VCSInfo{URL: urls[0], CloneOptions: r.CloneOptions()},
 		ExternalRepo: &r.ExternalRepo,
 	}
 
@@ -23,6 +23,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
 	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	gitserverprotocol "github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/pkg/jsonc"
 	"github.com/sourcegraph/sourcegraph/pkg/repoupdater"
 	"github.com/sourcegraph/sourcegraph/pkg/repoupdater/protocol"
@@ -809,7 +810,7 @@ func TestRepoLookup_syncer(t *testing.T) {
 				},
 				Name:        "github.com/foo/bar",
 				Description: "The description",
-				VCS:         protocol.VCSInfo{URL: "git@github.com:foo/bar.git"},
+				VCS:         protocol.VCSInfo{URL: "git@github.com:foo/bar.git", CloneOptions: &gitserverprotocol.CloneOptions{}},
 				Links: &protocol.RepoLinks{
 					Root:   "github.com/foo/bar",
 					Tree:   "github.com/foo/bar/tree/{rev}/{path}",
@@ -829,7 +830,7 @@ func TestRepoLookup_syncer(t *testing.T) {
 				},
 				Name:        "git-codecommit.us-west-1.amazonaws.com/stripe-go",
 				Description: "The stripe-go lib",
-				VCS:         protocol.VCSInfo{URL: "git@git-codecommit.us-west-1.amazonaws.com/v1/repos/stripe-go"},
+				VCS:         protocol.VCSInfo{URL: "git@git-codecommit.us-west-1.amazonaws.com/v1/repos/stripe-go", CloneOptions: &gitserverprotocol.CloneOptions{}},
 				Links: &protocol.RepoLinks{
 					Root:   "https://us-west-1.console.aws.amazon.com/codecommit/home#/repository/stripe-go",
 					Tree:   "https://us-west-1.console.aws.amazon.com/codecommit/home#/repository/stripe-go/browse/{rev}/--/{path}",
@@ -0,0 +1,204 @@
+package repoupdater
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitlab"
+	"github.com/sourcegraph/sourcegraph/schema"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// maxWebhookPayloadSize is the maximum size of webhook payloads that are
+// read. Push events for large pushes can be several megabytes.
+const maxWebhookPayloadSize = 25 * 1024 * 1024
+
+// errWebhookUnauthorized is returned by parseWebhook when a webhook request was
+// not sent by the code host of an external service.
+var errWebhookUnauthorized = errors.New("webhook secret does not match")
+
+// webhookEvent is a code host webhook event, as it affects Sourcegraph.
+type webhookEvent struct {
+	// repo is the repository whose contents changed (e.g. by a push), if any.
+	repo *api.ExternalRepoSpec
+
+	// sync is whether the repositories on the code host changed (e.g. by
+	// being created, renamed, or deleted), so that the external service needs
+	// to be synced.
+	sync bool
+}
+
+// handleWebhook handles webhook requests sent by code hosts to
+// /webhooks/{kind}, where kind is the (lowercase) kind of the external
+// service. The request must be sent by the code host of an external service
+// of that kind whose webhookSecret is set. Pushes enqueue the repository to be
+// updated as soon as possible, and created, renamed, and deleted repositories
+// cause the Syncer to run.
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	kind := strings.ToUpper(strings.TrimPrefix(r.URL.Path, "/webhooks/"))
+	switch kind {
+	case "GITHUB", "GITLAB", "BITBUCKETSERVER":
+	default:
+		respond(w, http.StatusNotFound, errors.Errorf("webhooks are not supported for external service kind %q", kind))
+		return
+	}
+
+	payload, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookPayloadSize))
+	if err != nil {
+		respond(w, http.StatusBadRequest, errors.Wrap(err, "failed to read webhook payload"))
+		return
+	}
+
+	es, err := s.Store.ListExternalServices(r.Context(), repos.StoreListExternalServicesArgs{
+		Kinds: []string{kind},
+	})
+	if err != nil {
+		respond(w, http.StatusInternalServerError, errors.Wrap(err, "store.list-external-services"))
+		return
+	}
+
+	var ev *webhookEvent
+	for _, e := range es {
+		if ev, err = parseWebhook(e, r.Header, payload); err != errWebhookUnauthorized {
+			break
+		}
+	}
+	switch {
+	case err == errWebhookUnauthorized || ev == nil && err == nil:
+		respond(w, http.StatusUnauthorized, errWebhookUnauthorized)
+		return
+	case err != nil:
+		respond(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if ev.repo != nil {
+		rs, err := s.Store.ListRepos(r.Context(), repos.StoreListReposArgs{
+			ExternalRepos: []api.ExternalRepoSpec{*ev.repo},
+		})
+		if err != nil {
+			respond(w, http.StatusInternalServerError, errors.Wrap(err, "store.list-repos"))
+			return
+		}
+		for _, repo := range rs {
+			var url string
+			if urls := repo.CloneURLs(); len(urls) > 0 {
+				url = urls[0]
+			}
+			log15.Debug("webhook: enqueueing repo update", "repo", repo.Name)
+			repos.Scheduler.UpdateOnce(repo.ID, api.RepoName(repo.Name), url, repo.CloneOptions())
+		}
+	}
+
+	if ev.sync && s.Syncer != nil {
+		for _, k := range s.Kinds {
+			if k == kind {
+				log15.Debug("webhook: triggering sync", "kind", kind)
+				s.Syncer.TriggerSync()
+				break
+			}
+		}
+	}
+
+	respond(w, http.StatusOK, struct{}{})
+}
+
+// parseWebhook returns the event in a webhook request with the given headers
+// and payload, if the request was sent by the code host of external service e.
+// Otherwise, it returns errWebhookUnauthorized. Events that don't affect
+// Sourcegraph result in an empty webhookEvent.
+func parseWebhook(e *repos.ExternalService, header http.Header, payload []byte) (*webhookEvent, error) {
+	c, err := e.Configuration()
+	if err != nil {
+		log15.Error("webhook: invalid external service configuration", "id", e.ID, "error", err)
+		return nil, errWebhookUnauthorized
+	}
+
+	switch cfg := c.(type) {
+	case *schema.GitHubConnection:
+		if cfg.WebhookSecret == "" || !github.ValidateWebhookSignature(payload, header.Get(github.WebhookSignatureHeader), cfg.WebhookSecret) {
+			return nil, errWebhookUnauthorized
+		}
+		var ev webhookEvent
+		switch header.Get(github.WebhookEventHeader) {
+		case github.WebhookEventPush, github.WebhookEventRepository:
+			var gev github.WebhookEvent
+			if err := json.Unmarshal(payload, &gev); err != nil {
+				return nil, errors.Wrap(err, "invalid GitHub webhook payload")
+			}
+			if gev.Repository == nil {
+				return nil, errors.New("GitHub webhook payload has no repository")
+			}
+			if gev.Action == "" {
+				ev.repo, err = webhookExternalRepo(github.ServiceType, cfg.Url, gev.Repository.NodeID)
+			} else {
+				ev.sync = true
+			}
+		}
+		return &ev, err
+
+	case *schema.GitLabConnection:
+		if cfg.WebhookSecret == "" || !gitlab.ValidateWebhookToken(header.Get(gitlab.WebhookTokenHeader), cfg.WebhookSecret) {
+			return nil, errWebhookUnauthorized
+		}
+		var gev gitlab.WebhookEvent
+		if err := json.Unmarshal(payload, &gev); err != nil {
+			return nil, errors.Wrap(err, "invalid GitLab webhook payload")
+		}
+		var ev webhookEvent
+		switch gev.EventName {
+		case gitlab.WebhookEventPush, gitlab.WebhookEventTagPush:
+			ev.repo, err = webhookExternalRepo(gitlab.ServiceType, cfg.Url, strconv.Itoa(gev.ProjectID))
+		case gitlab.WebhookEventProjectCreate, gitlab.WebhookEventProjectDestroy,
+			gitlab.WebhookEventProjectRename, gitlab.WebhookEventProjectTransfer:
+			ev.sync = true
+		}
+		return &ev, err
+
+	case *schema.BitbucketServerConnection:
+		if cfg.WebhookSecret == "" || !bitbucketserver.ValidateWebhookSignature(payload, header.Get(bitbucketserver.WebhookSignatureHeader), cfg.WebhookSecret) {
+			return nil, errWebhookUnauthorized
+		}
+		var ev webhookEvent
+		switch header.Get(bitbucketserver.WebhookEventHeader) {
+		case bitbucketserver.WebhookEventRefsChanged:
+			var bev bitbucketserver.WebhookEvent
+			if err := json.Unmarshal(payload, &bev); err != nil {
+				return nil, errors.Wrap(err, "invalid Bitbucket Server webhook payload")
+			}
+			if bev.Repository == nil {
+				return nil, errors.New("Bitbucket Server webhook payload has no repository")
+			}
+			ev.repo, err = webhookExternalRepo(bitbucketserver.ServiceType, cfg.Url, strconv.Itoa(bev.Repository.ID))
+		case bitbucketserver.WebhookEventRepoModified:
+			ev.sync = true
+		}
+		return &ev, err
+	}
+
+	return nil, errWebhookUnauthorized
+}
+
+// webhookExternalRepo returns the external repo spec of the repository with
+// the given ID on the code host with the given base URL.
+func webhookExternalRepo(serviceType, baseURL, id string) (*api.ExternalRepoSpec, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid external service URL")
+	}
+	return &api.ExternalRepoSpec{
+		ID:          id,
+		ServiceType: serviceType,
+		ServiceID:   repos.NormalizeBaseURL(u).String(),
+	}, nil
+}
@@ -0,0 +1,149 @@
+package repoupdater
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitlab"
+	"github.com/sourcegraph/sourcegraph/pkg/repoupdater"
+)
+
+func TestServer_handleWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	store := new(repos.FakeStore)
+	must(store.UpsertExternalServices(ctx,
+		&repos.ExternalService{
+			Kind:        "GITHUB",
+			DisplayName: "github.com - test",
+			Config:      `{"url": "https://github.com", "token": "abc", "repositoryQuery": ["none"], "webhookSecret": "s3cr3t"}`,
+		},
+		&repos.ExternalService{
+			Kind:        "GITLAB",
+			DisplayName: "gitlab.com - test",
+			Config:      `{"url": "https://gitlab.com", "token": "abc", "projectQuery": ["none"], "webhookSecret": "t0k3n"}`,
+		},
+	))
+
+	repo := &repos.Repo{
+		Name: "github.com/foo/bar",
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          "MDEwOlJlcG9zaXRvcnk0MTI4ODcwOA==",
+			ServiceType: github.ServiceType,
+			ServiceID:   "https://github.com/",
+		},
+		Metadata: new(github.Repository),
+		Sources: map[string]*repos.SourceInfo{
+			"extsvc:1": {
+				ID:       "extsvc:1",
+				CloneURL: "https://secret-token@github.com/foo/bar",
+			},
+		},
+	}
+	must(store.UpsertRepos(ctx, repo))
+
+	srv := httptest.NewServer((&Server{Store: store, Kinds: []string{"GITHUB", "GITLAB"}, Syncer: &repos.Syncer{}}).Handler())
+	defer srv.Close()
+	cli := repoupdater.Client{URL: srv.URL}
+
+	githubPush := []byte(`{"ref": "refs/heads/master", "repository": {"node_id": "MDEwOlJlcG9zaXRvcnk0MTI4ODcwOA==", "full_name": "foo/bar"}}`)
+	githubSignature := func(payload []byte, secret string) string {
+		mac := hmac.New(sha1.New, []byte(secret))
+		mac.Write(payload)
+		return "sha1=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	for _, tc := range []struct {
+		name    string
+		kind    string
+		header  http.Header
+		payload []byte
+		status  int
+	}{
+		{
+			name: "github push",
+			kind: "github",
+			header: http.Header{
+				github.WebhookEventHeader:     {github.WebhookEventPush},
+				github.WebhookSignatureHeader: {githubSignature(githubPush, "s3cr3t")},
+			},
+			payload: githubPush,
+			status:  http.StatusOK,
+		},
+		{
+			name: "github invalid signature",
+			kind: "github",
+			header: http.Header{
+				github.WebhookEventHeader:     {github.WebhookEventPush},
+				github.WebhookSignatureHeader: {githubSignature(githubPush, "wrong")},
+			},
+			payload: githubPush,
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name:    "github missing signature",
+			kind:    "github",
+			header:  http.Header{github.WebhookEventHeader: {github.WebhookEventPush}},
+			payload: githubPush,
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name: "gitlab project created",
+			kind: "gitlab",
+			header: http.Header{
+				gitlab.WebhookEventHeader: {"System Hook"},
+				gitlab.WebhookTokenHeader: {"t0k3n"},
+			},
+			payload: []byte(`{"event_name": "project_create", "project_id": 74}`),
+			status:  http.StatusOK,
+		},
+		{
+			name: "gitlab invalid JSON",
+			kind: "gitlab",
+			header: http.Header{
+				gitlab.WebhookEventHeader: {"Push Hook"},
+				gitlab.WebhookTokenHeader: {"t0k3n"},
+			},
+			payload: []byte(`{`),
+			status:  http.StatusBadRequest,
+		},
+		{
+			name: "no external service with webhook secret",
+			kind: "bitbucketserver",
+			header: http.Header{
+				"X-Event-Key":     {"repo:refs_changed"},
+				"X-Hub-Signature": {"sha256=00"},
+			},
+			payload: []byte(`{}`),
+			status:  http.StatusUnauthorized,
+		},
+		{
+			name:    "unsupported kind",
+			kind:    "gitolite",
+			payload: []byte(`{}`),
+			status:  http.StatusNotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body, err := cli.Webhook(ctx, tc.kind, tc.header, tc.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != tc.status {
+				t.Errorf("got status %d (%s), want %d", status, body, tc.status)
+			}
+		})
+	}
+
+	if info := repos.Scheduler.ScheduleInfo(repo.ID); info.Queue == nil {
+		t.Errorf("expected %s to be enqueued for update by push webhook", repo.Name)
+	}
+}
@@ -61,6 +61,28 @@ type PatternInfo struct {
 	// when finding matches.
 	IsCaseSensitive bool
 
+	// IsStructuralPat if true will treat the Pattern as a structural
+	// search template (e.g. "foo(:[args])"). Holes like :[args] match text
+	// with balanced delimiters, which may span multiple lines. Structural
+	// patterns are always matched case sensitively and only against file
+	// contents, and IsRegExp, IsWordMatch and PatternExpr must not be set.
+	IsStructuralPat bool
+
+	// IsMultiline if true will match the Pattern against the whole content of
+	// each file instead of line by line, so that a regular expression can
+	// match text spanning multiple lines (e.g. "defer.*\n\s*return"). The
+	// matches are reported in MultilineMatches.
+	IsMultiline bool
+
+	// PatternExpr, if set, is a boolean expression over patterns that a
+	// file's content must satisfy for the file to match. Pattern is still
+	// used to find the matching lines in such a file, so it should be the
+	// union of the non-negated patterns in PatternExpr. The patterns are
+	// interpreted like Pattern (e.g. according to IsRegExp).
+	//
+	// It is sent as a JSON-encoded form value.
+	PatternExpr *PatternExpr `schema:"-"`
+
 	// ExcludePattern is a pattern that may not match the returned files' paths.
 	// eg '**/node_modules'
 	ExcludePattern string
@@ -99,6 +121,25 @@ type PatternInfo struct {
 	PatternMatchesPath bool
 }
 
+// PatternOp is a boolean operator in a PatternExpr.
+type PatternOp string
+
+// All PatternOp values.
+const (
+	PatternOpAnd PatternOp = "and"
+	PatternOpOr  PatternOp = "or"
+)
+
+// PatternExpr is a boolean expression over patterns. A leaf node has an empty
+// Op and matches content that its Pattern matches. Other nodes combine their
+// Operands with Op.
+type PatternExpr struct {
+	Op       PatternOp      `json:",omitempty"`
+	Not      bool           `json:",omitempty"` // negates the node
+	Pattern  string         `json:",omitempty"` // only set for leaf nodes
+	Operands []*PatternExpr `json:",omitempty"` // only set for non-leaf nodes
+}
+
 // AllIncludePatterns returns all include patterns (including the deprecated
 // single p.IncludePattern).
 func (p PatternInfo) AllIncludePatterns() []string {
@@ -130,6 +171,13 @@ type FileMatch struct {
 	Path        string
 	LineMatches []LineMatch
 
+	// MultilineMatches are the matches in the file as ranges that may span
+	// multiple lines. It is only set for structural and multiline searches
+	// (see IsStructuralPat and IsMultiline). The lines they span are also
+	// reported in LineMatches, so that clients that only understand
+	// LineMatches still see them.
+	MultilineMatches []MultilineMatch `json:",omitempty"`
+
 	// LimitHit is true if LineMatches may not include all LineMatches.
 	LimitHit bool
 }
@@ -151,3 +199,24 @@ type LineMatch struct {
 	// LimitHit is true if OffsetAndLengths may not include all OffsetAndLengths.
 	LimitHit bool
 }
+
+// MultilineMatch is a match that may span multiple lines.
+type MultilineMatch struct {
+	// Preview is the content of the lines that the match spans, without the
+	// trailing newline.
+	Preview string
+
+	// Start is the location of the first character of the match and End is
+	// the location just after its last character.
+	Start, End Location
+}
+
+// Location is a position in a file.
+type Location struct {
+	// Line is the 0-based line number.
+	Line int
+
+	// Column is the 0-based offset in the line, measured in characters, not
+	// bytes.
+	Column int
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"regexp"
 	"regexp/syntax"
@@ -65,6 +66,14 @@ type readerGrep struct {
 	// re is the regexp to match, or nil if empty ("match all files' content").
 	re *regexp.Regexp
 
+	// expr, if non-nil, is a boolean expression that a file's content must
+	// satisfy before re is used to find its matching lines.
+	expr *matchExpr
+
+	// multiline if true means re is matched against the whole content of a
+	// file instead of line by line (see FindMultiline).
+	multiline bool
+
 	// ignoreCase if true means we need to do case insensitive matching.
 	ignoreCase bool
 
@@ -91,32 +100,18 @@ func compile(p *protocol.PatternInfo) (*readerGrep, error) {
 		re               *regexp.Regexp
 		literalSubstring []byte
 	)
-	if p.Pattern != "" {
-		expr := p.Pattern
-		if !p.IsRegExp {
-			expr = regexp.QuoteMeta(expr)
-		}
-		if p.IsWordMatch {
-			expr = `\b` + expr + `\b`
-		}
-		if p.IsRegExp {
-			// We don't do the search line by line, therefore we want the
-			// regex engine to consider newlines for anchors (^$).
-			expr = "(?m:" + expr + ")"
+	if p.IsStructuralPat {
+		// Structural patterns are matched by comby (see structuralSearch), so
+		// only the path patterns are compiled.
+		if p.IsRegExp || p.IsWordMatch || p.IsMultiline || p.PatternExpr != nil {
+			return nil, errors.New("structural patterns may not be combined with regexp, word match, multiline or pattern expression options")
 		}
-		if !p.IsCaseSensitive {
-			// We don't just use (?i) because regexp library doesn't seem
-			// to contain good optimizations for case insensitive
-			// search. Instead we lowercase the input and pattern.
-			re, err := syntax.Parse(expr, syntax.Perl)
-			if err != nil {
-				return nil, err
-			}
-			lowerRegexpASCII(re)
-			expr = re.String()
+	} else if p.Pattern != "" {
+		expr, err := compilePattern(p.Pattern, p)
+		if err != nil {
+			return nil, err
 		}
 
-		var err error
 		re, err = regexp.Compile(expr)
 		if err != nil {
 			return nil, err
@@ -134,6 +129,15 @@ func compile(p *protocol.PatternInfo) (*readerGrep, error) {
 		}
 	}
 
+	var expr *matchExpr
+	if p.PatternExpr != nil {
+		var err error
+		expr, err = compileMatchExpr(p.PatternExpr, p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	pathOptions := pathmatch.CompileOptions{
 		RegExp:        p.PathPatternsAreRegExps,
 		CaseSensitive: p.PathPatternsAreCaseSensitive,
@@ -145,12 +149,122 @@ func compile(p *protocol.PatternInfo) (*readerGrep, error) {
 
 	return &readerGrep{
 		re:               re,
+		expr:             expr,
+		multiline:        p.IsMultiline && re != nil,
 		ignoreCase:       !p.IsCaseSensitive,
 		matchPath:        matchPath,
 		literalSubstring: literalSubstring,
 	}, nil
 }
 
+// compilePattern returns the regexp source for matching pattern according to
+// the options in p.
+func compilePattern(pattern string, p *protocol.PatternInfo) (string, error) {
+	expr := pattern
+	if !p.IsRegExp {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if p.IsWordMatch {
+		expr = `\b` + expr + `\b`
+	}
+	if p.IsRegExp {
+		// We don't do the search line by line, therefore we want the
+		// regex engine to consider newlines for anchors (^$).
+		expr = "(?m:" + expr + ")"
+	}
+	if !p.IsCaseSensitive {
+		// We don't just use (?i) because regexp library doesn't seem
+		// to contain good optimizations for case insensitive
+		// search. Instead we lowercase the input and pattern.
+		re, err := syntax.Parse(expr, syntax.Perl)
+		if err != nil {
+			return "", err
+		}
+		lowerRegexpASCII(re)
+		expr = re.String()
+	}
+	return expr, nil
+}
+
+// matchExpr is a compiled protocol.PatternExpr.
+type matchExpr struct {
+	op       protocol.PatternOp
+	not      bool
+	re       *regexp.Regexp // only set for leaf nodes
+	operands []*matchExpr
+}
+
+// compileMatchExpr compiles e, interpreting its patterns according to the
+// options in p.
+func compileMatchExpr(e *protocol.PatternExpr, p *protocol.PatternInfo) (*matchExpr, error) {
+	m := &matchExpr{op: e.Op, not: e.Not}
+	switch e.Op {
+	case "":
+		expr, err := compilePattern(e.Pattern, p)
+		if err != nil {
+			return nil, err
+		}
+		m.re, err = regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+	case protocol.PatternOpAnd, protocol.PatternOpOr:
+		m.operands = make([]*matchExpr, len(e.Operands))
+		for i, o := range e.Operands {
+			var err error
+			m.operands[i], err = compileMatchExpr(o, p)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized pattern expression operator %q", e.Op)
+	}
+	return m, nil
+}
+
+// copy returns a copied version of m that is safe to use from another
+// goroutine.
+func (m *matchExpr) copy() *matchExpr {
+	c := &matchExpr{op: m.op, not: m.not}
+	if m.re != nil {
+		c.re = m.re.Copy()
+	}
+	if m.operands != nil {
+		c.operands = make([]*matchExpr, len(m.operands))
+		for i, o := range m.operands {
+			c.operands[i] = o.copy()
+		}
+	}
+	return c
+}
+
+// match reports whether the (already transformed, e.g. lowercased) content
+// satisfies m.
+func (m *matchExpr) match(content []byte) bool {
+	var match bool
+	switch m.op {
+	case protocol.PatternOpAnd:
+		match = true
+		for _, o := range m.operands {
+			if !o.match(content) {
+				match = false
+				break
+			}
+		}
+	case protocol.PatternOpOr:
+		for _, o := range m.operands {
+			if o.match(content) {
+				match = true
+				break
+			}
+		}
+	default:
+		match = m.re.Match(content)
+	}
+	return match != m.not
+}
+
 // Copy returns a copied version of rg that is safe to use from another
 // goroutine.
 func (rg *readerGrep) Copy() *readerGrep {
@@ -158,8 +272,14 @@ func (rg *readerGrep) Copy() *readerGrep {
 	if rg.re != nil {
 		reCopy = rg.re.Copy()
 	}
+	var exprCopy *matchExpr
+	if rg.expr != nil {
+		exprCopy = rg.expr.copy()
+	}
 	return &readerGrep{
 		re:               reCopy,
+		expr:             exprCopy,
+		multiline:        rg.multiline,
 		ignoreCase:       rg.ignoreCase,
 		matchPath:        rg.matchPath.Copy(),
 		literalSubstring: rg.literalSubstring,
@@ -215,6 +335,9 @@ func (rg *readerGrep) Find(zf *store.ZipFile, f *store.SrcFile) (matches []proto
 	if first == nil {
 		return nil, false, nil
 	}
+	if rg.expr != nil && !rg.expr.match(fileMatchBuf) {
+		return nil, false, nil
+	}
 
 	idx := 0
 	for i := 0; len(matches) < maxLineMatches; i++ {
@@ -277,6 +400,15 @@ func (rg *readerGrep) Find(zf *store.ZipFile, f *store.SrcFile) (matches []proto
 
 // FindZip is a convenience function to run Find on f.
 func (rg *readerGrep) FindZip(zf *store.ZipFile, f *store.SrcFile) (protocol.FileMatch, error) {
+	if rg.multiline {
+		lm, mm, limitHit := rg.FindMultiline(zf, f)
+		return protocol.FileMatch{
+			Path:             f.Name,
+			LineMatches:      lm,
+			MultilineMatches: mm,
+			LimitHit:         limitHit,
+		}, nil
+	}
 	lm, limitHit, err := rg.Find(zf, f)
 	return protocol.FileMatch{
 		Path:        f.Name,
@@ -292,6 +424,7 @@ func concurrentFind(ctx context.Context, rg *readerGrep, zf *store.ZipFile, file
 	if rg.re != nil {
 		span.SetTag("re", rg.re.String())
 	}
+	span.SetTag("multiline", rg.multiline)
 	span.SetTag("path", rg.matchPath.String())
 	defer func() {
 		if err != nil {
@@ -0,0 +1,143 @@
+package search
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/store"
+)
+
+// FindMultiline is like Find, except that it matches rg against the whole
+// content of the file (instead of line by line), so that matches may span
+// multiple lines. Each match is returned as a MultilineMatch, and the lines
+// that it spans are also returned as LineMatches.
+// NOTE: This is not safe to use concurrently.
+func (rg *readerGrep) FindMultiline(zf *store.ZipFile, f *store.SrcFile) (lineMatches []protocol.LineMatch, multilineMatches []protocol.MultilineMatch, limitHit bool) {
+	if rg.ignoreCase && rg.transformBuf == nil {
+		rg.transformBuf = make([]byte, zf.MaxLen)
+	}
+
+	// See Find for why fileMatchBuf is transformed and fileBuf is not.
+	fileBuf := zf.DataFor(f)
+	fileMatchBuf := fileBuf
+	if rg.ignoreCase {
+		fileMatchBuf = rg.transformBuf[:len(fileBuf)]
+		bytesToLowerASCII(fileMatchBuf, fileBuf)
+	}
+
+	if !bytes.Contains(fileMatchBuf, rg.literalSubstring) {
+		return nil, nil, false
+	}
+	if rg.expr != nil && !rg.expr.match(fileMatchBuf) {
+		return nil, nil, false
+	}
+
+	locs := rg.re.FindAllIndex(fileMatchBuf, maxLineMatches+1)
+	if len(locs) == 0 {
+		return nil, nil, false
+	}
+	limitHit = len(locs) > maxLineMatches
+	if limitHit {
+		locs = locs[:maxLineMatches]
+	}
+	ranges := make([][2]int, len(locs))
+	for i, loc := range locs {
+		ranges[i] = [2]int{loc[0], loc[1]}
+	}
+	lineMatches, multilineMatches, rangesLimitHit := rangeMatches(fileBuf, ranges)
+	return lineMatches, multilineMatches, limitHit || rangesLimitHit
+}
+
+// rangeMatches converts the [start, end) byte ranges of matches in content
+// (in increasing order and non-overlapping) to multiline matches, and to the
+// line matches of the lines that they span. The limitHit return value is true
+// if some lines were omitted because of maxLineMatches.
+func rangeMatches(content []byte, ranges [][2]int) (lineMatches []protocol.LineMatch, multilineMatches []protocol.MultilineMatch, limitHit bool) {
+	if len(ranges) == 0 {
+		return nil, nil, false
+	}
+
+	// lineStarts[i] is the offset of the first byte of line i.
+	lineStarts := []int{0}
+	for i, c := range content {
+		if c == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	lineEnd := func(line int) int {
+		if line+1 < len(lineStarts) {
+			return lineStarts[line+1] - 1 // exclude the newline
+		}
+		return len(content)
+	}
+	lineAt := func(offset, fromLine int) int {
+		line := fromLine
+		for line+1 < len(lineStarts) && lineStarts[line+1] <= offset {
+			line++
+		}
+		return line
+	}
+	column := func(line, offset int) int {
+		return utf8.RuneCount(content[lineStarts[line]:offset])
+	}
+
+	line := 0
+	for _, r := range ranges {
+		start, end := r[0], r[1]
+		startLine := lineAt(start, line)
+		endLine := lineAt(end, startLine)
+		if end > start && end == lineStarts[endLine] {
+			// The match ends with a newline, so it ends on the previous line.
+			endLine--
+		}
+		line = endLine
+		multilineMatches = append(multilineMatches, protocol.MultilineMatch{
+			Preview: string(content[lineStarts[startLine]:lineEnd(endLine)]),
+			Start:   protocol.Location{Line: startLine, Column: column(startLine, start)},
+			End:     protocol.Location{Line: endLine, Column: column(endLine, min(end, lineEnd(endLine)))},
+		})
+
+		for l := startLine; l <= endLine; l++ {
+			segStart, segEnd := lineStarts[l], lineEnd(l)
+			if start > segStart {
+				segStart = start
+			}
+			if end < segEnd {
+				segEnd = end
+			}
+			if segStart > segEnd || segStart == segEnd && start != end {
+				// Only empty matches have empty segments. The newline at the
+				// end of a line is not part of its line match.
+				continue
+			}
+			offsetAndLength := [2]int{column(l, segStart), utf8.RuneCount(content[segStart:segEnd])}
+			if n := len(lineMatches); n > 0 && lineMatches[n-1].LineNumber == l {
+				if len(lineMatches[n-1].OffsetAndLengths) == maxOffsets {
+					lineMatches[n-1].LimitHit = true
+				} else {
+					lineMatches[n-1].OffsetAndLengths = append(lineMatches[n-1].OffsetAndLengths, offsetAndLength)
+				}
+				continue
+			}
+			if len(lineMatches) == maxLineMatches {
+				return lineMatches, multilineMatches, true
+			}
+			lineMatches = append(lineMatches, protocol.LineMatch{
+				// Copy the line, because content may not be used after the
+				// ZipFile has been closed (see readerGrep.Find).
+				Preview:          string(content[lineStarts[l]:lineEnd(l)]),
+				LineNumber:       l,
+				OffsetAndLengths: [][2]int{offsetAndLength},
+			})
+		}
+	}
+	return lineMatches, multilineMatches, false
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,62 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+)
+
+func TestRangeMatches(t *testing.T) {
+	content := []byte("defer f()\nreturn\nx\n")
+	cases := []struct {
+		name             string
+		ranges           [][2]int
+		lineMatches      []protocol.LineMatch
+		multilineMatches []protocol.MultilineMatch
+	}{
+		{
+			name:   "span lines",
+			ranges: [][2]int{{0, 16}},
+			lineMatches: []protocol.LineMatch{
+				{Preview: "defer f()", LineNumber: 0, OffsetAndLengths: [][2]int{{0, 9}}},
+				{Preview: "return", LineNumber: 1, OffsetAndLengths: [][2]int{{0, 6}}},
+			},
+			multilineMatches: []protocol.MultilineMatch{
+				{Preview: "defer f()\nreturn", Start: protocol.Location{Line: 0, Column: 0}, End: protocol.Location{Line: 1, Column: 6}},
+			},
+		},
+		{
+			name:   "trailing newline",
+			ranges: [][2]int{{6, 10}},
+			lineMatches: []protocol.LineMatch{
+				{Preview: "defer f()", LineNumber: 0, OffsetAndLengths: [][2]int{{6, 3}}},
+			},
+			multilineMatches: []protocol.MultilineMatch{
+				{Preview: "defer f()", Start: protocol.Location{Line: 0, Column: 6}, End: protocol.Location{Line: 0, Column: 9}},
+			},
+		},
+		{
+			name:   "empty match",
+			ranges: [][2]int{{17, 17}},
+			lineMatches: []protocol.LineMatch{
+				{Preview: "x", LineNumber: 2, OffsetAndLengths: [][2]int{{0, 0}}},
+			},
+			multilineMatches: []protocol.MultilineMatch{
+				{Preview: "x", Start: protocol.Location{Line: 2, Column: 0}, End: protocol.Location{Line: 2, Column: 0}},
+			},
+		},
+	}
+	for _, test := range cases {
+		lineMatches, multilineMatches, limitHit := rangeMatches(content, test.ranges)
+		if limitHit {
+			t.Errorf("%s: unexpected limitHit", test.name)
+		}
+		if !reflect.DeepEqual(lineMatches, test.lineMatches) {
+			t.Errorf("%s: got line matches %+v, want %+v", test.name, lineMatches, test.lineMatches)
+		}
+		if !reflect.DeepEqual(multilineMatches, test.multilineMatches) {
+			t.Errorf("%s: got multiline matches %+v, want %+v", test.name, multilineMatches, test.multilineMatches)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
 	"github.com/sourcegraph/sourcegraph/cmd/searcher/search"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/comby"
 	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
 	searchapi "github.com/sourcegraph/sourcegraph/pkg/search"
 	"github.com/sourcegraph/sourcegraph/pkg/search/query"
@@ -47,6 +48,9 @@ func main() {
 `,
 		"abc.txt":    "w",
 		"milton.png": string(miltonPNG),
+		"call.js": `f(1,
+  g(2))
+`,
 	}
 
 	cases := []struct {
@@ -127,11 +131,46 @@ main.go:6:	fmt.Println("Hello world")
 `},
 		{protocol.PatternInfo{Pattern: "world", IncludePattern: `\.(MD|go)`, PathPatternsAreRegExps: true, PathPatternsAreCaseSensitive: true}, `
 main.go:6:	fmt.Println("Hello world")
+`},
+
+		{protocol.PatternInfo{Pattern: "world", PatternExpr: &protocol.PatternExpr{Op: protocol.PatternOpAnd, Operands: []*protocol.PatternExpr{
+			{Pattern: "world"},
+			{Not: true, Pattern: "fmt"},
+		}}}, `
+README.md:1:# Hello World
+README.md:3:Hello world example in go
+`},
+		{protocol.PatternInfo{Pattern: "(package)|(example)", IsRegExp: true, PatternExpr: &protocol.PatternExpr{Op: protocol.PatternOpOr, Operands: []*protocol.PatternExpr{
+			{Pattern: "package"},
+			{Pattern: "example"},
+		}}}, `
+README.md:3:Hello world example in go
+main.go:1:package main
+`},
+		{protocol.PatternInfo{Pattern: "(package)|(example)", IsRegExp: true, PatternExpr: &protocol.PatternExpr{Op: protocol.PatternOpAnd, Operands: []*protocol.PatternExpr{
+			{Op: protocol.PatternOpOr, Operands: []*protocol.PatternExpr{{Pattern: "package"}, {Pattern: "example"}}},
+			{Not: true, Pattern: "^func"},
+		}}}, `
+README.md:3:Hello world example in go
 `},
 
 		{protocol.PatternInfo{Pattern: "doesnotmatch"}, ""},
 		{protocol.PatternInfo{Pattern: "", IsRegExp: false, IncludePatterns: []string{"\\.png"}, PathPatternsAreRegExps: true, PatternMatchesPath: true}, `
 milton.png
+`},
+
+		{protocol.PatternInfo{Pattern: "fmt.Println(:[args])", IsStructuralPat: true}, `
+main.go:6:	fmt.Println("Hello world")
+`},
+		{protocol.PatternInfo{Pattern: "f(:[a], g(:[b]))", IsStructuralPat: true}, `
+call.js:1:f(1,
+call.js:2:  g(2))
+`},
+		{protocol.PatternInfo{Pattern: "println(:[args])", IsStructuralPat: true}, ""},
+
+		{protocol.PatternInfo{Pattern: `println\(.*\n}`, IsRegExp: true, IsMultiline: true}, `
+main.go:6:	fmt.Println("Hello world")
+main.go:7:}
 `},
 	}
 
@@ -146,7 +185,12 @@ milton.png
 	s := &search.StoreSearcher{Store: store}
 	defer s.Close()
 
+	_, combyErr := exec.LookPath(comby.BinaryPath)
 	for i, test := range cases {
+		if test.arg.IsStructuralPat && combyErr != nil {
+			t.Logf("skipping structural search %q: %s", test.arg.Pattern, combyErr)
+			continue
+		}
 		test.arg.PatternMatchesContent = true
 		req := protocol.Request{
 			Repo:         "foo",
@@ -186,6 +230,9 @@ milton.png
 		if test.arg.IsWordMatch {
 			continue
 		}
+		if test.arg.PatternExpr != nil || test.arg.IsStructuralPat || test.arg.IsMultiline {
+			continue
+		}
 
 		q, err := patternToQuery(&test.arg)
 		if err != nil {
@@ -227,6 +274,18 @@ func TestSearch_badrequest(t *testing.T) {
 			},
 		},
 
+		// Structural pattern with regexp options
+		{
+			Repo:   "foo",
+			URL:    "u",
+			Commit: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			PatternInfo: protocol.PatternInfo{
+				Pattern:         "foo(:[args])",
+				IsRegExp:        true,
+				IsStructuralPat: true,
+			},
+		},
+
 		// Unsupported regex
 		{
 			Repo:   "foo",
@@ -352,6 +411,12 @@ func doSearch(u string, p *protocol.Request) ([]protocol.FileMatch, error) {
 	if p.IsCaseSensitive {
 		form.Set("IsCaseSensitive", "true")
 	}
+	if p.IsStructuralPat {
+		form.Set("IsStructuralPat", "true")
+	}
+	if p.IsMultiline {
+		form.Set("IsMultiline", "true")
+	}
 	if p.PathPatternsAreRegExps {
 		form.Set("PathPatternsAreRegExps", "true")
 	}
@@ -364,6 +429,13 @@ func doSearch(u string, p *protocol.Request) ([]protocol.FileMatch, error) {
 	if p.PatternMatchesPath {
 		form.Set("PatternMatchesPath", "true")
 	}
+	if p.PatternExpr != nil {
+		expr, err := json.Marshal(p.PatternExpr)
+		if err != nil {
+			return nil, err
+		}
+		form.Set("PatternExpr", string(expr))
+	}
 	resp, err := http.PostForm(u, form)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,93 @@
+package search
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/comby"
+	"github.com/sourcegraph/sourcegraph/pkg/store"
+)
+
+// structuralSearch finds the matches of the structural search template
+// p.Pattern in the files of the zip archive at zipPath that rg's path patterns
+// match. The template is matched by comby, the same external tool that the
+// replacer uses to rewrite code, so that search and rewrite agree on what a
+// template matches.
+func structuralSearch(ctx context.Context, rg *readerGrep, p *protocol.PatternInfo, zipPath string, zf *store.ZipFile) (matches []protocol.FileMatch, limitHit bool, err error) {
+	fileMatchLimit := p.FileMatchLimit
+	if fileMatchLimit > maxFileMatches || fileMatchLimit <= 0 {
+		fileMatchLimit = maxFileMatches
+	}
+
+	// comby reads all files in the archive, but only those that the store
+	// indexed (not binary and not too large) are searched.
+	files := make(map[string]*store.SrcFile, len(zf.Files))
+	for i := range zf.Files {
+		files[zf.Files[i].Name] = &zf.Files[i]
+	}
+
+	matches = []protocol.FileMatch{}
+	err = comby.Matches(ctx, p.Pattern, zipPath, func(fm *comby.FileMatch) bool {
+		f, ok := files[fm.URI]
+		if !ok || !rg.matchPath.MatchPath(f.Name) {
+			return true
+		}
+		if len(matches) == fileMatchLimit {
+			limitHit = true
+			return false
+		}
+		lm, mm, lineLimitHit := structuralMatches(zf.DataFor(f), fm.Matches)
+		if len(lm) > 0 {
+			matches = append(matches, protocol.FileMatch{
+				Path:             f.Name,
+				LineMatches:      lm,
+				MultilineMatches: mm,
+				LimitHit:         lineLimitHit,
+			})
+		}
+		return true
+	})
+	if err == comby.ErrNotInstalled {
+		return nil, false, badRequestError{"structural search is not available because comby is not installed on the searcher (a site admin must install it)"}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	// comby reports files in no particular order.
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Path < matches[j].Path })
+	return matches, limitHit, nil
+}
+
+// structuralMatches converts comby's matches in content to multiline matches
+// and to the line matches of the lines that they span.
+func structuralMatches(content []byte, cms []comby.Match) (lineMatches []protocol.LineMatch, multilineMatches []protocol.MultilineMatch, limitHit bool) {
+	ranges := make([][2]int, 0, len(cms))
+	for _, m := range cms {
+		start, end := m.Range.Start.Offset, m.Range.End.Offset
+		if start < 0 || end < start || end > len(content) {
+			// The match doesn't fit the file as the store read it.
+			continue
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+
+	// rangeMatches requires ranges in increasing order that don't overlap.
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	nonOverlapping := ranges[:0]
+	for _, r := range ranges {
+		if n := len(nonOverlapping); n > 0 && r[0] < nonOverlapping[n-1][1] {
+			continue
+		}
+		nonOverlapping = append(nonOverlapping, r)
+	}
+	ranges = nonOverlapping
+
+	if len(ranges) > maxLineMatches {
+		ranges = ranges[:maxLineMatches]
+		limitHit = true
+	}
+	lineMatches, multilineMatches, rangesLimitHit := rangeMatches(content, ranges)
+	return lineMatches, multilineMatches, limitHit || rangesLimitHit
+}
@@ -0,0 +1,53 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/pkg/comby"
+)
+
+func TestStructuralMatches(t *testing.T) {
+	content := []byte("x := foo(a,\n\tb)\ny := foo()\n")
+	match := func(start, end int) comby.Match {
+		return comby.Match{Range: comby.Range{
+			Start: comby.Location{Offset: start},
+			End:   comby.Location{Offset: end},
+		}}
+	}
+	cms := []comby.Match{
+		match(21, 26), // foo(), reported out of order
+		match(5, 15),  // foo(a,\n\tb)
+		match(9, 10),  // overlaps the previous match
+		match(20, 40), // past the end of the file
+	}
+
+	lineMatches, multilineMatches, limitHit := structuralMatches(content, cms)
+	wantLineMatches := []protocol.LineMatch{
+		{Preview: "x := foo(a,", LineNumber: 0, OffsetAndLengths: [][2]int{{5, 6}}},
+		{Preview: "\tb)", LineNumber: 1, OffsetAndLengths: [][2]int{{0, 3}}},
+		{Preview: "y := foo()", LineNumber: 2, OffsetAndLengths: [][2]int{{5, 5}}},
+	}
+	wantMultilineMatches := []protocol.MultilineMatch{
+		{
+			Preview: "x := foo(a,\n\tb)",
+			Start:   protocol.Location{Line: 0, Column: 5},
+			End:     protocol.Location{Line: 1, Column: 3},
+		},
+		{
+			Preview: "y := foo()",
+			Start:   protocol.Location{Line: 2, Column: 5},
+			End:     protocol.Location{Line: 2, Column: 10},
+		},
+	}
+	if !reflect.DeepEqual(lineMatches, wantLineMatches) {
+		t.Errorf("got line matches %+v, want %+v", lineMatches, wantLineMatches)
+	}
+	if !reflect.DeepEqual(multilineMatches, wantMultilineMatches) {
+		t.Errorf("got multiline matches %+v, want %+v", multilineMatches, wantMultilineMatches)
+	}
+	if limitHit {
+		t.Error("got limitHit, want false")
+	}
+}
@@ -0,0 +1,196 @@
+package symbols
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+	"github.com/sourcegraph/sourcegraph/pkg/symbols/protocol"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// maxIndexedCommitsPerRepo is the number of recently indexed commits per
+// repository that are considered when deriving the database of a new commit.
+const maxIndexedCommitsPerRepo = 5
+
+// maxIncrementalChangedPaths is the maximum number of changed and deleted
+// files between two commits for which the database of one commit is derived
+// from the other. When more files changed, parsing all files is about as fast
+// and the list of paths gets unwieldy.
+const maxIncrementalChangedPaths = 1000
+
+// indexedCommit is a commit whose symbols database is in the disk cache.
+type indexedCommit struct {
+	commitID api.CommitID
+	dbFile   string
+}
+
+// recordIndexedCommit records that the symbols database of repo@commitID is
+// in the file dbFile.
+func (s *Service) recordIndexedCommit(repo api.RepoName, commitID api.CommitID, dbFile string) {
+	s.indexedMu.Lock()
+	defer s.indexedMu.Unlock()
+
+	if s.indexed == nil {
+		s.indexed = map[api.RepoName][]indexedCommit{}
+	}
+	commits := []indexedCommit{{commitID: commitID, dbFile: dbFile}}
+	for _, c := range s.indexed[repo] {
+		if c.commitID != commitID && len(commits) < maxIndexedCommitsPerRepo {
+			commits = append(commits, c)
+		}
+	}
+	s.indexed[repo] = commits
+}
+
+// closestIndexedCommit returns the recently indexed commit of repo with the
+// fewest files that differ from commitID, along with the paths of the files
+// that were changed and deleted since that commit. It returns ok == false if
+// there is no such commit whose database is still in the disk cache, or if too
+// many files differ.
+func (s *Service) closestIndexedCommit(ctx context.Context, repo api.RepoName, commitID api.CommitID) (closest indexedCommit, changed, deleted []string, ok bool) {
+	if s.FetchTarPaths == nil || s.GitDiff == nil {
+		return indexedCommit{}, nil, nil, false
+	}
+
+	s.indexedMu.Lock()
+	candidates := append([]indexedCommit(nil), s.indexed[repo]...)
+	s.indexedMu.Unlock()
+
+	for _, c := range candidates {
+		if c.commitID == commitID {
+			continue
+		}
+		if _, err := os.Stat(c.dbFile); err != nil {
+			continue // evicted from the disk cache
+		}
+		cChanged, cDeleted, err := s.GitDiff(ctx, gitserver.Repo{Name: repo}, c.commitID, commitID)
+		if err != nil {
+			log15.Warn("Unable to diff commits for incremental symbol indexing.", "repo", repo, "from", c.commitID, "to", commitID, "error", err)
+			continue
+		}
+		n := len(cChanged) + len(cDeleted)
+		if n > maxIncrementalChangedPaths {
+			continue
+		}
+		if !ok || n < len(changed)+len(deleted) {
+			closest, changed, deleted, ok = c, cChanged, cDeleted, true
+		}
+	}
+	return closest, changed, deleted, ok
+}
+
+// writeSymbolsToNewDB writes the symbols of repo@commitID to the blank
+// database file `dbFile`. If the database of a recently indexed commit of the
+// repository is available, it is copied and only the files that differ
+// between the commits are parsed. Otherwise, all the files are parsed.
+func (s *Service) writeSymbolsToNewDB(ctx context.Context, dbFile string, repoName api.RepoName, commitID api.CommitID) error {
+	if prev, changed, deleted, ok := s.closestIndexedCommit(ctx, repoName, commitID); ok {
+		err := s.writeIncrementalSymbolsToNewDB(ctx, dbFile, prev.dbFile, repoName, commitID, changed, deleted)
+		if err == nil {
+			incrementalIndexes.Inc()
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		log15.Warn("Incremental symbol indexing failed, parsing all files instead.", "repo", repoName, "from", prev.commitID, "to", commitID, "error", err)
+		if err := os.Truncate(dbFile, 0); err != nil {
+			return err
+		}
+	}
+	return s.writeAllSymbolsToNewDB(ctx, dbFile, repoName, commitID)
+}
+
+// writeIncrementalSymbolsToNewDB copies the database `prevDBFile` of another
+// commit to `dbFile`, and then replaces the symbols of the files that were
+// changed or deleted since that commit with the symbols parsed from those
+// files at repo@commitID.
+func (s *Service) writeIncrementalSymbolsToNewDB(ctx context.Context, dbFile, prevDBFile string, repoName api.RepoName, commitID api.CommitID, changed, deleted []string) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "writeIncrementalSymbolsToNewDB")
+	span.SetTag("changed", len(changed))
+	span.SetTag("deleted", len(deleted))
+	defer func() {
+		if err != nil {
+			span.SetTag("err", err.Error())
+		}
+		span.Finish()
+	}()
+
+	if err := copyFile(dbFile, prevDBFile); err != nil {
+		return err
+	}
+
+	db, err := sqlx.Open("sqlite3_with_pcre", dbFile)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, paths := range [][]string{changed, deleted} {
+		for _, path := range paths {
+			if _, err := tx.Exec(`DELETE FROM symbols WHERE path = ?`, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(changed) > 0 {
+		insertStatement, err := prepareInsertSymbol(tx)
+		if err != nil {
+			return err
+		}
+
+		err = s.parseUncached(ctx, repoName, commitID, changed, func(symbol protocol.Symbol) error {
+			symbolInDBValue := symbolToSymbolInDB(symbol)
+			_, err := insertStatement.Exec(&symbolInDBValue)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// copyFile copies the contents of the file src to the existing file dst.
+func copyFile(dst, src string) error {
+	r, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := os.OpenFile(dst, os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+var incrementalIndexes = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "symbols",
+	Subsystem: "store",
+	Name:      "incremental_indexes",
+	Help:      "The total number of databases derived from the database of a previously indexed commit.",
+})
+
+func init() {
+	prometheus.MustRegister(incrementalIndexes)
+}
@@ -114,10 +114,11 @@ func (s *Service) search(ctx context.Context, args protocol.SearchArgs) (result
 
 // getDBFile returns the path to the sqlite3 database for the repo@commit
 // specified in `args`. If the database doesn't already exist in the disk cache,
-// it will create a new one and write all the symbols into it.
+// it will create a new one and write all the symbols into it (see
+// writeSymbolsToNewDB).
 func (s *Service) getDBFile(ctx context.Context, args protocol.SearchArgs) (string, error) {
 	diskcacheFile, err := s.cache.OpenWithPath(ctx, fmt.Sprintf("%d-%s@%s", symbolsDBVersion, args.Repo, args.CommitID), func(fetcherCtx context.Context, tempDBFile string) error {
-		err := s.writeAllSymbolsToNewDB(fetcherCtx, tempDBFile, args.Repo, args.CommitID)
+		err := s.writeSymbolsToNewDB(fetcherCtx, tempDBFile, args.Repo, args.CommitID)
 		if err != nil {
 			if err == context.Canceled {
 				log15.Error("Unable to parse repository symbols within the context", "repo", args.Repo, "commit", args.CommitID, "query", args.Query)
@@ -131,6 +132,7 @@ func (s *Service) getDBFile(ctx context.Context, args protocol.SearchArgs) (stri
 	}
 	defer diskcacheFile.File.Close()
 
+	s.recordIndexedCommit(args.Repo, args.CommitID, diskcacheFile.File.Name())
 	return diskcacheFile.File.Name(), err
 }
 
@@ -192,6 +194,28 @@ func filterSymbols(ctx context.Context, db *sqlx.DB, args protocol.SearchArgs) (
 		return conditions
 	}
 
+	// makeRegexpCondition is like makeCondition, but for columns without a
+	// lowercase variant (so exact matches can't use an index).
+	makeRegexpCondition := func(column string, regex string) []*sqlf.Query {
+		if regex == "" {
+			return nil
+		}
+		if !args.IsCaseSensitive {
+			regex = "(?i:" + regex + ")"
+		}
+		return []*sqlf.Query{sqlf.Sprintf(column+" REGEXP %s", regex)}
+	}
+
+	// makeOneOfCondition returns a condition that the column's value is one
+	// of the values, ignoring case.
+	makeOneOfCondition := func(column string, values []string) *sqlf.Query {
+		lowercaseValues := make([]*sqlf.Query, len(values))
+		for i, value := range values {
+			lowercaseValues[i] = sqlf.Sprintf("%s", strings.ToLower(value))
+		}
+		return sqlf.Sprintf("lower("+column+") IN (%s)", sqlf.Join(lowercaseValues, ","))
+	}
+
 	negateAll := func(oldConditions []*sqlf.Query) []*sqlf.Query {
 		newConditions := []*sqlf.Query{}
 
@@ -208,6 +232,19 @@ func filterSymbols(ctx context.Context, db *sqlx.DB, args protocol.SearchArgs) (
 		conditions = append(conditions, makeCondition("path", includePattern)...)
 	}
 	conditions = append(conditions, negateAll(makeCondition("path", args.ExcludePattern))...)
+	for _, includePattern := range args.IncludeContainerPatterns {
+		conditions = append(conditions, makeRegexpCondition("parent", includePattern)...)
+	}
+	conditions = append(conditions, negateAll(makeRegexpCondition("parent", args.ExcludeContainerPattern))...)
+	if len(args.Kinds) > 0 {
+		conditions = append(conditions, makeOneOfCondition("kind", args.Kinds))
+	}
+	if len(args.ExcludeKinds) > 0 {
+		conditions = append(conditions, negateAll([]*sqlf.Query{makeOneOfCondition("kind", args.ExcludeKinds)})...)
+	}
+	if len(args.Languages) > 0 {
+		conditions = append(conditions, makeOneOfCondition("language", args.Languages))
+	}
 
 	var sqlQuery *sqlf.Query
 	if len(conditions) == 0 {
@@ -347,16 +384,12 @@ func (s *Service) writeAllSymbolsToNewDB(ctx context.Context, dbFile string, rep
 		return err
 	}
 
-	insertStatement, err := tx.PrepareNamed(
-		fmt.Sprintf(
-			"INSERT INTO symbols %s VALUES %s",
-			"( name,  namelowercase,  path,  pathlowercase,  line,  kind,  language,  parent,  parentkind,  signature,  pattern,  filelimited)",
-			"(:name, :namelowercase, :path, :pathlowercase, :line, :kind, :language, :parent, :parentkind, :signature, :pattern, :filelimited)"))
+	insertStatement, err := prepareInsertSymbol(tx)
 	if err != nil {
 		return err
 	}
 
-	err = s.parseUncached(ctx, repoName, commitID, func(symbol protocol.Symbol) error {
+	err = s.parseUncached(ctx, repoName, commitID, nil, func(symbol protocol.Symbol) error {
 		symbolInDBValue := symbolToSymbolInDB(symbol)
 		_, err := insertStatement.Exec(&symbolInDBValue)
 		return err
@@ -372,3 +405,13 @@ func (s *Service) writeAllSymbolsToNewDB(ctx context.Context, dbFile string, rep
 
 	return nil
 }
+
+// prepareInsertSymbol prepares a statement that inserts a `symbolInDB` into
+// the symbols table.
+func prepareInsertSymbol(tx *sqlx.Tx) (*sqlx.NamedStmt, error) {
+	return tx.PrepareNamed(
+		fmt.Sprintf(
+			"INSERT INTO symbols %s VALUES %s",
+			"( name,  namelowercase,  path,  pathlowercase,  line,  kind,  language,  parent,  parentkind,  signature,  pattern,  filelimited)",
+			"(:name, :namelowercase, :path, :pathlowercase, :line, :kind, :language, :parent, :parentkind, :signature, :pattern, :filelimited)"))
+}
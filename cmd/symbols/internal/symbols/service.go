@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -22,6 +23,16 @@ type Service struct {
 	// determine if the error is a bad request (eg invalid repo).
 	FetchTar func(context.Context, gitserver.Repo, api.CommitID) (io.ReadCloser, error)
 
+	// FetchTarPaths is like FetchTar, except that the tar archive only includes the files with the
+	// specified paths. It is used to parse only the files that changed since a previously indexed
+	// commit (see GitDiff).
+	FetchTarPaths func(context.Context, gitserver.Repo, api.CommitID, []string) (io.ReadCloser, error)
+
+	// GitDiff returns the paths of the files that were changed (added or modified) and deleted
+	// between two commits of a repository. If GitDiff or FetchTarPaths is nil, the symbols of
+	// every commit are parsed from scratch.
+	GitDiff func(ctx context.Context, repo gitserver.Repo, a, b api.CommitID) (changed, deleted []string, err error)
+
 	// MaxConcurrentFetchTar is the maximum number of concurrent calls allowed
 	// to FetchTar. It defaults to 15.
 	MaxConcurrentFetchTar int
@@ -49,6 +60,14 @@ type Service struct {
 
 	// pool of ctags parser child processes
 	parsers chan ctags.Parser
+
+	// indexedMu protects indexed.
+	indexedMu sync.Mutex
+
+	// indexed is the most recently indexed commits of each repository, most
+	// recent first. It is used to find a database to derive the database of
+	// a new commit from.
+	indexed map[api.RepoName][]indexedCommit
 }
 
 // Start must be called before any requests are handled.
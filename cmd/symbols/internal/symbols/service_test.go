@@ -13,7 +13,9 @@ import (
 	"path"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/pkg/ctags"
@@ -87,7 +89,10 @@ func TestService(t *testing.T) {
 			return createTar(files)
 		},
 		NewParser: func() (ctags.Parser, error) {
-			return mockParser{"x", "y"}, nil
+			return mockParser{
+				{Name: "x", Kind: "variable", Language: "JavaScript"},
+				{Name: "y", Kind: "function", Language: "JavaScript", Parent: "x", ParentKind: "variable"},
+			}, nil
 		},
 		Path: tmpDir,
 	}
@@ -98,8 +103,8 @@ func TestService(t *testing.T) {
 	server := httptest.NewServer(service.Handler())
 	defer server.Close()
 	client := symbolsclient.Client{URL: server.URL}
-	x := protocol.Symbol{Name: "x", Path: "a.js"}
-	y := protocol.Symbol{Name: "y", Path: "a.js"}
+	x := protocol.Symbol{Name: "x", Path: "a.js", Kind: "variable", Language: "JavaScript"}
+	y := protocol.Symbol{Name: "y", Path: "a.js", Kind: "function", Language: "JavaScript", Parent: "x", ParentKind: "variable"}
 
 	tests := map[string]struct {
 		args protocol.SearchArgs
@@ -145,6 +150,34 @@ func TestService(t *testing.T) {
 			args: protocol.SearchArgs{ExcludePattern: "a.js", IsCaseSensitive: true, First: 10},
 			want: protocol.SearchResult{},
 		},
+		"kind": {
+			args: protocol.SearchArgs{Kinds: []string{"Function", "class"}, First: 10},
+			want: protocol.SearchResult{Symbols: []protocol.Symbol{y}},
+		},
+		"excludekind": {
+			args: protocol.SearchArgs{ExcludeKinds: []string{"function"}, First: 10},
+			want: protocol.SearchResult{Symbols: []protocol.Symbol{x}},
+		},
+		"language": {
+			args: protocol.SearchArgs{Languages: []string{"javascript"}, First: 10},
+			want: protocol.SearchResult{Symbols: []protocol.Symbol{x, y}},
+		},
+		"nolanguagematch": {
+			args: protocol.SearchArgs{Languages: []string{"go"}, First: 10},
+			want: protocol.SearchResult{},
+		},
+		"container": {
+			args: protocol.SearchArgs{IncludeContainerPatterns: []string{"^X$"}, First: 10},
+			want: protocol.SearchResult{Symbols: []protocol.Symbol{y}},
+		},
+		"casesensitivecontainer": {
+			args: protocol.SearchArgs{IncludeContainerPatterns: []string{"^X$"}, IsCaseSensitive: true, First: 10},
+			want: protocol.SearchResult{},
+		},
+		"excludecontainer": {
+			args: protocol.SearchArgs{ExcludeContainerPattern: "x", First: 10},
+			want: protocol.SearchResult{Symbols: []protocol.Symbol{x}},
+		},
 	}
 	for label, test := range tests {
 		t.Run(label, func(t *testing.T) {
@@ -159,6 +192,91 @@ func TestService(t *testing.T) {
 	}
 }
 
+func TestService_incremental(t *testing.T) {
+	MustRegisterSqlite3WithPcre()
+
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { os.RemoveAll(tmpDir) }()
+
+	commits := map[api.CommitID]map[string]string{
+		"c1": {"a.js": "a", "b.js": "b", "c.js": "c"},
+		"c2": {"a.js": "a2", "b.js": "b", "d.js": "d"},
+	}
+	var (
+		mu     sync.Mutex
+		parsed []string
+	)
+	service := Service{
+		FetchTar: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error) {
+			return createTar(commits[commit])
+		},
+		FetchTarPaths: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+			files := map[string]string{}
+			for _, p := range paths {
+				files[p] = commits[commit][p]
+			}
+			return createTar(files)
+		},
+		GitDiff: func(ctx context.Context, repo gitserver.Repo, a, b api.CommitID) (changed, deleted []string, err error) {
+			for name, body := range commits[b] {
+				if commits[a][name] != body {
+					changed = append(changed, name)
+				}
+			}
+			for name := range commits[a] {
+				if _, ok := commits[b][name]; !ok {
+					deleted = append(deleted, name)
+				}
+			}
+			return changed, deleted, nil
+		},
+		NewParser: func() (ctags.Parser, error) {
+			return contentParser(func(name string) {
+				mu.Lock()
+				parsed = append(parsed, name)
+				mu.Unlock()
+			}), nil
+		},
+		Path: tmpDir,
+	}
+
+	if err := service.Start(); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(service.Handler())
+	defer server.Close()
+	client := symbolsclient.Client{URL: server.URL}
+
+	search := func(commit api.CommitID) []string {
+		result, err := client.Search(context.Background(), protocol.SearchArgs{Repo: "r", CommitID: commit, First: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var symbols []string
+		for _, s := range result.Symbols {
+			symbols = append(symbols, s.Path+":"+s.Name)
+		}
+		sort.Strings(symbols)
+		return symbols
+	}
+
+	if got, want := search("c1"), []string{"a.js:a", "b.js:b", "c.js:c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got symbols %q, want %q", got, want)
+	}
+	parsed = nil
+
+	if got, want := search("c2"), []string{"a.js:a2", "b.js:b", "d.js:d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got symbols %q, want %q", got, want)
+	}
+	sort.Strings(parsed)
+	if want := []string{"a.js", "d.js"}; !reflect.DeepEqual(parsed, want) {
+		t.Errorf("got parsed files %q, want only the changed files %q", parsed, want)
+	}
+}
+
 func createTar(files map[string]string) (io.ReadCloser, error) {
 	buf := new(bytes.Buffer)
 	w := tar.NewWriter(buf)
@@ -183,14 +301,26 @@ func createTar(files map[string]string) (io.ReadCloser, error) {
 	return ioutil.NopCloser(bytes.NewReader(buf.Bytes())), nil
 }
 
-type mockParser []string
+type mockParser []ctags.Entry
 
 func (m mockParser) Parse(name string, content []byte) ([]ctags.Entry, error) {
 	entries := make([]ctags.Entry, len(m))
-	for i, name := range m {
-		entries[i] = ctags.Entry{Name: name, Path: "a.js"}
+	for i, e := range m {
+		entries[i] = e
+		entries[i].Path = "a.js"
 	}
 	return entries, nil
 }
 
 func (mockParser) Close() {}
+
+// contentParser is a parser that returns a symbol named after the contents of
+// each file, and calls the function for each parsed file.
+type contentParser func(name string)
+
+func (p contentParser) Parse(name string, content []byte) ([]ctags.Entry, error) {
+	p(name)
+	return []ctags.Entry{{Name: string(content), Path: name}}, nil
+}
+
+func (contentParser) Close() {}
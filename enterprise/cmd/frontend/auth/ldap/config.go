@@ -0,0 +1,118 @@
+package ldap
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+	goldap "gopkg.in/ldap.v3"
+)
+
+var mockGetProviderValue *provider
+
+// getProvider looks up the registered ldap auth provider with the given ID.
+func getProvider(id string) *provider {
+	if mockGetProviderValue != nil {
+		return mockGetProviderValue
+	}
+	p, _ := providers.GetProviderByConfigID(providers.ConfigID{Type: providerType, ID: id}).(*provider)
+	return p
+}
+
+func init() {
+	conf.ContributeValidator(validateConfig)
+}
+
+func validateConfig(c conf.Unified) (problems []string) {
+	seen := map[string]int{}
+	for i, p := range c.Critical.AuthProviders {
+		if p.Ldap == nil {
+			continue
+		}
+		if j, ok := seen[providerConfigID(p.Ldap)]; ok {
+			problems = append(problems, fmt.Sprintf("LDAP auth provider at index %d is duplicate of index %d, ignoring", i, j))
+			continue
+		}
+		seen[providerConfigID(p.Ldap)] = i
+
+		if p.Ldap.BindPassword != "" && p.Ldap.BindDN == "" {
+			problems = append(problems, fmt.Sprintf("LDAP auth provider at index %d has a bindPassword but no bindDN", i))
+		}
+		// 🚨 SECURITY: Users' passwords are sent to the LDAP server when they sign in, so they must
+		// not be sent in plain text unless the site admin explicitly allows it.
+		if strings.HasPrefix(p.Ldap.Url, "ldap://") && !p.Ldap.StartTLS && !p.Ldap.AllowInsecure {
+			problems = append(problems, fmt.Sprintf("LDAP auth provider at index %d sends passwords to the LDAP server in plain text (use an ldaps URL or startTLS, or set allowInsecure if the LDAP server is on a trusted network)", i))
+		}
+		pc := withDefaults(*p.Ldap)
+		if _, err := goldap.CompileFilter(pc.UserFilter); err != nil {
+			problems = append(problems, fmt.Sprintf("LDAP auth provider at index %d has an invalid userFilter: %s", i, err))
+		}
+		if pc.GroupSync != nil {
+			if _, err := goldap.CompileFilter(pc.GroupSync.Filter); err != nil {
+				problems = append(problems, fmt.Sprintf("LDAP auth provider at index %d has an invalid groupSync.filter: %s", i, err))
+			}
+		}
+	}
+	return problems
+}
+
+// withDefaults returns a copy of the provider config with the default values of unset optional
+// properties filled in.
+func withDefaults(pc schema.LDAPAuthProvider) schema.LDAPAuthProvider {
+	if pc.UserFilter == "" {
+		pc.UserFilter = "(objectClass=person)"
+	}
+	pc.UserFilter = parenthesizeFilter(pc.UserFilter)
+	if pc.UsernameAttribute == "" {
+		pc.UsernameAttribute = "uid"
+	}
+	if pc.EmailAttribute == "" {
+		pc.EmailAttribute = "mail"
+	}
+	if pc.DisplayNameAttribute == "" {
+		pc.DisplayNameAttribute = "cn"
+	}
+	if pc.GroupSync != nil {
+		gs := *pc.GroupSync
+		if gs.Filter == "" {
+			gs.Filter = "(|(objectClass=groupOfNames)(objectClass=groupOfUniqueNames)(objectClass=group))"
+		}
+		gs.Filter = parenthesizeFilter(gs.Filter)
+		if gs.MemberAttribute == "" {
+			gs.MemberAttribute = "member"
+		}
+		if gs.NameAttribute == "" {
+			gs.NameAttribute = "cn"
+		}
+		pc.GroupSync = &gs
+	}
+	return pc
+}
+
+// parenthesizeFilter returns the LDAP filter enclosed in parentheses, which RFC 4515 requires but
+// are often omitted from a filter that is not a combination of other filters (such as
+// "objectClass=person").
+func parenthesizeFilter(filter string) string {
+	filter = strings.TrimSpace(filter)
+	if !strings.HasPrefix(filter, "(") {
+		filter = "(" + filter + ")"
+	}
+	return filter
+}
+
+// providerConfigID produces a semi-stable identifier for an ldap auth provider config object. It
+// is used to distinguish between multiple auth providers of the same type. Its value is never
+// persisted, and it must be deterministic.
+func providerConfigID(pc *schema.LDAPAuthProvider) string {
+	data, err := json.Marshal(pc)
+	if err != nil {
+		panic(err)
+	}
+	b := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(b[:16])
+}
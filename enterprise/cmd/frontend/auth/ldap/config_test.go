@@ -0,0 +1,71 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestValidateCustom(t *testing.T) {
+	tests := map[string]struct {
+		input        conf.Unified
+		wantProblems []string
+	}{
+		"duplicates": {
+			input: conf.Unified{Critical: schema.CriticalConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldaps://x", UserBaseDN: "dc=x"}},
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldaps://x", UserBaseDN: "dc=x"}},
+				},
+			}},
+			wantProblems: []string{"LDAP auth provider at index 1 is duplicate of index 0"},
+		},
+		"bindPassword without bindDN": {
+			input: conf.Unified{Critical: schema.CriticalConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldaps://x", UserBaseDN: "dc=x", BindPassword: "p"}},
+				},
+			}},
+			wantProblems: []string{"has a bindPassword but no bindDN"},
+		},
+		"invalid filters": {
+			input: conf.Unified{Critical: schema.CriticalConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{
+						Type:       "ldap",
+						Url:        "ldaps://x",
+						UserBaseDN: "dc=x",
+						UserFilter: "(objectClass=person",
+						GroupSync:  &schema.LDAPGroupSync{BaseDN: "dc=x", Filter: "(&(cn=x)"},
+					}},
+				},
+			}},
+			wantProblems: []string{"invalid userFilter", "invalid groupSync.filter"},
+		},
+		"cleartext": {
+			input: conf.Unified{Critical: schema.CriticalConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserBaseDN: "dc=x"}},
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserBaseDN: "dc=x", StartTLS: true}},
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserBaseDN: "dc=y", AllowInsecure: true}},
+				},
+			}},
+			wantProblems: []string{"LDAP auth provider at index 0 sends passwords to the LDAP server in plain text"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf.TestValidator(t, test.input, validateConfig, test.wantProblems)
+		})
+	}
+}
+
+func TestProviderConfigID(t *testing.T) {
+	p := schema.LDAPAuthProvider{Url: "ldap://x"}
+	id1 := providerConfigID(&p)
+	id2 := providerConfigID(&p)
+	if id1 != id2 {
+		t.Errorf("id1 (%q) != id2 (%q)", id1, id2)
+	}
+}
@@ -0,0 +1,88 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/schema"
+	goldap "gopkg.in/ldap.v3"
+)
+
+// defaultTimeout is the timeout for LDAP operations if the context has no deadline.
+const defaultTimeout = 30 * time.Second
+
+// dial connects to the LDAP server of the provider config. If pc.StartTLS is true and the URL
+// scheme is ldap, the connection is upgraded to TLS before it is returned. The caller must call
+// Close when done with the connection.
+func dial(ctx context.Context, pc *schema.LDAPAuthProvider) (*goldap.Conn, error) {
+	u, err := url.Parse(pc.Url)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse LDAP server URL")
+	}
+	var defaultPort string
+	switch u.Scheme {
+	case "ldap":
+		defaultPort = "389"
+	case "ldaps":
+		defaultPort = "636"
+	default:
+		return nil, errors.Errorf("unsupported LDAP server URL scheme %q (must be ldap or ldaps)", u.Scheme)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), defaultPort)
+	}
+	tlsCfg, err := tlsConfig(pc, u.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	if err := nc.SetDeadline(deadline); err != nil {
+		nc.Close()
+		return nil, err
+	}
+
+	if u.Scheme == "ldaps" {
+		tc := tls.Client(nc, tlsCfg)
+		if err := tc.Handshake(); err != nil {
+			nc.Close()
+			return nil, errors.Wrap(err, "TLS handshake")
+		}
+		nc = tc
+	}
+	c := goldap.NewConn(nc, u.Scheme == "ldaps")
+	c.Start()
+	c.SetTimeout(time.Until(deadline))
+	if u.Scheme == "ldap" && pc.StartTLS {
+		if err := c.StartTLS(tlsCfg); err != nil {
+			c.Close()
+			return nil, errors.Wrap(err, "StartTLS")
+		}
+	}
+	return c, nil
+}
+
+func tlsConfig(pc *schema.LDAPAuthProvider, serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{ServerName: serverName}
+	if pc.Certificate != "" {
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM([]byte(pc.Certificate)) {
+			return nil, errors.New("invalid LDAP server TLS certificate")
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,305 @@
+package ldap
+
+import (
+	"bufio"
+	"encoding/asn1"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	goldap "gopkg.in/ldap.v3"
+)
+
+// testEntry is an entry in the directory of a testServer.
+type testEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+// testServer is a minimal in-memory LDAP server for tests. Only the service account (bindDN) may
+// search the directory.
+type testServer struct {
+	t         *testing.T
+	ln        net.Listener
+	bindDN    string
+	bindPW    string
+	directory []testEntry
+}
+
+// newTestServer starts a test LDAP server. The caller must call close.
+func newTestServer(t *testing.T, bindDN, bindPW string, directory []testEntry) *testServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{t: t, ln: ln, bindDN: bindDN, bindPW: bindPW, directory: directory}
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(nc)
+		}
+	}()
+	return s
+}
+
+func (s *testServer) url() string { return "ldap://" + s.ln.Addr().String() }
+
+func (s *testServer) close() { s.ln.Close() }
+
+func (s *testServer) serve(nc net.Conn) {
+	defer nc.Close()
+	r := bufio.NewReader(nc)
+	var boundDN string
+	for {
+		msg, err := testReadPacket(r)
+		if err != nil {
+			return
+		}
+		elems := testChildren(s.t, msg)
+		if len(elems) < 2 {
+			s.t.Error("malformed LDAP message")
+			return
+		}
+		msgID := testInt(elems[0])
+		op := elems[1]
+		reply := func(op []byte) {
+			nc.Write(testEncode(asn1.ClassUniversal, asn1.TagSequence, true, testMarshal(s.t, msgID), op))
+		}
+
+		switch op.Tag {
+		case goldap.ApplicationBindRequest:
+			parts := testChildren(s.t, op)
+			dn, password := string(parts[1].Bytes), string(parts[2].Bytes)
+			code := int64(goldap.LDAPResultInvalidCredentials)
+			switch {
+			case dn == "" && password == "":
+				code = goldap.LDAPResultSuccess
+			case dn == s.bindDN && password == s.bindPW:
+				code = goldap.LDAPResultSuccess
+			default:
+				for _, e := range s.directory {
+					if e.dn == dn && e.password == password {
+						code = goldap.LDAPResultSuccess
+					}
+				}
+			}
+			if code == goldap.LDAPResultSuccess {
+				boundDN = dn
+			}
+			reply(testResult(s.t, goldap.ApplicationBindResponse, code))
+
+		case goldap.ApplicationSearchRequest:
+			parts := testChildren(s.t, op)
+			baseDN := string(parts[0].Bytes)
+			sizeLimit := testInt(parts[3])
+			filter := parts[6]
+			if boundDN != s.bindDN {
+				reply(testResult(s.t, goldap.ApplicationSearchResultDone, goldap.LDAPResultInsufficientAccessRights))
+				continue
+			}
+			var n int64
+			code := int64(goldap.LDAPResultSuccess)
+			for _, e := range s.directory {
+				if !strings.HasSuffix(e.dn, ","+baseDN) || !testMatchFilter(s.t, filter, e) {
+					continue
+				}
+				if sizeLimit > 0 && n == sizeLimit {
+					code = goldap.LDAPResultSizeLimitExceeded
+					break
+				}
+				n++
+				reply(testSearchResultEntry(s.t, e))
+			}
+			reply(testResult(s.t, goldap.ApplicationSearchResultDone, code))
+
+		case goldap.ApplicationUnbindRequest:
+			return
+
+		default:
+			s.t.Errorf("unexpected LDAP operation tag %d", op.Tag)
+			return
+		}
+	}
+}
+
+// testReadPacket reads the next BER element from r. LDAP messages use definite lengths, so the
+// element can be decoded with encoding/asn1 once all of its octets have been read.
+func testReadPacket(r *bufio.Reader) (asn1.RawValue, error) {
+	var v asn1.RawValue
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return v, err
+	}
+	n := int(header[1])
+	if n >= 0x80 {
+		lenOctets := make([]byte, n&0x7f)
+		if _, err := io.ReadFull(r, lenOctets); err != nil {
+			return v, err
+		}
+		header = append(header, lenOctets...)
+		n = 0
+		for _, b := range lenOctets {
+			n = n<<8 | int(b)
+		}
+	}
+	data := make([]byte, len(header)+n)
+	copy(data, header)
+	if _, err := io.ReadFull(r, data[len(header):]); err != nil {
+		return v, err
+	}
+	_, err := asn1.Unmarshal(data, &v)
+	return v, err
+}
+
+// testChildren decodes the elements contained in the constructed element v. It is called by the
+// server goroutines, so it must not call t.Fatal.
+func testChildren(t *testing.T, v asn1.RawValue) []asn1.RawValue {
+	var elems []asn1.RawValue
+	for rest := v.Bytes; len(rest) > 0; {
+		var e asn1.RawValue
+		var err error
+		if rest, err = asn1.Unmarshal(rest, &e); err != nil {
+			t.Errorf("malformed BER element: %v", err)
+			return elems
+		}
+		elems = append(elems, e)
+	}
+	return elems
+}
+
+// testInt decodes an INTEGER or ENUMERATED element.
+func testInt(v asn1.RawValue) int64 {
+	var n int64
+	for i, b := range v.Bytes {
+		if i == 0 {
+			n = int64(int8(b))
+		} else {
+			n = n<<8 | int64(b)
+		}
+	}
+	return n
+}
+
+func testMarshal(t *testing.T, v interface{}) []byte {
+	b, err := asn1.Marshal(v)
+	if err != nil {
+		t.Errorf("marshal BER element: %v", err)
+	}
+	return b
+}
+
+// testEncode returns the encoding of an element with the given class and tag that contains the
+// concatenation of the given octets.
+func testEncode(class, tag int, compound bool, contents ...[]byte) []byte {
+	var data []byte
+	for _, c := range contents {
+		data = append(data, c...)
+	}
+	b, _ := asn1.Marshal(asn1.RawValue{Class: class, Tag: tag, IsCompound: compound, Bytes: data})
+	return b
+}
+
+func testResult(t *testing.T, tag int, code int64) []byte {
+	return testEncode(asn1.ClassApplication, tag, true,
+		testMarshal(t, asn1.Enumerated(code)),
+		testMarshal(t, []byte{}), // matchedDN
+		testMarshal(t, []byte{}), // diagnosticMessage
+	)
+}
+
+func testSearchResultEntry(t *testing.T, e testEntry) []byte {
+	var attrs [][]byte
+	for name, vals := range e.attrs {
+		var set [][]byte
+		for _, v := range vals {
+			set = append(set, testMarshal(t, []byte(v)))
+		}
+		attrs = append(attrs, testEncode(asn1.ClassUniversal, asn1.TagSequence, true,
+			testMarshal(t, []byte(name)),
+			testEncode(asn1.ClassUniversal, asn1.TagSet, true, set...),
+		))
+	}
+	return testEncode(asn1.ClassApplication, goldap.ApplicationSearchResultEntry, true,
+		testMarshal(t, []byte(e.dn)),
+		testEncode(asn1.ClassUniversal, asn1.TagSequence, true, attrs...),
+	)
+}
+
+// testMatchFilter reports whether the entry matches the BER-encoded filter. Attribute names and
+// values are compared case-insensitively. It is called by the server goroutines, so it must not
+// call t.Fatal.
+func testMatchFilter(t *testing.T, filter asn1.RawValue, e testEntry) bool {
+	values := func(attr string) []string {
+		for name, vals := range e.attrs {
+			if strings.EqualFold(name, attr) {
+				return vals
+			}
+		}
+		return nil
+	}
+
+	switch filter.Tag {
+	case goldap.FilterPresent:
+		return len(values(string(filter.Bytes))) > 0
+	case goldap.FilterAnd:
+		for _, f := range testChildren(t, filter) {
+			if !testMatchFilter(t, f, e) {
+				return false
+			}
+		}
+		return true
+	case goldap.FilterOr:
+		for _, f := range testChildren(t, filter) {
+			if testMatchFilter(t, f, e) {
+				return true
+			}
+		}
+		return false
+	case goldap.FilterNot:
+		return !testMatchFilter(t, testChildren(t, filter)[0], e)
+	case goldap.FilterEqualityMatch:
+		parts := testChildren(t, filter)
+		for _, v := range values(string(parts[0].Bytes)) {
+			if strings.EqualFold(v, string(parts[1].Bytes)) {
+				return true
+			}
+		}
+		return false
+	case goldap.FilterSubstrings:
+		parts := testChildren(t, filter)
+	nextValue:
+		for _, v := range values(string(parts[0].Bytes)) {
+			v = strings.ToLower(v)
+			for _, sub := range testChildren(t, parts[1]) {
+				s := strings.ToLower(string(sub.Bytes))
+				switch sub.Tag {
+				case goldap.FilterSubstringsInitial:
+					if !strings.HasPrefix(v, s) {
+						continue nextValue
+					}
+					v = v[len(s):]
+				case goldap.FilterSubstringsAny:
+					i := strings.Index(v, s)
+					if i == -1 {
+						continue nextValue
+					}
+					v = v[i+len(s):]
+				case goldap.FilterSubstringsFinal:
+					if !strings.HasSuffix(v, s) {
+						continue nextValue
+					}
+				}
+			}
+			return true
+		}
+		return false
+	default:
+		t.Errorf("unsupported filter tag %d", filter.Tag)
+		return false
+	}
+}
@@ -0,0 +1,119 @@
+// Package ldap implements auth via LDAP.
+package ldap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+// All LDAP endpoints are under this path prefix.
+const authPrefix = auth.AuthURLPrefix + "/ldap"
+
+// loginTimeout is the maximum duration of the LDAP operations performed to sign in a user.
+const loginTimeout = 30 * time.Second
+
+// Middleware is middleware for LDAP authentication, adding endpoints under the auth path prefix
+// ("/.auth") to sign in with the username and password of an account in an LDAP directory.
+//
+// Unlike the SSO auth providers, users do not leave Sourcegraph to sign in: the sign-in page posts
+// the credentials to the login endpoint, which verifies them against the LDAP server and starts a
+// new session.
+//
+// 🚨 SECURITY
+var Middleware = &auth.Middleware{
+	API: func(next http.Handler) http.Handler {
+		return next
+	},
+	App: func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, authPrefix+"/") {
+				authHandler(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	},
+}
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// authHandler handles the LDAP login endpoint.
+//
+// 🚨 SECURITY
+func authHandler(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, authPrefix) {
+	case "/login":
+		if r.Method != "POST" {
+			http.Error(w, fmt.Sprintf("Unsupported method %s", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		// 🚨 SECURITY: This endpoint is not protected by the app's CSRF middleware (because auth
+		// middleware runs first), so require a header that cross-origin HTML forms can't set, to
+		// prevent login CSRF. The web app sets it on all of its requests.
+		if r.Header.Get("X-Requested-With") == "" {
+			http.Error(w, "Missing X-Requested-With header.", http.StatusForbidden)
+			return
+		}
+
+		p := getProvider(r.URL.Query().Get("pc"))
+		if p == nil {
+			log15.Error("No LDAP auth provider found with ID.", "id", r.URL.Query().Get("pc"))
+			http.Error(w, "Misconfigured LDAP auth provider.", http.StatusInternalServerError)
+			return
+		}
+
+		var creds credentials
+		if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+			http.Error(w, "Could not decode request body", http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), loginTimeout)
+		defer cancel()
+
+		info, err := authenticate(ctx, &p.config, creds.Username, creds.Password)
+		if err == errInvalidCredentials {
+			http.Error(w, "Authentication failed", http.StatusUnauthorized)
+			return
+		} else if err != nil {
+			log15.Error("Error authenticating with LDAP.", "username", creds.Username, "err", err)
+			http.Error(w, "Unexpected error authenticating with the LDAP server. Ask a site admin for help.", http.StatusInternalServerError)
+			return
+		}
+
+		actor, safeErrMsg, err := getOrCreateUser(ctx, &p.config, info)
+		if err != nil {
+			log15.Error("Error looking up LDAP-authenticated user.", "err", err, "userErr", safeErrMsg)
+			http.Error(w, safeErrMsg, http.StatusInternalServerError)
+			return
+		}
+
+		if gs := p.config.GroupSync; gs != nil {
+			// Failing to sync org memberships should not prevent the user from signing in.
+			if err := syncOrgMemberships(ctx, gs, actor.UID, info.Groups); err != nil {
+				log15.Error("Error syncing LDAP group memberships to organization memberships.", "userID", actor.UID, "err", err)
+			}
+		}
+
+		if err := session.SetActor(w, r, actor, 0); err != nil {
+			log15.Error("Error setting LDAP-authenticated actor in session.", "err", err)
+			http.Error(w, "Error starting LDAP-authenticated session. Try signing in again.", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		http.Error(w, "", http.StatusNotFound)
+	}
+}
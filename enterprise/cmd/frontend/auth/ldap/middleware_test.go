@@ -0,0 +1,141 @@
+package ldap
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestMiddleware(t *testing.T) {
+	cleanup := session.ResetMockSessionStore(t)
+	defer cleanup()
+
+	s := newTestServer(t, testBindDN, testBindPW, testDirectory)
+	defer s.close()
+
+	mockGetProviderValue = &provider{
+		config: schema.LDAPAuthProvider{
+			Type:         providerType,
+			Url:          s.url(),
+			BindDN:       testBindDN,
+			BindPassword: testBindPW,
+			UserBaseDN:   "ou=people,dc=example,dc=com",
+			GroupSync: &schema.LDAPGroupSync{
+				BaseDN: "ou=groups,dc=example,dc=com",
+				Orgs:   map[string]string{"engineering": "eng"},
+			},
+		},
+	}
+	defer func() { mockGetProviderValue = nil }()
+
+	const mockUserID = 123
+	var gotOp *auth.GetAndSaveUserOp
+	auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (userID int32, safeErrMsg string, err error) {
+		gotOp = &op
+		return mockUserID, "", nil
+	}
+	defer func() { auth.MockGetAndSaveUser = nil }()
+
+	db.Mocks.Orgs.GetByName = func(ctx context.Context, name string) (*types.Org, error) {
+		return &types.Org{ID: 1, Name: name}, nil
+	}
+	db.Mocks.OrgMembers.GetByOrgIDAndUserID = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+		return nil, &db.ErrOrgMemberNotFound{}
+	}
+	var joinedOrgs []int32
+	db.Mocks.OrgMembers.Create = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+		if userID != mockUserID {
+			t.Errorf("got user ID %d, want %d", userID, mockUserID)
+		}
+		joinedOrgs = append(joinedOrgs, orgID)
+		return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	authedHandler := http.NewServeMux()
+	authedHandler.Handle("/.api/", Middleware.API(h))
+	authedHandler.Handle("/", Middleware.App(h))
+
+	loginURL := mockGetProviderValue.CachedInfo().AuthenticationURL
+	doRequest := func(method, urlStr, body string, xhr bool) *http.Response {
+		req := httptest.NewRequest(method, "http://example.com"+urlStr, bytes.NewBufferString(body))
+		if xhr {
+			req.Header.Set("X-Requested-With", "Sourcegraph")
+		}
+		respRecorder := httptest.NewRecorder()
+		authedHandler.ServeHTTP(respRecorder, req)
+		return respRecorder.Result()
+	}
+
+	t.Run("other app request", func(t *testing.T) {
+		if resp := doRequest("GET", "/", "", false); resp.StatusCode != http.StatusOK {
+			t.Errorf("got response code %v, want %v", resp.StatusCode, http.StatusOK)
+		}
+	})
+	t.Run("GET login", func(t *testing.T) {
+		if resp := doRequest("GET", loginURL, "", true); resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("got response code %v, want %v", resp.StatusCode, http.StatusMethodNotAllowed)
+		}
+	})
+	t.Run("login without X-Requested-With header", func(t *testing.T) {
+		if resp := doRequest("POST", loginURL, `{"username":"alice","password":"alicepw"}`, false); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("got response code %v, want %v", resp.StatusCode, http.StatusForbidden)
+		}
+		if gotOp != nil {
+			t.Error("unexpected user lookup")
+		}
+	})
+	t.Run("login with wrong password", func(t *testing.T) {
+		resp := doRequest("POST", loginURL, `{"username":"alice","password":"wrong"}`, true)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("got response code %v, want %v", resp.StatusCode, http.StatusUnauthorized)
+		}
+		if len(resp.Cookies()) != 0 {
+			t.Errorf("got cookies %v, want none", resp.Cookies())
+		}
+		if gotOp != nil {
+			t.Error("unexpected user lookup")
+		}
+	})
+	t.Run("login", func(t *testing.T) {
+		resp := doRequest("POST", loginURL, `{"username":"alice","password":"alicepw"}`, true)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("got response code %v, want %v", resp.StatusCode, http.StatusOK)
+		}
+		if len(resp.Cookies()) == 0 {
+			t.Error("got no session cookie")
+		}
+		if gotOp == nil {
+			t.Fatal("got no user lookup")
+		}
+		if want := (db.NewUser{Username: "alice", Email: "alice@example.com", EmailIsVerified: true, DisplayName: "Alice Smith"}); gotOp.UserProps != want {
+			t.Errorf("got user props %+v, want %+v", gotOp.UserProps, want)
+		}
+		wantAccount := extsvc.ExternalAccountSpec{
+			ServiceType: "ldap",
+			ServiceID:   "ldap://" + s.ln.Addr().String() + "/",
+			ClientID:    "ou=people,dc=example,dc=com",
+			AccountID:   testDN,
+		}
+		if gotOp.ExternalAccount != wantAccount {
+			t.Errorf("got external account %+v, want %+v", gotOp.ExternalAccount, wantAccount)
+		}
+		if !gotOp.CreateIfNotExist {
+			t.Error("got CreateIfNotExist false, want true")
+		}
+		if want := []int32{1}; !reflect.DeepEqual(joinedOrgs, want) {
+			t.Errorf("got joined orgs %v, want %v", joinedOrgs, want)
+		}
+	})
+}
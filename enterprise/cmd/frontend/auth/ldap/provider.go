@@ -0,0 +1,64 @@
+package ldap
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const providerType = "ldap"
+
+type provider struct {
+	config schema.LDAPAuthProvider
+}
+
+// ConfigID implements providers.Provider.
+func (p *provider) ConfigID() providers.ConfigID {
+	return providers.ConfigID{
+		Type: providerType,
+		ID:   providerConfigID(&p.config),
+	}
+}
+
+// Config implements providers.Provider.
+func (p *provider) Config() schema.AuthProviders {
+	return schema.AuthProviders{Ldap: &p.config}
+}
+
+// Refresh implements providers.Provider.
+func (p *provider) Refresh(context.Context) error { return nil }
+
+// CachedInfo implements providers.Provider.
+func (p *provider) CachedInfo() *providers.Info {
+	info := providers.Info{
+		ServiceID:   serviceID(&p.config),
+		ClientID:    p.config.UserBaseDN,
+		DisplayName: p.config.DisplayName,
+		// The sign-in page shows a username and password form for this provider, which is
+		// submitted to this URL.
+		AuthenticationURL: (&url.URL{
+			Path:     path.Join(authPrefix, "login"),
+			RawQuery: (url.Values{"pc": []string{providerConfigID(&p.config)}}).Encode(),
+		}).String(),
+	}
+	if info.DisplayName == "" {
+		info.DisplayName = "LDAP"
+	}
+	return &info
+}
+
+// serviceID returns the service ID of external accounts authenticated by the LDAP server of the
+// provider config, such as "ldaps://ldap.example.com/".
+func serviceID(pc *schema.LDAPAuthProvider) string {
+	u, err := url.Parse(pc.Url)
+	if err != nil {
+		return pc.Url
+	}
+	u.Host = strings.ToLower(u.Host)
+	u.Path = "/"
+	return u.String()
+}
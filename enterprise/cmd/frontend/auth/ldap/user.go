@@ -0,0 +1,220 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/errcode"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+	goldap "gopkg.in/ldap.v3"
+)
+
+// errInvalidCredentials is returned by authenticate if the username or password is incorrect.
+var errInvalidCredentials = errors.New("invalid LDAP username or password")
+
+// userInfo is the information about a user that is read from the directory.
+type userInfo struct {
+	DN          string   `json:"dn"`
+	Username    string   `json:"username"`
+	Email       string   `json:"email,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Groups      []string `json:"groups,omitempty"` // only if group sync is enabled
+}
+
+// authenticate looks up the directory entry of the user with the given username and verifies the
+// password by binding as that entry. If group sync is enabled, it also looks up the names of the
+// groups that the user is a member of.
+//
+// 🚨 SECURITY: It returns errInvalidCredentials unless the username matches exactly one entry (that
+// matches the configured user filter) and the password is correct for that entry.
+func authenticate(ctx context.Context, pc *schema.LDAPAuthProvider, username, password string) (*userInfo, error) {
+	// 🚨 SECURITY: A simple bind with a DN and an empty password is an "unauthenticated" bind (RFC
+	// 4513 section 5.1.2), which many servers allow and report as successful. It must not be
+	// mistaken for a correct password.
+	if username == "" || password == "" {
+		return nil, errInvalidCredentials
+	}
+
+	cfg := withDefaults(*pc)
+	c, err := dial(ctx, &cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "connect to LDAP server")
+	}
+	defer c.Close()
+
+	if err := bindAsService(c, &cfg); err != nil {
+		return nil, errors.Wrap(err, "bind as bindDN")
+	}
+
+	res, err := c.Search(&goldap.SearchRequest{
+		BaseDN:       cfg.UserBaseDN,
+		Scope:        goldap.ScopeWholeSubtree,
+		DerefAliases: goldap.NeverDerefAliases,
+		SizeLimit:    2,
+		// 🚨 SECURITY: The username must be escaped to prevent LDAP filter injection.
+		Filter:     fmt.Sprintf("(&%s(%s=%s))", cfg.UserFilter, cfg.UsernameAttribute, goldap.EscapeFilter(username)),
+		Attributes: []string{cfg.UsernameAttribute, cfg.EmailAttribute, cfg.DisplayNameAttribute},
+	})
+	if goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+		return nil, errors.Errorf("multiple LDAP entries match username %q", username)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "search for user")
+	}
+	switch len(res.Entries) {
+	case 0:
+		return nil, errInvalidCredentials
+	case 1:
+	default:
+		return nil, errors.Errorf("multiple LDAP entries match username %q", username)
+	}
+	user := res.Entries[0]
+
+	if err := c.Bind(user.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, errInvalidCredentials
+		}
+		return nil, errors.Wrap(err, "bind as user")
+	}
+
+	info := &userInfo{
+		DN:          user.DN,
+		Username:    attributeValue(user, cfg.UsernameAttribute),
+		Email:       attributeValue(user, cfg.EmailAttribute),
+		DisplayName: attributeValue(user, cfg.DisplayNameAttribute),
+	}
+	if info.Username == "" {
+		info.Username = username
+	}
+
+	if gs := cfg.GroupSync; gs != nil {
+		// Search for groups with the permissions of bindDN (or anonymously), not of the user.
+		if err := bindAsService(c, &cfg); err != nil {
+			return nil, errors.Wrap(err, "bind as bindDN to search for groups")
+		}
+		res, err := c.Search(&goldap.SearchRequest{
+			BaseDN:       gs.BaseDN,
+			Scope:        goldap.ScopeWholeSubtree,
+			DerefAliases: goldap.NeverDerefAliases,
+			Filter:       fmt.Sprintf("(&%s(%s=%s))", gs.Filter, gs.MemberAttribute, goldap.EscapeFilter(user.DN)),
+			Attributes:   []string{gs.NameAttribute},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "search for groups of user")
+		}
+		for _, g := range res.Entries {
+			if name := attributeValue(g, gs.NameAttribute); name != "" {
+				info.Groups = append(info.Groups, name)
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// bindAsService binds as the bindDN of the provider config, or anonymously if it has none.
+func bindAsService(c *goldap.Conn, pc *schema.LDAPAuthProvider) error {
+	if pc.BindDN == "" {
+		return c.UnauthenticatedBind("")
+	}
+	return c.Bind(pc.BindDN, pc.BindPassword)
+}
+
+// attributeValue returns the first value of the entry's attribute, or "" if the entry has no such
+// attribute. Attribute names are case-insensitive.
+func attributeValue(e *goldap.Entry, attr string) string {
+	for _, a := range e.Attributes {
+		if strings.EqualFold(a.Name, attr) && len(a.Values) > 0 {
+			return a.Values[0]
+		}
+	}
+	return ""
+}
+
+// getOrCreateUser returns the actor of the Sourcegraph user associated with the LDAP user, creating
+// the user if needed.
+func getOrCreateUser(ctx context.Context, pc *schema.LDAPAuthProvider, info *userInfo) (_ *actor.Actor, safeErrMsg string, err error) {
+	login, err := auth.NormalizeUsername(info.Username)
+	if err != nil {
+		return nil, fmt.Sprintf("Error normalizing the username %q. See https://docs.sourcegraph.com/admin/auth/#username-normalization.", info.Username), err
+	}
+
+	var data extsvc.ExternalAccountData
+	data.SetAccountData(info)
+
+	userID, safeErrMsg, err := auth.GetAndSaveUser(ctx, auth.GetAndSaveUserOp{
+		UserProps: db.NewUser{
+			Username: login,
+			Email:    info.Email,
+			// The directory is managed by the organization's administrators, so its email addresses
+			// are trusted.
+			EmailIsVerified: info.Email != "",
+			DisplayName:     info.DisplayName,
+		},
+		ExternalAccount: extsvc.ExternalAccountSpec{
+			ServiceType: providerType,
+			ServiceID:   serviceID(pc),
+			ClientID:    pc.UserBaseDN,
+			AccountID:   info.DN,
+		},
+		ExternalAccountData: data,
+		CreateIfNotExist:    true,
+	})
+	if err != nil {
+		return nil, safeErrMsg, err
+	}
+	return actor.FromUser(userID), "", nil
+}
+
+// syncOrgMemberships adds the user to the organizations that the user's LDAP groups are mapped to,
+// and removes the user from the other mapped organizations. Organizations that are not mapped to
+// any group are not affected.
+func syncOrgMemberships(ctx context.Context, gs *schema.LDAPGroupSync, userID int32, groups []string) error {
+	want := map[string]bool{} // org name -> whether the user should be a member
+	for _, orgName := range gs.Orgs {
+		want[orgName] = false
+	}
+	for _, group := range groups {
+		if orgName, ok := gs.Orgs[group]; ok {
+			want[orgName] = true
+		}
+	}
+
+	var multi *multierror.Error
+	for orgName, member := range want {
+		if err := syncOrgMembership(ctx, orgName, userID, member); err != nil {
+			multi = multierror.Append(multi, errors.Wrapf(err, "organization %q", orgName))
+		}
+	}
+	return multi.ErrorOrNil()
+}
+
+func syncOrgMembership(ctx context.Context, orgName string, userID int32, member bool) error {
+	org, err := db.Orgs.GetByName(ctx, orgName)
+	if _, ok := err.(*db.OrgNotFoundError); ok {
+		log15.Warn("Organization in LDAP auth provider groupSync.orgs does not exist.", "org", orgName)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	_, err = db.OrgMembers.GetByOrgIDAndUserID(ctx, org.ID, userID)
+	switch {
+	case err == nil && !member:
+		return db.OrgMembers.Remove(ctx, org.ID, userID)
+	case errcode.IsNotFound(err) && member:
+		_, err := db.OrgMembers.Create(ctx, org.ID, userID)
+		return err
+	case errcode.IsNotFound(err):
+		return nil
+	default:
+		return err
+	}
+}
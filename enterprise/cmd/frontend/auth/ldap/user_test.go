@@ -0,0 +1,179 @@
+package ldap
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	testBindDN = "cn=sourcegraph,ou=services,dc=example,dc=com"
+	testBindPW = "s3cret"
+	testDN     = "uid=alice,ou=people,dc=example,dc=com"
+)
+
+var testDirectory = []testEntry{
+	{
+		dn:       testDN,
+		password: "alicepw",
+		attrs: map[string][]string{
+			"objectClass": {"top", "person", "inetOrgPerson"},
+			"uid":         {"alice"},
+			"mail":        {"alice@example.com"},
+			"cn":          {"Alice Smith"},
+		},
+	},
+	{
+		dn:       "uid=bob,ou=people,dc=example,dc=com",
+		password: "bobpw",
+		attrs: map[string][]string{
+			"objectClass": {"top", "person"},
+			"uid":         {"bob"},
+			"cn":          {"Bob"},
+		},
+	},
+	{
+		dn:       "uid=carol,ou=contractors,ou=people,dc=example,dc=com",
+		password: "carolpw",
+		attrs:    map[string][]string{"objectClass": {"person"}, "uid": {"carol"}},
+	},
+	{
+		dn:       "uid=carol,ou=people,dc=example,dc=com",
+		password: "carolpw",
+		attrs:    map[string][]string{"objectClass": {"person"}, "uid": {"carol"}},
+	},
+	{
+		dn:    "cn=engineering,ou=groups,dc=example,dc=com",
+		attrs: map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"engineering"}, "member": {testDN}},
+	},
+	{
+		dn:    "cn=everyone,ou=groups,dc=example,dc=com",
+		attrs: map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"everyone"}, "member": {testDN, "uid=bob,ou=people,dc=example,dc=com"}},
+	},
+	{
+		dn:    "cn=design,ou=groups,dc=example,dc=com",
+		attrs: map[string][]string{"objectClass": {"groupOfNames"}, "cn": {"design"}, "member": {"uid=bob,ou=people,dc=example,dc=com"}},
+	},
+}
+
+func TestAuthenticate(t *testing.T) {
+	s := newTestServer(t, testBindDN, testBindPW, testDirectory)
+	defer s.close()
+
+	pc := schema.LDAPAuthProvider{
+		Type:         providerType,
+		Url:          s.url(),
+		BindDN:       testBindDN,
+		BindPassword: testBindPW,
+		UserBaseDN:   "ou=people,dc=example,dc=com",
+		GroupSync:    &schema.LDAPGroupSync{BaseDN: "ou=groups,dc=example,dc=com"},
+	}
+	ctx := context.Background()
+
+	t.Run("valid credentials", func(t *testing.T) {
+		info, err := authenticate(ctx, &pc, "alice", "alicepw")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(info.Groups)
+		want := &userInfo{
+			DN:          testDN,
+			Username:    "alice",
+			Email:       "alice@example.com",
+			DisplayName: "Alice Smith",
+			Groups:      []string{"engineering", "everyone"},
+		}
+		if !reflect.DeepEqual(info, want) {
+			t.Errorf("got %+v, want %+v", info, want)
+		}
+	})
+
+	for name, test := range map[string]struct {
+		username, password string
+		userFilter         string
+	}{
+		"wrong password":     {username: "alice", password: "bobpw"},
+		"empty password":     {username: "alice", password: ""},
+		"unknown user":       {username: "mallory", password: "alicepw"},
+		"wildcard username":  {username: "*", password: "alicepw"},
+		"injected filter":    {username: "alice)(uid=*", password: "alicepw"},
+		"excluded by filter": {username: "bob", password: "bobpw", userFilter: "(objectClass=inetOrgPerson)"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			pc := pc
+			pc.UserFilter = test.userFilter
+			if _, err := authenticate(ctx, &pc, test.username, test.password); err != errInvalidCredentials {
+				t.Errorf("got error %v, want %v", err, errInvalidCredentials)
+			}
+		})
+	}
+
+	t.Run("username matches multiple entries", func(t *testing.T) {
+		if _, err := authenticate(ctx, &pc, "carol", "carolpw"); err == nil || err == errInvalidCredentials {
+			t.Errorf("got error %v, want error about multiple entries", err)
+		}
+	})
+
+	t.Run("wrong bindPassword", func(t *testing.T) {
+		pc := pc
+		pc.BindPassword = "wrong"
+		if _, err := authenticate(ctx, &pc, "alice", "alicepw"); err == nil || err == errInvalidCredentials {
+			t.Errorf("got error %v, want bind error", err)
+		}
+	})
+}
+
+func TestSyncOrgMemberships(t *testing.T) {
+	orgs := map[string]*types.Org{
+		"eng":    {ID: 1, Name: "eng"},
+		"design": {ID: 2, Name: "design"},
+		"sales":  {ID: 3, Name: "sales"},
+	}
+	db.Mocks.Orgs.GetByName = func(ctx context.Context, name string) (*types.Org, error) {
+		if org, ok := orgs[name]; ok {
+			return org, nil
+		}
+		return nil, &db.OrgNotFoundError{Message: name}
+	}
+	members := map[int32]bool{2: true, 3: true} // org ID -> whether user 123 is a member
+	db.Mocks.OrgMembers.GetByOrgIDAndUserID = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+		if userID != 123 {
+			t.Errorf("got user ID %d, want 123", userID)
+		}
+		if members[orgID] {
+			return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+		}
+		return nil, &db.ErrOrgMemberNotFound{}
+	}
+	db.Mocks.OrgMembers.Create = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+		members[orgID] = true
+		return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+	}
+	db.Mocks.OrgMembers.Remove = func(ctx context.Context, orgID, userID int32) error {
+		delete(members, orgID)
+		return nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	gs := &schema.LDAPGroupSync{
+		Orgs: map[string]string{
+			"engineering": "eng",
+			"platform":    "eng",
+			"design":      "design",
+			"nonexistent": "nonexistent",
+		},
+	}
+	if err := syncOrgMemberships(context.Background(), gs, 123, []string{"platform", "everyone"}); err != nil {
+		t.Fatal(err)
+	}
+	// The user is added to eng (via platform) and removed from design. The user's membership in
+	// sales is not affected, because sales is not mapped to any group.
+	if want := map[int32]bool{1: true, 3: true}; !reflect.DeepEqual(members, want) {
+		t.Errorf("got org memberships %v, want %v", members, want)
+	}
+}
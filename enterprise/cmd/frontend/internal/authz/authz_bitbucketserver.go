@@ -0,0 +1,71 @@
+package authz
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	permbbs "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func bitbucketServerProviders(ctx context.Context, bitbucketServers []*schema.BitbucketServerConnection) (
+	authzProviders []authz.Provider,
+	seriousProblems []string,
+	warnings []string,
+) {
+	for _, b := range bitbucketServers {
+		p, err := bitbucketServerProvider(b.Authorization, b.Url)
+		if err != nil {
+			seriousProblems = append(seriousProblems, err.Error())
+			continue
+		}
+		if p != nil {
+			authzProviders = append(authzProviders, p)
+		}
+	}
+	return authzProviders, seriousProblems, warnings
+}
+
+func bitbucketServerProvider(a *schema.BitbucketServerAuthorization, instanceURL string) (authz.Provider, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	bbsURL, err := url.Parse(instanceURL)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse URL for Bitbucket Server instance %q: %s", instanceURL, err)
+	}
+
+	switch idp := a.IdentityProvider.Type; idp {
+	case "username", "email":
+	default:
+		return nil, fmt.Errorf("No valid identityProvider was specified for Bitbucket Server instance %q (got %q)", instanceURL, idp)
+	}
+
+	signingKey, err := base64.StdEncoding.DecodeString(a.Oauth.SigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("Could not decode the OAuth signing key for Bitbucket Server instance %q (it must be base64-encoded): %s", instanceURL, err)
+	}
+	client := bitbucketserver.NewClient(bbsURL, nil)
+	if err := client.SetOAuth(a.Oauth.ConsumerKey, signingKey); err != nil {
+		return nil, fmt.Errorf("Invalid OAuth signing key for Bitbucket Server instance %q: %s", instanceURL, err)
+	}
+
+	ttl, err := parseTTL(a.Ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return permbbs.NewProvider(client, a.IdentityProvider.Type, ttl, nil), nil
+}
+
+// ValidateBitbucketServerAuthz validates the authorization fields of the given Bitbucket Server
+// external service config.
+func ValidateBitbucketServerAuthz(cfg *schema.BitbucketServerConnection) error {
+	_, err := bitbucketServerProvider(cfg.Authorization, cfg.Url)
+	return err
+}
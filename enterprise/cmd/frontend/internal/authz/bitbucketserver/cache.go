@@ -0,0 +1,67 @@
+package bitbucketserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// cache describes the shape of the repo permissions cache that Provider uses internally.
+type cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, b []byte)
+	Delete(key string)
+}
+
+// userReposCacheKey returns the key for caching the IDs of the repositories that the given
+// Bitbucket Server user can read. This key must be distinct from publicReposCacheKey.
+func userReposCacheKey(bitbucketAccountID string) string {
+	return fmt.Sprintf("u:%s", bitbucketAccountID)
+}
+
+// publicReposCacheKey is the key for caching the IDs of the public repositories, which anyone
+// can read.
+const publicReposCacheKey = "public"
+
+type reposCacheVal struct {
+	// IDs are the IDs of the repositories that can be read.
+	IDs []int
+
+	TTL time.Duration
+}
+
+// cacheGetRepos returns the set of repository IDs that is cached under the given key. If the
+// cached value was stored with a different TTL, it is invalid and is deleted.
+func cacheGetRepos(c cache, key string, ttl time.Duration) (ids map[int]bool, exists bool) {
+	b, exists := c.Get(key)
+	if !exists {
+		return nil, false
+	}
+	var v reposCacheVal
+	if err := json.Unmarshal(b, &v); err != nil {
+		c.Delete(key)
+		return nil, false
+	}
+	if v.TTL != ttl {
+		c.Delete(key)
+		return nil, false
+	}
+	ids = make(map[int]bool, len(v.IDs))
+	for _, id := range v.IDs {
+		ids[id] = true
+	}
+	return ids, true
+}
+
+func cacheSetRepos(c cache, key string, ids map[int]bool, ttl time.Duration) error {
+	v := reposCacheVal{IDs: make([]int, 0, len(ids)), TTL: ttl}
+	for id := range ids {
+		v.IDs = append(v.IDs, id)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.Set(key, b)
+	return nil
+}
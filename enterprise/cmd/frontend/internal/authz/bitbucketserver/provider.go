@@ -0,0 +1,268 @@
+// Package bitbucketserver contains an authorization provider for Bitbucket Server.
+package bitbucketserver
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/pkg/rcache"
+)
+
+// Provider implements authz.Provider for Bitbucket Server repository permissions.
+//
+// It determines the repositories that a user can read by listing the repositories on Bitbucket
+// Server while impersonating the user, which requires the OAuth credentials of an Application
+// Link that allows impersonation.
+type Provider struct {
+	client           *bitbucketserver.Client
+	codeHost         *bitbucketserver.CodeHost
+	identityProvider string
+	cacheTTL         time.Duration
+	cache            cache
+}
+
+var _ authz.Provider = ((*Provider)(nil))
+
+// NewProvider returns a new Bitbucket Server authorization provider that uses the given client,
+// which must be configured with the OAuth credentials of an Application Link (see
+// (*bitbucketserver.Client).SetOAuth). identityProvider defines how Sourcegraph users are mapped
+// to Bitbucket Server users: "username" maps them by username and "email" maps them by verified
+// email address.
+func NewProvider(client *bitbucketserver.Client, identityProvider string, cacheTTL time.Duration, mockCache cache) *Provider {
+	p := &Provider{
+		client:           client,
+		codeHost:         bitbucketserver.NewCodeHost(client.URL),
+		identityProvider: identityProvider,
+		cacheTTL:         cacheTTL,
+		cache:            mockCache,
+	}
+	// Note: this will use the same underlying Redis instance and key namespace for every instance
+	// of Provider.  This is by design, so that different instances, even in different processes,
+	// will share cache entries.
+	if p.cache == nil {
+		p.cache = rcache.NewWithTTL(fmt.Sprintf("bitbucketServerAuthz:%s", p.codeHost.ServiceID()), int(math.Ceil(cacheTTL.Seconds())))
+	}
+	return p
+}
+
+// Repos implements the authz.Provider interface.
+func (p *Provider) Repos(ctx context.Context, repos map[authz.Repo]struct{}) (mine map[authz.Repo]struct{}, others map[authz.Repo]struct{}) {
+	return authz.GetCodeHostRepos(p.codeHost, repos)
+}
+
+// RepoPerms implements the authz.Provider interface.
+//
+// A user can read the repositories that Bitbucket Server lists when impersonating them. Users
+// without a Bitbucket Server account (and anonymous users) can only read public repositories. Both
+// lists are cached for the provider's cache TTL.
+func (p *Provider) RepoPerms(ctx context.Context, account *extsvc.ExternalAccount, repos map[authz.Repo]struct{}) (map[api.RepoName]map[authz.Perm]bool, error) {
+	mine, _ := p.Repos(ctx, repos)
+	if len(mine) == 0 {
+		return nil, nil
+	}
+
+	var (
+		canRead map[int]bool
+		err     error
+	)
+	if account != nil && account.ServiceID == p.codeHost.ServiceID() && account.ServiceType == p.codeHost.ServiceType() {
+		canRead, err = p.userRepos(ctx, account)
+	} else {
+		canRead, err = p.publicRepos(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	perms := make(map[api.RepoName]map[authz.Perm]bool, len(mine))
+	for repo := range mine {
+		id, err := strconv.Atoi(repo.ExternalRepoSpec.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "Bitbucket Server repo external ID did not parse to int")
+		}
+		perms[repo.RepoName] = map[authz.Perm]bool{authz.Read: canRead[id]}
+	}
+	return perms, nil
+}
+
+// userRepos returns the IDs of the repositories that the Bitbucket Server user of the given
+// account can read. It consults and updates the cache.
+func (p *Provider) userRepos(ctx context.Context, account *extsvc.ExternalAccount) (map[int]bool, error) {
+	key := userReposCacheKey(account.AccountID)
+	if ids, ok := cacheGetRepos(p.cache, key, p.cacheTTL); ok {
+		return ids, nil
+	}
+
+	usr, err := bitbucketserver.GetExternalAccountData(&account.ExternalAccountData)
+	if err != nil {
+		return nil, err
+	}
+	if usr == nil {
+		return nil, fmt.Errorf("external account %d has no Bitbucket Server user data", account.ID)
+	}
+	client, err := p.client.Sudo(usr.Name)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := fetchRepoIDs(ctx, client, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list Bitbucket Server repositories of user %q", usr.Name)
+	}
+	if err := cacheSetRepos(p.cache, key, ids, p.cacheTTL); err != nil {
+		return nil, errors.Wrap(err, "could not set cached user repos")
+	}
+	return ids, nil
+}
+
+// publicRepos returns the IDs of the public repositories, which anyone can read. It consults and
+// updates the cache.
+func (p *Provider) publicRepos(ctx context.Context) (map[int]bool, error) {
+	if ids, ok := cacheGetRepos(p.cache, publicReposCacheKey, p.cacheTTL); ok {
+		return ids, nil
+	}
+
+	ids, err := fetchRepoIDs(ctx, p.client, "visibility=public")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list public Bitbucket Server repositories")
+	}
+	if err := cacheSetRepos(p.cache, publicReposCacheKey, ids, p.cacheTTL); err != nil {
+		return nil, errors.Wrap(err, "could not set cached public repos")
+	}
+	return ids, nil
+}
+
+// fetchRepoIDs returns the IDs of all the repositories that the client can read and that match
+// the given repository search query (if any).
+func fetchRepoIDs(ctx context.Context, client *bitbucketserver.Client, query string) (map[int]bool, error) {
+	var queries []string
+	if query != "" {
+		queries = append(queries, query)
+	}
+	ids := make(map[int]bool)
+	page := &bitbucketserver.PageToken{Limit: 1000}
+	for page.HasMore() {
+		var (
+			repos []*bitbucketserver.Repo
+			err   error
+		)
+		if repos, page, err = client.Repos(ctx, page, queries...); err != nil {
+			return nil, err
+		}
+		for _, r := range repos {
+			ids[r.ID] = true
+		}
+	}
+	return ids, nil
+}
+
+// FetchAccount implements the authz.Provider interface. It looks up the Bitbucket Server user
+// with the same username as the given user or with one of the given user's verified email
+// addresses, depending on the provider's identity provider.
+func (p *Provider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.ExternalAccount) (mine *extsvc.ExternalAccount, err error) {
+	if user == nil {
+		return nil, nil
+	}
+
+	var bbUser *bitbucketserver.User
+	switch p.identityProvider {
+	case "username":
+		bbUser, err = p.fetchUser(ctx, user.Username, func(u *bitbucketserver.User) bool {
+			return strings.EqualFold(u.Name, user.Username)
+		})
+	case "email":
+		var emails []*db.UserEmail
+		if emails, err = db.UserEmails.ListByUser(ctx, user.ID); err != nil {
+			return nil, err
+		}
+		for _, email := range emails {
+			if email.VerifiedAt == nil {
+				continue
+			}
+			bbUser, err = p.fetchUser(ctx, email.Email, func(u *bitbucketserver.User) bool {
+				return strings.EqualFold(u.EmailAddress, email.Email)
+			})
+			if err != nil || bbUser != nil {
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown Bitbucket Server identity provider type %q", p.identityProvider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bbUser == nil {
+		return nil, nil
+	}
+
+	var accountData extsvc.ExternalAccountData
+	bitbucketserver.SetExternalAccountData(&accountData, bbUser)
+
+	return &extsvc.ExternalAccount{
+		UserID: user.ID,
+		ExternalAccountSpec: extsvc.ExternalAccountSpec{
+			ServiceType: p.codeHost.ServiceType(),
+			ServiceID:   p.codeHost.ServiceID(),
+			AccountID:   strconv.Itoa(bbUser.ID),
+		},
+		ExternalAccountData: accountData,
+	}, nil
+}
+
+// fetchUser returns the active Bitbucket Server user that matches the given filter and for which
+// match returns true, or nil if there is none. It is an error if there is more than one such user.
+func (p *Provider) fetchUser(ctx context.Context, filter string, match func(*bitbucketserver.User) bool) (*bitbucketserver.User, error) {
+	var found *bitbucketserver.User
+	page := &bitbucketserver.PageToken{Limit: 1000}
+	for page.HasMore() {
+		var (
+			users []*bitbucketserver.User
+			err   error
+		)
+		if users, page, err = p.client.Users(ctx, page, filter); err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			if !u.Active || !match(u) {
+				continue
+			}
+			if found != nil {
+				return nil, fmt.Errorf("failed to determine unique Bitbucket Server user for %q", filter)
+			}
+			found = u
+		}
+	}
+	return found, nil
+}
+
+func (p *Provider) ServiceID() string {
+	return p.codeHost.ServiceID()
+}
+
+func (p *Provider) ServiceType() string {
+	return p.codeHost.ServiceType()
+}
+
+func (p *Provider) Validate() (problems []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, _, err := p.client.Users(ctx, &bitbucketserver.PageToken{Limit: 1}, ""); err != nil {
+		if err == ctx.Err() {
+			problems = append(problems, fmt.Sprintf("Bitbucket Server API did not respond within 5s (%s)", err.Error()))
+		} else {
+			problems = append(problems, fmt.Sprintf("could not list Bitbucket Server users with the OAuth credentials of the Application Link: %s", err))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,252 @@
+package bitbucketserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketserver"
+)
+
+// mockBitbucketServer serves the users and repositories of a fake Bitbucket Server instance.
+type mockBitbucketServer struct {
+	users []*bitbucketserver.User
+	// repos maps usernames to the IDs of the repositories they can read. The public repositories
+	// are those of the empty username.
+	repos map[string][]int
+
+	reposRequests int
+}
+
+func (m *mockBitbucketServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "OAuth ") {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	var values interface{}
+	switch r.URL.Path {
+	case "/rest/api/1.0/users":
+		var users []*bitbucketserver.User
+		for _, u := range m.users {
+			filter := strings.ToLower(q.Get("filter"))
+			if strings.Contains(strings.ToLower(u.Name), filter) || strings.Contains(strings.ToLower(u.EmailAddress), filter) {
+				users = append(users, u)
+			}
+		}
+		values = users
+	case "/rest/api/1.0/repos":
+		m.reposRequests++
+		username := q.Get("xoauth_requestor_id")
+		if username == "" && q.Get("visibility") != "public" {
+			http.Error(w, "unexpected query", http.StatusBadRequest)
+			return
+		}
+		var repos []*bitbucketserver.Repo
+		for _, id := range m.repos[username] {
+			repos = append(repos, &bitbucketserver.Repo{ID: id})
+		}
+		values = repos
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"isLastPage": true, "values": values})
+}
+
+func newTestProvider(t *testing.T, identityProvider string, m *mockBitbucketServer) (*Provider, func()) {
+	srv := httptest.NewServer(m)
+	srvURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := bitbucketserver.NewClient(srvURL, nil)
+	if err := client.SetOAuth("sourcegraph", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})); err != nil {
+		t.Fatal(err)
+	}
+	return NewProvider(client, identityProvider, 3*time.Hour, make(authz.MockCache)), srv.Close
+}
+
+func TestProvider_RepoPerms(t *testing.T) {
+	m := &mockBitbucketServer{
+		users: []*bitbucketserver.User{{Name: "alice", ID: 1, Active: true}},
+		repos: map[string][]int{
+			"":      {3},
+			"alice": {1, 3},
+		},
+	}
+	p, done := newTestProvider(t, "username", m)
+	defer done()
+	ctx := context.Background()
+
+	account, err := p.FetchAccount(ctx, &types.User{ID: 42, Username: "alice"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := func(name string, id int) authz.Repo {
+		return authz.Repo{
+			RepoName: api.RepoName(name),
+			ExternalRepoSpec: api.ExternalRepoSpec{
+				ID:          strconv.Itoa(id),
+				ServiceType: bitbucketserver.ServiceType,
+				ServiceID:   p.ServiceID(),
+			},
+		}
+	}
+	repos := map[authz.Repo]struct{}{
+		repo("private", 1):      {},
+		repo("inaccessible", 2): {},
+		repo("public", 3):       {},
+		{RepoName: "other", ExternalRepoSpec: api.ExternalRepoSpec{ID: "1", ServiceType: "github", ServiceID: "https://github.com/"}}: {},
+	}
+
+	for _, test := range []struct {
+		description      string
+		account          *extsvc.ExternalAccount
+		cacheTTL         time.Duration
+		wantPerms        map[api.RepoName]map[authz.Perm]bool
+		wantReposFetches int
+	}{
+		{
+			description: "user",
+			account:     account,
+			cacheTTL:    3 * time.Hour,
+			wantPerms: map[api.RepoName]map[authz.Perm]bool{
+				"private":      {authz.Read: true},
+				"inaccessible": {authz.Read: false},
+				"public":       {authz.Read: true},
+			},
+			wantReposFetches: 1,
+		},
+		{
+			description: "user (cached)",
+			account:     account,
+			cacheTTL:    3 * time.Hour,
+			wantPerms: map[api.RepoName]map[authz.Perm]bool{
+				"private":      {authz.Read: true},
+				"inaccessible": {authz.Read: false},
+				"public":       {authz.Read: true},
+			},
+			wantReposFetches: 0,
+		},
+		{
+			description: "user (cache TTL changed)",
+			account:     account,
+			cacheTTL:    time.Hour,
+			wantPerms: map[api.RepoName]map[authz.Perm]bool{
+				"private":      {authz.Read: true},
+				"inaccessible": {authz.Read: false},
+				"public":       {authz.Read: true},
+			},
+			wantReposFetches: 1,
+		},
+		{
+			description: "anonymous",
+			cacheTTL:    time.Hour,
+			wantPerms: map[api.RepoName]map[authz.Perm]bool{
+				"private":      {authz.Read: false},
+				"inaccessible": {authz.Read: false},
+				"public":       {authz.Read: true},
+			},
+			wantReposFetches: 1,
+		},
+		{
+			description: "anonymous (cached)",
+			cacheTTL:    time.Hour,
+			wantPerms: map[api.RepoName]map[authz.Perm]bool{
+				"private":      {authz.Read: false},
+				"inaccessible": {authz.Read: false},
+				"public":       {authz.Read: true},
+			},
+			wantReposFetches: 0,
+		},
+	} {
+		m.reposRequests = 0
+		p.cacheTTL = test.cacheTTL
+		perms, err := p.RepoPerms(ctx, test.account, repos)
+		if err != nil {
+			t.Fatalf("%s: %s", test.description, err)
+		}
+		if !reflect.DeepEqual(perms, test.wantPerms) {
+			t.Errorf("%s: got perms %v, want %v", test.description, perms, test.wantPerms)
+		}
+		if m.reposRequests != test.wantReposFetches {
+			t.Errorf("%s: got %d repository list requests, want %d", test.description, m.reposRequests, test.wantReposFetches)
+		}
+	}
+}
+
+func TestProvider_FetchAccount(t *testing.T) {
+	m := &mockBitbucketServer{
+		users: []*bitbucketserver.User{
+			{Name: "alice", EmailAddress: "alice@example.com", ID: 1, Active: true},
+			{Name: "alice2", EmailAddress: "alice2@example.com", ID: 2, Active: true},
+			{Name: "bob", EmailAddress: "bob@example.com", ID: 3},
+		},
+	}
+	db.Mocks.UserEmails.ListByUser = func(id int32) ([]*db.UserEmail, error) {
+		verified := time.Now()
+		switch id {
+		case 1:
+			return []*db.UserEmail{
+				{Email: "unverified@example.com"},
+				{Email: "Alice@example.com", VerifiedAt: &verified},
+			}, nil
+		case 2:
+			return []*db.UserEmail{{Email: "alice2@example.com"}}, nil
+		}
+		return nil, nil
+	}
+	defer func() { db.Mocks.UserEmails.ListByUser = nil }()
+
+	for _, test := range []struct {
+		identityProvider string
+		user             *types.User
+		wantAccountID    string
+	}{
+		{identityProvider: "username", user: &types.User{ID: 1, Username: "alice"}, wantAccountID: "1"},
+		{identityProvider: "username", user: &types.User{ID: 2, Username: "alice2"}, wantAccountID: "2"},
+		{identityProvider: "username", user: &types.User{ID: 3, Username: "bob"}}, // inactive
+		{identityProvider: "username", user: &types.User{ID: 4, Username: "carol"}},
+		{identityProvider: "email", user: &types.User{ID: 1, Username: "sg-alice"}, wantAccountID: "1"},
+		{identityProvider: "email", user: &types.User{ID: 2, Username: "alice2"}}, // unverified email
+	} {
+		p, done := newTestProvider(t, test.identityProvider, m)
+		account, err := p.FetchAccount(context.Background(), test.user, nil)
+		done()
+		if err != nil {
+			t.Fatalf("%s %s: %s", test.identityProvider, test.user.Username, err)
+		}
+		var gotAccountID string
+		if account != nil {
+			gotAccountID = account.AccountID
+			if account.UserID != test.user.ID || account.ServiceType != bitbucketserver.ServiceType || account.ServiceID != p.ServiceID() {
+				t.Errorf("%s %s: unexpected account %+v", test.identityProvider, test.user.Username, account)
+			}
+		}
+		if gotAccountID != test.wantAccountID {
+			t.Errorf("%s %s: got account ID %q, want %q", test.identityProvider, test.user.Username, gotAccountID, test.wantAccountID)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 )
 
 type ExternalServicesStore interface {
+	ListBitbucketServerConnections(context.Context) ([]*schema.BitbucketServerConnection, error)
 	ListGitLabConnections(context.Context) ([]*schema.GitLabConnection, error)
 	ListGitHubConnections(context.Context) ([]*schema.GitHubConnection, error)
 }
@@ -55,5 +56,14 @@ func ProvidersFromConfig(
 		warnings = append(warnings, ghwarnings...)
 	}
 
+	if bitbucketServers, err := s.ListBitbucketServerConnections(ctx); err != nil {
+		seriousProblems = append(seriousProblems, fmt.Sprintf("Could not load Bitbucket Server external service configs: %s", err))
+	} else {
+		bbsp, bbsproblems, bbswarnings := bitbucketServerProviders(ctx, bitbucketServers)
+		authzProviders = append(authzProviders, bbsp...)
+		seriousProblems = append(seriousProblems, bbsproblems...)
+		warnings = append(warnings, bbswarnings...)
+	}
+
 	return allowAccessByDefault, authzProviders, seriousProblems, warnings
 }
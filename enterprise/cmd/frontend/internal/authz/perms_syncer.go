@@ -0,0 +1,284 @@
+package authz
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/conf"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
+	log15 "gopkg.in/inconshreveable/log15.v2"
+)
+
+const (
+	// defaultPermsSyncInterval is the default interval after which a user's repository permissions
+	// are synced again.
+	defaultPermsSyncInterval = time.Hour
+
+	// permsSyncBatchSize is the maximum number of users whose repository permissions are synced
+	// in each periodic sync.
+	permsSyncBatchSize = 100
+)
+
+// permsSyncReposPageSize is the number of repositories that are listed at a time when syncing
+// permissions. It is a variable so that tests can change it.
+var permsSyncReposPageSize = 10000
+
+// PermsBackgroundSyncEnabled reports whether the repository permissions of users are synced in the
+// background, according to the site configuration.
+func PermsBackgroundSyncEnabled(cfg *schema.SiteConfiguration) bool {
+	return cfg.PermissionsBackgroundSync == nil || cfg.PermissionsBackgroundSync.Enabled == nil || *cfg.PermissionsBackgroundSync.Enabled
+}
+
+// permsSyncInterval returns the interval after which a user's repository permissions are synced
+// again, according to the site configuration.
+func permsSyncInterval(cfg *schema.SiteConfiguration) time.Duration {
+	if cfg.PermissionsBackgroundSync == nil || cfg.PermissionsBackgroundSync.Interval <= 0 {
+		return defaultPermsSyncInterval
+	}
+	return time.Duration(cfg.PermissionsBackgroundSync.Interval) * time.Minute
+}
+
+// PermsSyncer implements authz.PermsSyncer. It syncs the repository permissions of users from the
+// authz providers and stores them in the database (see db.UserPermissions), periodically and when
+// requested.
+type PermsSyncer struct {
+	mu      sync.Mutex
+	pending map[int32]struct{} // IDs of the users for whom a sync was requested
+	syncing map[int32]struct{} // IDs of the users whose permissions are being synced
+	signal  chan struct{}
+}
+
+var _ authz.PermsSyncer = ((*PermsSyncer)(nil))
+
+// NewPermsSyncer returns a new syncer of users' repository permissions. Its Run method must be
+// called to sync permissions.
+func NewPermsSyncer() *PermsSyncer {
+	return &PermsSyncer{
+		pending: make(map[int32]struct{}),
+		signal:  make(chan struct{}, 1),
+	}
+}
+
+// RequestSync implements the authz.PermsSyncer interface. A request for a user whose permissions
+// are being synced is ignored.
+func (s *PermsSyncer) RequestSync(userID int32) {
+	s.mu.Lock()
+	if _, ok := s.syncing[userID]; ok {
+		s.mu.Unlock()
+		return
+	}
+	s.pending[userID] = struct{}{}
+	s.mu.Unlock()
+
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+// Interval implements the authz.PermsSyncer interface.
+func (s *PermsSyncer) Interval() time.Duration {
+	return permsSyncInterval(&conf.Get().SiteConfiguration)
+}
+
+// Run syncs repository permissions until ctx is done. Every minute, it syncs the permissions of the
+// users whose permissions were not synced within the configured interval. It syncs the permissions
+// of the users for whom a sync was requested as soon as possible.
+func (s *PermsSyncer) Run(ctx context.Context) {
+	t := time.NewTicker(time.Minute)
+	defer t.Stop()
+
+	for {
+		var periodic bool
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.signal:
+		case <-t.C:
+			periodic = true
+		}
+
+		cfg := conf.Get()
+		if !PermsBackgroundSyncEnabled(&cfg.SiteConfiguration) {
+			continue
+		}
+		if err := s.sync(ctx, periodic, permsSyncInterval(&cfg.SiteConfiguration)); err != nil {
+			log15.Error("Failed to sync repository permissions.", "error", err)
+		}
+	}
+}
+
+// sync syncs the repository permissions of the users for whom a sync was requested and, if periodic
+// is true, of the users whose permissions were not synced within the given interval.
+func (s *PermsSyncer) sync(ctx context.Context, periodic bool, interval time.Duration) error {
+	s.mu.Lock()
+	userIDs := make(map[int32]struct{}, len(s.pending))
+	for id := range s.pending {
+		userIDs[id] = struct{}{}
+	}
+	s.pending = make(map[int32]struct{})
+	s.mu.Unlock()
+
+	_, providers := authz.GetProviders()
+	if len(providers) == 0 {
+		return nil
+	}
+	if periodic {
+		since := time.Now().Add(-interval)
+		for _, p := range providers {
+			ids, err := db.UserPermissions.ListStaleUserIDs(ctx, authz.Read, p.ServiceType(), p.ServiceID(), since, permsSyncBatchSize)
+			if err != nil {
+				return errors.Wrap(err, "list users with stale permissions")
+			}
+			for _, id := range ids {
+				userIDs[id] = struct{}{}
+			}
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	s.syncing = userIDs
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.syncing = nil
+		s.mu.Unlock()
+	}()
+
+	var syncs []*userPermsSync
+	for userID := range userIDs {
+		userSyncs, err := newUserPermsSyncs(ctx, userID, providers)
+		if err != nil {
+			log15.Warn("Failed to sync repository permissions of user.", "userID", userID, "error", err)
+			continue
+		}
+		syncs = append(syncs, userSyncs...)
+	}
+
+	// 🚨 SECURITY: All repositories are listed (bypassing permissions checks), because their
+	// permissions are the ones being synced. They are only passed to the authz providers. They are
+	// listed in pages (ordered by ID), so that all of them are never held in memory at once.
+	listCtx := actor.WithActor(ctx, &actor.Actor{Internal: true})
+	for offset := 0; ; {
+		repos, err := db.Repos.List(listCtx, db.ReposListOptions{
+			Enabled:     true,
+			Disabled:    true,
+			LimitOffset: &db.LimitOffset{Limit: permsSyncReposPageSize, Offset: offset},
+		})
+		if err != nil {
+			return errors.Wrap(err, "list repositories")
+		}
+		addRepoPerms(ctx, syncs, repos)
+		if len(repos) < permsSyncReposPageSize {
+			break
+		}
+		offset += len(repos)
+	}
+
+	for _, us := range syncs {
+		if us.err == nil {
+			us.err = db.UserPermissions.Upsert(ctx, us.perm)
+		}
+		if us.err != nil {
+			log15.Warn("Failed to sync repository permissions of user.", "userID", us.perm.UserID, "provider", us.provider.ServiceID(), "error", us.err)
+		}
+	}
+	return nil
+}
+
+// userPermsSync is the sync of a user's repository permissions from an authz provider.
+type userPermsSync struct {
+	provider authz.Provider
+	acct     *extsvc.ExternalAccount // the user's account on the provider's code host (nil if none)
+	perm     *db.UserPermission      // the permissions synced so far
+	err      error                   // the error that the sync failed with, if any
+}
+
+// newUserPermsSyncs returns the syncs of the permissions of the user with the given ID from each
+// authz provider. If the user has no external account for a provider, it is fetched from the
+// provider (and saved). If that fails, the returned sync from that provider has failed.
+func newUserPermsSyncs(ctx context.Context, userID int32, providers []authz.Provider) ([]*userPermsSync, error) {
+	user, err := db.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	accts, err := db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	syncs := make([]*userPermsSync, 0, len(providers))
+	for _, p := range providers {
+		us := &userPermsSync{
+			provider: p,
+			perm:     &db.UserPermission{UserID: user.ID, Perm: authz.Read, ServiceType: p.ServiceType(), ServiceID: p.ServiceID()},
+		}
+		us.acct, us.err = providerAccount(ctx, user, accts, p)
+		syncs = append(syncs, us)
+	}
+	return syncs, nil
+}
+
+// providerAccount returns the user's external account for the authz provider. accts are the
+// user's external accounts. If none of them is for the provider, the account is fetched from the
+// provider and saved.
+func providerAccount(ctx context.Context, user *types.User, accts []*extsvc.ExternalAccount, p authz.Provider) (*extsvc.ExternalAccount, error) {
+	for _, acct := range accts {
+		if acct.ServiceID == p.ServiceID() && acct.ServiceType == p.ServiceType() {
+			return acct, nil
+		}
+	}
+	acct, err := p.FetchAccount(ctx, user, accts)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch account")
+	}
+	if acct != nil {
+		if err := db.ExternalAccounts.AssociateUserAndSave(ctx, user.ID, acct.ExternalAccountSpec, acct.ExternalAccountData); err != nil {
+			return nil, err
+		}
+	}
+	return acct, nil
+}
+
+// addRepoPerms adds the permissions on the given repositories to each sync that has not failed.
+// A sync fails if the permissions can't be fetched from its provider.
+func addRepoPerms(ctx context.Context, syncs []*userPermsSync, repos []*types.Repo) {
+	repoIDs := make(map[api.RepoName]api.RepoID, len(repos))
+	for _, repo := range repos {
+		repoIDs[repo.Name] = repo.ID
+	}
+	authzRepos := authz.ToRepos(repos)
+	providerRepos := make(map[string]map[authz.Repo]struct{}) // by provider service ID
+
+	for _, us := range syncs {
+		if us.err != nil {
+			continue
+		}
+		mine, ok := providerRepos[us.provider.ServiceID()]
+		if !ok {
+			mine, _ = us.provider.Repos(ctx, authzRepos)
+			providerRepos[us.provider.ServiceID()] = mine
+		}
+		perms, err := us.provider.RepoPerms(ctx, us.acct, mine)
+		if err != nil {
+			us.err = errors.Wrap(err, "fetch repository permissions")
+			continue
+		}
+		for repoName, repoPerms := range perms {
+			if id, ok := repoIDs[repoName]; ok && repoPerms[authz.Read] {
+				us.perm.AddRepo(id)
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package authz
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/pkg/actor"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc"
+)
+
+// mockPermsProvider is an authz provider for the code host "https://mock.example.com/" that grants
+// each user account read access to the repositories listed in readable.
+type mockPermsProvider struct {
+	readable map[string][]api.RepoName // account ID -> readable repositories
+}
+
+func (p *mockPermsProvider) Repos(ctx context.Context, repos map[authz.Repo]struct{}) (mine map[authz.Repo]struct{}, others map[authz.Repo]struct{}) {
+	mine, others = make(map[authz.Repo]struct{}), make(map[authz.Repo]struct{})
+	for repo := range repos {
+		if repo.ServiceID == p.ServiceID() {
+			mine[repo] = struct{}{}
+		} else {
+			others[repo] = struct{}{}
+		}
+	}
+	return mine, others
+}
+
+func (p *mockPermsProvider) RepoPerms(ctx context.Context, account *extsvc.ExternalAccount, repos map[authz.Repo]struct{}) (map[api.RepoName]map[authz.Perm]bool, error) {
+	var accountID string
+	if account != nil {
+		accountID = account.AccountID
+	}
+	readable := make(map[api.RepoName]bool)
+	for _, name := range p.readable[accountID] {
+		readable[name] = true
+	}
+	perms := make(map[api.RepoName]map[authz.Perm]bool)
+	for repo := range repos {
+		perms[repo.RepoName] = map[authz.Perm]bool{authz.Read: readable[repo.RepoName]}
+	}
+	return perms, nil
+}
+
+func (p *mockPermsProvider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.ExternalAccount) (*extsvc.ExternalAccount, error) {
+	if user.Username != "alice" {
+		return nil, nil
+	}
+	return &extsvc.ExternalAccount{
+		UserID:              user.ID,
+		ExternalAccountSpec: extsvc.ExternalAccountSpec{ServiceType: p.ServiceType(), ServiceID: p.ServiceID(), AccountID: "alice"},
+	}, nil
+}
+
+func (p *mockPermsProvider) ServiceID() string   { return "https://mock.example.com/" }
+func (p *mockPermsProvider) ServiceType() string { return "mock" }
+func (p *mockPermsProvider) Validate() []string  { return nil }
+
+func TestPermsSyncer(t *testing.T) {
+	authz.SetProviders(false, []authz.Provider{&mockPermsProvider{
+		readable: map[string][]api.RepoName{
+			"":      {"public"},
+			"alice": {"public", "private"},
+		},
+	}})
+	defer authz.SetProviders(true, nil)
+
+	defer func(size int) { permsSyncReposPageSize = size }(permsSyncReposPageSize)
+	permsSyncReposPageSize = 2
+
+	s := NewPermsSyncer()
+
+	repo := func(id api.RepoID, name api.RepoName, serviceID string) *types.Repo {
+		return &types.Repo{ID: id, Name: name, ExternalRepo: &api.ExternalRepoSpec{ID: string(name), ServiceType: "mock", ServiceID: serviceID}}
+	}
+	repos := []*types.Repo{
+		repo(1, "public", "https://mock.example.com/"),
+		repo(2, "private", "https://mock.example.com/"),
+		repo(3, "other", "https://other.example.com/"),
+	}
+	var listed []db.LimitOffset
+	db.Mocks.Repos.List = func(ctx context.Context, opt db.ReposListOptions) ([]*types.Repo, error) {
+		if !actor.FromContext(ctx).Internal {
+			t.Error("repositories must be listed by an internal actor")
+		}
+		if opt.LimitOffset == nil {
+			t.Fatal("repositories must be listed in pages")
+		}
+		listed = append(listed, *opt.LimitOffset)
+		page := repos[opt.Offset:]
+		if len(page) > opt.Limit {
+			page = page[:opt.Limit]
+		}
+		return page, nil
+	}
+	users := map[int32]*types.User{1: {ID: 1, Username: "alice"}, 2: {ID: 2, Username: "bob"}}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		s.RequestSync(id) // e.g., the user signs in again while their permissions are being synced
+		return users[id], nil
+	}
+	db.Mocks.ExternalAccounts.List = func(db.ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) { return nil, nil }
+	var associated []int32
+	db.Mocks.ExternalAccounts.AssociateUserAndSave = func(userID int32, spec extsvc.ExternalAccountSpec, data extsvc.ExternalAccountData) error {
+		associated = append(associated, userID)
+		return nil
+	}
+	var staleSince time.Time
+	db.Mocks.UserPermissions.ListStaleUserIDs = func(perm authz.Perm, serviceType, serviceID string, since time.Time, limit int) ([]int32, error) {
+		staleSince = since
+		return []int32{2}, nil
+	}
+	synced := make(map[int32][]api.RepoID)
+	db.Mocks.UserPermissions.Upsert = func(p *db.UserPermission) error {
+		if p.Perm != authz.Read || p.ServiceType != "mock" || p.ServiceID != "https://mock.example.com/" {
+			t.Errorf("unexpected permission %+v", p)
+		}
+		synced[p.UserID] = p.RepoIDs()
+		return nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	ctx := context.Background()
+
+	// Requested syncs
+	s.RequestSync(1)
+	s.RequestSync(1)
+	if err := s.sync(ctx, false, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int32][]api.RepoID{1: {1, 2}}; !reflect.DeepEqual(synced, want) {
+		t.Errorf("got synced permissions %v, want %v", synced, want)
+	}
+	if want := []int32{1}; !reflect.DeepEqual(associated, want) {
+		t.Errorf("got associated accounts of users %v, want %v", associated, want)
+	}
+	if !staleSince.IsZero() {
+		t.Error("unexpected periodic sync")
+	}
+	if want := []db.LimitOffset{{Limit: 2}, {Limit: 2, Offset: 2}}; !reflect.DeepEqual(listed, want) {
+		t.Errorf("got listed repository pages %v, want %v", listed, want)
+	}
+	if len(s.pending) != 0 {
+		t.Errorf("got pending syncs for users %v, want none while they were being synced", s.pending)
+	}
+
+	// Periodic syncs
+	synced = make(map[int32][]api.RepoID)
+	if err := s.sync(ctx, true, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int32][]api.RepoID{2: {1}}; !reflect.DeepEqual(synced, want) {
+		t.Errorf("got synced permissions %v, want %v", synced, want)
+	}
+	if d := time.Since(staleSince); d < time.Hour || d > time.Hour+time.Minute {
+		t.Errorf("got stale permissions since %s ago, want 1h", d)
+	}
+}
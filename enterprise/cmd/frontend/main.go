@@ -37,14 +37,22 @@ func main() {
 
 	hooks.AfterDBInit = func() {
 		ctx := context.Background()
+		permsSyncer := iauthz.NewPermsSyncer()
 		go func() {
 			t := time.NewTicker(5 * time.Second)
 			for range t.C {
 				allowAccessByDefault, authzProviders, _, _ :=
 					iauthz.ProvidersFromConfig(ctx, conf.Get(), db.ExternalServices)
 				authz.SetProviders(allowAccessByDefault, authzProviders)
+
+				if iauthz.PermsBackgroundSyncEnabled(&conf.Get().SiteConfiguration) {
+					authz.SetPermsSyncer(permsSyncer)
+				} else {
+					authz.SetPermsSyncer(nil)
+				}
 			}
 		}()
+		go permsSyncer.Run(ctx)
 		go licensing.StartMaxUserCount(&usersStore{})
 	}
 
@@ -136,6 +144,20 @@ func initAuthz() {
 			}
 		}
 
+		bitbucketServers, err := db.ExternalServices.ListBitbucketServerConnections(ctx)
+		if err != nil {
+			return []*graphqlbackend.Alert{{
+				TypeValue:    graphqlbackend.AlertTypeError,
+				MessageValue: fmt.Sprintf("Unable to fetch Bitbucket Server external services: %s", err),
+			}}
+		}
+		for _, b := range bitbucketServers {
+			if b.Authorization != nil {
+				authzTypes = append(authzTypes, "Bitbucket Server")
+				break
+			}
+		}
+
 		if len(authzTypes) > 0 {
 			return []*graphqlbackend.Alert{{
 				TypeValue:    graphqlbackend.AlertTypeError,
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS "rewrite_batch_repos";
+DROP TABLE IF EXISTS "rewrite_batches";
+
+COMMIT;
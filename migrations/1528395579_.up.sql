@@ -0,0 +1,31 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS "rewrite_batches" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "user_id" integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    "query" text NOT NULL,
+    "match_template" text NOT NULL,
+    "rewrite_template" text NOT NULL,
+    "file_extension" text NOT NULL,
+    "state" text NOT NULL,
+    "branch" text NOT NULL DEFAULT '',
+    "commit_message" text NOT NULL DEFAULT '',
+    "created_at" timestamp with time zone DEFAULT now() NOT NULL,
+    "updated_at" timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "rewrite_batch_repos" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "rewrite_batch_id" integer NOT NULL REFERENCES rewrite_batches (id) ON DELETE CASCADE,
+    "repo_id" integer NOT NULL REFERENCES repo (id) ON DELETE CASCADE,
+    "rev" text NOT NULL,
+    "base_commit" text NOT NULL DEFAULT '',
+    "diff" text NOT NULL DEFAULT '',
+    "state" text NOT NULL,
+    "error" text NOT NULL DEFAULT '',
+    "commit_ref" text NOT NULL DEFAULT '',
+    "updated_at" timestamp with time zone DEFAULT now() NOT NULL,
+    UNIQUE ("rewrite_batch_id", "repo_id")
+);
+
+COMMIT;
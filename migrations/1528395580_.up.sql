@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS "search_exports" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "user_id" integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    "query" text NOT NULL,
+    "format" text NOT NULL,
+    "state" text NOT NULL,
+    "row_count" integer NOT NULL DEFAULT 0,
+    "incomplete" boolean NOT NULL DEFAULT false,
+    "error" text NOT NULL DEFAULT '',
+    "data" bytea,
+    "created_at" timestamp with time zone DEFAULT now() NOT NULL,
+    "finished_at" timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS "search_exports_user_id" ON "search_exports" ("user_id");
+
+COMMIT;
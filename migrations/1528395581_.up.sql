@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS "query_runner_runs" (
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "query" text NOT NULL,
+    "matches" jsonb NOT NULL,
+    "added" jsonb NOT NULL,
+    "removed" jsonb NOT NULL,
+    "executed_at" timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS "query_runner_runs_query" ON "query_runner_runs" ("query", "id");
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS "saved_query_webhook_deliveries" (
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "user_id" integer REFERENCES users (id) ON DELETE CASCADE,
+    "org_id" integer REFERENCES orgs (id) ON DELETE CASCADE,
+    "saved_query_key" text NOT NULL,
+    "event" text NOT NULL,
+    "url" text NOT NULL,
+    "status_code" integer,
+    "response_body" text NOT NULL DEFAULT '',
+    "error" text NOT NULL DEFAULT '',
+    "attempts" integer NOT NULL,
+    "created_at" timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS "saved_query_webhook_deliveries_saved_query_key" ON "saved_query_webhook_deliveries" ("saved_query_key", "id");
+
+COMMIT;
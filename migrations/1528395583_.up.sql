@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS "user_permissions" (
+    "user_id" integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    "permission" text NOT NULL,
+    "service_type" text NOT NULL,
+    "service_id" text NOT NULL,
+    "object_ids" bytea NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "user_permissions_perm_service_unique" ON "user_permissions" ("user_id", "permission", "service_type", "service_id");
+
+COMMIT;
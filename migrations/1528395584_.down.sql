@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "access_tokens" DROP COLUMN IF EXISTS "expires_at";
+
+COMMIT;
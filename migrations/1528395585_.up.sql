@@ -0,0 +1,29 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS "audit_log" (
+    "id" bigserial NOT NULL PRIMARY KEY,
+    "created_at" timestamp with time zone NOT NULL DEFAULT now(),
+    "actor_user_id" integer,
+    "action" text NOT NULL,
+    "target_type" text NOT NULL,
+    "target_id" text NOT NULL,
+    "ip" text NOT NULL,
+    "forwarded_for" text NOT NULL,
+    "diff" jsonb
+);
+
+CREATE INDEX IF NOT EXISTS "audit_log_created_at" ON "audit_log" ("created_at");
+CREATE INDEX IF NOT EXISTS "audit_log_actor_user_id" ON "audit_log" ("actor_user_id");
+CREATE INDEX IF NOT EXISTS "audit_log_action" ON "audit_log" ("action");
+
+-- The audit log is append-only. Entries may only be deleted (when they are older than the retention
+-- period), not updated.
+CREATE OR REPLACE FUNCTION audit_log_prevent_update() RETURNS TRIGGER AS $$
+begin
+raise exception 'audit log entries may not be updated';
+end;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER trig_audit_log_prevent_update BEFORE UPDATE ON "audit_log" FOR EACH ROW
+  EXECUTE PROCEDURE audit_log_prevent_update();
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS search_exports_state;
+ALTER TABLE search_exports DROP COLUMN IF EXISTS attempts;
+ALTER TABLE search_exports DROP COLUMN IF EXISTS heartbeat_at;
+
+COMMIT;
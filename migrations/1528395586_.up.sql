@@ -0,0 +1,11 @@
+BEGIN;
+
+-- Search exports are now run by a worker that claims queued exports, so that they survive restarts
+-- of the frontend. heartbeat_at is updated periodically while an export is running; exports whose
+-- heartbeat stops (because the process running them died) are run again.
+ALTER TABLE search_exports ADD COLUMN heartbeat_at timestamp with time zone;
+ALTER TABLE search_exports ADD COLUMN attempts integer NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS "search_exports_state" ON "search_exports" ("state");
+
+COMMIT;
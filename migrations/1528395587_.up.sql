@@ -0,0 +1,15 @@
+BEGIN;
+
+-- The secrets that saved query webhook notifications are signed with. They used to be stored in the
+-- (readable) settings of the saved query's user or org, so they had to be set again.
+CREATE TABLE IF NOT EXISTS "saved_query_webhook_secrets" (
+    "user_id" integer REFERENCES users (id) ON DELETE CASCADE,
+    "org_id" integer REFERENCES orgs (id) ON DELETE CASCADE,
+    "saved_query_key" text NOT NULL,
+    "secret" text NOT NULL,
+    "updated_at" timestamp with time zone DEFAULT now() NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "saved_query_webhook_secrets_saved_query" ON "saved_query_webhook_secrets" (COALESCE(user_id, 0), COALESCE(org_id, 0), saved_query_key);
+
+COMMIT;
@@ -219,6 +219,24 @@
 // 1528395577_.up.sql (106B)
 // 1528395578_.down.sql (169B)
 // 1528395578_.up.sql (714B)
+// 1528395579_.down.sql (101B)
+// 1528395579_.up.sql (1.136kB)
+// 1528395580_.down.sql (56B)
+// 1528395580_.up.sql (602B)
+// 1528395581_.down.sql (59B)
+// 1528395581_.up.sql (385B)
+// 1528395582_.down.sql (72B)
+// 1528395582_.up.sql (685B)
+// 1528395583_.down.sql (58B)
+// 1528395583_.up.sql (489B)
+// 1528395584_.down.sql (81B)
+// 1528395584_.up.sql (95B)
+// 1528395585_.down.sql (103B)
+// 1528395585_.up.sql (1.033kB)
+// 1528395586_.down.sql (182B)
+// 1528395586_.up.sql (524B)
+// 1528395587_.down.sql (67B)
+// 1528395587_.up.sql (692B)
 
 package migrations
 
@@ -4667,6 +4685,366 @@ func _1528395578_UpSql() (*asset, error) {
 	return a, nil
 }
 
+var __1528395579_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x50\x2a\x4a\x2d\x2f\xca\x2c\x49\x8d\x4f\x4a\x2c\x49\xce\x88\x2f\x4a\x2d\xc8\x2f\x56\xb2\x26\x46\x6d\x2a\x48\x1d\x97\xb3\xbf\xaf\xaf\x67\x88\x35\x17\x00\x52\x6b\xde\xb8\x65\x00\x00\x00")
+
+func _1528395579_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395579_DownSql,
+		"1528395579_.down.sql",
+	)
+}
+
+func _1528395579_DownSql() (*asset, error) {
+	bytes, err := _1528395579_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395579_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa3, 0x22, 0x74, 0xb4, 0xaf, 0x83, 0x64, 0xe1, 0x6c, 0x1b, 0x21, 0x8c, 0xdb, 0x49, 0xb1, 0xdf, 0xd9, 0xd5, 0x13, 0xcf, 0x44, 0x59, 0x3, 0xd0, 0x3c, 0x2, 0x4a, 0xa2, 0xe, 0x8d, 0xe6, 0x86}}
+	return a, nil
+}
+
+var __1528395579_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa5\x92\xdf\x6e\x82\x30\x14\x87\xef\x79\x8a\x13\x6e\x84\xc4\x37\xf0\x0a\xb1\x2e\x64\x88\x1b\x7f\x92\x79\xd5\x54\x39\x4a\x13\xa1\xac\xad\x73\xdb\xd3\xaf\x88\xd3\xcc\xa9\xe8\xc6\x5d\xe9\x77\x7e\x3d\xed\xf9\x86\xe4\x21\x88\x06\x96\xe5\xc7\xc4\x4b\x09\xa4\xde\x30\x24\x10\x8c\x21\x9a\xa6\x40\x5e\x82\x24\x4d\xc0\x96\xb8\x95\x5c\x23\x9d\x33\xbd\x28\x50\xd9\xe0\x58\x60\x3e\x9b\xe7\x36\x28\x94\x9c\xad\x77\x78\x94\x85\x21\x3c\xc5\xc1\xc4\x8b\x67\xf0\x48\x66\xfd\x96\xda\x18\x84\x36\x28\xaf\x34\xae\x50\x1e\xd9\x98\x8c\x49\x4c\x22\x9f\x24\xd0\x40\x0a\x1c\x9e\xbb\x30\x8d\x60\x44\x42\x62\x9a\xf1\xbd\xc4\xf7\x46\x64\x9f\xf3\xba\x41\xf9\x61\x83\xc6\x77\x7d\x88\xd8\x6f\x95\x4d\x63\x54\x63\x59\xaf\x99\xc6\xf3\xcc\xf7\x25\xae\x53\x4b\xbe\x46\x6a\xfe\x63\xa5\xb8\xa8\xce\x33\x4a\x5f\x2c\x9f\x4b\x56\x2d\x8a\x93\x3d\x73\x9d\xb1\x97\x85\x29\xf4\x7a\x7b\x6c\x21\xca\x92\x6b\x5a\xa2\x52\x6c\x85\xdd\xb8\x44\x73\x62\x4e\x99\x36\x28\x37\x55\x9a\x95\x35\x6c\xb9\x2e\x76\x4b\xf8\x14\x15\x1e\xaa\x2a\xb1\x75\xdc\xd3\xbe\x36\x75\xfe\xd7\x04\xcb\xbd\xc7\x0e\x2a\xb1\x16\xf7\x1a\xf2\x33\xa1\x4b\x95\x13\x1b\xaf\x4b\xd3\xb4\x73\x43\x62\x2d\xba\x62\xde\x2e\x0c\x9c\x29\xa4\xed\x38\x3b\xc7\x98\xf3\xe5\xb2\x13\xba\x22\x17\x4a\x29\xe4\xad\x6e\x49\xec\x3e\xeb\x1f\x56\xb4\x09\x59\x14\x3c\x67\x04\x9c\xdf\x13\xec\x1f\x9f\xde\x6d\x0d\x9a\x4e\x26\x41\x3a\xb0\xbe\x00\x84\x06\x03\xdb\x70\x04\x00\x00")
+
+func _1528395579_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395579_UpSql,
+		"1528395579_.up.sql",
+	)
+}
+
+func _1528395579_UpSql() (*asset, error) {
+	bytes, err := _1528395579_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395579_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x8c, 0x22, 0x4e, 0xac, 0xba, 0x70, 0xf0, 0xa1, 0xd8, 0x2a, 0x22, 0xef, 0xb0, 0x39, 0x70, 0x99, 0x67, 0xf3, 0xe7, 0x25, 0x36, 0xe3, 0xcd, 0xe3, 0xb1, 0x1b, 0x9e, 0xc9, 0x8d, 0xb4, 0x55, 0x8}}
+	return a, nil
+}
+
+var __1528395580_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x50\x2a\x4e\x4d\x2c\x4a\xce\x88\x4f\xad\x28\xc8\x2f\x2a\x29\x56\x02\xaa\x73\xf6\xf7\xf5\xf5\x0c\xb1\xe6\x02\x00\xf5\x99\xac\x4d\x38\x00\x00\x00")
+
+func _1528395580_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395580_DownSql,
+		"1528395580_.down.sql",
+	)
+}
+
+func _1528395580_DownSql() (*asset, error) {
+	bytes, err := _1528395580_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395580_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x36, 0x5d, 0xff, 0xfe, 0x1, 0xc6, 0x7d, 0x6c, 0xd6, 0xf0, 0xb2, 0x95, 0xb7, 0xfc, 0x25, 0xbf, 0x3b, 0x1e, 0x19, 0xd5, 0xb7, 0x1, 0xf6, 0xe4, 0xda, 0x50, 0x27, 0xdb, 0x2a, 0xbd, 0x9, 0x70}}
+	return a, nil
+}
+
+var __1528395580_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x85\x92\xc1\x6f\x82\x30\x18\xc5\xef\xfc\x15\x5f\xb8\xa8\xc9\x0e\xbb\x7b\x42\xf8\x5c\xc8\x10\x17\xa8\x89\x9e\x48\x07\x9f\xa3\x09\xb4\xae\x2d\x41\xf7\xd7\xaf\x32\x82\xc9\x34\x5b\x6f\xed\xfb\xf5\xf5\xb5\xaf\x2b\x7c\x89\xd3\xa5\xe7\x85\x19\x06\x0c\x81\x05\xab\x04\x21\x5e\x43\xba\x65\x80\xfb\x38\x67\x39\xf8\x86\xb8\x2e\xeb\x82\xce\x27\xa5\xad\xf1\x61\xee\x81\x1b\xbe\xa8\x7c\x30\xa4\x05\x6f\x06\x3a\xdd\x25\x09\xbc\x65\xf1\x26\xc8\x0e\xf0\x8a\x87\xa7\x1f\xaa\x73\x48\x71\x45\x85\xb4\xf4\x41\xfa\xc6\x66\xb8\xc6\x0c\xd3\x10\x73\xb8\x42\x06\xe6\xa2\x5a\xc0\x36\x85\x08\x13\x74\x59\xc2\x20\x0f\x83\x08\x47\x9f\xcf\x8e\xf4\xc5\x07\x4b\x67\x3b\x59\x8c\xd2\x51\xe9\x96\xdb\xc7\x9a\xb1\xdc\xd2\x63\x49\xab\xbe\x28\x55\x27\xed\x83\x6c\x11\xae\x83\x5d\xc2\xe0\x79\x64\x85\x2c\x55\x7b\x6a\xe8\xea\xf5\xae\x54\x43\x5c\xde\xc3\x47\xde\x18\x1a\x37\x90\xd6\x4a\xff\x3a\x77\x02\x67\xb3\x91\xaa\xb8\xe5\xce\xf0\x62\x89\x8f\x2b\xa5\x26\x17\xb8\x2a\x86\xfb\x88\x96\x5c\xfe\xf6\x04\xbd\xb0\xf5\x30\x85\x2f\x25\x69\xf2\x91\xaa\x9f\x2f\xee\x5e\x43\x48\x61\xea\x7f\x2c\xbc\xc5\xad\xf3\x38\x8d\x70\xff\x77\xe7\xc5\xd4\xa2\xab\xe7\xfe\x3f\x4c\x25\x0f\xae\xdb\xcd\x26\x66\x4b\xef\x1b\x33\x20\xe4\xcf\x5a\x02\x00\x00")
+
+func _1528395580_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395580_UpSql,
+		"1528395580_.up.sql",
+	)
+}
+
+func _1528395580_UpSql() (*asset, error) {
+	bytes, err := _1528395580_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395580_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x7c, 0x79, 0xb5, 0xaf, 0x5d, 0x93, 0x6a, 0xa6, 0x8b, 0x6a, 0x6c, 0x3b, 0xde, 0xad, 0x64, 0xc6, 0xbd, 0xda, 0x52, 0x4e, 0x65, 0xc2, 0x7f, 0x8f, 0xc1, 0x48, 0x16, 0x8b, 0x92, 0x4e, 0x3e, 0x7b}}
+	return a, nil
+}
+
+var __1528395581_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x50\x2a\x2c\x4d\x2d\xaa\x8c\x2f\x2a\xcd\xcb\x4b\x2d\x02\x51\xc5\x4a\x40\xa5\xce\xfe\xbe\xbe\x9e\x21\xd6\x5c\x00\xcb\x1d\xdb\xee\x3b\x00\x00\x00")
+
+func _1528395581_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395581_DownSql,
+		"1528395581_.down.sql",
+	)
+}
+
+func _1528395581_DownSql() (*asset, error) {
+	bytes, err := _1528395581_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395581_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xce, 0x26, 0x5c, 0x18, 0xf6, 0x46, 0x98, 0xdb, 0x27, 0x24, 0x2, 0x5c, 0xcc, 0x4f, 0x69, 0x32, 0xfb, 0xa3, 0xeb, 0xdd, 0xe4, 0x5a, 0xce, 0x54, 0xdf, 0xc2, 0x8d, 0xa0, 0xb, 0xc3, 0x7e, 0x76}}
+	return a, nil
+}
+
+var __1528395581_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x85\x90\xc1\x8a\xc2\x30\x10\x86\xef\x79\x8a\x21\x27\x05\xdf\xc0\x53\xd5\xb8\x04\xdb\x54\x6a\x0a\x7a\x2a\xa9\x19\x34\xcb\x36\xdd\x4d\x53\xad\xfb\xf4\x5b\x63\xe9\xc2\xb2\xe2\x5c\x86\xe1\xff\xe7\x1f\xe6\x5b\xb0\x37\x2e\xe6\x84\x2c\x33\x16\x49\x06\x32\x5a\xc4\x0c\xf8\x1a\x44\x2a\x81\xed\xf9\x4e\xee\x80\x7e\xb5\xe8\x6e\x85\x6b\xad\x45\x77\x6f\x0d\x85\x09\x81\xbe\xa8\xd1\x14\x4a\x73\x6a\xd0\x19\xf5\x11\x76\x44\x1e\xc7\xb0\xcd\x78\x12\x65\x07\xd8\xb0\xc3\xec\x61\x0c\x11\x14\x3c\x76\x7e\xb4\x0d\x52\xa5\xfc\xf1\x8c\x7d\xe6\x7b\x53\xdb\xf2\xaf\xaa\xb4\x46\xfd\x44\x73\x58\xd5\x97\xa7\x2a\x76\x78\x6c\x3d\xea\x42\xf9\xfe\xb0\xa9\xb0\xf1\xaa\xfa\x84\xab\xf1\xe7\x30\xc2\x77\x6d\x11\x56\x6c\x1d\xe5\xb1\x04\x5b\x5f\x27\xd3\x31\x82\x4c\x7f\x91\x70\xb1\x62\xfb\x97\x48\x8a\xe1\xc3\x54\xfc\xcf\x6b\x20\x30\x0b\xcc\x42\x7a\x9a\x24\x5c\xce\xc9\x0f\x07\xf5\x70\x77\x81\x01\x00\x00")
+
+func _1528395581_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395581_UpSql,
+		"1528395581_.up.sql",
+	)
+}
+
+func _1528395581_UpSql() (*asset, error) {
+	bytes, err := _1528395581_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395581_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x82, 0x15, 0xd1, 0xed, 0x82, 0xb1, 0x91, 0xdc, 0x34, 0x1e, 0x54, 0xe8, 0xad, 0x55, 0x91, 0x48, 0xb6, 0x8b, 0xc6, 0x21, 0xbe, 0x39, 0xfb, 0x34, 0xd5, 0xee, 0xcc, 0xc9, 0xe1, 0x89, 0xbe, 0x33}}
+	return a, nil
+}
+
+var __1528395582_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x50\x2a\x4e\x2c\x4b\x4d\x89\x2f\x2c\x4d\x2d\xaa\x8c\x2f\x4f\x4d\xca\xc8\xcf\xcf\x8e\x4f\x49\xcd\xc9\x2c\x4b\x2d\xca\x4c\x2d\x56\x02\xea\x73\xf6\xf7\xf5\xf5\x0c\xb1\xe6\x02\x00\x41\xa9\x15\x08\x48\x00\x00\x00")
+
+func _1528395582_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395582_DownSql,
+		"1528395582_.down.sql",
+	)
+}
+
+func _1528395582_DownSql() (*asset, error) {
+	bytes, err := _1528395582_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395582_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x1f, 0xb1, 0xc4, 0x99, 0x89, 0x46, 0x85, 0xba, 0x47, 0xd5, 0x7a, 0xd5, 0x9, 0xa4, 0xf4, 0xfc, 0x8, 0xde, 0x62, 0xd9, 0x6b, 0xb1, 0x7e, 0xe5, 0x97, 0x44, 0x54, 0xc7, 0x26, 0x22, 0xd8, 0x38}}
+	return a, nil
+}
+
+var __1528395582_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x95\x52\xc1\x6e\x82\x40\x10\xbd\xf3\x15\x13\x2e\x62\xe2\x1f\x78\x42\x1c\x1a\x52\xc4\x06\x30\xd1\xd3\x06\x65\x82\x1b\x81\xb5\xbb\x0b\xd6\x7e\x7d\x17\x6a\xb1\x51\x1b\xd3\xbd\xcd\xbe\x37\x33\x6f\x66\xde\x0c\x5f\x82\x68\x6a\x59\x5e\x8c\x6e\x8a\x90\xba\xb3\x10\x21\xf0\x21\x5a\xa6\x80\xeb\x20\x49\x13\xb0\x55\xd6\x52\xce\xde\x1b\x92\x67\x76\xa2\xed\x5e\x88\x03\xcb\xa9\xe4\x2d\x49\x4e\xca\x06\xc7\x02\xf3\x6c\x9e\xdb\xb0\xe5\x85\x32\xbf\x59\xd9\x17\x88\x56\x61\x08\x6f\x71\xb0\x70\xe3\x0d\xbc\xe2\x66\xf2\x4d\x6c\x0c\x85\x75\x6c\x5e\x6b\x2a\x48\x42\x8c\x3e\xc6\x18\x79\x98\x40\x87\x29\x70\x78\x3e\x86\x65\x04\x73\x0c\xd1\xa8\xf2\xdc\xc4\x73\xe7\x78\x49\x17\xb2\xf8\x2b\xdb\x40\x4f\x92\x7f\xcf\x72\xa0\xb3\x0d\x9a\x3e\xf4\x20\xf6\x42\xa2\x96\x6a\xfd\x18\x6a\x64\xf9\x18\x50\x3a\xd3\x8d\x62\x3b\x91\xd3\x20\xed\x02\x49\x52\x47\x51\x2b\x62\x5b\x91\xdf\x76\x34\x2a\x7d\x77\x15\xa6\x30\x1a\xfd\x34\x97\x52\xc8\xa7\xac\x4c\x6b\xaa\x8e\x5a\x5d\xd7\x70\xa3\x67\x27\x29\xd3\x66\xd4\xac\x1b\x84\x57\x64\xf4\x55\x47\x38\x71\xbd\xef\x43\xf8\x14\x35\x0d\x65\x6b\x71\x72\xc6\x43\x05\x6b\x7c\x35\x44\x10\xcd\x71\xfd\x3f\x43\xb0\xbb\x1d\x9b\x63\x3c\x37\xd1\xdd\x69\x26\xbd\xa5\x7a\x2d\xcb\xc5\x22\x48\xa7\xd6\x17\x8e\x8b\x1c\xe0\xad\x02\x00\x00")
+
+func _1528395582_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395582_UpSql,
+		"1528395582_.up.sql",
+	)
+}
+
+func _1528395582_UpSql() (*asset, error) {
+	bytes, err := _1528395582_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395582_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x6e, 0x43, 0x28, 0x7a, 0xc5, 0x51, 0xde, 0xd1, 0x3d, 0xee, 0x4b, 0x2d, 0xb4, 0x97, 0x8e, 0x67, 0xd, 0x79, 0x7b, 0x10, 0x86, 0xef, 0xa5, 0xaf, 0x12, 0xad, 0xf2, 0xbe, 0x28, 0xa0, 0x5, 0x11}}
+	return a, nil
+}
+
+var __1528395583_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x50\x2a\x2d\x4e\x2d\x8a\x2f\x48\x2d\xca\xcd\x2c\x2e\xce\xcc\xcf\x2b\x56\x02\xaa\x74\xf6\xf7\xf5\xf5\x0c\xb1\xe6\x02\x00\x74\x9d\x3c\x1c\x3a\x00\x00\x00")
+
+func _1528395583_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395583_DownSql,
+		"1528395583_.down.sql",
+	)
+}
+
+func _1528395583_DownSql() (*asset, error) {
+	bytes, err := _1528395583_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395583_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x24, 0x8a, 0xcf, 0xc8, 0x3c, 0xd0, 0x60, 0x46, 0x63, 0xe9, 0xcc, 0xaf, 0x5e, 0xc9, 0xb1, 0xda, 0x23, 0x92, 0x84, 0x3f, 0x31, 0x81, 0xd0, 0xd0, 0xe4, 0xb4, 0x6f, 0x1b, 0x99, 0x5b, 0xad, 0x2e}}
+	return a, nil
+}
+
+var __1528395583_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x85\x90\xcd\x6e\x83\x30\x10\x84\xef\x7e\x8a\x15\x27\x90\x78\x83\x9c\x08\x2c\x95\x25\xc7\x28\x60\xa4\xdc\x10\x09\xab\xd6\x95\xf8\x29\x36\x49\xd3\xa7\xaf\x43\xda\xa0\x44\x51\xeb\x93\xad\x19\xcf\xce\x7e\x6b\x7c\xe1\x72\xc5\x58\x9c\x63\xa4\x10\x54\xb4\x16\x08\x3c\x05\x99\x29\xc0\x1d\x2f\x54\x01\xde\x64\x68\xac\x06\x1a\x5b\x6d\x8c\xee\x3b\xe3\x81\xcf\xc0\x9d\xab\xa0\x1b\x0f\x74\x67\xe9\x95\xc6\xf9\x97\x2c\x85\x80\x1c\x53\xcc\x51\xc6\x58\xc0\xc5\x64\xc0\xd7\x4d\x00\x99\x84\x04\x05\xba\x39\x71\x54\xc4\x51\x82\xe1\x35\x67\xc9\xf6\xc0\xd2\xa7\xbd\xe5\xfc\xe8\x2e\xe1\xa8\x0f\x54\xd9\xf3\x40\x7f\x3b\x2e\x65\x9e\xe9\xfd\xfe\x9d\x0e\xd6\xc9\xae\xfc\xfe\x6c\xa9\x7e\x34\x4c\x43\x53\x5b\x6a\xaa\xda\xba\x00\xdd\x92\xb1\x75\x3b\xc0\x49\xdb\xb7\xf9\x09\x5f\x7d\x47\xcb\x7a\x09\xa6\x51\x29\x14\x74\xfd\xc9\x0f\x58\xb0\xf0\x2b\x25\xdf\x96\x0e\xa0\x4c\x70\xf7\x1f\xc6\xf9\x5e\xfd\x36\x9f\x3a\xfd\x31\xb9\xed\x1c\xa3\x67\xc0\x6f\xac\xc3\x3b\x5c\xe1\x03\x9c\xf0\x0e\xc5\x5c\x2c\xdb\x6c\xb8\x5a\xb1\x6f\x29\x17\x58\x83\xe9\x01\x00\x00")
+
+func _1528395583_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395583_UpSql,
+		"1528395583_.up.sql",
+	)
+}
+
+func _1528395583_UpSql() (*asset, error) {
+	bytes, err := _1528395583_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395583_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x86, 0xf, 0x88, 0x51, 0x82, 0x7a, 0x90, 0x17, 0xfb, 0xec, 0xd, 0xfe, 0x9f, 0x39, 0xe2, 0x50, 0x1b, 0x5f, 0xa, 0x82, 0xef, 0xaa, 0x19, 0x4a, 0xc8, 0x36, 0xd7, 0xc9, 0xfe, 0x69, 0xb4, 0xad}}
+	return a, nil
+}
+
+var __1528395584_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x50\x4a\x4c\x4e\x4e\x2d\x2e\x8e\x2f\xc9\xcf\x4e\xcd\x2b\x56\x52\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x50\x4a\xad\x28\xc8\x2c\x4a\x2d\x8e\x4f\x2c\x51\x02\x1a\xe0\xec\xef\xeb\xeb\x19\x62\xcd\x05\x00\x09\x82\x95\xae\x51\x00\x00\x00")
+
+func _1528395584_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395584_DownSql,
+		"1528395584_.down.sql",
+	)
+}
+
+func _1528395584_DownSql() (*asset, error) {
+	bytes, err := _1528395584_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395584_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x99, 0x4f, 0xdc, 0x85, 0x34, 0x4, 0x11, 0x3a, 0x1d, 0x7c, 0x2a, 0x94, 0xf0, 0x0, 0x58, 0x51, 0x9e, 0x1f, 0x97, 0x96, 0x0, 0xe2, 0x99, 0xcd, 0x5, 0xd0, 0x73, 0x33, 0xe1, 0xc5, 0x19, 0x16}}
+	return a, nil
+}
+
+var __1528395584_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x1d\xcc\x41\x0a\x80\x20\x10\x00\xc0\xbb\xaf\x58\xfc\x46\x27\x4b\x09\x41\x0d\xc2\xce\x21\xb2\x90\x44\x25\xed\x42\xd1\xeb\x8b\x8e\x73\x99\xd6\xf4\x36\x34\x42\x28\x17\xcd\x08\x51\xb5\xce\x80\x4c\x39\x23\xd1\xcc\xc7\x8a\x3b\x49\x50\x5a\x43\x37\xb8\xc9\x07\x90\x78\xd7\x72\x22\xcd\x89\x25\x70\xd9\x90\x38\x6d\x15\xae\xc2\xcb\x4f\x78\x8e\x1d\xbf\xae\x1b\xbc\xb7\xb1\x11\x2f\xbe\x07\x20\x85\x5f\x00\x00\x00")
+
+func _1528395584_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395584_UpSql,
+		"1528395584_.up.sql",
+	)
+}
+
+func _1528395584_UpSql() (*asset, error) {
+	bytes, err := _1528395584_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395584_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xbf, 0x34, 0x6c, 0x1a, 0xbb, 0x70, 0xab, 0x36, 0xf5, 0x54, 0x87, 0x74, 0x2e, 0x7f, 0x21, 0xdd, 0x4a, 0x73, 0x62, 0xf8, 0xea, 0x55, 0x55, 0x89, 0x5a, 0x2d, 0xf, 0x3a, 0x6, 0x5f, 0x3b, 0x10}}
+	return a, nil
+}
+
+var __1528395585_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x50\x4a\x2c\x4d\xc9\x2c\x89\xcf\xc9\x4f\x57\xb2\x86\xa8\x70\x0b\xf5\x73\x0e\xf1\xf4\xf7\x43\x52\x04\x57\x13\x5f\x50\x94\x5a\x96\x9a\x57\x12\x5f\x5a\x90\x92\x58\x92\xaa\xa1\x09\x34\xd6\xd9\xdf\xd7\xd7\x33\xc4\x9a\x0b\x00\x1b\xdb\x3c\x51\x67\x00\x00\x00")
+
+func _1528395585_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395585_DownSql,
+		"1528395585_.down.sql",
+	)
+}
+
+func _1528395585_DownSql() (*asset, error) {
+	bytes, err := _1528395585_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395585_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xc4, 0xca, 0x5b, 0xc1, 0xcc, 0x8a, 0x7f, 0x57, 0x54, 0xe, 0x64, 0xbb, 0x1e, 0x7f, 0xcb, 0x98, 0x54, 0x14, 0xc, 0x51, 0xa0, 0x5e, 0xe, 0xaf, 0xd8, 0x66, 0x5e, 0xe2, 0xf5, 0xcb, 0x5e, 0x77}}
+	return a, nil
+}
+
+var __1528395585_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x95\x52\xd1\x8e\xda\x30\x10\x7c\xcf\x57\xac\x22\xa4\x03\xe9\xb8\x1f\xe0\x29\x17\x0c\x8d\x1a\x12\x64\x12\x95\x7b\x8a\x0c\x59\x82\xab\xe0\xb8\x8e\x29\x47\xbf\xbe\xeb\x1c\x77\xc0\x15\xaa\x36\x4f\xf1\xce\xec\xec\xce\x68\x9f\xd9\x34\x4a\x46\x9e\x17\x72\x16\x64\x0c\xb2\xe0\x39\x66\x10\x4d\x20\x49\x33\x60\xcb\x68\x91\x2d\xc0\x17\xfb\x52\xda\xa2\x6e\x2a\x1f\xfa\x1e\xd0\xe7\xcb\xd2\x87\x95\xac\x5a\x34\x52\xd4\x1d\x37\xc9\xe3\x18\xe6\x3c\x9a\x05\xfc\x05\xbe\xb2\x97\xc7\x37\xe2\xda\xa0\xb0\x58\x16\xc2\xfa\x60\xe5\x0e\x5b\x2b\x76\x1a\x0e\xd2\x6e\xbb\x27\xfc\x6a\x14\x9e\xfb\xc7\x6c\x12\xe4\x71\x06\xaa\x39\xf4\x07\x27\x05\xb1\xb6\x8d\x29\xf6\x34\xaa\x70\x53\xa5\xb2\x58\xa1\x39\x83\xb2\x51\x24\x8d\xaf\xf6\x43\xe6\x84\x59\x61\x2a\xb4\x85\x3d\x6a\xfc\x2b\xc1\xa9\xde\x82\xa5\xbe\x5d\xdf\x34\xe6\x20\x4c\x49\xa6\xe8\xef\x36\xa5\x94\x9b\x8d\x0f\xdf\xdb\x46\xad\xbc\xc1\x39\xdc\x28\x19\xb3\xe5\xdd\x70\x8b\xcb\xac\xd2\xe4\x3a\xf6\xcb\x20\x49\xf1\xdf\x04\x3f\x45\xf7\x87\xe6\x35\xfe\x3f\xb2\x5d\xe8\xb7\xf4\x1c\xe0\x1c\x0f\x87\x90\x6d\x11\x3a\x18\x08\x06\xd9\x82\xd0\x1a\x55\x39\x6c\x54\x7d\x7c\x02\xa6\xac\x91\xd8\xc2\x4e\x1c\xc1\x55\x60\x85\x50\x62\x8d\xe4\x11\xfa\x87\x2d\x2a\xb0\x5b\x3c\x82\x30\x08\x4d\x5d\xa2\xa1\xa7\xe8\x6a\x60\x88\xa3\xdc\x20\x37\x44\xd3\x05\x36\xe5\xe0\x91\x4e\xc6\xc2\x5e\x97\x2e\xa3\xa7\x77\x1f\x29\x07\xce\xe6\x71\x10\x32\x98\xe4\x49\x98\x45\xb4\xf1\xd9\x86\x36\xf8\x93\x84\x8a\xb7\xae\xfe\x80\xb8\x59\xce\x93\x05\x64\x3c\x9a\x4e\x19\x87\x60\x01\xbd\x9e\xb7\xc2\x4a\x2a\xcf\x08\xd9\x22\xe0\xeb\x1a\xb5\x1b\x0d\x0f\x67\x67\x78\xe1\xc4\x6d\x41\x46\x4e\x8b\x3c\x8c\x3c\x32\x3c\xf2\x7a\x3d\x88\x83\x64\x9a\x07\x53\x06\xba\xd6\x55\xfb\xa3\xfe\xc8\xfa\x7d\x18\x69\x50\xb2\x77\x96\x83\x67\x36\x49\x39\x83\x7c\x3e\xee\x7c\x5d\x07\x4f\x10\xb0\x20\xfc\x02\x3c\xfd\x46\xf7\xc7\x96\x2c\xcc\x89\x35\xe7\x69\xc8\xc6\x39\xb5\xdd\xf7\xec\x6e\x33\x9d\xcd\xa2\x6c\xe4\xfd\x06\x15\xb6\x13\x6c\x09\x04\x00\x00")
+
+func _1528395585_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395585_UpSql,
+		"1528395585_.up.sql",
+	)
+}
+
+func _1528395585_UpSql() (*asset, error) {
+	bytes, err := _1528395585_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395585_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x6b, 0x3f, 0x6d, 0x21, 0x63, 0xe, 0xd5, 0x99, 0x97, 0x7e, 0x1c, 0xb3, 0x1d, 0xec, 0xae, 0xcf, 0x1, 0xba, 0x61, 0x3d, 0x89, 0x90, 0xfd, 0xef, 0x6a, 0xc9, 0xbb, 0x38, 0xab, 0x17, 0xb, 0x23}}
+	return a, nil
+}
+
+var __1528395586_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4e\x4d\x2c\x4a\xce\x88\x4f\xad\x28\xc8\x2f\x2a\x29\x8e\x2f\x2e\x49\x2c\x49\xb5\xe6\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x45\x53\xa1\x00\x36\xc5\xd9\xdf\x27\xd4\xd7\x0f\xc9\x98\xc4\x92\x92\xd4\xdc\x82\x92\x62\x32\xb4\x66\x00\x15\x95\x24\xa5\x26\x96\xc4\x27\x96\x00\x5d\xe9\xec\xef\xeb\xeb\x19\x62\xcd\x05\x00\x4e\xe2\x63\xa9\xb6\x00\x00\x00")
+
+func _1528395586_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395586_DownSql,
+		"1528395586_.down.sql",
+	)
+}
+
+func _1528395586_DownSql() (*asset, error) {
+	bytes, err := _1528395586_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395586_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x25, 0xb9, 0xca, 0x13, 0xd4, 0x10, 0xc1, 0x5a, 0x63, 0x5d, 0x13, 0x8e, 0x35, 0x17, 0x74, 0xd4, 0xd0, 0x10, 0x1e, 0xa, 0xb2, 0x37, 0xf5, 0x8e, 0x1a, 0xf5, 0xf5, 0x8e, 0x2, 0x52, 0xf8, 0xd0}}
+	return a, nil
+}
+
+var __1528395586_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8d\x51\xcb\x6e\xc2\x30\x10\xbc\xe7\x2b\x46\x39\x81\xd4\xa2\xde\x73\x0a\x60\xaa\x48\x21\x91\x20\x48\xdc\x90\x49\x16\x62\x35\xb1\x53\xdb\x21\xa5\x5f\x5f\x3b\x14\x24\x7a\xea\xd1\x3b\x3b\x8f\x1d\xcf\xd9\x7b\x92\x45\x41\xf0\xfa\x8a\x2d\x71\x5d\xd6\xa0\xaf\x4e\x69\x6b\xc0\x35\x41\xaa\x01\xba\x97\x38\x5e\xc1\x31\x28\xfd\x41\x1a\xb6\xe6\x16\x65\xc3\x45\x6b\xf0\xd9\x53\x4f\xd5\x9d\xf2\x02\xa3\x6e\xb0\xad\xe9\x0a\xd3\xeb\x8b\xb8\x10\x34\x19\xcb\x1d\xec\x3d\xd4\xc9\x63\x38\x69\x25\x2d\xc9\x6a\x86\xda\x99\xda\x23\x71\x7b\x70\x34\x61\xd0\x77\x15\xb7\x4e\xb2\x23\x2d\x54\x25\x4a\xde\x34\x57\x0c\xb5\x68\x08\x5c\xfe\x1a\xf9\x3d\x97\x4a\x0a\x79\x8e\x1e\x71\x87\x5a\x19\xf2\x16\x0f\x45\x18\xab\x3a\x83\xc9\x91\x4a\xde\x1b\x1a\x8d\x3b\xad\x4a\x32\x0f\xba\x9f\xb5\xa8\x04\x55\xd3\xf1\x5e\x7f\x2b\x3f\x73\x21\x67\x41\x9c\x16\x6c\x83\x22\x9e\xa7\x0c\x66\x6c\xe6\x70\xb7\x8a\x97\x4b\x2c\xf2\x74\xb7\xce\x9e\xe3\x5b\xd1\xfa\x53\xdb\x0e\x83\xb0\xf5\xf8\xc4\xb7\x92\x14\xfd\x53\x8c\x5b\x4b\x6d\xe7\x66\xc2\x95\x73\x76\x55\x67\x79\x81\x6c\x97\xa6\x58\xb2\x55\xbc\x4b\x0b\xbc\xb9\x9f\x5a\x6c\x58\x5c\x30\x24\xd9\x92\xed\x91\xac\xc6\x25\xb6\x4f\xb6\xc5\x16\xe1\xb3\xf6\xc1\x85\xb1\x14\x22\xcf\xfe\x22\x21\x26\xe1\x0d\x9c\x7a\xc5\x7c\xbd\x4e\x8a\x28\xf8\x01\x6a\x43\x70\xe7\x0c\x02\x00\x00")
+
+func _1528395586_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395586_UpSql,
+		"1528395586_.up.sql",
+	)
+}
+
+func _1528395586_UpSql() (*asset, error) {
+	bytes, err := _1528395586_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395586_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe8, 0x30, 0x62, 0xad, 0xd4, 0xf7, 0xc8, 0x83, 0x2a, 0xa9, 0x8b, 0xdf, 0x60, 0xe4, 0xbe, 0x50, 0xfe, 0x88, 0x11, 0x7, 0x91, 0xd8, 0xa1, 0x5b, 0x16, 0x86, 0x3c, 0xb9, 0x41, 0x66, 0x59, 0x75}}
+	return a, nil
+}
+
+var __1528395587_DownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x73\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x4e\x2c\x4b\x4d\x89\x2f\x2c\x4d\x2d\xaa\x8c\x2f\x4f\x4d\xca\xc8\xcf\xcf\x8e\x2f\x4e\x4d\x2e\x4a\x2d\x29\x06\x6a\x71\xf6\xf7\xf5\xf5\x0c\xb1\xe6\x02\x00\xe6\x29\xe9\x33\x43\x00\x00\x00")
+
+func _1528395587_DownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395587_DownSql,
+		"1528395587_.down.sql",
+	)
+}
+
+func _1528395587_DownSql() (*asset, error) {
+	bytes, err := _1528395587_DownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395587_.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa9, 0x87, 0x66, 0xba, 0x61, 0x81, 0xbc, 0x41, 0x6, 0x46, 0x1, 0x5c, 0x14, 0xb5, 0xb7, 0x13, 0x31, 0xd, 0x68, 0xb8, 0xa2, 0x1a, 0xfa, 0x1e, 0xa6, 0xd9, 0x80, 0xea, 0xf2, 0x56, 0xb9, 0xf0}}
+	return a, nil
+}
+
+var __1528395587_UpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x95\x51\xc1\x6e\x82\x40\x10\xbd\xf3\x15\x13\x2e\x85\x04\x4d\xef\x9e\x10\xd7\x86\x04\x21\x15\x48\xbc\x91\xb5\xac\xb0\xb1\xb2\x96\x1d\x6b\xe9\xd7\x77\x76\xa5\xc6\x34\x6d\x4c\x09\x97\xd9\x37\xf3\xde\x9b\x37\x73\xf6\x14\xa7\x33\xc7\x99\x4c\xa0\x68\x05\x68\xf1\xd2\x0b\xd4\x80\x2d\x47\xd0\xfc\x5d\xd4\xf0\x76\x12\xfd\x00\x67\xb1\x6d\x95\xda\x43\xa7\x50\xee\xe4\x0b\x47\xa9\x3a\x0d\xbc\xa7\x11\xd9\x74\xd4\x76\x96\xd8\x4e\x0d\xc7\x00\x27\x4d\x35\x2a\xd8\x12\x88\xaa\xa7\x42\x76\xc4\x28\x8c\x88\xd7\x0b\x5e\xf3\xed\xab\xf0\x49\x0b\x51\x76\x8d\x06\xb5\x33\xe8\xad\xdc\x83\x36\x24\x3d\x28\xf3\x37\x01\x68\x65\x3a\x06\x68\xf9\x95\x58\x20\xf0\x86\xcb\x6e\xea\x44\x6b\x16\x16\x0c\x8a\x70\x9e\x30\x88\x97\x90\x66\x05\xb0\x4d\x9c\x17\x39\xb8\x96\xb3\xb2\x9c\xd5\xb8\x42\x35\xee\xe8\x82\xe7\x00\x7d\xae\x51\xaa\x64\xed\x92\x4b\x14\x0d\xa9\xae\xd9\x92\xad\x59\x1a\xb1\xdc\xba\xd0\xe0\xc9\xda\x87\x2c\x85\x05\x4b\x18\x29\x45\x61\x1e\x85\x0b\x16\x5c\xc6\xc9\xe0\x5f\xd3\x04\xdd\x19\xbe\xf5\xb7\x17\x83\x0b\x28\x3e\xd0\x6e\x90\x96\x49\xf2\xdd\x64\x0d\xff\x8e\x9d\x8e\x35\x47\xa2\xe0\x06\x97\x07\xa1\x91\x1f\x8e\xf6\x18\xb6\x84\x4f\xd5\x09\x92\x5e\x86\x65\x52\xd0\xf1\xce\x9e\x7f\x65\x70\x7c\xba\xfb\x18\x5e\x99\xc6\xcf\x25\xa5\x97\x2e\xd8\xe6\x1f\x19\x56\x37\x98\x6b\x96\xbc\x13\x78\x94\x85\x09\xcb\x23\xe6\x8d\x99\x07\xf0\xe8\x07\x70\x7d\xbd\x44\x79\x79\xfc\x91\x8c\xf5\x9a\xad\x56\x71\x31\x73\xbe\x00\x9c\xa7\x7f\x7a\xb4\x02\x00\x00")
+
+func _1528395587_UpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395587_UpSql,
+		"1528395587_.up.sql",
+	)
+}
+
+func _1528395587_UpSql() (*asset, error) {
+	bytes, err := _1528395587_UpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395587_.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xc0, 0xac, 0x4f, 0x5f, 0x92, 0xfa, 0xf0, 0x1a, 0xda, 0x3d, 0xb5, 0xf9, 0xf2, 0xe7, 0xe9, 0x8f, 0x16, 0x90, 0x28, 0xe1, 0x4e, 0x8f, 0xc5, 0x2e, 0x11, 0xe2, 0x8, 0x6f, 0x1f, 0xed, 0x1d, 0x13}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -5195,17 +5573,55 @@ var _bindata = map[string]func() (*asset, error){
 	"1528395578_.down.sql": _1528395578_DownSql,
 
 	"1528395578_.up.sql": _1528395578_UpSql,
+
+	"1528395579_.down.sql": _1528395579_DownSql,
+
+	"1528395579_.up.sql": _1528395579_UpSql,
+
+	"1528395580_.down.sql": _1528395580_DownSql,
+
+	"1528395580_.up.sql": _1528395580_UpSql,
+
+	"1528395581_.down.sql": _1528395581_DownSql,
+
+	"1528395581_.up.sql": _1528395581_UpSql,
+
+	"1528395582_.down.sql": _1528395582_DownSql,
+
+	"1528395582_.up.sql": _1528395582_UpSql,
+
+	"1528395583_.down.sql": _1528395583_DownSql,
+
+	"1528395583_.up.sql": _1528395583_UpSql,
+
+	"1528395584_.down.sql": _1528395584_DownSql,
+
+	"1528395584_.up.sql": _1528395584_UpSql,
+
+	"1528395585_.down.sql": _1528395585_DownSql,
+
+	"1528395585_.up.sql": _1528395585_UpSql,
+
+	"1528395586_.down.sql": _1528395586_DownSql,
+
+	"1528395586_.up.sql": _1528395586_UpSql,
+
+	"1528395587_.down.sql": _1528395587_DownSql,
+
+	"1528395587_.up.sql": _1528395587_UpSql,
 }
 
 // AssetDir returns the file names below a certain
 // directory embedded in the file by go-bindata.
 // For example if you run go-bindata on data/... and data contains the
 // following hierarchy:
-//     data/
-//       foo.txt
-//       img/
-//         a.png
-//         b.png
+//
+//	data/
+//	  foo.txt
+//	  img/
+//	    a.png
+//	    b.png
+//
 // then AssetDir("data") would return []string{"foo.txt", "img"},
 // AssetDir("data/img") would return []string{"a.png", "b.png"},
 // AssetDir("foo.txt") and AssetDir("notexist") would return an error, and
@@ -5457,6 +5873,24 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"1528395577_.up.sql":                                          {_1528395577_UpSql, map[string]*bintree{}},
 	"1528395578_.down.sql":                                        {_1528395578_DownSql, map[string]*bintree{}},
 	"1528395578_.up.sql":                                          {_1528395578_UpSql, map[string]*bintree{}},
+	"1528395579_.down.sql":                                        {_1528395579_DownSql, map[string]*bintree{}},
+	"1528395579_.up.sql":                                          {_1528395579_UpSql, map[string]*bintree{}},
+	"1528395580_.down.sql":                                        {_1528395580_DownSql, map[string]*bintree{}},
+	"1528395580_.up.sql":                                          {_1528395580_UpSql, map[string]*bintree{}},
+	"1528395581_.down.sql":                                        {_1528395581_DownSql, map[string]*bintree{}},
+	"1528395581_.up.sql":                                          {_1528395581_UpSql, map[string]*bintree{}},
+	"1528395582_.down.sql":                                        {_1528395582_DownSql, map[string]*bintree{}},
+	"1528395582_.up.sql":                                          {_1528395582_UpSql, map[string]*bintree{}},
+	"1528395583_.down.sql":                                        {_1528395583_DownSql, map[string]*bintree{}},
+	"1528395583_.up.sql":                                          {_1528395583_UpSql, map[string]*bintree{}},
+	"1528395584_.down.sql":                                        {_1528395584_DownSql, map[string]*bintree{}},
+	"1528395584_.up.sql":                                          {_1528395584_UpSql, map[string]*bintree{}},
+	"1528395585_.down.sql":                                        {_1528395585_DownSql, map[string]*bintree{}},
+	"1528395585_.up.sql":                                          {_1528395585_UpSql, map[string]*bintree{}},
+	"1528395586_.down.sql":                                        {_1528395586_DownSql, map[string]*bintree{}},
+	"1528395586_.up.sql":                                          {_1528395586_UpSql, map[string]*bintree{}},
+	"1528395587_.down.sql":                                        {_1528395587_DownSql, map[string]*bintree{}},
+	"1528395587_.up.sql":                                          {_1528395587_UpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory.
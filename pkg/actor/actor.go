@@ -24,6 +24,25 @@ type Actor struct {
 	// to selectively display a logout link. (If the actor wasn't authenticated with a session
 	// cookie, logout would be ineffective.)
 	FromSessionCookie bool `json:"-"`
+
+	// Scopes, if non-nil, is the list of access token scopes that the actor is restricted to. It
+	// is nil if the actor was not authenticated with an access token, or if the access token grants
+	// full control of the user account.
+	Scopes []string `json:"-"`
+}
+
+// HasScope reports whether the actor is permitted to perform operations that require the given
+// access token scope. Actors that are not restricted to a list of scopes have all scopes.
+func (a *Actor) HasScope(scope string) bool {
+	if a.Scopes == nil {
+		return true
+	}
+	for _, s := range a.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
 
 // FromUser returns an actor corresponding to a user
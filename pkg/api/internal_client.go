@@ -86,6 +86,8 @@ type ConfigSavedQuery struct {
 	UserID          *int32  `json:"userID"`
 	OrgID           *int32  `json:"orgID"`
 	SlackWebhookURL *string `json:"slackWebhookURL"`
+
+	NotifyWebhook *schema.SavedQueryWebhook `json:"notifyWebhook,omitempty"`
 }
 
 func (sq ConfigSavedQuery) Equals(other ConfigSavedQuery) bool {
@@ -158,6 +160,118 @@ func (c *internalClient) SavedQueriesDeleteInfo(ctx context.Context, query strin
 	return c.postInternal(ctx, "saved-queries/delete-info", query, nil)
 }
 
+// SavedQueryMatch is a single match in the search results of a saved query.
+type SavedQueryMatch struct {
+	// Fingerprint identifies the match across runs of the saved query. It is
+	// derived from the match's contents (not its line number), so that a match
+	// is not reported as new when lines are added or removed above it.
+	Fingerprint string
+
+	Repository RepoName
+	Commit     CommitID `json:",omitempty"` // the commit of a commit or diff match
+	Path       string   `json:",omitempty"` // the file of a file match
+	LineNumber int32    `json:",omitempty"` // the 1-based line number of a line match
+	Preview    string   `json:",omitempty"` // the matched line, or the commit message subject
+}
+
+// SavedQueryRun represents the matches of a single execution of a saved query
+// and how they differ from the previous execution.
+type SavedQueryRun struct {
+	// Query is the search query in question.
+	Query string
+
+	// ExecutedAt is the timestamp of the execution.
+	ExecutedAt time.Time
+
+	// Matches is the set of matches found by the execution.
+	Matches []SavedQueryMatch
+
+	// Added and Removed are the matches that were found by this execution but
+	// not by the previous execution, and vice versa.
+	Added, Removed []SavedQueryMatch
+}
+
+// SavedQueriesGetLastRun gets the last run from the DB for the given saved
+// query. nil is returned if the saved query has not run yet.
+func (c *internalClient) SavedQueriesGetLastRun(ctx context.Context, query string) (*SavedQueryRun, error) {
+	var result *SavedQueryRun
+	err := c.postInternal(ctx, "saved-queries/get-last-run", query, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SavedQueriesAddRun records a run of the given run.Query in the DB.
+func (c *internalClient) SavedQueriesAddRun(ctx context.Context, run *SavedQueryRun) error {
+	return c.postInternal(ctx, "saved-queries/add-run", run, nil)
+}
+
+// SavedQueryWebhookDelivery represents the delivery of a saved query
+// notification to the webhook configured for the saved query.
+type SavedQueryWebhookDelivery struct {
+	ID   int64 `json:",omitempty"`
+	Spec SavedQueryIDSpec
+
+	// Event is the type of the notification, such as "saved_search.results".
+	Event string
+
+	// URL is the webhook URL that the notification was sent to.
+	URL string
+
+	// StatusCode and ResponseBody are the status code and the (truncated) body
+	// of the response to the last attempt. StatusCode is 0 if there was no
+	// response.
+	StatusCode   int
+	ResponseBody string
+
+	// Error is the error of the last attempt, or empty if the delivery
+	// succeeded.
+	Error string
+
+	// Attempts is the number of attempts that were made to deliver the
+	// notification.
+	Attempts int
+
+	CreatedAt time.Time
+}
+
+// SavedQueriesAddWebhookDelivery records the delivery of a saved query
+// notification to a webhook in the DB.
+func (c *internalClient) SavedQueriesAddWebhookDelivery(ctx context.Context, delivery *SavedQueryWebhookDelivery) error {
+	return c.postInternal(ctx, "saved-queries/add-webhook-delivery", delivery, nil)
+}
+
+// SavedQueriesGetWebhookSecret returns the secret that the saved query's
+// webhook notifications are signed with, or "" if there is none.
+func (c *internalClient) SavedQueriesGetWebhookSecret(ctx context.Context, spec SavedQueryIDSpec) (secret string, err error) {
+	err = c.postInternal(ctx, "saved-queries/get-webhook-secret", spec, &secret)
+	return secret, err
+}
+
+// SavedQueriesFilterMatchesRequest is a request for the matches in
+// repositories that a user can access.
+type SavedQueriesFilterMatchesRequest struct {
+	UserID  int32
+	Matches []SavedQueryMatch
+}
+
+var MockSavedQueriesFilterMatches func(userID int32, matches []SavedQueryMatch) ([]SavedQueryMatch, error)
+
+// SavedQueriesFilterMatches returns the matches (of a saved query's search,
+// which the query-runner runs as an internal actor) in repositories that the
+// user can access.
+func (c *internalClient) SavedQueriesFilterMatches(ctx context.Context, userID int32, matches []SavedQueryMatch) (filtered []SavedQueryMatch, err error) {
+	if MockSavedQueriesFilterMatches != nil {
+		return MockSavedQueriesFilterMatches(userID, matches)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	err = c.postInternal(ctx, "saved-queries/filter-matches", &SavedQueriesFilterMatchesRequest{UserID: userID, Matches: matches}, &filtered)
+	return filtered, err
+}
+
 func (c *internalClient) SettingsGetForSubject(ctx context.Context, subject SettingsSubject) (parsed *schema.Settings, settings *Settings, err error) {
 	err = c.postInternal(ctx, "settings/get-for-subject", subject, &settings)
 	if err == nil {
@@ -0,0 +1,53 @@
+package auditlog
+
+import (
+	"testing"
+)
+
+func TestDiffJSONC(t *testing.T) {
+	tests := map[string]struct {
+		before, after string
+		want          string
+	}{
+		"unchanged": {
+			before: `{"a": 1, "b": [1, 2]}`,
+			after:  `{"b": [1, 2], /* comment */ "a": 1,}`,
+			want:   `[]`,
+		},
+		"changed, added, and removed properties": {
+			before: `{"a": 1, "b": {"c": true, "d": "x"}, "e": null}`,
+			after:  `{"a": 2, "b": {"c": true, "f": [1]}}`,
+			want:   `[{"path":["a"],"before":1,"after":2},{"path":["b","d"],"before":"x"},{"path":["b","f"],"after":[1]},{"path":["e"],"before":null}]`,
+		},
+		"empty document": {
+			before: ``,
+			after:  `{"a": 1}`,
+			want:   `[{"path":[],"after":{"a":1}}]`,
+		},
+		"secrets": {
+			before: `{"email.smtp": {"password": "p1"}, "auth.providers": []}`,
+			after:  `{"email.smtp": {"password": "p2"}, "auth.providers": [{"type": "github", "clientSecret": "s"}]}`,
+			want:   `[{"path":["auth.providers"],"before":[],"after":[{"clientSecret":"REDACTED","type":"github"}]},{"path":["email.smtp","password"],"before":"REDACTED","after":"REDACTED"}]`,
+		},
+		"URL userinfo": {
+			before: `{"url": "https://example.com"}`,
+			after:  `{"url": "https://user:p@ss@example.com/path?q=1", "repos": ["git://token@example.com/a", "a@b.com"]}`,
+			want:   `[{"path":["repos"],"after":["git://REDACTED@example.com/a","a@b.com"]},{"path":["url"],"before":"https://example.com","after":"https://REDACTED@example.com/path?q=1"}]`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := DiffJSONC(test.before, test.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got  %s\nwant %s", got, test.want)
+			}
+		})
+	}
+
+	if _, err := DiffJSONC(`{`, `{}`); err == nil {
+		t.Error("got no error for invalid JSONC, want error")
+	}
+}
@@ -0,0 +1,125 @@
+// Package comby runs comby (https://comby.dev), the external tool that matches and rewrites code
+// templates, over the files of a repository archive. It is used by the replacer to rewrite code and
+// by the searcher for structural search, so that both interpret templates in the same way.
+package comby
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BinaryPath is the path of the comby executable. It is a variable so that
+// tests can change it.
+var BinaryPath = "comby"
+
+// ErrNotInstalled is returned when the comby executable is not found.
+var ErrNotInstalled = errors.New("comby is not installed (see https://comby.dev)")
+
+// maxOutputLineSize is the maximum size of a line of comby's output (the
+// matches or the diff of a single file).
+const maxOutputLineSize = 10 * 1024 * 1024
+
+// RewriteArgs returns the arguments with which comby rewrites the matches of
+// matchTemplate with rewriteTemplate in the files of the zip archive (only
+// those whose names end with fileExtension, if it is set). comby then writes
+// the diff of each rewritten file to its stdout as JSON lines.
+func RewriteArgs(matchTemplate, rewriteTemplate, fileExtension, zipPath string) []string {
+	return []string{matchTemplate, rewriteTemplate, fileExtension, "-zip", zipPath, "-json-lines"}
+}
+
+// Location is a position in a file.
+type Location struct {
+	Offset int `json:"offset"` // the 0-based byte offset in the file
+	Line   int `json:"line"`   // the 1-based line number
+	Column int `json:"column"` // the 1-based column number
+}
+
+// Range is the [Start, End) range of a match.
+type Range struct {
+	Start Location `json:"start"`
+	End   Location `json:"end"`
+}
+
+// Match is a match of a template.
+type Match struct {
+	Range   Range  `json:"range"`
+	Matched string `json:"matched"` // the matched text
+}
+
+// FileMatch is the matches of a template in a file.
+type FileMatch struct {
+	URI     string  `json:"uri"` // the path of the file in the archive
+	Matches []Match `json:"matches"`
+}
+
+// Matches finds the matches of matchTemplate in the files of the zip archive
+// and calls f with the matches in each file (in no particular order) until f
+// returns false.
+func Matches(ctx context.Context, matchTemplate, zipPath string, f func(*FileMatch) bool) error {
+	if strings.TrimSpace(matchTemplate) == "" {
+		return errors.New("comby: the match template must contain non-whitespace characters")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, BinaryPath, matchTemplate, "", "-zip", zipPath, "-match-only", "-json-lines")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		if execErr, ok := err.(*exec.Error); ok && execErr.Err == exec.ErrNotFound {
+			return ErrNotInstalled
+		}
+		return errors.Wrap(err, "comby")
+	}
+
+	stopped, readErr := readMatches(stdout, f)
+	if stopped || readErr != nil {
+		// Either f doesn't need more matches or the output can't be read, so
+		// comby's exit status no longer matters.
+		cancel()
+		_ = cmd.Wait()
+		return readErr
+	}
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return errors.Wrapf(err, "comby: %s", strings.TrimSpace(stderr.String()))
+	}
+	return readErr
+}
+
+// readMatches reads comby's JSON lines output of matches and calls f with the
+// matches in each file until f returns false, in which case stopped is true.
+func readMatches(r io.Reader, f func(*FileMatch) bool) (stopped bool, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxOutputLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var fm FileMatch
+		if err := json.Unmarshal(line, &fm); err != nil {
+			return true, errors.Wrap(err, "invalid comby output")
+		}
+		if len(fm.Matches) == 0 {
+			continue
+		}
+		if !f(&fm) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
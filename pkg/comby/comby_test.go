@@ -0,0 +1,73 @@
+package comby
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadMatches(t *testing.T) {
+	output := `{"uri":"main.go","matches":[{"range":{"start":{"offset":29,"line":4,"column":1},"end":{"offset":40,"line":5,"column":5}},"environment":[{"variable":"args","value":"a,\n\tb","range":{"start":{"offset":33,"line":4,"column":5},"end":{"offset":39,"line":5,"column":4}}}],"matched":"foo(a,\n\tb)"}]}
+
+{"uri":"empty.go","matches":[]}
+{"uri":"dir/a.go","matches":[{"range":{"start":{"offset":0,"line":1,"column":1},"end":{"offset":5,"line":1,"column":6}},"environment":[],"matched":"foo()"}]}
+`
+	var got []*FileMatch
+	stopped, err := readMatches(strings.NewReader(output), func(fm *FileMatch) bool {
+		got = append(got, fm)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stopped {
+		t.Error("got stopped, want false")
+	}
+	want := []*FileMatch{
+		{URI: "main.go", Matches: []Match{{
+			Range: Range{
+				Start: Location{Offset: 29, Line: 4, Column: 1},
+				End:   Location{Offset: 40, Line: 5, Column: 5},
+			},
+			Matched: "foo(a,\n\tb)",
+		}}},
+		{URI: "dir/a.go", Matches: []Match{{
+			Range: Range{
+				Start: Location{Offset: 0, Line: 1, Column: 1},
+				End:   Location{Offset: 5, Line: 1, Column: 6},
+			},
+			Matched: "foo()",
+		}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// Reading stops when f returns false.
+	var n int
+	stopped, err = readMatches(strings.NewReader(output), func(*FileMatch) bool {
+		n++
+		return false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stopped || n != 1 {
+		t.Errorf("got stopped=%v after %d files, want stopped=true after 1 file", stopped, n)
+	}
+
+	if _, err := readMatches(strings.NewReader("not json\n"), func(*FileMatch) bool { return true }); err == nil {
+		t.Error("got nil error for invalid output")
+	}
+}
+
+func TestMatches_notInstalled(t *testing.T) {
+	defer func(path string) { BinaryPath = path }(BinaryPath)
+	BinaryPath = "comby-not-installed"
+
+	err := Matches(context.Background(), "foo(:[args])", "x.zip", func(*FileMatch) bool { return true })
+	if err != ErrNotInstalled {
+		t.Errorf("got error %v, want %v", err, ErrNotInstalled)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/conf/confdefaults"
@@ -167,6 +168,15 @@ func AccessTokensAllow() AccessTokAllow {
 	}
 }
 
+// AuditLogRetention returns how long entries are kept in the audit log before they are deleted.
+func AuditLogRetention() time.Duration {
+	days := Get().AuditLogRetentionDays
+	if days <= 0 {
+		days = 90
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
 // EmailVerificationRequired returns whether users must verify an email address before they
 // can perform most actions on this site.
 //
@@ -275,6 +285,34 @@ func readSrcGitServers() []string {
 	return strings.Fields(v)
 }
 
+// SrcGitServerReplicas represents the SRC_GIT_SERVER_REPLICAS environment
+// variable, which is the number of gitservers that each repository is stored
+// on. It must be set to the same value for all services.
+var SrcGitServerReplicas = readSrcGitServerReplicas()
+
+func readSrcGitServerReplicas() int {
+	v := env.Get("SRC_GIT_SERVER_REPLICAS", "1", "number of gitservers that each repository is stored on")
+	replicas, err := strconv.Atoi(v)
+	if err != nil || replicas < 1 {
+		log.Fatalf("The 'SRC_GIT_SERVER_REPLICAS' environment variable is invalid. Expected a positive integer. Got: %q", v)
+	}
+	return replicas
+}
+
+// SrcGitServerPlacement represents the SRC_GIT_SERVER_PLACEMENT environment
+// variable, which is how repositories are placed on gitservers ("modulo" or
+// "rendezvous", see gitserver.Placement). It must be set to the same value for
+// all services.
+var SrcGitServerPlacement = readSrcGitServerPlacement()
+
+func readSrcGitServerPlacement() string {
+	v := env.Get("SRC_GIT_SERVER_PLACEMENT", "modulo", "how repositories are placed on gitservers (modulo or rendezvous)")
+	if v != "modulo" && v != "rendezvous" {
+		log.Fatalf("The 'SRC_GIT_SERVER_PLACEMENT' environment variable is invalid. Expected \"modulo\" or \"rendezvous\". Got: %q", v)
+	}
+	return v
+}
+
 func UsingExternalURL() bool {
 	url := Get().Critical.ExternalURL
 	return !(url == "" || strings.HasPrefix(url, "http://localhost") || strings.HasPrefix(url, "https://localhost") || strings.HasPrefix(url, "http://127.0.0.1") || strings.HasPrefix(url, "https://127.0.0.1")) // CI:LOCALHOST_OK
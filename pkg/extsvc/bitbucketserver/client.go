@@ -56,6 +56,13 @@ type Client struct {
 	// version 5.4 and older). If both Token and Username/Password are specified, Token is used.
 	Username, Password string
 
+	// oauth, if non-nil, are the OAuth credentials of an Application Link, which are used to
+	// authenticate requests instead of Token or Username/Password (see SetOAuth).
+	oauth *oauthCredentials
+
+	// sudo is the username of the user that requests are made as, if any (see Sudo).
+	sudo string
+
 	// RateLimit is the self-imposed rate limiter (since Bitbucket does not have a concept
 	// of rate limiting in HTTP response headers).
 	RateLimit *rate.Limiter
@@ -79,6 +86,55 @@ func NewClient(url *url.URL, httpClient httpcli.Doer) *Client {
 	}
 }
 
+// SetOAuth configures the client to authenticate requests with the OAuth credentials of an
+// Application Link with incoming authentication (2-legged OAuth). consumerKey is the consumer key
+// of the Application Link, and signingKey is the PEM-encoded RSA private key whose public key is
+// configured in it.
+func (c *Client) SetOAuth(consumerKey string, signingKey []byte) error {
+	key, err := parseSigningKey(signingKey)
+	if err != nil {
+		return err
+	}
+	c.oauth = &oauthCredentials{consumerKey: consumerKey, signingKey: key}
+	return nil
+}
+
+// Sudo returns a copy of the client that makes requests as the user with the given username. It
+// requires the OAuth credentials (see SetOAuth) of an Application Link that allows user
+// impersonation.
+func (c *Client) Sudo(username string) (*Client, error) {
+	if c.oauth == nil {
+		return nil, errors.New("bitbucketserver.Client: impersonating a user requires OAuth credentials")
+	}
+	sudo := *c
+	sudo.sudo = username
+	return &sudo, nil
+}
+
+// Users returns a page of the users whose username, display name, or email address contain the
+// given filter. If filter is empty, all users are returned.
+func (c *Client) Users(ctx context.Context, pageToken *PageToken, filter string) ([]*User, *PageToken, error) {
+	qry := pageToken.Values()
+	if filter != "" {
+		qry.Set("filter", filter)
+	}
+
+	u := fmt.Sprintf("rest/api/1.0/users?%s", qry.Encode())
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var resp struct {
+		*PageToken
+		Values []*User
+	}
+	err = c.do(ctx, req, &resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Values, resp.PageToken, nil
+}
+
 func (c *Client) Repo(ctx context.Context, projectKey, repoSlug string) (*Repo, error) {
 	u := fmt.Sprintf("rest/api/1.0/projects/%s/repos/%s", projectKey, repoSlug)
 	req, err := http.NewRequest("GET", u, nil)
@@ -145,8 +201,17 @@ func (c *Client) do(ctx context.Context, req *http.Request, result interface{})
 	req.URL = c.URL.ResolveReference(req.URL)
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 
-	// Authenticate request, preferring token.
-	if c.Token != "" {
+	// Authenticate request, preferring OAuth, then token.
+	if c.oauth != nil {
+		if c.sudo != "" {
+			qry := req.URL.Query()
+			qry.Set("xoauth_requestor_id", c.sudo)
+			req.URL.RawQuery = qry.Encode()
+		}
+		if err := c.oauth.sign(req); err != nil {
+			return err
+		}
+	} else if c.Token != "" {
 		req.Header.Set("Authorization", "Bearer "+c.Token)
 	} else if c.Username != "" || c.Password != "" {
 		req.SetBasicAuth(c.Username, c.Password)
@@ -190,8 +255,10 @@ func categorize(u *url.URL) string {
 	// 	Repo -> rest/api/1.0/profile/recent/repos%s
 	// 	Repos -> rest/api/1.0/projects/%s/repos/%s
 	// 	RecentRepos -> rest/api/1.0/repos%s
+	// 	Users -> rest/api/1.0/users%s
 	//
-	// We guess the category based on the fourth path component ("profile", "projects", "repos%s").
+	// We guess the category based on the fourth path component ("profile", "projects", "repos%s",
+	// "users%s").
 	var category string
 	if parts := strings.SplitN(u.Path, "/", 3); len(parts) >= 4 {
 		category = parts[3]
@@ -203,6 +270,8 @@ func categorize(u *url.URL) string {
 		return "Repos"
 	case strings.HasPrefix(category, "repos"):
 		return "RecentRepos"
+	case strings.HasPrefix(category, "users"):
+		return "Users"
 	default:
 		// don't return category directly as that could introduce too much dimensionality
 		return "unknown"
@@ -273,6 +342,17 @@ func (r *Repo) IsPersonalRepository() bool {
 	return r.Project.Type == "PERSONAL"
 }
 
+// User is a Bitbucket Server user.
+type User struct {
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+	ID           int    `json:"id"`
+	DisplayName  string `json:"displayName"`
+	Active       bool   `json:"active"`
+	Slug         string `json:"slug"`
+	Type         string `json:"type"`
+}
+
 type Project struct {
 	Key    string `json:"key"`
 	ID     int    `json:"id"`
@@ -0,0 +1,140 @@
+package bitbucketserver
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// oauthCredentials are the OAuth 1.0a credentials of a Bitbucket Server Application Link with
+// incoming authentication. They are used for 2-legged OAuth, in which requests are signed with the
+// consumer's RSA private key and no token.
+type oauthCredentials struct {
+	consumerKey string
+	signingKey  *rsa.PrivateKey
+}
+
+// parseSigningKey parses a PEM-encoded RSA private key in PKCS #1 or PKCS #8 form.
+func parseSigningKey(pemKey []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemKey)
+	if block == nil {
+		return nil, errors.New("signing key is not PEM-encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse signing key")
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("signing key is not an RSA private key")
+	}
+	return rsaKey, nil
+}
+
+// sign sets the Authorization header of req to an OAuth 1.0a authorization with an RSA-SHA1
+// signature of the request (see https://tools.ietf.org/html/rfc5849#section-3). The request URL
+// must be absolute and the request must not have a form-encoded body, whose parameters would be
+// part of the signature.
+func (o *oauthCredentials) sign(req *http.Request) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	params := map[string]string{
+		"oauth_consumer_key":     o.consumerKey,
+		"oauth_nonce":            hex.EncodeToString(nonce),
+		"oauth_signature_method": "RSA-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(time.Now().Unix(), 10),
+		"oauth_version":          "1.0",
+	}
+
+	hashed := sha1.Sum([]byte(signatureBaseString(req.Method, req.URL, params)))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, o.signingKey, crypto.SHA1, hashed[:])
+	if err != nil {
+		return errors.Wrap(err, "failed to sign request")
+	}
+	params["oauth_signature"] = base64.StdEncoding.EncodeToString(sig)
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString("OAuth ")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(oauthEscape(k) + `="` + oauthEscape(params[k]) + `"`)
+	}
+	req.Header.Set("Authorization", b.String())
+	return nil
+}
+
+// signatureBaseString returns the string that is signed for a request with the given method and
+// URL and the given OAuth protocol parameters (see
+// https://tools.ietf.org/html/rfc5849#section-3.4.1).
+func signatureBaseString(method string, u *url.URL, oauthParams map[string]string) string {
+	var params [][2]string
+	for k, vs := range u.Query() {
+		for _, v := range vs {
+			params = append(params, [2]string{oauthEscape(k), oauthEscape(v)})
+		}
+	}
+	for k, v := range oauthParams {
+		params = append(params, [2]string{oauthEscape(k), oauthEscape(v)})
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i][0] != params[j][0] {
+			return params[i][0] < params[j][0]
+		}
+		return params[i][1] < params[j][1]
+	})
+	pairs := make([]string, len(params))
+	for i, p := range params {
+		pairs[i] = p[0] + "=" + p[1]
+	}
+
+	scheme, host := strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+	if (scheme == "http" && strings.HasSuffix(host, ":80")) || (scheme == "https" && strings.HasSuffix(host, ":443")) {
+		host = host[:strings.LastIndex(host, ":")]
+	}
+	baseURL := scheme + "://" + host + u.EscapedPath()
+
+	return strings.ToUpper(method) + "&" + oauthEscape(baseURL) + "&" + oauthEscape(strings.Join(pairs, "&"))
+}
+
+// oauthEscape percent-encodes s as required by OAuth 1.0a, which only leaves unreserved
+// characters unencoded (see https://tools.ietf.org/html/rfc5849#section-3.6).
+func oauthEscape(s string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&15])
+		}
+	}
+	return b.String()
+}
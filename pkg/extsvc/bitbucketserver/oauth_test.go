@@ -0,0 +1,112 @@
+package bitbucketserver
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestOAuthEscape(t *testing.T) {
+	tests := map[string]string{
+		"abcXYZ019-._~": "abcXYZ019-._~",
+		"a b+c":         "a%20b%2Bc",
+		"a=b&c/d":       "a%3Db%26c%2Fd",
+		"ü":             "%C3%BC",
+	}
+	for in, want := range tests {
+		if got := oauthEscape(in); got != want {
+			t.Errorf("oauthEscape(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSignatureBaseString(t *testing.T) {
+	u, _ := url.Parse("HTTPS://Bitbucket.Example.com:443/rest/api/1.0/users?filter=a%20b&limit=1000&filter=c")
+	got := signatureBaseString("get", u, map[string]string{"oauth_consumer_key": "sourcegraph"})
+	want := "GET&https%3A%2F%2Fbitbucket.example.com%2Frest%2Fapi%2F1.0%2Fusers&filter%3Da%2520b%26filter%3Dc%26limit%3D1000%26oauth_consumer_key%3Dsourcegraph"
+	if got != want {
+		t.Errorf("got base string\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestClient_Sudo(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var gotReq *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReq = r
+		w.Write([]byte(`{"isLastPage": true, "values": [{"name": "alice", "id": 1}]}`))
+	}))
+	defer srv.Close()
+	srvURL, _ := url.Parse(srv.URL)
+
+	c := NewClient(srvURL, nil)
+	if _, err := c.Sudo("alice"); err == nil {
+		t.Fatal("expected error impersonating a user without OAuth credentials")
+	}
+	if err := c.SetOAuth("sourcegraph", []byte("not a key")); err == nil {
+		t.Fatal("expected error for invalid signing key")
+	}
+	if err := c.SetOAuth("sourcegraph", pemKey); err != nil {
+		t.Fatal(err)
+	}
+	sudo, err := c.Sudo("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users, _, err := sudo.Users(context.Background(), &PageToken{Limit: 1000}, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].Name != "alice" {
+		t.Errorf("got users %+v, want alice", users)
+	}
+	if got := gotReq.URL.Query().Get("xoauth_requestor_id"); got != "alice" {
+		t.Errorf("got xoauth_requestor_id %q, want alice", got)
+	}
+
+	// Verify the signature of the request with the public key.
+	auth := gotReq.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "OAuth ") {
+		t.Fatalf("got Authorization header %q, want OAuth", auth)
+	}
+	params := map[string]string{}
+	for _, p := range strings.Split(strings.TrimPrefix(auth, "OAuth "), ", ") {
+		kv := strings.SplitN(p, "=", 2)
+		v, err := url.PathUnescape(strings.Trim(kv[1], `"`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		params[kv[0]] = v
+	}
+	sig, err := base64.StdEncoding.DecodeString(params["oauth_signature"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(params, "oauth_signature")
+	if params["oauth_consumer_key"] != "sourcegraph" || params["oauth_signature_method"] != "RSA-SHA1" {
+		t.Errorf("unexpected OAuth parameters %v", params)
+	}
+	reqURL := *srvURL
+	reqURL.Path = gotReq.URL.Path
+	reqURL.RawQuery = gotReq.URL.RawQuery
+	hashed := sha1.Sum([]byte(signatureBaseString(gotReq.Method, &reqURL, params)))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA1, hashed[:], sig); err != nil {
+		t.Errorf("invalid signature: %s", err)
+	}
+}
@@ -0,0 +1,55 @@
+package bitbucketserver
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Webhook request headers (see
+// https://confluence.atlassian.com/bitbucketserver/managing-webhooks-in-bitbucket-server-938025878.html).
+const (
+	// WebhookEventHeader is the header with the type of the webhook event.
+	WebhookEventHeader = "X-Event-Key"
+	// WebhookSignatureHeader is the header with the signature of the webhook
+	// request's payload (see ValidateWebhookSignature).
+	WebhookSignatureHeader = "X-Hub-Signature"
+)
+
+// Webhook event types (the values of the WebhookEventHeader header).
+const (
+	WebhookEventPing         = "diagnostics:ping"
+	WebhookEventRefsChanged  = "repo:refs_changed"
+	WebhookEventRepoModified = "repo:modified"
+)
+
+// ValidateWebhookSignature reports whether signature (the value of the
+// WebhookSignatureHeader header) is the HMAC-SHA256 signature of the webhook
+// request's payload with the webhook's secret.
+func ValidateWebhookSignature(payload []byte, signature, secret string) bool {
+	const prefix = "sha256="
+	if !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+	got, err := hex.DecodeString(signature[len(prefix):])
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// WebhookEvent is the part of the payload of a webhook event that is used by
+// Sourcegraph.
+type WebhookEvent struct {
+	// Repository is the repository that the event is about, for
+	// repo:refs_changed events.
+	Repository *Repo `json:"repository,omitempty"`
+
+	// Old and New are the repository before and after it was changed, for
+	// repo:modified events (e.g., when it is renamed).
+	Old *Repo `json:"old,omitempty"`
+	New *Repo `json:"new,omitempty"`
+}
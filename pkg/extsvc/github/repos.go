@@ -33,6 +33,32 @@ type Repository struct {
 	IsFork           bool   // whether the repository is a fork of another repository
 	IsArchived       bool   // whether the repository is archived on the code host
 	ViewerPermission string // ADMIN, WRITE, READ, or empty if unknown. Only the graphql api populates this.
+
+	// PrimaryLanguage is the primary language of the repository, if known.
+	PrimaryLanguage struct {
+		Name string // the name of the language ("Go")
+	}
+
+	// Stargazers is the number of users who starred the repository.
+	Stargazers struct {
+		TotalCount int
+	}
+
+	// RepositoryTopics are the topics of the repository (in the form returned
+	// by the GraphQL API).
+	RepositoryTopics RepositoryTopics
+}
+
+// RepositoryTopics is a list of repository topics.
+type RepositoryTopics struct {
+	Nodes []RepositoryTopic
+}
+
+// RepositoryTopic is a repository topic.
+type RepositoryTopic struct {
+	Topic struct {
+		Name string // the name of the topic ("go")
+	}
 }
 
 // repositoryFieldsGraphQLFragment returns a GraphQL fragment that contains the fields needed to populate the
@@ -48,6 +74,19 @@ fragment RepositoryFields on Repository {
 	isPrivate
 	isFork
 	isArchived
+	primaryLanguage {
+		name
+	}
+	stargazers {
+		totalCount
+	}
+	repositoryTopics(first: 100) {
+		nodes {
+			topic {
+				name
+			}
+		}
+	}
 	viewerPermission
 }
 	`
@@ -64,6 +103,19 @@ fragment RepositoryFields on Repository {
 	isPrivate
 	isFork
 	isArchived
+	primaryLanguage {
+		name
+	}
+	stargazers {
+		totalCount
+	}
+	repositoryTopics(first: 100) {
+		nodes {
+			topic {
+				name
+			}
+		}
+	}
 }
 	`
 }
@@ -242,6 +294,9 @@ type restRepository struct {
 	Private     bool
 	Fork        bool
 	Archived    bool
+	Language    string
+	Stargazers  int      `json:"stargazers_count"`
+	Topics      []string // only included with the mercy-preview media type
 }
 
 // getRepositoryFromAPI attempts to fetch a repository from the GitHub API without use of the redis cache.
@@ -260,7 +315,7 @@ func (c *Client) getRepositoryFromAPI(ctx context.Context, owner, name string) (
 // convertRestRepo converts repo information returned by the rest API
 // to a standard format.
 func convertRestRepo(restRepo restRepository) *Repository {
-	return &Repository{
+	repo := &Repository{
 		ID:            restRepo.ID,
 		DatabaseID:    restRepo.DatabaseID,
 		NameWithOwner: restRepo.FullName,
@@ -270,6 +325,14 @@ func convertRestRepo(restRepo restRepository) *Repository {
 		IsFork:        restRepo.Fork,
 		IsArchived:    restRepo.Archived,
 	}
+	repo.PrimaryLanguage.Name = restRepo.Language
+	repo.Stargazers.TotalCount = restRepo.Stargazers
+	for _, name := range restRepo.Topics {
+		var topic RepositoryTopic
+		topic.Topic.Name = name
+		repo.RepositoryTopics.Nodes = append(repo.RepositoryTopics.Nodes, topic)
+	}
+	return repo
 }
 
 // getPublicRepositories returns a page of public repositories that were created
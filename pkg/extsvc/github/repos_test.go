@@ -87,7 +87,8 @@ func TestClient_GetRepository(t *testing.T) {
 	"full_name": "o/r",
 	"description": "d",
 	"html_url": "https://github.example.com/o/r",
-	"fork": true
+	"fork": true,
+	"topics": ["go"]
 }
 `}
 	c := newTestClient(t, &mock)
@@ -99,6 +100,8 @@ func TestClient_GetRepository(t *testing.T) {
 		URL:           "https://github.example.com/o/r",
 		IsFork:        true,
 	}
+	want.RepositoryTopics.Nodes = make([]RepositoryTopic, 1)
+	want.RepositoryTopics.Nodes[0].Topic.Name = "go"
 
 	repo, err := c.GetRepository(context.Background(), "owner", "repo")
 	if err != nil {
@@ -236,7 +239,7 @@ func repoListsAreEqual(a []*Repository, b []*Repository) bool {
 		return false
 	}
 	for i := 0; i < len(a); i++ {
-		if *a[i] != *b[i] {
+		if !reflect.DeepEqual(*a[i], *b[i]) {
 			return false
 		}
 	}
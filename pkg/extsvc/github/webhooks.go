@@ -0,0 +1,60 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+)
+
+// Webhook request headers (see
+// https://developer.github.com/webhooks/#delivery-headers).
+const (
+	// WebhookEventHeader is the header with the type of the webhook event.
+	WebhookEventHeader = "X-GitHub-Event"
+	// WebhookSignatureHeader is the header with the signature of the webhook
+	// request's payload (see ValidateWebhookSignature).
+	WebhookSignatureHeader = "X-Hub-Signature"
+)
+
+// Webhook event types (the values of the WebhookEventHeader header).
+const (
+	WebhookEventPing       = "ping"
+	WebhookEventPush       = "push"
+	WebhookEventRepository = "repository"
+)
+
+// ValidateWebhookSignature reports whether signature (the value of the
+// WebhookSignatureHeader header) is the HMAC-SHA1 signature of the webhook
+// request's payload with the webhook's secret.
+func ValidateWebhookSignature(payload []byte, signature, secret string) bool {
+	const prefix = "sha1="
+	if !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+	got, err := hex.DecodeString(signature[len(prefix):])
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// WebhookEvent is the part of the payload of a webhook event that is used by
+// Sourcegraph. See https://developer.github.com/v3/activity/events/types/.
+type WebhookEvent struct {
+	// Action is the action that was performed on the repository, for
+	// repository events (e.g., "created", "deleted", "renamed", or
+	// "archived").
+	Action string `json:"action,omitempty"`
+
+	// Repository is the repository that the event is about.
+	Repository *WebhookRepository `json:"repository,omitempty"`
+}
+
+// WebhookRepository is a repository in the payload of a webhook event.
+type WebhookRepository struct {
+	NodeID   string `json:"node_id"`   // the GraphQL ID of the repository (same as (Repository).ID)
+	FullName string `json:"full_name"` // the full name of the repository (e.g., "gorilla/mux")
+}
@@ -0,0 +1,43 @@
+package gitlab
+
+import "crypto/subtle"
+
+// Webhook request headers (see
+// https://docs.gitlab.com/ee/user/project/integrations/webhooks.html).
+const (
+	// WebhookEventHeader is the header with the type of the webhook event
+	// (e.g., "Push Hook" or "System Hook").
+	WebhookEventHeader = "X-Gitlab-Event"
+	// WebhookTokenHeader is the header with the webhook's secret token (see
+	// ValidateWebhookToken).
+	WebhookTokenHeader = "X-Gitlab-Token"
+)
+
+// Names of webhook events (the values of (WebhookEvent).EventName). Project
+// webhooks send push events, and system hooks additionally send events about
+// projects being created, renamed, and deleted.
+const (
+	WebhookEventPush            = "push"
+	WebhookEventTagPush         = "tag_push"
+	WebhookEventProjectCreate   = "project_create"
+	WebhookEventProjectDestroy  = "project_destroy"
+	WebhookEventProjectRename   = "project_rename"
+	WebhookEventProjectTransfer = "project_transfer"
+)
+
+// ValidateWebhookToken reports whether token (the value of the
+// WebhookTokenHeader header) is the webhook's secret token.
+func ValidateWebhookToken(token, secret string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+// WebhookEvent is the part of the payload of a webhook event that is used by
+// Sourcegraph. Both project webhooks and system hooks set these fields.
+type WebhookEvent struct {
+	// EventName is the name of the event (e.g., "push" or "project_rename").
+	EventName string `json:"event_name"`
+
+	// ProjectID is the ID of the project that the event is about (same as
+	// (Project).ID).
+	ProjectID int `json:"project_id"`
+}
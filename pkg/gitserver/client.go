@@ -3,8 +3,6 @@ package gitserver
 import (
 	"bytes"
 	"context"
-	"crypto/md5"
-	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -54,6 +52,8 @@ func NewClient(cli httpcli.Doer) *Client {
 			updateGitServerAddrList()
 			return gitserverAddrList.Load().([]string)
 		},
+		Placement:   Placement(conf.SrcGitServerPlacement),
+		Replicas:    conf.SrcGitServerReplicas,
 		HTTPClient:  cli,
 		HTTPLimiter: parallel.NewRun(500),
 		// Use the binary name for UserAgent. This should effectively identify
@@ -127,6 +127,16 @@ type Client struct {
 	// concurrent use. It may return different results at different times.
 	Addrs func(ctx context.Context) []string
 
+	// Placement is how repositories are placed on gitservers (see
+	// AddrsForRepo).
+	Placement Placement
+
+	// Replicas is the number of gitservers that each repository is stored on
+	// (see AddrsForRepo). Requests for a repository are sent to its primary
+	// gitserver, and to its replicas if the primary can't be reached. Values
+	// less than 1 are treated as 1.
+	Replicas int
+
 	// UserAgent is a string identifing who the client is. It will be logged in
 	// the telemetry in gitserver.
 	UserAgent string
@@ -134,8 +144,17 @@ type Client struct {
 
 // addrForRepo returns the gitserver address to use for the given repo name.
 func (c *Client) addrForRepo(ctx context.Context, repo api.RepoName) string {
-	repo = protocol.NormalizeRepo(repo) // in case the caller didn't already normalize it
-	return c.addrForKey(ctx, string(repo))
+	return c.addrsForRepo(ctx, repo)[0]
+}
+
+// addrsForRepo returns the addresses of the primary gitserver and the
+// replicas of the given repo name (see AddrsForRepo).
+func (c *Client) addrsForRepo(ctx context.Context, repo api.RepoName) []string {
+	addrs := c.Addrs(ctx)
+	if len(addrs) == 0 {
+		panic("unexpected state: no gitserver addresses")
+	}
+	return AddrsForRepo(repo, addrs, c.Placement, c.Replicas)
 }
 
 // addrForKey returns the gitserver address to use for the given string key,
@@ -145,9 +164,7 @@ func (c *Client) addrForKey(ctx context.Context, key string) string {
 	if len(addrs) == 0 {
 		panic("unexpected state: no gitserver addresses")
 	}
-	sum := md5.Sum([]byte(key))
-	serverIndex := binary.BigEndian.Uint64(sum[:]) % uint64(len(addrs))
-	return addrs[serverIndex]
+	return addrsForKey(key, addrs, c.Placement, 1)[0]
 }
 
 func (c *Cmd) sendExec(ctx context.Context) (_ io.ReadCloser, _ http.Header, errRes error) {
@@ -177,6 +194,7 @@ func (c *Cmd) sendExec(ctx context.Context) (_ io.ReadCloser, _ http.Header, err
 		URL:            c.Repo.URL,
 		EnsureRevision: c.EnsureRevision,
 		Args:           c.Args[1:],
+		CloneOptions:   c.Repo.CloneOptions,
 	}
 	resp, err := c.client.httpPost(ctx, repoName, "exec", req)
 	if err != nil {
@@ -232,6 +250,10 @@ type Repo struct {
 	// this field is optional (it will use the last-used Git remote URL). If the repository is not
 	// cloned on the gitserver, the request will fail.
 	URL string
+
+	// CloneOptions are the options to clone and fetch the repository with. If
+	// nil, the options that the repository was cloned with are used.
+	CloneOptions *protocol.CloneOptions
 }
 
 // Command creates a new Cmd. Command name must be 'git',
@@ -409,6 +431,7 @@ func (c *Client) ListCloned(ctx context.Context) ([]string, error) {
 		wg    sync.WaitGroup
 		mu    sync.Mutex
 		err   error
+		seen  = make(map[string]bool)
 		repos []string
 	)
 	for _, addr := range c.Addrs(ctx) {
@@ -420,7 +443,14 @@ func (c *Client) ListCloned(ctx context.Context) ([]string, error) {
 			if e != nil {
 				err = e
 			}
-			repos = append(repos, r...)
+			for _, repo := range r {
+				// A repository is listed by each gitserver that stores it
+				// (e.g. its replicas).
+				if !seen[repo] {
+					seen[repo] = true
+					repos = append(repos, repo)
+				}
+			}
 			mu.Unlock()
 		}(addr)
 	}
@@ -476,10 +506,31 @@ func (c *Client) doListOne(ctx context.Context, urlSuffix, addr string) ([]strin
 // update won't happen.
 func (c *Client) RequestRepoUpdate(ctx context.Context, repo Repo, since time.Duration) (*protocol.RepoUpdateResponse, error) {
 	req := &protocol.RepoUpdateRequest{
-		Repo:  repo.Name,
-		URL:   repo.URL,
-		Since: since,
+		Repo:         repo.Name,
+		URL:          repo.URL,
+		Since:        since,
+		CloneOptions: repo.CloneOptions,
+	}
+	addrs := c.addrsForRepo(ctx, repo.Name)
+	if len(addrs) > 1 {
+		// Keep the replicas up to date too. Only the primary's response is
+		// returned, so errors from replicas are just logged.
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		for _, addr := range addrs[1:] {
+			wg.Add(1)
+			go func(addr string) {
+				defer wg.Done()
+				resp, err := c.httpPostAddr(ctx, addr, "repo-update", req)
+				if err != nil {
+					log15.Warn("failed to update gitserver replica", "repo", repo.Name, "addr", addr, "error", err)
+					return
+				}
+				resp.Body.Close()
+			}(addr)
+		}
 	}
+
 	resp, err := c.httpPost(ctx, repo.Name, "repo-update", req)
 	if err != nil {
 		return nil, err
@@ -659,12 +710,22 @@ func (c *Client) RepoInfo(ctx context.Context, repos ...api.RepoName) (*protocol
 	return &res, err.ErrorOrNil()
 }
 
-// Remove removes the repository clone from gitserver.
+// Remove removes the repository clone from gitserver (and from the
+// gitservers that store replicas of it).
 func (c *Client) Remove(ctx context.Context, repo api.RepoName) error {
 	req := &protocol.RepoDeleteRequest{
 		Repo: repo,
 	}
-	resp, err := c.httpPost(ctx, repo, "delete", req)
+	for _, addr := range c.addrsForRepo(ctx, repo) {
+		if err := c.removeFrom(ctx, addr, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) removeFrom(ctx context.Context, addr string, req *protocol.RepoDeleteRequest) error {
+	resp, err := c.httpPostAddr(ctx, addr, "delete", req)
 	if err != nil {
 		return err
 	}
@@ -677,9 +738,50 @@ func (c *Client) Remove(ctx context.Context, repo api.RepoName) error {
 	return nil
 }
 
+// TransferRepo copies the repository clone from the gitserver at addr to the
+// gitservers that the repository is placed on, instead of cloning it from its
+// code host. The copy on the gitserver at addr is not removed.
+func (c *Client) TransferRepo(ctx context.Context, repo api.RepoName, from string) error {
+	req := &protocol.RepoTransferRequest{
+		Repo: repo,
+		From: from,
+	}
+	for _, addr := range c.addrsForRepo(ctx, repo) {
+		if addr == from {
+			continue
+		}
+		resp, err := c.httpPostAddr(ctx, addr, "repo-transfer", req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			// best-effort inclusion of body in error message
+			body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 200))
+			resp.Body.Close()
+			return &url.Error{URL: resp.Request.URL.String(), Op: "TransferRepo", Err: fmt.Errorf("TransferRepo: http status %d: %s", resp.StatusCode, string(body))}
+		}
+		resp.Body.Close()
+	}
+	return nil
+}
+
 // httpPost performs a POST request to a gitserver, sharding based on the given
-// repo name (the repo name is otherwise not used).
+// repo name (the repo name is otherwise not used). If the repo's primary
+// gitserver can't be reached, the request is sent to its replicas in turn.
 func (c *Client) httpPost(ctx context.Context, repo api.RepoName, method string, payload interface{}) (resp *http.Response, err error) {
+	addrs := c.addrsForRepo(ctx, repo)
+	for i, addr := range addrs {
+		resp, err = c.httpPostAddr(ctx, addr, method, payload)
+		if err == nil || ctx.Err() != nil || i == len(addrs)-1 {
+			break
+		}
+		log15.Warn("gitserver request failed, trying replica", "repo", repo, "addr", addr, "replica", addrs[i+1], "error", err)
+	}
+	return resp, err
+}
+
+// httpPostAddr performs a POST request to the gitserver at addr.
+func (c *Client) httpPostAddr(ctx context.Context, addr, method string, payload interface{}) (resp *http.Response, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Client.httpPost")
 	defer func() {
 		if err != nil {
@@ -694,7 +796,6 @@ func (c *Client) httpPost(ctx context.Context, repo api.RepoName, method string,
 		return nil, err
 	}
 
-	addr := c.addrForRepo(ctx, repo)
 	req, err := http.NewRequest("POST", "http://"+addr+"/"+method, bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, err
@@ -0,0 +1,48 @@
+package gitserver
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"path"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/httpcli"
+)
+
+// ProxyGitHTTP proxies a read-only git smart HTTP request for the repository
+// to the gitserver that the repository is placed on. The suffix is the part
+// of the URL path after the repository name ("/info/refs" or
+// "/git-upload-pack"). The query string and body of the request are proxied
+// as is.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to access
+// the repository.
+func (c *Client) ProxyGitHTTP(w http.ResponseWriter, r *http.Request, repo api.RepoName, suffix string) {
+	addr := c.addrForRepo(r.Context(), repo)
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = "http"
+			req.URL.Host = addr
+			req.URL.Path = path.Join("/git", string(repo)) + suffix
+			req.URL.RawPath = ""
+			req.Header.Set("User-Agent", c.UserAgent)
+
+			// 🚨 SECURITY: Don't send the user's credentials to gitserver.
+			req.Header.Del("Authorization")
+			req.Header.Del("Cookie")
+		},
+		Transport: doerTransport{c.HTTPClient},
+		// Flush periodically so that the client receives progress messages
+		// and the packfile as they are written.
+		FlushInterval: 100 * time.Millisecond,
+	}
+	proxy.ServeHTTP(w, r)
+}
+
+// doerTransport adapts an httpcli.Doer to an http.RoundTripper.
+type doerTransport struct{ httpcli.Doer }
+
+func (t doerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.Do(req)
+}
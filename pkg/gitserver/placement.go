@@ -0,0 +1,87 @@
+package gitserver
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"sort"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
+)
+
+// Placement is a strategy for placing repositories on gitservers. It must be
+// the same for all services.
+type Placement string
+
+const (
+	// PlacementModulo places each repo on the gitserver whose index in the
+	// list of gitserver addresses is the hash of the repo name modulo the
+	// number of gitservers. Replicas are placed on the gitservers that follow
+	// it in the list. Adding or removing a gitserver moves most repos. This
+	// is the default (and the zero value), because it is how repos were
+	// always placed.
+	PlacementModulo Placement = "modulo"
+
+	// PlacementRendezvous places repos with rendezvous hashing: every address
+	// is given a score based on the hash of the repo name and the address,
+	// and the addresses with the highest scores are chosen. Adding (or
+	// removing) a gitserver only moves the repos that are placed on (or were
+	// placed on) that gitserver.
+	PlacementRendezvous Placement = "rendezvous"
+)
+
+// AddrsForRepo returns the addresses of the gitservers that store repo, in
+// order of preference: the first is the repo's primary gitserver, and the
+// others (if replicas > 1) hold replicas of it. At most replicas (but at least
+// one) addresses are returned.
+func AddrsForRepo(repo api.RepoName, addrs []string, placement Placement, replicas int) []string {
+	repo = protocol.NormalizeRepo(repo) // in case the caller didn't already normalize it
+	return addrsForKey(string(repo), addrs, placement, replicas)
+}
+
+// addrsForKey is like AddrsForRepo, but for any string key.
+func addrsForKey(key string, addrs []string, placement Placement, replicas int) []string {
+	if replicas < 1 {
+		replicas = 1
+	}
+	if replicas > len(addrs) {
+		replicas = len(addrs)
+	}
+	chosen := make([]string, replicas)
+	if placement != PlacementRendezvous {
+		sum := md5.Sum([]byte(key))
+		serverIndex := binary.BigEndian.Uint64(sum[:]) % uint64(len(addrs))
+		for i := range chosen {
+			chosen[i] = addrs[(serverIndex+uint64(i))%uint64(len(addrs))]
+		}
+		return chosen
+	}
+
+	scored := make([]scoredAddr, len(addrs))
+	for i, addr := range addrs {
+		scored[i] = scoredAddr{addr: addr, score: rendezvousScore(key, addr)}
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].addr < scored[j].addr
+	})
+	for i := range chosen {
+		chosen[i] = scored[i].addr
+	}
+	return chosen
+}
+
+type scoredAddr struct {
+	addr  string
+	score uint64
+}
+
+func rendezvousScore(key, addr string) uint64 {
+	h := md5.New()
+	h.Write([]byte(key))
+	h.Write([]byte{0}) // so that e.g. ("a", "bc") and ("ab", "c") differ
+	h.Write([]byte(addr))
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
@@ -0,0 +1,88 @@
+package gitserver
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+)
+
+func TestAddrsForRepo(t *testing.T) {
+	addrs := []string{"gitserver-0:3178", "gitserver-1:3178", "gitserver-2:3178"}
+
+	for _, placement := range []Placement{PlacementModulo, PlacementRendezvous} {
+		t.Run(string(placement)+" normalized", func(t *testing.T) {
+			got := AddrsForRepo("GitHub.com/foo/bar.git", addrs, placement, 2)
+			want := AddrsForRepo("github.com/foo/bar", addrs, placement, 2)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+
+		t.Run(string(placement)+" replicas", func(t *testing.T) {
+			for replicas, wantLen := range map[int]int{-1: 1, 0: 1, 1: 1, 2: 2, 3: 3, 10: 3} {
+				got := AddrsForRepo("github.com/foo/bar", addrs, placement, replicas)
+				if len(got) != wantLen {
+					t.Errorf("replicas=%d: got %d addrs, want %d", replicas, len(got), wantLen)
+				}
+				seen := map[string]bool{}
+				for _, addr := range got {
+					if seen[addr] {
+						t.Errorf("replicas=%d: duplicate addr %s in %v", replicas, addr, got)
+					}
+					seen[addr] = true
+				}
+				// The primary does not depend on the number of replicas.
+				if primary := AddrsForRepo("github.com/foo/bar", addrs, placement, 1)[0]; got[0] != primary {
+					t.Errorf("replicas=%d: got primary %s, want %s", replicas, got[0], primary)
+				}
+			}
+		})
+	}
+
+	t.Run("modulo is the legacy placement", func(t *testing.T) {
+		for _, placement := range []Placement{"", PlacementModulo} {
+			for i := 0; i < 100; i++ {
+				repo := api.RepoName(fmt.Sprintf("github.com/foo/repo-%d", i))
+				sum := md5.Sum([]byte(repo))
+				want := addrs[binary.BigEndian.Uint64(sum[:])%uint64(len(addrs))]
+				if got := AddrsForRepo(repo, addrs, placement, 1)[0]; got != want {
+					t.Errorf("placement=%q %s: got %s, want %s", placement, repo, got, want)
+				}
+			}
+		}
+	})
+
+	t.Run("rendezvous order of addrs", func(t *testing.T) {
+		reversed := []string{addrs[2], addrs[1], addrs[0]}
+		for i := 0; i < 100; i++ {
+			repo := api.RepoName(fmt.Sprintf("github.com/foo/repo-%d", i))
+			if got, want := AddrsForRepo(repo, reversed, PlacementRendezvous, 2), AddrsForRepo(repo, addrs, PlacementRendezvous, 2); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: got %v, want %v", repo, got, want)
+			}
+		}
+	})
+
+	t.Run("rendezvous add gitserver", func(t *testing.T) {
+		added := append(append([]string{}, addrs...), "gitserver-3:3178")
+		moved := 0
+		for i := 0; i < 1000; i++ {
+			repo := api.RepoName(fmt.Sprintf("github.com/foo/repo-%d", i))
+			before, after := AddrsForRepo(repo, addrs, PlacementRendezvous, 1)[0], AddrsForRepo(repo, added, PlacementRendezvous, 1)[0]
+			if before == after {
+				continue
+			}
+			if after != "gitserver-3:3178" {
+				t.Errorf("%s: moved from %s to %s, want it to only move to the new gitserver", repo, before, after)
+			}
+			moved++
+		}
+		// Roughly a quarter of the repos should move to the new gitserver.
+		if moved < 150 || moved > 350 {
+			t.Errorf("got %d of 1000 repos moved, want about 250", moved)
+		}
+	})
+}
@@ -18,6 +18,11 @@ type ExecRequest struct {
 	EnsureRevision string      `json:"ensureRevision"`
 	Args           []string    `json:"args"`
 	Opt            *RemoteOpts `json:"opt"`
+
+	// CloneOptions are the options to clone the repository with if it is not
+	// cloned yet, and to fetch it with if EnsureRevision is not found. If nil,
+	// the options that the repository was cloned with are used.
+	CloneOptions *CloneOptions `json:"cloneOptions,omitempty"`
 }
 
 // RemoteOpts configures interactions with a remote repository.
@@ -44,6 +49,46 @@ type RepoUpdateRequest struct {
 	Repo  api.RepoName  `json:"repo"`  // identifying URL for repo
 	URL   string        `json:"url"`   // repo's remote URL
 	Since time.Duration `json:"since"` // debounce interval for queries, used only with request-repo-update
+
+	// CloneOptions are the options to clone and fetch the repo with. If nil,
+	// the options that the repo was cloned with are used.
+	CloneOptions *CloneOptions `json:"cloneOptions,omitempty"`
+}
+
+// CloneOptions restrict the objects and refs of a repository that gitserver
+// clones and fetches, to reduce the time and disk space needed for large
+// repositories, and limit the disk space it may use. The zero value clones all
+// objects and refs without a size limit.
+type CloneOptions struct {
+	// Filter is the object filter of a partial clone (such as
+	// "blob:limit=1m"). Objects that are omitted by the filter are fetched
+	// from the remote on demand.
+	Filter string `json:"filter,omitempty"`
+
+	// Refs are the refs to fetch, which are ref names, patterns with a
+	// trailing "*", or "HEAD" for the remote's default branch. If empty, all
+	// branches and tags are fetched.
+	Refs []string `json:"refs,omitempty"`
+
+	// Depth, if positive, truncates the history of the fetched refs to this
+	// number of commits.
+	Depth int `json:"depth,omitempty"`
+
+	// MaxSize, if positive, is the maximum size in bytes of the repository's
+	// clone on disk. Gitserver refuses to clone a repository that is larger,
+	// and removes a clone that grows larger.
+	MaxSize int64 `json:"maxSize,omitempty"`
+}
+
+// IsZero reports whether o is nil or the zero value.
+func (o *CloneOptions) IsZero() bool {
+	return o == nil || (!o.RestrictsFetch() && o.MaxSize == 0)
+}
+
+// RestrictsFetch reports whether o restricts the objects or refs that are
+// cloned and fetched. If not, the repository is cloned as a full mirror.
+func (o *CloneOptions) RestrictsFetch() bool {
+	return o != nil && (o.Filter != "" || len(o.Refs) > 0 || o.Depth > 0)
 }
 
 // RepoUpdateResponse returns meta information of the repo enqueued for
@@ -115,6 +160,19 @@ type DeprecatedRepoInfoResponse struct {
 	// recloned automatically, so this time is likely to move forward
 	// periodically.
 	CloneTime *time.Time
+
+	// Size is the size in bytes of the repository's clone on disk, as of the
+	// last time gitserver measured it. It is 0 if the size is not known.
+	Size int64 `json:",omitempty"`
+
+	// LastAccessed is the time that the repository was last read by a git
+	// command (such as a search or a clone by a user), with a granularity of
+	// about a minute.
+	LastAccessed *time.Time `json:",omitempty"`
+
+	// SizeLimitError, if set, is the reason why the repository is not cloned:
+	// it exceeded its size limit (see CloneOptions.MaxSize).
+	SizeLimitError string `json:",omitempty"`
 }
 
 // RepoInfoRequest is a request for information about multiple repositories on gitserver.
@@ -129,6 +187,28 @@ type RepoDeleteRequest struct {
 	Repo api.RepoName
 }
 
+// RepoTransferRequest is a request to copy a repository clone from another
+// gitserver, instead of cloning it from its code host.
+type RepoTransferRequest struct {
+	// Repo is the repository to transfer.
+	Repo api.RepoName
+	// From is the address of the gitserver to copy the repository from.
+	From string
+}
+
+// DrainResponse is the response to a request to move all repository clones
+// off of a gitserver.
+type DrainResponse struct {
+	// Transferred is the repositories that were moved to other gitservers.
+	Transferred []api.RepoName
+	// Skipped is the repositories that were not moved because they were busy
+	// (e.g. being cloned). They are still stored on the drained gitserver.
+	Skipped []api.RepoName
+	// Failed maps from the repositories that could not be moved to the
+	// reason why. They are still stored on the drained gitserver.
+	Failed map[api.RepoName]string
+}
+
 // RepoInfo is the information requests about a single repository
 // via a RepoInfoRequest.
 type RepoInfo struct {
@@ -143,6 +223,19 @@ type RepoInfo struct {
 	// recloned automatically, so this time is likely to move forward
 	// periodically.
 	CloneTime *time.Time
+
+	// Size is the size in bytes of the repository's clone on disk, as of the
+	// last time gitserver measured it. It is 0 if the size is not known.
+	Size int64 `json:",omitempty"`
+
+	// LastAccessed is the time that the repository was last read by a git
+	// command (such as a search or a clone by a user), with a granularity of
+	// about a minute.
+	LastAccessed *time.Time `json:",omitempty"`
+
+	// SizeLimitError, if set, is the reason why the repository is not cloned:
+	// it exceeded its size limit (see CloneOptions.MaxSize).
+	SizeLimitError string `json:",omitempty"`
 }
 
 // RepoInfoResponse is the response to a repository information request
@@ -165,6 +258,16 @@ type CreateCommitFromPatchRequest struct {
 	TargetRef string
 	// CommitInfo is the information that will be used when creating the commit from a patch
 	CommitInfo PatchCommitInfo
+	// Push, if non-nil, is the remote that the commit is pushed to (at TargetRef)
+	// before TargetRef is created locally. The push fails if TargetRef already
+	// exists on the remote.
+	Push *PushConfig
+}
+
+// PushConfig describes the remote that a commit created from a patch is pushed to.
+type PushConfig struct {
+	// RemoteURL is the git remote URL to push to, which may contain credentials.
+	RemoteURL string
 }
 
 // PatchCommitInfo will be used for commit information when creating a commit from a patch
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 
 	"github.com/opentracing-contrib/go-stdlib/nethttp"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -14,6 +15,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/pkg/api"
 	"github.com/sourcegraph/sourcegraph/pkg/env"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/pkg/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
 	"github.com/sourcegraph/sourcegraph/pkg/repoupdater/protocol"
 )
@@ -257,6 +261,51 @@ func (c *Client) ExcludeRepo(ctx context.Context, id uint32) (*protocol.ExcludeR
 	return &res, nil
 }
 
+// webhookHeaders are the headers of webhook requests that are forwarded to
+// repo-updater by Webhook. Other headers (e.g. cookies) are not.
+var webhookHeaders = []string{
+	github.WebhookEventHeader,
+	github.WebhookSignatureHeader,
+	gitlab.WebhookEventHeader,
+	gitlab.WebhookTokenHeader,
+	bitbucketserver.WebhookEventHeader,
+	bitbucketserver.WebhookSignatureHeader,
+}
+
+// Webhook forwards a webhook request that a code host sent to Sourcegraph to
+// repo-updater, which validates it and updates the affected repositories. The
+// kind is the lowercase kind of the external service (e.g. "github"). It
+// returns the HTTP status code and body of repo-updater's response, so that
+// they can be relayed to the code host.
+func (c *Client) Webhook(ctx context.Context, kind string, header http.Header, payload []byte) (statusCode int, body []byte, err error) {
+	req, err := http.NewRequest("POST", c.URL+"/webhooks/"+url.PathEscape(kind), bytes.NewReader(payload))
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, h := range webhookHeaders {
+		if v := header.Get(h); v != "" {
+			req.Header.Set(h, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "failed to read response body")
+	}
+	return resp.StatusCode, body, nil
+}
+
 func (c *Client) httpPost(ctx context.Context, method string, payload interface{}) (resp *http.Response, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Client.httpPost")
 	defer func() {
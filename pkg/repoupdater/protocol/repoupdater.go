@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/sourcegraph/sourcegraph/pkg/api"
+	gitserverprotocol "github.com/sourcegraph/sourcegraph/pkg/gitserver/protocol"
 )
 
 type RepoUpdateSchedulerInfoArgs struct {
@@ -134,6 +135,10 @@ func (r *RepoInfo) String() string {
 // VCSInfo describes how to access an external repository's Git data (to clone or update it).
 type VCSInfo struct {
 	URL string // the Git remote URL
+
+	// CloneOptions are the options to clone and fetch the repository with
+	// (configured in the external service).
+	CloneOptions *gitserverprotocol.CloneOptions `json:",omitempty"`
 }
 
 // RepoLinks contains URLs and URL patterns for objects in this repository.
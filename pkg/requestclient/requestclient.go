@@ -0,0 +1,49 @@
+// Package requestclient provides the network address of the client that made an HTTP request, for
+// use in code that does not have access to the HTTP request (such as the audit log).
+package requestclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// Client describes the client that made an HTTP request.
+type Client struct {
+	// IP is the IP address of the direct peer of the HTTP server (which may be a proxy).
+	IP string
+
+	// ForwardedFor is the value of the X-Forwarded-For HTTP request header. It is set by proxies,
+	// but it may also be spoofed by clients, so it must not be relied upon for security decisions.
+	ForwardedFor string
+}
+
+type key int
+
+const clientKey key = iota
+
+// FromContext returns the client of the HTTP request that the context is associated with, or nil
+// if there is none.
+func FromContext(ctx context.Context) *Client {
+	c, _ := ctx.Value(clientKey).(*Client)
+	return c
+}
+
+// WithClient returns a copy of the context that is associated with the client.
+func WithClient(ctx context.Context, client *Client) context.Context {
+	return context.WithValue(ctx, clientKey, client)
+}
+
+// HTTPMiddleware associates the context of each HTTP request with the client that made it.
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		next.ServeHTTP(w, r.WithContext(WithClient(r.Context(), &Client{
+			IP:           ip,
+			ForwardedFor: r.Header.Get("X-Forwarded-For"),
+		})))
+	})
+}
@@ -0,0 +1,25 @@
+package requestclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	var got *Client
+	h := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.1, 198.51.100.1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := &Client{IP: "192.0.2.1", ForwardedFor: "203.0.113.1, 198.51.100.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -35,6 +35,30 @@ type SearchArgs struct {
 	// need to match to get included in the result
 	ExcludePattern string
 
+	// Kinds is a list of symbol kinds (e.g., "function" or "struct"). If
+	// nonempty, only symbols of one of these kinds are returned. Kinds are
+	// matched case insensitively.
+	Kinds []string
+
+	// ExcludeKinds is a list of symbol kinds. Symbols of these kinds are
+	// not returned.
+	ExcludeKinds []string
+
+	// Languages is a list of languages (e.g., "Go"). If nonempty, only
+	// symbols in one of these languages are returned. Languages are matched
+	// case insensitively.
+	Languages []string
+
+	// IncludeContainerPatterns is a list of regexes that the name of the
+	// symbol's container (its Parent, such as the struct or class that
+	// declares it) needs to match to get included in the result. The patterns
+	// are ANDed together, like IncludePatterns.
+	IncludeContainerPatterns []string
+
+	// ExcludeContainerPattern is an optional regex that the name of the
+	// symbol's container must not match to get included in the result.
+	ExcludeContainerPattern string
+
 	// First indicates that only the first n symbols should be returned.
 	First int
 }
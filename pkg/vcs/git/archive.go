@@ -17,7 +17,7 @@ import (
 type ArchiveOptions struct {
 	Treeish string   // the tree or commit to produce an archive for
 	Format  string   // format of the resulting archive (usually "tar" or "zip")
-	Paths   []string // if nonempty, only include these paths
+	Paths   []string // if nonempty, only include these paths (matched literally, not as pathspecs)
 }
 
 // archiveReader wraps the StdoutReader yielded by gitserver's
@@ -63,6 +63,9 @@ func Archive(ctx context.Context, repo gitserver.Repo, opt ArchiveOptions) (_ io
 	}
 
 	cmd := gitserver.DefaultClient.Command("git",
+		// Paths are file paths, not pathspec patterns (e.g., a path that
+		// contains "*" only matches the file with that name).
+		"--literal-pathspecs",
 		"archive",
 
 		// Suppresses fatal error when the repo contains paths matching **/.git/** and instead
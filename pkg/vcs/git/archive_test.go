@@ -92,6 +92,39 @@ func TestRepository_Archive(t *testing.T) {
 	}
 }
 
+func TestRepository_Archive_literalPaths(t *testing.T) {
+	t.Parallel()
+
+	repo := makeGitRepository(t,
+		"echo -n a > 'a*'",
+		"echo -n b > ab",
+		"git add 'a*' ab",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m commit1 --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+	)
+
+	// "a*" is not a wildcard, so ab is not included.
+	rc, err := git.Archive(ctx, repo, git.ArchiveOptions{Treeish: "HEAD", Format: "zip", Paths: []string{"a*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range zr.File {
+		got = append(got, f.Name)
+	}
+	if want := []string{"a*"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got files %q, want %q", got, want)
+	}
+}
+
 func createRepoWithDotGitDir(dir string) error {
 	b64 := func(s string) string {
 		b, err := base64.StdEncoding.DecodeString(s)
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/pkg/api"
+	"github.com/sourcegraph/sourcegraph/pkg/gitserver"
+)
+
+// DiffPaths returns the paths of the files that differ between the specified
+// commits. Paths of files that exist in b (because they were added or
+// modified) are returned in changed, and paths of files that only exist in a
+// are returned in deleted. Renames are reported as a deletion of the old path
+// and an addition of the new path.
+func DiffPaths(ctx context.Context, repo gitserver.Repo, a, b api.CommitID) (changed, deleted []string, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Git: DiffPaths")
+	span.SetTag("A", a)
+	span.SetTag("B", b)
+	defer span.Finish()
+
+	if err := checkSpecArgSafety(string(a)); err != nil {
+		return nil, nil, err
+	}
+	if err := checkSpecArgSafety(string(b)); err != nil {
+		return nil, nil, err
+	}
+
+	cmd := gitserver.DefaultClient.Command("git", "--literal-pathspecs", "diff", "--name-status", "--no-renames", "-z", string(a), string(b), "--")
+	cmd.Repo = repo
+	out, err := cmd.CombinedOutput(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, fmt.Sprintf("git command %v failed (output: %q)", cmd.Args, out))
+	}
+	return parseDiffNameStatus(out)
+}
+
+// parseDiffNameStatus parses the output of `git diff --name-status
+// --no-renames -z`, which is a NUL-separated list of status and path pairs.
+func parseDiffNameStatus(out []byte) (changed, deleted []string, err error) {
+	fields := bytes.Split(bytes.TrimSuffix(out, []byte{0}), []byte{0})
+	if len(fields) == 1 && len(fields[0]) == 0 {
+		return nil, nil, nil
+	}
+	if len(fields)%2 != 0 {
+		return nil, nil, errors.Errorf("invalid git diff --name-status output: %q", out)
+	}
+	for i := 0; i < len(fields); i += 2 {
+		status, path := fields[i], string(fields[i+1])
+		if len(status) == 0 {
+			return nil, nil, errors.Errorf("invalid git diff --name-status output: %q", out)
+		}
+		if status[0] == 'D' {
+			deleted = append(deleted, path)
+		} else {
+			changed = append(changed, path)
+		}
+	}
+	return changed, deleted, nil
+}
@@ -0,0 +1,56 @@
+package git_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/pkg/vcs/git"
+)
+
+func TestDiffPaths(t *testing.T) {
+	t.Parallel()
+
+	repo := makeGitRepository(t,
+		"echo line1 > f",
+		"echo line1 > g",
+		"echo line1 > h",
+		"git add f g h",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m foo --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+		"git tag base",
+		"echo line2 >> f",
+		"git rm g",
+		"mkdir dir",
+		"git mv h 'dir/h 2'",
+		"echo line1 > i",
+		"git add f i",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m bar --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+	)
+
+	a, err := git.ResolveRevision(ctx, repo, nil, "base", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := git.ResolveRevision(ctx, repo, nil, "master", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, deleted, err := git.DiffPaths(ctx, repo, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"dir/h 2", "f", "i"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("got changed %q, want %q", changed, want)
+	}
+	if want := []string{"g", "h"}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("got deleted %q, want %q", deleted, want)
+	}
+
+	changed, deleted, err = git.DiffPaths(ctx, repo, b, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 0 || len(deleted) != 0 {
+		t.Errorf("got changed %q and deleted %q for identical commits, want none", changed, deleted)
+	}
+}
@@ -247,6 +247,97 @@ func ListTags(ctx context.Context, repo gitserver.Repo) ([]*Tag, error) {
 	return tags, nil
 }
 
+// A Ref is a Git ref and the commit that it points to.
+type Ref struct {
+	Name     string       // the full name of the ref (e.g., "refs/heads/master")
+	CommitID api.CommitID // the commit that the ref points to (tags are dereferenced)
+}
+
+// ListRefs returns the refs that match any of the globs in include and none of
+// the globs in exclude, sorted by name. The globs have the same meaning as the
+// --glob and --exclude options of git-log: "refs/" is prepended to a glob that
+// doesn't start with it, and "/*" is appended to a glob that has no glob
+// characters.
+func ListRefs(ctx context.Context, repo gitserver.Repo, include, exclude []string) ([]Ref, error) {
+	if Mocks.ListRefs != nil {
+		return Mocks.ListRefs(include, exclude)
+	}
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Git: ListRefs")
+	span.SetTag("include", include)
+	span.SetTag("exclude", exclude)
+	defer span.Finish()
+
+	if len(include) == 0 {
+		return nil, nil
+	}
+	refs, err := forEachRef(ctx, repo, include)
+	if err != nil {
+		return nil, err
+	}
+	if len(exclude) > 0 && len(refs) > 0 {
+		excluded, err := forEachRef(ctx, repo, exclude)
+		if err != nil {
+			return nil, err
+		}
+		excludedNames := make(map[string]struct{}, len(excluded))
+		for _, ref := range excluded {
+			excludedNames[ref.Name] = struct{}{}
+		}
+		filtered := refs[:0]
+		for _, ref := range refs {
+			if _, ok := excludedNames[ref.Name]; !ok {
+				filtered = append(filtered, ref)
+			}
+		}
+		refs = filtered
+	}
+	return refs, nil
+}
+
+// forEachRef returns the refs that match any of the globs (see ListRefs),
+// sorted by name.
+func forEachRef(ctx context.Context, repo gitserver.Repo, globs []string) ([]Ref, error) {
+	args := []string{"for-each-ref", "--format", "%(if)%(*objectname)%(then)%(*objectname)%(else)%(objectname)%(end)%00%(refname)", "--"}
+	for _, glob := range globs {
+		if strings.HasPrefix(glob, "-") {
+			return nil, fmt.Errorf("invalid ref glob %q", glob)
+		}
+		if !strings.HasPrefix(glob, "refs/") {
+			glob = "refs/" + glob
+		}
+		if !strings.ContainsAny(glob, "*?[") {
+			glob = strings.TrimSuffix(glob, "/") + "/*"
+		}
+		args = append(args, glob)
+	}
+	cmd := gitserver.DefaultClient.Command("git", args...)
+	cmd.Repo = repo
+	out, err := cmd.CombinedOutput(ctx)
+	if err != nil {
+		if vcs.IsRepoNotExist(err) {
+			return nil, err
+		}
+		return nil, errors.WithMessage(err, fmt.Sprintf("git command %v failed (output: %q)", cmd.Args, out))
+	}
+
+	out = bytes.TrimSuffix(out, []byte("\n")) // remove trailing newline
+	if len(out) == 0 {
+		return nil, nil // no matching refs
+	}
+	lines := bytes.Split(out, []byte("\n"))
+	refs := make([]Ref, len(lines))
+	for i, line := range lines {
+		parts := bytes.SplitN(line, []byte("\x00"), 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid git for-each-ref output line: %q", line)
+		}
+		refs[i] = Ref{Name: string(parts[1]), CommitID: api.CommitID(parts[0])}
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Name < refs[j].Name })
+	return refs, nil
+}
+
 type byteSlices [][]byte
 
 func (p byteSlices) Len() int           { return len(p) }
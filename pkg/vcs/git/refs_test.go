@@ -280,3 +280,51 @@ func TestRepository_ListTags(t *testing.T) {
 		}
 	}
 }
+
+func TestRepository_ListRefs(t *testing.T) {
+	t.Parallel()
+
+	dateEnv := "GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z"
+	gitCommands := []string{
+		dateEnv + " git commit --allow-empty -m foo --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+		"git branch release-1",
+		"git branch release-2",
+		"git tag t0",
+		dateEnv + " git tag --annotate -m foo t1",
+	}
+	repo := makeGitRepository(t, gitCommands...)
+	const commitID = "ea167fe3d76b1e5fd3ed8ca44cbd2fe3897684f8"
+
+	tests := map[string]struct {
+		include, exclude []string
+		wantRefs         []git.Ref
+	}{
+		"glob": {
+			include:  []string{"refs/heads/release-*"},
+			wantRefs: []git.Ref{{Name: "refs/heads/release-1", CommitID: commitID}, {Name: "refs/heads/release-2", CommitID: commitID}},
+		},
+		"prefix without refs/": {
+			include:  []string{"tags"},
+			wantRefs: []git.Ref{{Name: "refs/tags/t0", CommitID: commitID}, {Name: "refs/tags/t1", CommitID: commitID}},
+		},
+		"exclude": {
+			include:  []string{"heads/*", "tags/t1"},
+			exclude:  []string{"heads/release-*"},
+			wantRefs: []git.Ref{{Name: "refs/heads/master", CommitID: commitID}, {Name: "refs/tags/t1", CommitID: commitID}},
+		},
+		"no match": {
+			include:  []string{"heads/nonexistent-*"},
+			wantRefs: nil,
+		},
+	}
+	for label, test := range tests {
+		refs, err := git.ListRefs(ctx, repo, test.include, test.exclude)
+		if err != nil {
+			t.Errorf("%s: ListRefs: %s", label, err)
+			continue
+		}
+		if !reflect.DeepEqual(refs, test.wantRefs) {
+			t.Errorf("%s: got refs == %v, want %v", label, asJSON(refs), asJSON(test.wantRefs))
+		}
+	}
+}
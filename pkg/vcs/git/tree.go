@@ -293,3 +293,56 @@ func lsTreeUncached(ctx context.Context, repo gitserver.Repo, commit api.CommitI
 
 	return fis, nil
 }
+
+// BlobOIDs returns the object IDs of the blobs at the given paths in the
+// commit, keyed by path. Paths that don't exist (or aren't files) in the commit
+// are omitted.
+func BlobOIDs(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (map[string]string, error) {
+	if Mocks.BlobOIDs != nil {
+		return Mocks.BlobOIDs(commit, paths)
+	}
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "Git: BlobOIDs")
+	span.SetTag("Commit", commit)
+	span.SetTag("Paths", len(paths))
+	defer span.Finish()
+
+	ensureAbsCommit(commit)
+	if len(paths) == 0 {
+		return map[string]string{}, nil
+	}
+
+	args := []string{"ls-tree", "--full-name", "-z", string(commit), "--"}
+	for _, path := range paths {
+		if err := checkSpecArgSafety(path); err != nil {
+			return nil, err
+		}
+		args = append(args, filepath.ToSlash(path))
+	}
+	cmd := gitserver.DefaultClient.Command("git", args...)
+	cmd.Repo = repo
+	out, err := cmd.CombinedOutput(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, fmt.Sprintf("git command %v failed (output: %q)", cmd.Args, out))
+	}
+
+	oids := make(map[string]string, len(paths))
+	for _, line := range strings.Split(string(out), "\x00") {
+		if line == "" {
+			continue
+		}
+		// Each line is "<mode> SP <type> SP <object> TAB <file>".
+		tabPos := strings.IndexByte(line, '\t')
+		if tabPos == -1 {
+			return nil, fmt.Errorf("invalid `git ls-tree` output: %q", out)
+		}
+		info := strings.SplitN(line[:tabPos], " ", 3)
+		if len(info) != 3 {
+			return nil, fmt.Errorf("invalid `git ls-tree` output: %q", out)
+		}
+		if info[1] == "blob" {
+			oids[line[tabPos+1:]] = info[2]
+		}
+	}
+	return oids, nil
+}
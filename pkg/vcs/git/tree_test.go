@@ -304,6 +304,34 @@ func TestRepository_FileSystem(t *testing.T) {
 	}
 }
 
+func TestRepository_BlobOIDs(t *testing.T) {
+	t.Parallel()
+
+	gitCommands := []string{
+		"mkdir dir1",
+		"echo -n infile1 > dir1/file1",
+		"echo -n infile1 > file1",
+		"echo -n infile2 > 'file 2'",
+		"git add dir1/file1 file1 'file 2'",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m commit1 --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+	}
+	repo := makeGitRepository(t, gitCommands...)
+	commitID := api.CommitID(computeCommitHash(repo.URL, true))
+
+	oids, err := git.BlobOIDs(ctx, repo, commitID, []string{"dir1/file1", "file1", "file 2", "dir1", "notafile"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"dir1/file1": "a20cc2fb45631b1dd262371a058b1bf31702abaa",
+		"file1":      "a20cc2fb45631b1dd262371a058b1bf31702abaa",
+		"file 2":     "5eaf855a55b6a0e3efaea61f22cdb27f1b33a2b8",
+	}
+	if !reflect.DeepEqual(oids, want) {
+		t.Errorf("got %v, want %v", oids, want)
+	}
+}
+
 func TestRepository_FileSystem_quoteChars(t *testing.T) {
 	t.Parallel()
 
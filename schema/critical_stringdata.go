@@ -91,14 +91,14 @@ const CriticalSchemaJSON = `{
       "group": "Sourcegraph Enterprise license"
     },
     "auth.providers": {
-      "description": "The authentication providers to use for identifying and signing in users. See instructions below for configuring SAML, OpenID Connect (including G Suite), and HTTP authentication proxies. Multiple authentication providers are supported (by specifying multiple elements in this array).",
+      "description": "The authentication providers to use for identifying and signing in users. See instructions below for configuring SAML, OpenID Connect (including G Suite), LDAP, and HTTP authentication proxies. Multiple authentication providers are supported (by specifying multiple elements in this array).",
       "type": "array",
       "items": {
         "required": ["type"],
         "properties": {
           "type": {
             "type": "string",
-            "enum": ["builtin", "saml", "openidconnect", "http-header", "github", "gitlab"]
+            "enum": ["builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "ldap"]
           }
         },
         "oneOf": [
@@ -107,7 +107,8 @@ const CriticalSchemaJSON = `{
           { "$ref": "#/definitions/OpenIDConnectAuthProvider" },
           { "$ref": "#/definitions/HTTPHeaderAuthProvider" },
           { "$ref": "#/definitions/GitHubAuthProvider" },
-          { "$ref": "#/definitions/GitLabAuthProvider" }
+          { "$ref": "#/definitions/GitLabAuthProvider" },
+          { "$ref": "#/definitions/LDAPAuthProvider" }
         ],
         "!go": {
           "taggedUnionType": true
@@ -358,6 +359,114 @@ const CriticalSchemaJSON = `{
         "displayName": { "$ref": "#/definitions/AuthProviderCommon/properties/displayName" }
       }
     },
+    "LDAPAuthProvider": {
+      "description": "Configures the LDAP authentication provider, which signs in users with the username and password of their account in an LDAP directory (such as OpenLDAP or Active Directory). Sourcegraph looks up the user's entry in the directory and then verifies the password by binding as that entry.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["type", "url", "userBaseDN"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "const": "ldap"
+        },
+        "url": {
+          "description": "URL of the LDAP server. Use the ` + "`" + `ldaps` + "`" + ` scheme for LDAP over TLS (or set ` + "`" + `startTLS` + "`" + ` to upgrade an ` + "`" + `ldap` + "`" + ` connection to TLS).",
+          "type": "string",
+          "pattern": "^ldaps?://[^/]+/?$",
+          "examples": ["ldaps://ldap.example.com", "ldap://ldap.example.com:389"]
+        },
+        "startTLS": {
+          "description": "Upgrade the connection to the LDAP server to TLS with the StartTLS operation. This has no effect if ` + "`" + `url` + "`" + ` uses the ` + "`" + `ldaps` + "`" + ` scheme.",
+          "type": "boolean",
+          "default": false
+        },
+        "allowInsecure": {
+          "description": "Allow connecting to the LDAP server without TLS (with an ` + "`" + `ldap` + "`" + ` URL and without ` + "`" + `startTLS` + "`" + `). Passwords are then sent to the LDAP server in plain text, so only set this if the LDAP server is on a trusted network.",
+          "type": "boolean",
+          "default": false
+        },
+        "certificate": {
+          "description": "TLS certificate of the LDAP server. This is only necessary if the certificate is self-signed or signed by an internal CA.",
+          "type": "string",
+          "pattern": "^-----BEGIN CERTIFICATE-----\n",
+          "examples": ["-----BEGIN CERTIFICATE-----\n..."]
+        },
+        "bindDN": {
+          "description": "The DN to bind as when searching the directory for users and groups. If empty, the directory is searched anonymously.",
+          "type": "string",
+          "examples": ["cn=sourcegraph,ou=services,dc=example,dc=com"]
+        },
+        "bindPassword": {
+          "description": "The password of ` + "`" + `bindDN` + "`" + `.",
+          "type": "string"
+        },
+        "userBaseDN": {
+          "description": "The DN of the subtree of the directory in which to search for users.",
+          "type": "string",
+          "examples": ["ou=people,dc=example,dc=com"]
+        },
+        "userFilter": {
+          "description": "An LDAP filter (RFC 4515) that entries must match to be able to sign in. It is combined with a filter that matches the entered username against ` + "`" + `usernameAttribute` + "`" + `.",
+          "type": "string",
+          "default": "(objectClass=person)",
+          "examples": ["(&(objectClass=user)(memberOf=cn=engineering,ou=groups,dc=example,dc=com))"]
+        },
+        "usernameAttribute": {
+          "description": "The attribute of user entries that holds the username entered on the sign-in page. It is also used as the user's Sourcegraph username (normalized). Use ` + "`" + `sAMAccountName` + "`" + ` for Active Directory.",
+          "type": "string",
+          "default": "uid",
+          "examples": ["sAMAccountName"]
+        },
+        "emailAttribute": {
+          "description": "The attribute of user entries that holds the user's email address, which Sourcegraph treats as verified.",
+          "type": "string",
+          "default": "mail"
+        },
+        "displayNameAttribute": {
+          "description": "The attribute of user entries that holds the user's display name.",
+          "type": "string",
+          "default": "cn",
+          "examples": ["displayName"]
+        },
+        "groupSync": { "$ref": "#/definitions/LDAPGroupSync" },
+        "displayName": { "$ref": "#/definitions/AuthProviderCommon/properties/displayName" }
+      }
+    },
+    "LDAPGroupSync": {
+      "description": "If non-null, syncs the user's LDAP group memberships to Sourcegraph organization memberships each time the user signs in. Only the organizations in ` + "`" + `orgs` + "`" + ` are affected: the user is added to those whose groups the user is a member of, and removed from the others. Organizations must already exist.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["baseDN", "orgs"],
+      "properties": {
+        "baseDN": {
+          "description": "The DN of the subtree of the directory in which to search for groups.",
+          "type": "string",
+          "examples": ["ou=groups,dc=example,dc=com"]
+        },
+        "filter": {
+          "description": "An LDAP filter (RFC 4515) that group entries must match. It is combined with a filter that matches the user's DN against ` + "`" + `memberAttribute` + "`" + `.",
+          "type": "string",
+          "default": "(|(objectClass=groupOfNames)(objectClass=groupOfUniqueNames)(objectClass=group))"
+        },
+        "memberAttribute": {
+          "description": "The attribute of group entries that holds the DNs of the group's members.",
+          "type": "string",
+          "default": "member",
+          "examples": ["uniqueMember"]
+        },
+        "nameAttribute": {
+          "description": "The attribute of group entries that holds the group name used as a key in ` + "`" + `orgs` + "`" + `.",
+          "type": "string",
+          "default": "cn"
+        },
+        "orgs": {
+          "description": "A map from LDAP group names to the names of the Sourcegraph organizations whose members are the group's members. Multiple groups may map to the same organization.",
+          "type": "object",
+          "additionalProperties": { "type": "string" },
+          "examples": [{ "engineering": "eng", "sourcegraph-admins": "eng" }]
+        }
+      }
+    },
     "AuthProviderCommon": {
       "$comment": "This schema is not used directly. The *AuthProvider schemas refer to its properties directly.",
       "description": "Common properties for authentication providers.",
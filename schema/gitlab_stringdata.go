@@ -28,6 +28,11 @@ const GitLabSchemaJSON = `{
       "type": "string",
       "minLength": 1
     },
+    "webhookSecret": {
+      "description": "A secret token used to validate webhooks sent by GitLab to Sourcegraph (at https://[your-sourcegraph-hostname]/.api/webhooks/gitlab). If set, pushes to projects (and created, renamed or deleted projects, with a system hook) update Sourcegraph immediately instead of when they are next polled. Use the same value as the webhook's secret token in GitLab.",
+      "type": "string",
+      "minLength": 1
+    },
     "gitURLType": {
       "description": "The type of Git URLs to use for cloning and fetching Git repositories on this GitLab instance.\n\nIf \"http\", Sourcegraph will access GitLab repositories using Git URLs of the form http(s)://gitlab.example.com/myteam/myproject.git (using https: if the GitLab instance uses HTTPS).\n\nIf \"ssh\", Sourcegraph will access GitLab repositories using Git URLs of the form git@example.gitlab.com:myteam/myproject.git. See the documentation for how to provide SSH private keys and known_hosts: https://docs.sourcegraph.com/admin/repo/auth#repositories-that-need-http-s-or-ssh-authentication.",
       "type": "string",
@@ -144,6 +149,26 @@ const GitLabSchemaJSON = `{
           "default": "3h"
         }
       }
+    },
+    "cloneOptions": {
+      "description": "Options for cloning and fetching large repositories (such as monorepos) from GitLab, to reduce the time and disk space that gitserver needs for them. The options of the first item whose ` + "`" + `repos` + "`" + ` matches a repository's name are used. See https://docs.sourcegraph.com/admin/repo/large_repositories.",
+      "type": "array",
+      "items": { "$ref": "#/definitions/CloneOptions" },
+      "examples": [
+        [
+          {
+            "repos": ["^gitlab\\.example\\.com/mygroup/monorepo$"],
+            "filter": "blob:limit=1m",
+            "refs": ["HEAD", "refs/tags/v*"]
+          }
+        ],
+        [{ "repos": ["^gitlab\\.example\\.com/mygroup/"], "depth": 100 }]
+      ]
+    },
+    "maxRepositorySizeMB": {
+      "description": "The maximum size in MB (1 MB = 1024 × 1024 bytes) of the clone of each repository on gitserver. Repositories that are larger are not cloned, and clones that grow larger are removed. The reason is shown in the repository's mirroring settings. Use ` + "`" + `maxSizeMB` + "`" + ` in ` + "`" + `cloneOptions` + "`" + ` to set a different limit for some repositories. See https://docs.sourcegraph.com/admin/repo/large_repositories#limiting-repository-size.",
+      "type": "integer",
+      "minimum": 1
     }
   },
   "definitions": {
@@ -191,6 +216,41 @@ const GitLabSchemaJSON = `{
           "description": "The name that identifies the authentication provider to GitLab. This is passed to the ` + "`" + `?provider=` + "`" + ` query parameter in calls to the GitLab Users API. If you're not sure what this value is, you can look at the ` + "`" + `identities` + "`" + ` field of the GitLab Users API result (` + "`" + `curl  -H 'PRIVATE-TOKEN: $YOUR_TOKEN' $GITLAB_URL/api/v4/users` + "`" + `)."
         }
       }
+    },
+    "CloneOptions": {
+      "description": "Options for cloning and fetching the repositories whose names match ` + "`" + `repos` + "`" + `.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["repos"],
+      "properties": {
+        "repos": {
+          "description": "Regular expressions that are matched against repository names (as shown on Sourcegraph, such as \"github.com/myorg/monorepo\").",
+          "type": "array",
+          "items": { "type": "string", "format": "regex" },
+          "minItems": 1
+        },
+        "filter": {
+          "description": "The object filter of a partial clone (` + "`" + `git clone --filter` + "`" + `), such as \"blob:none\" or \"blob:limit=1m\" (to omit blobs larger than 1 MB). The omitted objects are fetched from the code host on demand when they are needed (for example, by searches or file views).",
+          "type": "string",
+          "pattern": "^(blob:none|blob:limit=[0-9]+[kmg]?|tree:[0-9]+)$"
+        },
+        "refs": {
+          "description": "The refs to fetch, instead of all refs. Each item is a ref name (such as \"refs/heads/main\"), a pattern with a trailing \"*\" (such as \"refs/tags/v*\" for release tags), or \"HEAD\" for the repository's default branch.",
+          "type": "array",
+          "items": { "type": "string", "pattern": "^(HEAD|refs/[^*:\\s]+\\*?)$" },
+          "minItems": 1
+        },
+        "depth": {
+          "description": "If set, the repository is cloned with a history truncated to this number of commits (` + "`" + `git clone --depth` + "`" + `). Subsequent fetches add new commits. Searches and file views of older commits are not possible.",
+          "type": "integer",
+          "minimum": 1
+        },
+        "maxSizeMB": {
+          "description": "The maximum size in MB (1 MB = 1024 × 1024 bytes) of the clones of the matching repositories on gitserver. It overrides ` + "`" + `maxRepositorySizeMB` + "`" + ` for these repositories.",
+          "type": "integer",
+          "minimum": 1
+        }
+      }
     }
   }
 }
@@ -74,6 +74,63 @@ const GitoliteSchemaJSON = `{
           "type": "string"
         }
       }
+    },
+    "cloneOptions": {
+      "description": "Options for cloning and fetching large repositories (such as monorepos) from Gitolite, to reduce the time and disk space that gitserver needs for them. The options of the first item whose ` + "`" + `repos` + "`" + ` matches a repository's name are used. See https://docs.sourcegraph.com/admin/repo/large_repositories.",
+      "type": "array",
+      "items": { "$ref": "#/definitions/CloneOptions" },
+      "examples": [
+        [
+          {
+            "repos": ["^gitolite\\.example\\.com/monorepo$"],
+            "filter": "blob:limit=1m",
+            "refs": ["HEAD", "refs/tags/v*"]
+          }
+        ],
+        [{ "repos": ["^gitolite\\.example\\.com/"], "depth": 100 }]
+      ]
+    },
+    "maxRepositorySizeMB": {
+      "description": "The maximum size in MB (1 MB = 1024 × 1024 bytes) of the clone of each repository on gitserver. Repositories that are larger are not cloned, and clones that grow larger are removed. The reason is shown in the repository's mirroring settings. Use ` + "`" + `maxSizeMB` + "`" + ` in ` + "`" + `cloneOptions` + "`" + ` to set a different limit for some repositories. See https://docs.sourcegraph.com/admin/repo/large_repositories#limiting-repository-size.",
+      "type": "integer",
+      "minimum": 1
+    }
+  },
+  "definitions": {
+    "CloneOptions": {
+      "description": "Options for cloning and fetching the repositories whose names match ` + "`" + `repos` + "`" + `.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["repos"],
+      "properties": {
+        "repos": {
+          "description": "Regular expressions that are matched against repository names (as shown on Sourcegraph, such as \"github.com/myorg/monorepo\").",
+          "type": "array",
+          "items": { "type": "string", "format": "regex" },
+          "minItems": 1
+        },
+        "filter": {
+          "description": "The object filter of a partial clone (` + "`" + `git clone --filter` + "`" + `), such as \"blob:none\" or \"blob:limit=1m\" (to omit blobs larger than 1 MB). The omitted objects are fetched from the code host on demand when they are needed (for example, by searches or file views).",
+          "type": "string",
+          "pattern": "^(blob:none|blob:limit=[0-9]+[kmg]?|tree:[0-9]+)$"
+        },
+        "refs": {
+          "description": "The refs to fetch, instead of all refs. Each item is a ref name (such as \"refs/heads/main\"), a pattern with a trailing \"*\" (such as \"refs/tags/v*\" for release tags), or \"HEAD\" for the repository's default branch.",
+          "type": "array",
+          "items": { "type": "string", "pattern": "^(HEAD|refs/[^*:\\s]+\\*?)$" },
+          "minItems": 1
+        },
+        "depth": {
+          "description": "If set, the repository is cloned with a history truncated to this number of commits (` + "`" + `git clone --depth` + "`" + `). Subsequent fetches add new commits. Searches and file views of older commits are not possible.",
+          "type": "integer",
+          "minimum": 1
+        },
+        "maxSizeMB": {
+          "description": "The maximum size in MB (1 MB = 1024 × 1024 bytes) of the clones of the matching repositories on gitserver. It overrides ` + "`" + `maxRepositorySizeMB` + "`" + ` for these repositories.",
+          "type": "integer",
+          "minimum": 1
+        }
+      }
     }
   }
 }
@@ -11,9 +11,11 @@ import (
 // AWSCodeCommitConnection description: Configuration for a connection to AWS CodeCommit.
 type AWSCodeCommitConnection struct {
 	AccessKeyID                 string                       `json:"accessKeyID"`
+	CloneOptions                []*CloneOptions              `json:"cloneOptions,omitempty"`
 	Exclude                     []*ExcludedAWSCodeCommitRepo `json:"exclude,omitempty"`
 	GitCredentials              AWSCodeCommitGitCredentials  `json:"gitCredentials"`
 	InitialRepositoryEnablement bool                         `json:"initialRepositoryEnablement,omitempty"`
+	MaxRepositorySizeMB         int                          `json:"maxRepositorySizeMB,omitempty"`
 	Region                      string                       `json:"region"`
 	RepositoryPathPattern       string                       `json:"repositoryPathPattern,omitempty"`
 	SecretAccessKey             string                       `json:"secretAccessKey"`
@@ -44,6 +46,7 @@ type AuthProviders struct {
 	HttpHeader    *HTTPHeaderAuthProvider
 	Github        *GitHubAuthProvider
 	Gitlab        *GitLabAuthProvider
+	Ldap          *LDAPAuthProvider
 }
 
 func (v AuthProviders) MarshalJSON() ([]byte, error) {
@@ -65,6 +68,9 @@ func (v AuthProviders) MarshalJSON() ([]byte, error) {
 	if v.Gitlab != nil {
 		return json.Marshal(v.Gitlab)
 	}
+	if v.Ldap != nil {
+		return json.Marshal(v.Ldap)
+	}
 	return nil, errors.New("tagged union type must have exactly 1 non-nil field value")
 }
 func (v *AuthProviders) UnmarshalJSON(data []byte) error {
@@ -83,21 +89,33 @@ func (v *AuthProviders) UnmarshalJSON(data []byte) error {
 		return json.Unmarshal(data, &v.Gitlab)
 	case "http-header":
 		return json.Unmarshal(data, &v.HttpHeader)
+	case "ldap":
+		return json.Unmarshal(data, &v.Ldap)
 	case "openidconnect":
 		return json.Unmarshal(data, &v.Openidconnect)
 	case "saml":
 		return json.Unmarshal(data, &v.Saml)
 	}
-	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab"})
+	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "ldap"})
+}
+
+// BitbucketServerAuthorization description: If non-null, enforces Bitbucket Server repository permissions. Sourcegraph looks up the repositories that each user can read on Bitbucket Server by impersonating them through an Application Link (see the `oauth` field).
+type BitbucketServerAuthorization struct {
+	IdentityProvider BitbucketServerIdentityProvider `json:"identityProvider"`
+	Oauth            BitbucketServerOAuth            `json:"oauth"`
+	Ttl              string                          `json:"ttl,omitempty"`
 }
 
 // BitbucketServerConnection description: Configuration for a connection to Bitbucket Server.
 type BitbucketServerConnection struct {
+	Authorization               *BitbucketServerAuthorization  `json:"authorization,omitempty"`
 	Certificate                 string                         `json:"certificate,omitempty"`
+	CloneOptions                []*CloneOptions                `json:"cloneOptions,omitempty"`
 	Exclude                     []*ExcludedBitbucketServerRepo `json:"exclude,omitempty"`
 	ExcludePersonalRepositories bool                           `json:"excludePersonalRepositories,omitempty"`
 	GitURLType                  string                         `json:"gitURLType,omitempty"`
 	InitialRepositoryEnablement bool                           `json:"initialRepositoryEnablement,omitempty"`
+	MaxRepositorySizeMB         int                            `json:"maxRepositorySizeMB,omitempty"`
 	Password                    string                         `json:"password,omitempty"`
 	Repos                       []string                       `json:"repos,omitempty"`
 	RepositoryPathPattern       string                         `json:"repositoryPathPattern,omitempty"`
@@ -105,6 +123,22 @@ type BitbucketServerConnection struct {
 	Token                       string                         `json:"token,omitempty"`
 	Url                         string                         `json:"url"`
 	Username                    string                         `json:"username"`
+	WebhookSecret               string                         `json:"webhookSecret,omitempty"`
+}
+
+// BitbucketServerIdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Bitbucket Server identity to use for a given Sourcegraph user.
+//
+// If "username", the Sourcegraph user is mapped to the Bitbucket Server user with the same username. If "email", the Sourcegraph user is mapped to the Bitbucket Server user whose email address is one of the Sourcegraph user's verified email addresses.
+//
+// Usernames (and unverified email addresses) can be changed by users at will, so only use this if users can't choose the username or email address of their Sourcegraph account (for example, because it is set by an auth provider that is backed by the same directory as Bitbucket Server).
+type BitbucketServerIdentityProvider struct {
+	Type string `json:"type"`
+}
+
+// BitbucketServerOAuth description: The OAuth credentials of an Application Link on Bitbucket Server with incoming authentication, which Sourcegraph uses to impersonate users when it fetches their repository permissions. The Application Link must allow 2-legged OAuth with impersonation.
+type BitbucketServerOAuth struct {
+	ConsumerKey string `json:"consumerKey"`
+	SigningKey  string `json:"signingKey"`
 }
 type BrandAssets struct {
 	Logo   string `json:"logo,omitempty"`
@@ -126,6 +160,15 @@ type BuiltinAuthProvider struct {
 	Type        string `json:"type"`
 }
 
+// CloneOptions description: Options for cloning and fetching the repositories whose names match `repos`.
+type CloneOptions struct {
+	Depth     int      `json:"depth,omitempty"`
+	Filter    string   `json:"filter,omitempty"`
+	MaxSizeMB int      `json:"maxSizeMB,omitempty"`
+	Refs      []string `json:"refs,omitempty"`
+	Repos     []string `json:"repos"`
+}
+
 // CloneURLToRepositoryName description: Describes a mapping from clone URL to repository name. The `from` field contains a regular expression with named capturing groups. The `to` field contains a template string that references capturing group names. For instance, if `from` is "^../(?P<name>\w+)$" and `to` is "github.com/user/{name}", the clone URL "../myRepository" would be mapped to the repository name "github.com/user/myRepository".
 type CloneURLToRepositoryName struct {
 	From string `json:"from"`
@@ -216,14 +259,17 @@ type GitHubAuthorization struct {
 type GitHubConnection struct {
 	Authorization               *GitHubAuthorization  `json:"authorization,omitempty"`
 	Certificate                 string                `json:"certificate,omitempty"`
+	CloneOptions                []*CloneOptions       `json:"cloneOptions,omitempty"`
 	Exclude                     []*ExcludedGitHubRepo `json:"exclude,omitempty"`
 	GitURLType                  string                `json:"gitURLType,omitempty"`
 	InitialRepositoryEnablement bool                  `json:"initialRepositoryEnablement,omitempty"`
+	MaxRepositorySizeMB         int                   `json:"maxRepositorySizeMB,omitempty"`
 	Repos                       []string              `json:"repos,omitempty"`
 	RepositoryPathPattern       string                `json:"repositoryPathPattern,omitempty"`
 	RepositoryQuery             []string              `json:"repositoryQuery"`
 	Token                       string                `json:"token"`
 	Url                         string                `json:"url"`
+	WebhookSecret               string                `json:"webhookSecret,omitempty"`
 }
 
 // GitLabAuthProvider description: Configures the GitLab OAuth authentication provider for SSO. In addition to specifying this configuration object, you must also create a OAuth App on your GitLab instance: https://docs.gitlab.com/ee/integration/oauth_provider.html. The application should have `api` and `read_user` scopes and the callback URL set to the concatenation of your Sourcegraph instance URL and "/.auth/gitlab/callback".
@@ -245,14 +291,17 @@ type GitLabAuthorization struct {
 type GitLabConnection struct {
 	Authorization               *GitLabAuthorization     `json:"authorization,omitempty"`
 	Certificate                 string                   `json:"certificate,omitempty"`
+	CloneOptions                []*CloneOptions          `json:"cloneOptions,omitempty"`
 	Exclude                     []*ExcludedGitLabProject `json:"exclude,omitempty"`
 	GitURLType                  string                   `json:"gitURLType,omitempty"`
 	InitialRepositoryEnablement bool                     `json:"initialRepositoryEnablement,omitempty"`
+	MaxRepositorySizeMB         int                      `json:"maxRepositorySizeMB,omitempty"`
 	ProjectQuery                []string                 `json:"projectQuery"`
 	Projects                    []*GitLabProject         `json:"projects,omitempty"`
 	RepositoryPathPattern       string                   `json:"repositoryPathPattern,omitempty"`
 	Token                       string                   `json:"token"`
 	Url                         string                   `json:"url"`
+	WebhookSecret               string                   `json:"webhookSecret,omitempty"`
 }
 type GitLabProject struct {
 	Id   int    `json:"id,omitempty"`
@@ -262,8 +311,10 @@ type GitLabProject struct {
 // GitoliteConnection description: Configuration for a connection to Gitolite.
 type GitoliteConnection struct {
 	Blacklist                  string                  `json:"blacklist,omitempty"`
+	CloneOptions               []*CloneOptions         `json:"cloneOptions,omitempty"`
 	Exclude                    []*ExcludedGitoliteRepo `json:"exclude,omitempty"`
 	Host                       string                  `json:"host"`
+	MaxRepositorySizeMB        int                     `json:"maxRepositorySizeMB,omitempty"`
 	Phabricator                *Phabricator            `json:"phabricator,omitempty"`
 	PhabricatorMetadataCommand string                  `json:"phabricatorMetadataCommand,omitempty"`
 	Prefix                     string                  `json:"prefix"`
@@ -321,6 +372,33 @@ func (v *IdentityProvider) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"oauth", "username", "external"})
 }
 
+// LDAPAuthProvider description: Configures the LDAP authentication provider, which signs in users with the username and password of their account in an LDAP directory (such as OpenLDAP or Active Directory). Sourcegraph looks up the user's entry in the directory and then verifies the password by binding as that entry.
+type LDAPAuthProvider struct {
+	AllowInsecure        bool           `json:"allowInsecure,omitempty"`
+	BindDN               string         `json:"bindDN,omitempty"`
+	BindPassword         string         `json:"bindPassword,omitempty"`
+	Certificate          string         `json:"certificate,omitempty"`
+	DisplayName          string         `json:"displayName,omitempty"`
+	DisplayNameAttribute string         `json:"displayNameAttribute,omitempty"`
+	EmailAttribute       string         `json:"emailAttribute,omitempty"`
+	GroupSync            *LDAPGroupSync `json:"groupSync,omitempty"`
+	StartTLS             bool           `json:"startTLS,omitempty"`
+	Type                 string         `json:"type"`
+	Url                  string         `json:"url"`
+	UserBaseDN           string         `json:"userBaseDN"`
+	UserFilter           string         `json:"userFilter,omitempty"`
+	UsernameAttribute    string         `json:"usernameAttribute,omitempty"`
+}
+
+// LDAPGroupSync description: If non-null, syncs the user's LDAP group memberships to Sourcegraph organization memberships each time the user signs in. Only the organizations in `orgs` are affected: the user is added to those whose groups the user is a member of, and removed from the others. Organizations must already exist.
+type LDAPGroupSync struct {
+	BaseDN          string            `json:"baseDN"`
+	Filter          string            `json:"filter,omitempty"`
+	MemberAttribute string            `json:"memberAttribute,omitempty"`
+	NameAttribute   string            `json:"nameAttribute,omitempty"`
+	Orgs            map[string]string `json:"orgs"`
+}
+
 // Log description: Configuration for logging and alerting, including to external services.
 type Log struct {
 	Sentry *Sentry `json:"sentry,omitempty"`
@@ -347,8 +425,10 @@ type OpenIDConnectAuthProvider struct {
 
 // OtherExternalServiceConnection description: Configuration for a Connection to Git repositories for which an external service integration isn't yet available.
 type OtherExternalServiceConnection struct {
-	Repos []string `json:"repos"`
-	Url   string   `json:"url,omitempty"`
+	CloneOptions        []*CloneOptions `json:"cloneOptions,omitempty"`
+	MaxRepositorySizeMB int             `json:"maxRepositorySizeMB,omitempty"`
+	Repos               []string        `json:"repos"`
+	Url                 string          `json:"url,omitempty"`
 }
 
 // ParentSourcegraph description: URL to fetch unreachable repository details from. Defaults to "https://sourcegraph.com"
@@ -356,6 +436,14 @@ type ParentSourcegraph struct {
 	Url string `json:"url,omitempty"`
 }
 
+// PermissionsBackgroundSync description: Settings for syncing users' repository permissions from code hosts in the background. When enabled, repository permissions are fetched from code hosts with repository permissions enforcement (see the "authorization" property of the external service configuration) periodically and when a user signs in, instead of when the user's repositories are filtered.
+//
+// Only available in Sourcegraph Enterprise.
+type PermissionsBackgroundSync struct {
+	Enabled  *bool `json:"enabled,omitempty"`
+	Interval int   `json:"interval,omitempty"`
+}
+
 // Phabricator description: Phabricator instance that integrates with this Gitolite instance
 type Phabricator struct {
 	CallsignCommand string `json:"callsignCommand"`
@@ -373,6 +461,16 @@ type Repos struct {
 	Path     string `json:"path"`
 }
 
+// RepositoryGroupRule description: A rule that defines the repositories in a repository group. A repository is in the group if it satisfies all of the specified conditions.
+type RepositoryGroupRule struct {
+	ExternalService   string `json:"externalService,omitempty"`
+	Language          string `json:"language,omitempty"`
+	Owner             string `json:"owner,omitempty"`
+	RepositoryPattern string `json:"repositoryPattern,omitempty"`
+	SavedSearch       string `json:"savedSearch,omitempty"`
+	Topic             string `json:"topic,omitempty"`
+}
+
 // SAMLAuthProvider description: Configures the SAML authentication provider for SSO.
 //
 // Note: if you are using IdP-initiated login, you must have *at most one* SAMLAuthProvider in the `auth.providers` array.
@@ -399,13 +497,19 @@ type SMTPServerConfig struct {
 	Port           int    `json:"port"`
 	Username       string `json:"username,omitempty"`
 }
+
+// SavedQueryWebhook description: Notify a webhook when new results are available, by sending an HTTP POST request with a JSON payload that describes the added and removed results to the URL. To sign the requests, set a secret with the setSavedQueryWebhookSecret GraphQL mutation (secrets are not stored in settings, because other users may be able to read them).
+type SavedQueryWebhook struct {
+	Url string `json:"url"`
+}
 type SearchSavedQueries struct {
-	Description    string `json:"description"`
-	Key            string `json:"key"`
-	Notify         bool   `json:"notify,omitempty"`
-	NotifySlack    bool   `json:"notifySlack,omitempty"`
-	Query          string `json:"query"`
-	ShowOnHomepage bool   `json:"showOnHomepage,omitempty"`
+	Description    string             `json:"description"`
+	Key            string             `json:"key"`
+	Notify         bool               `json:"notify,omitempty"`
+	NotifySlack    bool               `json:"notifySlack,omitempty"`
+	NotifyWebhook  *SavedQueryWebhook `json:"notifyWebhook,omitempty"`
+	Query          string             `json:"query"`
+	ShowOnHomepage bool               `json:"showOnHomepage,omitempty"`
 }
 type SearchScope struct {
 	Description string `json:"description,omitempty"`
@@ -421,19 +525,21 @@ type Sentry struct {
 
 // Settings description: Configuration settings for users and organizations on Sourcegraph.
 type Settings struct {
-	AlertsShowPatchUpdates bool                      `json:"alerts.showPatchUpdates,omitempty"`
-	Extensions             map[string]bool           `json:"extensions,omitempty"`
-	Motd                   []string                  `json:"motd,omitempty"`
-	Notices                []*Notice                 `json:"notices,omitempty"`
-	NotificationsSlack     *SlackNotificationsConfig `json:"notifications.slack,omitempty"`
-	SearchContextLines     int                       `json:"search.contextLines,omitempty"`
-	SearchRepositoryGroups map[string][]string       `json:"search.repositoryGroups,omitempty"`
-	SearchSavedQueries     []*SearchSavedQueries     `json:"search.savedQueries,omitempty"`
-	SearchScopes           []*SearchScope            `json:"search.scopes,omitempty"`
+	AlertsShowPatchUpdates     bool                            `json:"alerts.showPatchUpdates,omitempty"`
+	Extensions                 map[string]bool                 `json:"extensions,omitempty"`
+	Motd                       []string                        `json:"motd,omitempty"`
+	Notices                    []*Notice                       `json:"notices,omitempty"`
+	NotificationsSlack         *SlackNotificationsConfig       `json:"notifications.slack,omitempty"`
+	SearchContextLines         int                             `json:"search.contextLines,omitempty"`
+	SearchRepositoryGroupRules map[string]*RepositoryGroupRule `json:"search.repositoryGroupRules,omitempty"`
+	SearchRepositoryGroups     map[string][]string             `json:"search.repositoryGroups,omitempty"`
+	SearchSavedQueries         []*SearchSavedQueries           `json:"search.savedQueries,omitempty"`
+	SearchScopes               []*SearchScope                  `json:"search.scopes,omitempty"`
 }
 
 // SiteConfiguration description: Configuration for a Sourcegraph site.
 type SiteConfiguration struct {
+	AuditLogRetentionDays             int                         `json:"auditLog.retentionDays,omitempty"`
 	AuthAccessTokens                  *AuthAccessTokens           `json:"auth.accessTokens,omitempty"`
 	Branding                          *Branding                   `json:"branding,omitempty"`
 	CorsOrigin                        string                      `json:"corsOrigin,omitempty"`
@@ -453,6 +559,7 @@ type SiteConfiguration struct {
 	GithubClientSecret                string                      `json:"githubClientSecret,omitempty"`
 	MaxReposToSearch                  int                         `json:"maxReposToSearch,omitempty"`
 	ParentSourcegraph                 *ParentSourcegraph          `json:"parentSourcegraph,omitempty"`
+	PermissionsBackgroundSync         *PermissionsBackgroundSync  `json:"permissions.backgroundSync,omitempty"`
 	RepoListUpdateInterval            int                         `json:"repoListUpdateInterval,omitempty"`
 	SearchIndexEnabled                *bool                       `json:"search.index.enabled,omitempty"`
 	SearchLargeFiles                  []string                    `json:"search.largeFiles,omitempty"`
@@ -39,6 +39,9 @@ const SettingsSchemaJSON = `{
           "notifySlack": {
             "type": "boolean",
             "description": "Notify Slack via the organization's Slack webhook URL when new results are available"
+          },
+          "notifyWebhook": {
+            "$ref": "#/definitions/SavedQueryWebhook"
           }
         },
         "additionalProperties": false,
@@ -60,6 +63,19 @@ const SettingsSchemaJSON = `{
         "items": { "type": "string" }
       }
     },
+    "search.repositoryGroupRules": {
+      "description": "Named groups of repositories that are defined by rules and can be referenced in a search query using the repogroup: operator. A repository is in a group if it satisfies all of the group's rule conditions. The repositories in each group are re-evaluated every few minutes, so new repositories that satisfy the rule are added automatically. If a group with the same name is also defined in search.repositoryGroups, the group contains the repositories of both.",
+      "type": "object",
+      "additionalProperties": {
+        "$ref": "#/definitions/RepositoryGroupRule"
+      },
+      "examples": [
+        {
+          "services": { "owner": "myorg", "repositoryPattern": "-service$" },
+          "python": { "language": "Python", "externalService": "RXh0ZXJuYWxTZXJ2aWNlOjE=" }
+        }
+      ]
+    },
     "search.contextLines": {
       "description": "The default number of lines to show as context below and above search results. Default is 1.",
       "type": "integer",
@@ -142,6 +158,45 @@ const SettingsSchemaJSON = `{
         }
       }
     },
+    "RepositoryGroupRule": {
+      "description": "A rule that defines the repositories in a repository group. A repository is in the group if it satisfies all of the specified conditions.",
+      "type": "object",
+      "additionalProperties": false,
+      "minProperties": 1,
+      "properties": {
+        "repositoryPattern": {
+          "description": "A regular expression that the repository name must match.",
+          "type": "string",
+          "format": "regex",
+          "minLength": 1
+        },
+        "externalService": {
+          "description": "The ID of the external service that the repository must be synced by. The ID is shown in the URL of the external service's page in site admin.",
+          "type": "string",
+          "minLength": 1
+        },
+        "owner": {
+          "description": "The organization or user (on GitHub), group (on GitLab, including its subgroups), or project key (on Bitbucket Server) that the repository must belong to on its code host. Case-insensitive.",
+          "type": "string",
+          "minLength": 1
+        },
+        "topic": {
+          "description": "A topic that the repository must have on its code host (GitHub and GitLab only). Case-insensitive.",
+          "type": "string",
+          "minLength": 1
+        },
+        "language": {
+          "description": "The primary language that the repository must have (as reported by its code host). Case-insensitive.",
+          "type": "string",
+          "minLength": 1
+        },
+        "savedSearch": {
+          "description": "The key of a saved search (in search.savedQueries) that must have results in the repository. The saved search's query must not reference a repository group defined by a rule.",
+          "type": "string",
+          "minLength": 1
+        }
+      }
+    },
     "SlackNotificationsConfig": {
       "type": "object",
       "description": "Configuration for sending notifications to Slack.",
@@ -154,6 +209,20 @@ const SettingsSchemaJSON = `{
           "format": "uri"
         }
       }
+    },
+    "SavedQueryWebhook": {
+      "type": "object",
+      "description": "Notify a webhook when new results are available, by sending an HTTP POST request with a JSON payload that describes the added and removed results to the URL. To sign the requests, set a secret with the setSavedQueryWebhookSecret GraphQL mutation (secrets are not stored in settings, because other users may be able to read them).",
+      "additionalProperties": false,
+      "required": ["url"],
+      "properties": {
+        "url": {
+          "type": "string",
+          "description": "The URL that the JSON payload is sent to.",
+          "format": "uri",
+          "pattern": "^https?://"
+        }
+      }
     }
   }
 }
@@ -132,6 +132,14 @@ const SiteSchemaJSON = `{
       },
       "group": "External services"
     },
+    "auditLog.retentionDays": {
+      "description": "The number of days for which entries are kept in the audit log of security-sensitive actions (such as changes to the site configuration, access tokens, site admins, and external services). Older entries are deleted.",
+      "type": "integer",
+      "minimum": 1,
+      "default": 90,
+      "examples": [365],
+      "group": "Security"
+    },
     "auth.accessTokens": {
       "description": "Settings for access tokens, which enable external tools to access the Sourcegraph API with the privileges of the user.",
       "type": "object",
@@ -155,6 +163,30 @@ const SiteSchemaJSON = `{
       ],
       "group": "Security"
     },
+    "permissions.backgroundSync": {
+      "description": "Settings for syncing users' repository permissions from code hosts in the background. When enabled, repository permissions are fetched from code hosts with repository permissions enforcement (see the \"authorization\" property of the external service configuration) periodically and when a user signs in, instead of when the user's repositories are filtered.\n\nOnly available in Sourcegraph Enterprise.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "enabled": {
+          "description": "Whether to sync repository permissions in the background.",
+          "type": "boolean",
+          "default": true
+        },
+        "interval": {
+          "description": "Interval (in minutes) after which a user's repository permissions are synced again.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 60
+        }
+      },
+      "default": {
+        "enabled": true,
+        "interval": 60
+      },
+      "examples": [{ "enabled": false }],
+      "group": "Security"
+    },
     "branding": {
       "description": "Customize Sourcegraph homepage logo and search icon.\n\nOnly available in Sourcegraph Enterprise.",
       "type": "object",
@@ -0,0 +1,125 @@
+import { LoadingSpinner } from '@sourcegraph/react-loading-spinner'
+import * as H from 'history'
+import { upperFirst } from 'lodash'
+import * as React from 'react'
+import { Form } from '../components/Form'
+import { eventLogger } from '../tracking/eventLogger'
+import { getReturnTo, PasswordInput } from './SignInSignUpCommon'
+
+interface Props {
+    location: H.Location
+    history: H.History
+
+    /** The display name of the LDAP auth provider. */
+    displayName: string
+
+    /** The URL of the LDAP auth provider's login endpoint. */
+    authenticationURL: string
+}
+
+interface State {
+    username: string
+    password: string
+    errorDescription: string
+    loading: boolean
+}
+
+/**
+ * The form for signing in with the username and password of an account in an LDAP directory.
+ */
+export class LDAPSignInForm extends React.Component<Props, State> {
+    public state: State = {
+        username: '',
+        password: '',
+        errorDescription: '',
+        loading: false,
+    }
+
+    public render(): JSX.Element | null {
+        return (
+            <Form className="signin-signup-form signin-form" onSubmit={this.handleSubmit}>
+                <p className="text-muted">Sign in with {this.props.displayName}</p>
+                {this.state.errorDescription !== '' && (
+                    <div className="alert alert-danger my-2">Error: {upperFirst(this.state.errorDescription)}</div>
+                )}
+                <div className="form-group">
+                    <input
+                        className="form-control signin-signup-form__input"
+                        type="text"
+                        placeholder="Username"
+                        onChange={this.onUsernameFieldChange}
+                        required={true}
+                        value={this.state.username}
+                        disabled={this.state.loading}
+                        autoCapitalize="off"
+                        autoComplete="username"
+                    />
+                </div>
+                <div className="form-group">
+                    <PasswordInput
+                        className="signin-signup-form__input"
+                        onChange={this.onPasswordFieldChange}
+                        value={this.state.password}
+                        required={true}
+                        disabled={this.state.loading}
+                        autoComplete="current-password"
+                    />
+                </div>
+                <div className="form-group">
+                    <button className="btn btn-primary btn-block" type="submit" disabled={this.state.loading}>
+                        Sign in
+                    </button>
+                </div>
+                {this.state.loading && (
+                    <div className="signin-signup-form__loader">
+                        <LoadingSpinner className="icon-inline" />
+                    </div>
+                )}
+            </Form>
+        )
+    }
+
+    private onUsernameFieldChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+        this.setState({ username: e.target.value })
+    }
+
+    private onPasswordFieldChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+        this.setState({ password: e.target.value })
+    }
+
+    private handleSubmit = (event: React.FormEvent<HTMLFormElement>) => {
+        event.preventDefault()
+        if (this.state.loading) {
+            return
+        }
+
+        this.setState({ loading: true })
+        eventLogger.log('InitiateSignIn')
+        fetch(this.props.authenticationURL, {
+            credentials: 'same-origin',
+            method: 'POST',
+            headers: {
+                ...window.context.xhrHeaders,
+                Accept: 'application/json',
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({
+                username: this.state.username,
+                password: this.state.password,
+            }),
+        })
+            .then(resp => {
+                if (resp.status === 401) {
+                    throw new Error('Username or password was incorrect')
+                } else if (resp.status !== 200) {
+                    return resp.text().then(text => Promise.reject(new Error(text)))
+                }
+                window.location.replace(getReturnTo(this.props.location))
+                return Promise.resolve()
+            })
+            .catch(err => {
+                console.error('auth error: ', err)
+                this.setState({ loading: false, errorDescription: (err && err.message) || 'Unknown Error' })
+            })
+    }
+}
@@ -6,6 +6,7 @@ import * as GQL from '../../../shared/src/graphql/schema'
 import { HeroPage } from '../components/HeroPage'
 import { PageTitle } from '../components/PageTitle'
 import { eventLogger } from '../tracking/eventLogger'
+import { LDAPSignInForm } from './LDAPSignInForm'
 import { getReturnTo } from './SignInSignUpCommon'
 import { UsernamePasswordSignInForm } from './UsernamePasswordSignInForm'
 
@@ -37,6 +38,13 @@ export class SignInPage extends React.Component<SignInPageProps> {
                             window.context.authProviders.map((p, i) =>
                                 p.isBuiltin ? (
                                     <UsernamePasswordSignInForm key={i} {...this.props} />
+                                ) : p.serviceType === 'ldap' && p.authenticationURL ? (
+                                    <LDAPSignInForm
+                                        key={i}
+                                        {...this.props}
+                                        displayName={p.displayName}
+                                        authenticationURL={p.authenticationURL}
+                                    />
                                 ) : (
                                     <a key={i} href={p.authenticationURL} className="btn btn-primary mt-3 mb-1">
                                         Sign in with {p.displayName}
@@ -3,5 +3,10 @@
  */
 export enum AccessTokenScopes {
     UserAll = 'user:all',
+    UserRead = 'user:read',
+    SettingsWrite = 'settings:write',
+    SavedSearchesWrite = 'saved-searches:write',
+    ExtensionsPublish = 'extensions:publish',
+    ExternalServicesWrite = 'external-services:write',
     SiteAdminSudo = 'site-admin:sudo',
 }
@@ -108,6 +108,7 @@ interface SourcegraphContext {
     authProviders?: {
         displayName: string
         isBuiltin: boolean
+        serviceType: string
         authenticationURL?: string
     }[]
 
@@ -2,6 +2,7 @@ import { LoadingSpinner } from '@sourcegraph/react-loading-spinner'
 import { upperFirst } from 'lodash'
 import CheckIcon from 'mdi-react/CheckIcon'
 import LockIcon from 'mdi-react/LockIcon'
+import prettyBytes from 'pretty-bytes'
 import * as React from 'react'
 import { RouteComponentProps } from 'react-router'
 import { Link } from 'react-router-dom'
@@ -74,6 +75,14 @@ class UpdateMirrorRepositoryActionContainer extends React.PureComponent<UpdateMi
                             'unknown'
                         )}{' '}
                     </div>
+                    {this.props.repo.mirrorInfo.diskSizeBytes && (
+                        <div>Disk usage: {prettyBytes(Number(this.props.repo.mirrorInfo.diskSizeBytes))}</div>
+                    )}
+                    {this.props.repo.mirrorInfo.lastAccessedAt && (
+                        <div>
+                            Last accessed: <Timestamp date={this.props.repo.mirrorInfo.lastAccessedAt} />
+                        </div>
+                    )}
                     {updateSchedule && (
                         <div>
                             Next scheduled update <Timestamp date={updateSchedule.due} /> (position{' '}
@@ -99,6 +108,17 @@ class UpdateMirrorRepositoryActionContainer extends React.PureComponent<UpdateMi
             title = 'Clone this repository'
             description = 'This repository has not yet been cloned from its remote repository.'
             buttonLabel = 'Clone now'
+            if (this.props.repo.mirrorInfo.sizeLimitError) {
+                info = (
+                    <div className="alert alert-warning action-container__alert">
+                        {upperFirst(this.props.repo.mirrorInfo.sizeLimitError)}. See{' '}
+                        <Link to="/help/admin/repo/large_repositories#limiting-repository-size">
+                            how to configure repository size limits
+                        </Link>
+                        .
+                    </div>
+                )
+            }
         }
 
         return (
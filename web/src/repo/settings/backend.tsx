@@ -23,6 +23,9 @@ export function fetchRepository(name: string): Observable<GQL.IRepository> {
                         cloneProgress
                         cloned
                         updatedAt
+                        diskSizeBytes
+                        lastAccessedAt
+                        sizeLimitError
                         updateSchedule {
                             due
                             index
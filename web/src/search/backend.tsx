@@ -263,6 +263,7 @@ const savedQueryFragment = gql`
         notify
         notifySlack
         query
+        webhookURL
     }
 `
 
@@ -284,6 +285,37 @@ export function fetchSavedQueries(): Observable<GQL.ISavedQuery[]> {
     )
 }
 
+export function fetchSavedQueryWebhookDeliveries(id: GQL.ID): Observable<GQL.ISavedQueryWebhookDelivery[]> {
+    return queryGraphQL(gql`
+        query SavedQueryWebhookDeliveries {
+            savedQueries {
+                id
+                webhookDeliveries(first: 10) {
+                    event
+                    url
+                    statusCode
+                    responseBody
+                    error
+                    attempts
+                    succeeded
+                    createdAt
+                }
+            }
+        }
+    `).pipe(
+        map(({ data, errors }) => {
+            if (!data || !data.savedQueries) {
+                throw createAggregateError(errors)
+            }
+            const savedQuery = data.savedQueries.find(savedQuery => savedQuery.id === id)
+            if (!savedQuery) {
+                throw new Error('saved query not found')
+            }
+            return savedQuery.webhookDeliveries
+        })
+    )
+}
+
 export function createSavedQuery(
     subject: GQL.SettingsSubject | GQL.ISettingsSubject | { id: GQL.ID },
     settingsLastID: number | null,
@@ -1,5 +1,6 @@
 import ContentCopyIcon from 'mdi-react/ContentCopyIcon'
 import DeleteIcon from 'mdi-react/DeleteIcon'
+import HistoryIcon from 'mdi-react/HistoryIcon'
 import PencilIcon from 'mdi-react/PencilIcon'
 import * as React from 'react'
 import { Subject, Subscription } from 'rxjs'
@@ -12,6 +13,7 @@ import { eventLogger } from '../../tracking/eventLogger'
 import { createSavedQuery, deleteSavedQuery } from '../backend'
 import { SavedQueryRow } from './SavedQueryRow'
 import { SavedQueryUpdateForm } from './SavedQueryUpdateForm'
+import { SavedQueryWebhookDeliveries } from './SavedQueryWebhookDeliveries'
 interface Props extends SettingsCascadeProps, ThemeProps {
     authenticatedUser: GQL.IUser | null
     savedQuery: GQL.ISavedQuery
@@ -22,6 +24,7 @@ interface Props extends SettingsCascadeProps, ThemeProps {
 
 interface State {
     isEditing: boolean
+    isShowingWebhookDeliveries: boolean
     isSaving: boolean
     loading: boolean
     error?: Error
@@ -32,7 +35,14 @@ interface State {
 }
 
 export class SavedQuery extends React.PureComponent<Props, State> {
-    public state: State = { isEditing: false, isSaving: false, loading: true, refreshedAt: 0, redirect: false }
+    public state: State = {
+        isEditing: false,
+        isShowingWebhookDeliveries: false,
+        isSaving: false,
+        loading: true,
+        refreshedAt: 0,
+        redirect: false,
+    }
 
     private componentUpdates = new Subject<Props>()
     private refreshRequested = new Subject<GQL.ISavedQuery>()
@@ -128,6 +138,12 @@ export class SavedQuery extends React.PureComponent<Props, State> {
                                     Edit
                                 </button>
                             )}
+                            {!this.state.isEditing && this.props.savedQuery.webhookURL && (
+                                <button className="btn btn-icon action" onClick={this.toggleWebhookDeliveries}>
+                                    <HistoryIcon className="icon-inline" />
+                                    Deliveries
+                                </button>
+                            )}
                             {!this.state.isEditing && (
                                 <button className="btn btn-icon action" onClick={this.duplicate}>
                                     <ContentCopyIcon className="icon-inline" />
@@ -142,7 +158,7 @@ export class SavedQuery extends React.PureComponent<Props, State> {
                     )
                 }
                 form={
-                    this.state.isEditing && (
+                    this.state.isEditing ? (
                         <div className="saved-query-row__row">
                             <SavedQueryUpdateForm
                                 authenticatedUser={this.props.authenticatedUser}
@@ -152,6 +168,10 @@ export class SavedQuery extends React.PureComponent<Props, State> {
                                 settingsCascade={this.props.settingsCascade}
                             />
                         </div>
+                    ) : (
+                        this.state.isShowingWebhookDeliveries && (
+                            <SavedQueryWebhookDeliveries savedQuery={this.props.savedQuery} />
+                        )
                     )
                 }
             />
@@ -167,6 +187,15 @@ export class SavedQuery extends React.PureComponent<Props, State> {
         this.setState(state => ({ isEditing: !state.isEditing }))
     }
 
+    private toggleWebhookDeliveries = (e: React.MouseEvent<HTMLElement>) => {
+        e.stopPropagation()
+        e.preventDefault()
+        eventLogger.log('SavedQueryToggleWebhookDeliveries', {
+            queries: { showing: !this.state.isShowingWebhookDeliveries },
+        })
+        this.setState(state => ({ isShowingWebhookDeliveries: !state.isShowingWebhookDeliveries }))
+    }
+
     private onDidUpdateSavedQuery = () => {
         eventLogger.log('SavedQueryUpdated')
         this.setState({ isEditing: false, approximateResultCount: undefined, loading: true }, () => {
@@ -0,0 +1,86 @@
+import { LoadingSpinner } from '@sourcegraph/react-loading-spinner'
+import { upperFirst } from 'lodash'
+import * as React from 'react'
+import { Subscription } from 'rxjs'
+import { catchError } from 'rxjs/operators'
+import * as GQL from '../../../../shared/src/graphql/schema'
+import { asError, ErrorLike, isErrorLike } from '../../../../shared/src/util/errors'
+import { Timestamp } from '../../components/time/Timestamp'
+import { fetchSavedQueryWebhookDeliveries } from '../backend'
+
+interface Props {
+    savedQuery: GQL.ISavedQuery
+}
+
+interface State {
+    /** The most recent webhook deliveries, undefined while loading, or an error. */
+    deliveriesOrError?: GQL.ISavedQueryWebhookDelivery[] | ErrorLike
+}
+
+/**
+ * Displays the history of the most recent webhook deliveries of a saved query's notifications.
+ */
+export class SavedQueryWebhookDeliveries extends React.PureComponent<Props, State> {
+    public state: State = {}
+
+    private subscriptions = new Subscription()
+
+    public componentDidMount(): void {
+        this.subscriptions.add(
+            fetchSavedQueryWebhookDeliveries(this.props.savedQuery.id)
+                .pipe(catchError(err => [asError(err)]))
+                .subscribe(deliveriesOrError => this.setState({ deliveriesOrError }))
+        )
+    }
+
+    public componentWillUnmount(): void {
+        this.subscriptions.unsubscribe()
+    }
+
+    public render(): JSX.Element | null {
+        const { deliveriesOrError } = this.state
+        return (
+            <div className="saved-query-webhook-deliveries px-2 py-2">
+                <h4>Recent webhook deliveries</h4>
+                {deliveriesOrError === undefined ? (
+                    <LoadingSpinner className="icon-inline" />
+                ) : isErrorLike(deliveriesOrError) ? (
+                    <div className="alert alert-danger">{upperFirst(deliveriesOrError.message)}</div>
+                ) : deliveriesOrError.length === 0 ? (
+                    <p className="text-muted">No webhook notifications have been sent yet.</p>
+                ) : (
+                    <table className="table table-sm">
+                        <thead>
+                            <tr>
+                                <th>Sent</th>
+                                <th>Event</th>
+                                <th>Status</th>
+                                <th>Attempts</th>
+                                <th>Response</th>
+                            </tr>
+                        </thead>
+                        <tbody>
+                            {deliveriesOrError.map((delivery, i) => (
+                                <tr key={i}>
+                                    <td>
+                                        <Timestamp date={delivery.createdAt} />
+                                    </td>
+                                    <td>
+                                        <code>{delivery.event}</code>
+                                    </td>
+                                    <td className={delivery.succeeded ? 'text-success' : 'text-danger'}>
+                                        {delivery.statusCode || ''} {delivery.succeeded ? 'Delivered' : delivery.error}
+                                    </td>
+                                    <td>{delivery.attempts}</td>
+                                    <td>
+                                        {delivery.responseBody && <code>{delivery.responseBody}</code>}
+                                    </td>
+                                </tr>
+                            ))}
+                        </tbody>
+                    </table>
+                )}
+            </div>
+        )
+    }
+}
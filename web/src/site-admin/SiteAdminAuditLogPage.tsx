@@ -0,0 +1,161 @@
+import * as React from 'react'
+import { RouteComponentProps } from 'react-router'
+import { Link } from 'react-router-dom'
+import * as GQL from '../../../shared/src/graphql/schema'
+import {
+    FilteredConnection,
+    FilteredConnectionFilter,
+    FilteredConnectionQueryArgs,
+} from '../components/FilteredConnection'
+import { PageTitle } from '../components/PageTitle'
+import { Timestamp } from '../components/time/Timestamp'
+import { eventLogger } from '../tracking/eventLogger'
+import { userURL } from '../user'
+import { fetchAuditLog } from './backend'
+
+/** A change to a single value, as recorded in the diff of an audit log entry. */
+interface AuditLogChange {
+    path: string[]
+    before?: any
+    after?: any
+}
+
+const formatValue = (value: any): string => (value === undefined ? '(none)' : JSON.stringify(value))
+
+interface AuditLogEntryNodeProps {
+    /** The audit log entry to display in this list item. */
+    node: GQL.IAuditLogEntry
+}
+
+const AuditLogEntryNode: React.FunctionComponent<AuditLogEntryNodeProps> = ({ node }) => {
+    const changes: AuditLogChange[] | null = node.diff
+    return (
+        <li className="list-group-item py-2">
+            <div className="d-flex align-items-center justify-content-between">
+                <div>
+                    <strong>
+                        {node.actor ? (
+                            <Link to={userURL(node.actor.username)}>{node.actor.username}</Link>
+                        ) : node.actorUserID !== null ? (
+                            `deleted user #${node.actorUserID}`
+                        ) : (
+                            'anonymous or internal actor'
+                        )}
+                    </strong>{' '}
+                    <code>{node.action}</code>{' '}
+                    <span className="text-muted">
+                        {node.targetType}
+                        {node.targetID && ` ${node.targetID}`}
+                    </span>
+                </div>
+                <div className="text-muted">
+                    {node.ip && (
+                        <small
+                            className="mr-2"
+                            data-tooltip={node.forwardedFor ? `X-Forwarded-For: ${node.forwardedFor}` : undefined}
+                        >
+                            {node.ip}
+                        </small>
+                    )}
+                    <Timestamp date={node.createdAt} />
+                </div>
+            </div>
+            {changes && changes.length > 0 && (
+                <details className="mt-2">
+                    <summary>
+                        {changes.length} {changes.length === 1 ? 'change' : 'changes'}
+                    </summary>
+                    <ul className="list-unstyled mt-1 mb-0">
+                        {changes.map((change, i) => (
+                            <li key={i}>
+                                <code>{change.path.length > 0 ? change.path.join(' > ') : '(root)'}</code>:{' '}
+                                <code>{formatValue(change.before)}</code> &rarr;{' '}
+                                <code>{formatValue(change.after)}</code>
+                            </li>
+                        ))}
+                    </ul>
+                </details>
+            )}
+        </li>
+    )
+}
+
+class FilteredAuditLogEntryConnection extends FilteredConnection<GQL.IAuditLogEntry, {}> {}
+
+interface Props extends RouteComponentProps<{}> {}
+
+/**
+ * A page displaying the audit log of security-sensitive actions performed on this site.
+ */
+export class SiteAdminAuditLogPage extends React.PureComponent<Props> {
+    private static FILTERS: FilteredConnectionFilter[] = [
+        {
+            label: 'All',
+            id: 'all',
+            tooltip: 'Show all actions',
+            args: {},
+        },
+        {
+            label: 'Configuration',
+            id: 'site-config',
+            tooltip: 'Show only changes to the site configuration',
+            args: { targetType: 'site_config' },
+        },
+        {
+            label: 'Global settings',
+            id: 'settings',
+            tooltip: 'Show only changes to the global settings',
+            args: { targetType: 'settings' },
+        },
+        {
+            label: 'Access tokens',
+            id: 'access-tokens',
+            tooltip: 'Show only access tokens that were created or deleted',
+            args: { targetType: 'access_token' },
+        },
+        {
+            label: 'Site admins',
+            id: 'site-admins',
+            tooltip: 'Show only users who were promoted to or demoted from site admin',
+            args: { targetType: 'user' },
+        },
+        {
+            label: 'External services',
+            id: 'external-services',
+            tooltip: 'Show only external services that were added, updated, or deleted',
+            args: { targetType: 'external_service' },
+        },
+    ]
+
+    public componentDidMount(): void {
+        eventLogger.logViewEvent('SiteAdminAuditLog')
+    }
+
+    public render(): JSX.Element | null {
+        return (
+            <div className="site-admin-audit-log-page">
+                <PageTitle title="Audit log - Admin" />
+                <h2>Audit log</h2>
+                <p>
+                    Security-sensitive actions performed on this site, most recent first. Entries are kept for the
+                    number of days in the <code>auditLog.retentionDays</code>{' '}
+                    <Link to="/site-admin/configuration">site configuration</Link> property (90 by default).
+                </p>
+                <FilteredAuditLogEntryConnection
+                    className="list-group list-group-flush mt-3"
+                    noun="audit log entry"
+                    pluralNoun="audit log entries"
+                    queryConnection={this.queryAuditLog}
+                    nodeComponent={AuditLogEntryNode}
+                    hideSearch={true}
+                    filters={SiteAdminAuditLogPage.FILTERS}
+                    history={this.props.history}
+                    location={this.props.location}
+                />
+            </div>
+        )
+    }
+
+    private queryAuditLog = (args: FilteredConnectionQueryArgs & { targetType?: string }) =>
+        fetchAuditLog({ first: args.first, targetType: args.targetType })
+}
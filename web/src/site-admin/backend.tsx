@@ -554,3 +554,45 @@ export function fetchSiteUpdateCheck(): Observable<{
         map(data => data.site)
     )
 }
+
+/**
+ * Fetches the audit log of security-sensitive actions performed on this site.
+ */
+export function fetchAuditLog(args: {
+    first?: number
+    action?: string
+    targetType?: string
+}): Observable<GQL.IAuditLogEntryConnection> {
+    return queryGraphQL(
+        gql`
+            query AuditLog($first: Int, $action: String, $targetType: String) {
+                site {
+                    auditLog(first: $first, action: $action, targetType: $targetType) {
+                        nodes {
+                            id
+                            createdAt
+                            actor {
+                                username
+                            }
+                            actorUserID
+                            action
+                            targetType
+                            targetID
+                            ip
+                            forwardedFor
+                            diff
+                        }
+                        totalCount
+                        pageInfo {
+                            hasNextPage
+                        }
+                    }
+                }
+            }
+        `,
+        args
+    ).pipe(
+        map(dataOrThrowErrors),
+        map(data => data.site.auditLog)
+    )
+}
@@ -8,6 +8,9 @@ const SiteAdminAddExternalServicesPage = React.lazy(async () => ({
 const SiteAdminAllUsersPage = React.lazy(async () => ({
     default: (await import('./SiteAdminAllUsersPage')).SiteAdminAllUsersPage,
 }))
+const SiteAdminAuditLogPage = React.lazy(async () => ({
+    default: (await import('./SiteAdminAuditLogPage')).SiteAdminAuditLogPage,
+}))
 const SiteAdminConfigurationPage = React.lazy(async () => ({
     default: (await import('./SiteAdminConfigurationPage')).SiteAdminConfigurationPage,
 }))
@@ -108,6 +111,11 @@ export const siteAdminAreaRoutes: ReadonlyArray<SiteAdminAreaRoute> = [
         exact: true,
         render: props => <SiteAdminTokensPage {...props} />,
     },
+    {
+        path: '/audit-log',
+        exact: true,
+        render: props => <SiteAdminAuditLogPage {...props} />,
+    },
     {
         path: '/usage-statistics',
         exact: true,
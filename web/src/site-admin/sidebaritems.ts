@@ -55,6 +55,10 @@ export const authGroup: SiteAdminSideBarGroup = {
             label: 'Access tokens',
             to: '/site-admin/tokens',
         },
+        {
+            label: 'Audit log',
+            to: '/site-admin/audit-log',
+        },
     ],
 }
 
@@ -17,17 +17,22 @@ import { SiteAdminAlert } from '../../../site-admin/SiteAdminAlert'
 import { eventLogger } from '../../../tracking/eventLogger'
 import { UserAreaRouteContext } from '../../area/UserArea'
 
-function createAccessToken(user: GQL.ID, scopes: string[], note: string): Observable<GQL.ICreateAccessTokenResult> {
+function createAccessToken(
+    user: GQL.ID,
+    scopes: string[],
+    note: string,
+    expiresAt: string | null
+): Observable<GQL.ICreateAccessTokenResult> {
     return mutateGraphQL(
         gql`
-            mutation CreateAccessToken($user: ID!, $scopes: [String!]!, $note: String!) {
-                createAccessToken(user: $user, scopes: $scopes, note: $note) {
+            mutation CreateAccessToken($user: ID!, $scopes: [String!]!, $note: String!, $expiresAt: String) {
+                createAccessToken(user: $user, scopes: $scopes, note: $note, expiresAt: $expiresAt) {
                     id
                     token
                 }
             }
         `,
-        { user, scopes, note }
+        { user, scopes, note, expiresAt }
     ).pipe(
         map(({ data, errors }) => {
             if (!data || !data.createAccessToken || (errors && errors.length > 0)) {
@@ -40,6 +45,43 @@ function createAccessToken(user: GQL.ID, scopes: string[], note: string): Observ
     )
 }
 
+interface ScopeOption {
+    scope: AccessTokenScopes
+    description: string
+
+    /** Whether the scope is only shown when creating a token for a site admin. */
+    siteAdminOnly?: boolean
+}
+
+const SCOPE_OPTIONS: ScopeOption[] = [
+    { scope: AccessTokenScopes.UserAll, description: 'Full control of all resources accessible to the user account' },
+    {
+        scope: AccessTokenScopes.UserRead,
+        description: 'Read-only access to all resources accessible to the user account (such as search)',
+    },
+    { scope: AccessTokenScopes.SettingsWrite, description: 'Edit settings (including saved searches)' },
+    { scope: AccessTokenScopes.SavedSearchesWrite, description: 'Create, update, and delete saved searches' },
+    { scope: AccessTokenScopes.ExtensionsPublish, description: 'Create, update, publish, and delete extensions' },
+    {
+        scope: AccessTokenScopes.ExternalServicesWrite,
+        description: 'Add, update, and delete external services',
+        siteAdminOnly: true,
+    },
+    {
+        scope: AccessTokenScopes.SiteAdminSudo,
+        description: 'Ability to perform any action as any other user',
+        siteAdminOnly: true,
+    },
+]
+
+/** The choices for the number of days until the token expires (or 0 for no expiration). */
+const EXPIRATION_DAYS_OPTIONS = [0, 7, 30, 90, 365]
+
+/** Returns the expiration date (in ISO 8601 format) of a token that expires in the given number of days. */
+function expirationDate(days: number): string | null {
+    return days > 0 ? new Date(Date.now() + days * 24 * 60 * 60 * 1000).toISOString() : null
+}
+
 interface Props extends UserAreaRouteContext, RouteComponentProps<{}> {
     /** Called when a new access token is created and should be temporarily displayed to the user. */
     onDidCreateAccessToken: (result: GQL.ICreateAccessTokenResult) => void
@@ -52,6 +94,9 @@ interface State {
     /** The selected scopes checkboxes. */
     scopes: string[]
 
+    /** The number of days until the token expires, or 0 if it never expires. */
+    expirationDays: number
+
     creationOrError?: 'loading' | GQL.ICreateAccessTokenResult | ErrorLike
 }
 
@@ -62,6 +107,7 @@ export class UserSettingsCreateAccessTokenPage extends React.PureComponent<Props
     public state: State = {
         note: '',
         scopes: [AccessTokenScopes.UserAll],
+        expirationDays: 0,
     }
 
     private submits = new Subject<React.FormEvent<HTMLFormElement>>()
@@ -77,7 +123,12 @@ export class UserSettingsCreateAccessTokenPage extends React.PureComponent<Props
                     concatMap(() =>
                         concat(
                             [{ creationOrError: 'loading' }],
-                            createAccessToken(this.props.user.id, this.state.scopes, this.state.note).pipe(
+                            createAccessToken(
+                                this.props.user.id,
+                                this.state.scopes,
+                                this.state.note,
+                                expirationDate(this.state.expirationDays)
+                            ).pipe(
                                 tap(result => {
                                     // Go back to access tokens list page and display the token secret value.
                                     this.props.history.push(`${this.props.match.url.replace(/\/new$/, '')}`)
@@ -130,52 +181,50 @@ export class UserSettingsCreateAccessTokenPage extends React.PureComponent<Props
                         <small className="form-help text-muted">What's this token for?</small>
                     </div>
                     <div className="form-group">
-                        <label className="mb-1" htmlFor="user-settings-create-access-token-page__note">
-                            Token scope
-                        </label>
+                        <label className="mb-1">Token scopes</label>
                         <div>
                             <small className="form-help text-muted">
-                                Tokens with limited user scopes are not yet supported.
+                                All tokens have read-only access. Tokens without the{' '}
+                                <strong>{AccessTokenScopes.UserAll}</strong> scope may only make changes permitted by
+                                their other scopes.
                             </small>
                         </div>
-                        <div className="form-check">
-                            <input
-                                className="form-check-input"
-                                type="checkbox"
-                                id="user-settings-create-access-token-page__scope-user:all"
-                                checked={true}
-                                value={AccessTokenScopes.UserAll}
-                                onChange={this.onScopesChange}
-                                disabled={true}
-                            />
-                            <label
-                                className="form-check-label"
-                                htmlFor="user-settings-create-access-token-page__scope-user:all"
-                            >
-                                <strong>{AccessTokenScopes.UserAll}</strong> — Full control of all resources accessible
-                                to the user account
-                            </label>
-                        </div>
-                        {this.props.user.siteAdmin && (
-                            <div className="form-check">
-                                <input
-                                    className="form-check-input"
-                                    type="checkbox"
-                                    id="user-settings-create-access-token-page__scope-site-admin:sudo"
-                                    checked={this.state.scopes.includes(AccessTokenScopes.SiteAdminSudo)}
-                                    value={AccessTokenScopes.SiteAdminSudo}
-                                    onChange={this.onScopesChange}
-                                />
-                                <label
-                                    className="form-check-label"
-                                    htmlFor="user-settings-create-access-token-page__scope-site-admin:sudo"
-                                >
-                                    <strong>{AccessTokenScopes.SiteAdminSudo}</strong> — Ability to perform any action
-                                    as any other user
-                                </label>
-                            </div>
+                        {SCOPE_OPTIONS.filter(({ siteAdminOnly }) => !siteAdminOnly || this.props.user.siteAdmin).map(
+                            ({ scope, description }) => (
+                                <div className="form-check" key={scope}>
+                                    <input
+                                        className="form-check-input"
+                                        type="checkbox"
+                                        id={`user-settings-create-access-token-page__scope-${scope}`}
+                                        checked={this.state.scopes.includes(scope)}
+                                        value={scope}
+                                        onChange={this.onScopesChange}
+                                    />
+                                    <label
+                                        className="form-check-label"
+                                        htmlFor={`user-settings-create-access-token-page__scope-${scope}`}
+                                    >
+                                        <strong>{scope}</strong> — {description}
+                                    </label>
+                                </div>
+                            )
                         )}
                     </div>
+                    <div className="form-group">
+                        <label htmlFor="user-settings-create-access-token-page__expiration">Expiration</label>
+                        <select
+                            className="form-control"
+                            id="user-settings-create-access-token-page__expiration"
+                            value={this.state.expirationDays}
+                            onChange={this.onExpirationChange}
+                        >
+                            {EXPIRATION_DAYS_OPTIONS.map(days => (
+                                <option key={days} value={days}>
+                                    {days === 0 ? 'Never' : `${days} days`}
+                                </option>
+                            ))}
+                        </select>
+                    </div>
                     <button
                         type="submit"
                         disabled={this.state.creationOrError === 'loading'}
@@ -204,6 +253,9 @@ export class UserSettingsCreateAccessTokenPage extends React.PureComponent<Props
     private onNoteChange: React.ChangeEventHandler<HTMLInputElement> = e =>
         this.setState({ note: e.currentTarget.value })
 
+    private onExpirationChange: React.ChangeEventHandler<HTMLSelectElement> = e =>
+        this.setState({ expirationDays: parseInt(e.currentTarget.value, 10) })
+
     private onScopesChange: React.ChangeEventHandler<HTMLInputElement> = e => {
         const checked = e.currentTarget.checked
         const value = e.currentTarget.value